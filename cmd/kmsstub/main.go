@@ -0,0 +1,70 @@
+// kmsstub is stand-in of KMS service for kms kek provider of keeppas-server, it isn't for production.
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/kek"
+	"github.com/spf13/pflag"
+)
+
+func main() {
+	listenAddr := pflag.StringP("listen", "l", "unix:///tmp/keeppas-kms.sock", "listen address: 'unix:///path' or 'host:port'")
+	keyFile := pflag.StringP("key-file", "k", "", "path to file with key of 16, 24 or 32 bytes, new key is generated when empty")
+	pflag.Parse()
+
+	key, err := stubKey(*keyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	stub, err := kek.NewKMSStub(key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	network, addr := "tcp", *listenAddr
+	if strings.HasPrefix(addr, "unix://") {
+		network, addr = "unix", strings.TrimPrefix(addr, "unix://")
+	}
+	listen, err := net.Listen(network, addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := &http.Server{Handler: stub, ReadHeaderTimeout: 5 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		if err := srv.Shutdown(context.Background()); err != nil {
+			log.Print(err)
+		}
+	}()
+	log.Printf("kms stub listens on %s", *listenAddr)
+	if err := srv.Serve(listen); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// stubKey reads key from file or generates new one, keys wrapped by generated key are lost after restart.
+func stubKey(path string) ([]byte, error) {
+	if path == "" {
+		log.Print("key file isn't set, generated key is lost after restart")
+		return crypto.GenSymmKey(32)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(data, "\r\n"), nil
+}
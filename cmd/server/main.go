@@ -2,9 +2,13 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"os"
 	"os/signal"
 	"sync"
 	"syscall"
@@ -14,15 +18,36 @@ import (
 	"github.com/hrapovd1/gokeepas/internal/crypto"
 	pb "github.com/hrapovd1/gokeepas/internal/proto"
 	"github.com/hrapovd1/gokeepas/internal/server"
+	"github.com/hrapovd1/gokeepas/internal/storage"
+	"github.com/spf13/pflag"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
+const metricsReadTimeout = 5 * time.Second // timeout of reading request headers and shutdown of metrics listener
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		initServer(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 2 && os.Args[1] == "journal" && os.Args[2] == "verify" {
+		os.Exit(verifyJournal(os.Args[3:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "unlock" {
+		unlock(os.Args[2:])
+		return
+	}
 	// create server config
 	srvConfig, err := config.NewServerConf()
+	if errors.Is(err, pflag.ErrHelp) {
+		return
+	}
 	if err != nil {
 		log.Fatalf("error create server configuration: %v", err)
 	}
@@ -37,8 +62,8 @@ func main() {
 		log.Fatalf("when create zap logger got error: %v", err)
 	}
 
-	// generate in-memory tls certificate
-	cert, err := crypto.GenX509KeyPair()
+	// load tls certificate or generate in-memory one
+	cert, err := serverCert(srvConfig)
 	if err != nil {
 		logger.Fatal(err.Error())
 	}
@@ -57,9 +82,25 @@ func main() {
 		}
 	}(logger)
 	// grpc server
-	srv := grpc.NewServer(grpc.Creds(creds), grpc.UnaryInterceptor(gkp.AuthInterceptor))
+	unary := []grpc.UnaryServerInterceptor{gkp.AuthInterceptor}
+	stream := []grpc.StreamServerInterceptor{gkp.StreamAuthInterceptor}
+	if gkp.Metrics != nil {
+		// metrics go first to measure calls rejected by auth
+		unary = append([]grpc.UnaryServerInterceptor{gkp.Metrics.UnaryInterceptor}, unary...)
+		stream = append([]grpc.StreamServerInterceptor{gkp.Metrics.StreamInterceptor}, stream...)
+	}
+	srv := grpc.NewServer(
+		grpc.Creds(creds),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
 	// register app on the server
 	pb.RegisterKeepPasServer(srv, gkp)
+	healthSrv := health.NewServer()
+	healthpb.RegisterHealthServer(srv, healthSrv)
+	if srvConfig.Reflection {
+		reflection.Register(srv)
+	}
 
 	// prepare server shutdown
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
@@ -69,9 +110,48 @@ func main() {
 	if err := gkp.Stor.Ping(ctx, srvConfig.ServerKey); err != nil {
 		logger.Fatal(err.Error())
 	}
+	if len(srvConfig.ServerKey) == 0 {
+		logger.Info("server is sealed, unseal it with 'keeppas operator unseal'")
+	}
 
 	wg := sync.WaitGroup{}
 
+	wg.Add(1)
+	go func(c context.Context, w *sync.WaitGroup) {
+		defer w.Done()
+		gkp.RunJournalCheckpoints(c, server.JournalCheckpointInterval)
+	}(ctx, &wg)
+
+	wg.Add(1)
+	go func(c context.Context, w *sync.WaitGroup) {
+		defer w.Done()
+		gkp.RunHealthChecks(c, healthSrv, server.HealthCheckInterval)
+	}(ctx, &wg)
+
+	if gkp.Metrics != nil {
+		metricsSrv := &http.Server{
+			Addr:              srvConfig.Metrics,
+			Handler:           metricsMux(gkp.Metrics.Handler()),
+			ReadHeaderTimeout: metricsReadTimeout,
+		}
+		wg.Add(1)
+		go func(c context.Context, w *sync.WaitGroup, s *http.Server, l *zap.Logger) {
+			defer w.Done()
+			<-c.Done()
+			sctx, cancel := context.WithTimeout(context.Background(), metricsReadTimeout)
+			defer cancel()
+			if err := s.Shutdown(sctx); err != nil {
+				l.Error(err.Error())
+			}
+		}(ctx, &wg, metricsSrv, logger)
+		go func(s *http.Server, l *zap.Logger) {
+			l.Sugar().Infof("metrics listener started on %s", s.Addr)
+			if err := s.ListenAndServe(); err != http.ErrServerClosed {
+				l.Fatal("failed to listen metrics: " + err.Error())
+			}
+		}(metricsSrv, logger)
+	}
+
 	wg.Add(1)
 	go func(c context.Context, w *sync.WaitGroup, s *grpc.Server, l *zap.Logger) {
 		defer w.Done()
@@ -94,3 +174,116 @@ func main() {
 	wg.Wait()
 	logger.Info("server stoped gracefully")
 }
+
+// metricsMux serves metrics handler on /metrics path
+func metricsMux(h http.Handler) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", h)
+	return mux
+}
+
+// serverCert loads tls certificate from configured files, without them it generates new one.
+func serverCert(conf *config.Config) (tls.Certificate, error) {
+	if conf.TLSCert != "" {
+		return tls.LoadX509KeyPair(conf.TLSCert, conf.TLSKey)
+	}
+	return crypto.GenX509KeyPair()
+}
+
+// initServer splits master key in shares and prints them, shares aren't kept anywhere.
+func initServer(args []string) {
+	conf, err := config.NewInitConf(args, os.Stdin)
+	if err != nil {
+		log.Fatalf("error create init configuration: %v", err)
+	}
+	stor, err := storage.NewRedisStor(*conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		if err := stor.Close(); err != nil {
+			log.Print(err)
+		}
+	}()
+	ctx := context.Background()
+	if err := stor.Ping(ctx, nil); err != nil {
+		log.Fatal(err)
+	}
+	shares, journalKey, err := server.InitSeal(ctx, stor, conf.ServerKey, conf.KeyShares, conf.Threshold)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for i, share := range shares {
+		fmt.Printf("Key share %d: %s\n", i+1, share)
+	}
+	fmt.Printf("\nServer is initialized with %d key shares, %d of them unseal the server.\n", conf.KeyShares, conf.Threshold)
+	fmt.Println("Distribute shares to different operators, they aren't kept by server.")
+	fmt.Printf("\nJournal public key: %s\n", journalKey)
+	fmt.Println("Keep it apart from server, security journal is verified by it: keeppas-server journal verify --journal-key <key>.")
+}
+
+// verifyJournal walks security journal and prints found gaps and modifications,
+// it returns exit code 1 when journal is broken. Records after last checkpoint are only counted.
+func verifyJournal(args []string) int {
+	conf, err := config.NewJournalConf(args)
+	if errors.Is(err, pflag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		log.Fatalf("error create journal configuration: %v", err)
+	}
+	stor, err := storage.NewRedisStor(*conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		if err := stor.Close(); err != nil {
+			log.Print(err)
+		}
+	}()
+	report, err := server.VerifyJournal(context.Background(), stor, conf.JournalKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Checked %d records, last record %d.\n", report.Entries, report.Last)
+	fmt.Printf("Valid checkpoints: %d, records up to %d are signed.\n", report.Checkpoints, report.Signed)
+	if report.Unsigned > 0 {
+		fmt.Printf("Records after last checkpoint: %d, next checkpoint signs them.\n", report.Unsigned)
+	}
+	if len(report.Problems) == 0 {
+		fmt.Println("Journal is intact.")
+		return 0
+	}
+	for _, p := range report.Problems {
+		fmt.Println("PROBLEM: " + p)
+	}
+	return 1
+}
+
+// unlock removes lockout of user or client address after failed logins or wrong key shares,
+// args are "login <name>", "ip <address>" or "share <address>" followed by server settings.
+func unlock(args []string) {
+	if len(args) < 2 {
+		log.Fatalf("usage: keeppas-server unlock %s <name> | %s <address> | %s <address> [flags]", server.LockoutLogin, server.LockoutIP, server.LockoutShare)
+	}
+	conf, err := config.NewUnlockConf(args[2:], os.Stdin)
+	if errors.Is(err, pflag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("error create unlock configuration: %v", err)
+	}
+	stor, err := storage.NewRedisStor(*conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer func() {
+		if err := stor.Close(); err != nil {
+			log.Print(err)
+		}
+	}()
+	if err := server.Unlock(context.Background(), stor, args[0], args[1]); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Failed logins of %s %s are forgotten.\n", args[0], args[1])
+}
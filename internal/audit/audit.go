@@ -0,0 +1,122 @@
+/*
+Package audit contents sinks of audit log, they keep record of every rpc call to server.
+
+Storage sink keeps last events of each user in server storage. File sink appends events to
+JSON-lines file, one event per line, file is opened on each event so it can be rotated.
+*/
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/hrapovd1/gokeepas/internal/types"
+)
+
+// Names of sinks
+const (
+	SinkStorage = "storage" // server storage, default
+	SinkFile    = "file"    // local JSON-lines file
+)
+
+const fileMode = 0600 // permissions of audit file, it shows activity of users
+
+// Sink records audit events and returns them to their users.
+type Sink interface {
+	// Record saves event of rpc call.
+	Record(ctx context.Context, ev *types.AuditEvent) error
+	// List returns up to limit last events of user login after unix milliseconds since,
+	// events are ordered by time. Limit 0 means all events.
+	List(ctx context.Context, login string, since int64, limit int) ([]types.AuditEvent, error)
+}
+
+// Store is part of server storage which keeps audit events.
+type Store interface {
+	AppendAudit(context.Context, string, *types.AuditEvent) error
+	ListAudit(context.Context, string, int64, int) ([]types.AuditEvent, error)
+}
+
+// StorageSink keeps audit events of users in server storage.
+type StorageSink struct {
+	store Store
+}
+
+// NewStorageSink returns sink of server storage.
+func NewStorageSink(store Store) *StorageSink {
+	return &StorageSink{store: store}
+}
+
+// Record saves event in audit log of its user, events of unknown user aren't kept
+// because nobody can read them.
+func (s *StorageSink) Record(ctx context.Context, ev *types.AuditEvent) error {
+	if ev.Login == "" {
+		return nil
+	}
+	return s.store.AppendAudit(ctx, ev.Login, ev)
+}
+
+// List returns last events of user login from storage.
+func (s *StorageSink) List(ctx context.Context, login string, since int64, limit int) ([]types.AuditEvent, error) {
+	return s.store.ListAudit(ctx, login, since, limit)
+}
+
+// FileSink appends audit events to JSON-lines file.
+type FileSink struct {
+	mu   sync.Mutex // serializes writes, so lines aren't mixed
+	path string
+}
+
+// NewFileSink returns sink of file path, file is created on first event.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Record appends event as one line of file.
+func (s *FileSink) Record(_ context.Context, ev *types.AuditEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, fileMode)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// List reads whole file and returns last events of user login, broken lines are skipped.
+func (s *FileSink) List(_ context.Context, login string, since int64, limit int) ([]types.AuditEvent, error) {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var events []types.AuditEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		ev := types.AuditEvent{}
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			continue
+		}
+		if ev.Login != login || ev.Time <= since {
+			continue
+		}
+		events = append(events, ev)
+		if limit > 0 && len(events) > limit {
+			events = events[1:]
+		}
+	}
+	return events, scanner.Err()
+}
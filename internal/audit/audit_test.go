@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSink(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink := NewFileSink(path)
+	events, err := sink.List(ctx, "test", 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	for i, ev := range []types.AuditEvent{
+		{Login: "test", Method: "/gokeepas.KeepPas/Get", Key: "one", Code: "OK", Time: 1},
+		{Login: "other", Method: "/gokeepas.KeepPas/Get", Key: "one", Code: "OK", Time: 2},
+		{Login: "test", Method: "/gokeepas.KeepPas/Add", Key: "two", Code: "OK", Time: 3},
+		{Method: "/gokeepas.KeepPas/LogIn", Code: "Unauthenticated", Peer: "127.0.0.1:5555", Time: 4},
+		{Login: "test", Method: "/gokeepas.KeepPas/Remove", Key: "two", Code: "OK", Time: 5},
+	} {
+		ev := ev
+		require.NoError(t, sink.Record(ctx, &ev), i)
+	}
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(fileMode), info.Mode().Perm())
+
+	events, err = sink.List(ctx, "test", 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []types.AuditEvent{
+		{Login: "test", Method: "/gokeepas.KeepPas/Add", Key: "two", Code: "OK", Time: 3},
+		{Login: "test", Method: "/gokeepas.KeepPas/Remove", Key: "two", Code: "OK", Time: 5},
+	}, events)
+	events, err = sink.List(ctx, "test", 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []types.AuditEvent{{Login: "test", Method: "/gokeepas.KeepPas/Remove", Key: "two", Code: "OK", Time: 5}}, events)
+}
+
+// fakeStore keeps audit events in memory
+type fakeStore struct {
+	events map[string][]types.AuditEvent
+}
+
+func (f *fakeStore) AppendAudit(_ context.Context, login string, ev *types.AuditEvent) error {
+	f.events[login] = append(f.events[login], *ev)
+	return nil
+}
+
+func (f *fakeStore) ListAudit(_ context.Context, login string, _ int64, _ int) ([]types.AuditEvent, error) {
+	return f.events[login], nil
+}
+
+func TestStorageSink(t *testing.T) {
+	ctx := context.Background()
+	store := fakeStore{events: map[string][]types.AuditEvent{}}
+	sink := NewStorageSink(&store)
+	require.NoError(t, sink.Record(ctx, &types.AuditEvent{Login: "test", Method: "/gokeepas.KeepPas/Get", Code: "OK", Time: 1}))
+	// event of unknown user isn't kept
+	require.NoError(t, sink.Record(ctx, &types.AuditEvent{Method: "/gokeepas.KeepPas/LogIn", Code: "Unauthenticated", Time: 2}))
+	assert.Len(t, store.events, 1)
+	events, err := sink.List(ctx, "test", 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
@@ -0,0 +1,24 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func newAccountCmd() *cobra.Command {
+	// accountCmd represents the account command
+	return &cobra.Command{
+		Use:   "account",
+		Short: "Manage user account",
+	}
+}
+
+func newTwoFACmd() *cobra.Command {
+	// twoFACmd represents the account 2fa command
+	return &cobra.Command{
+		Use:   "2fa",
+		Short: "Manage two-factor authentication",
+	}
+}
@@ -6,31 +6,36 @@ package cli
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"strconv"
 	"strings"
 
 	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/otp"
 	pb "github.com/hrapovd1/gokeepas/internal/proto"
 	"github.com/hrapovd1/gokeepas/internal/types"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
-	"google.golang.org/grpc/metadata"
 )
 
 func newKVCmdAdd(clnt *cliClient) *cobra.Command {
 	secrt := rawSecret{delim: `,`}
 	// addCmd represents the add command
 	addCmd := &cobra.Command{
-		Use: `add [-d DELIM] -t TYPE [-e EXTRA] -k KEY VALUE_FIELDS
+		Use: `add [-d DELIM] -t TYPE [-e EXTRA] [-g [GEN_OPTIONS]] -k KEY VALUE_FIELDS
 
 	DELIM: values delimiter, ',' is default
-	Allowed TYPE: login | text | bin | cart
+	Allowed TYPE: login | text | bin | cart | otp
 	KEY: name of secret
 	VALUE_FIELDS:
-		login: LOGIN,PASSWORD
+		login: LOGIN,PASSWORD or LOGIN with -g flag
 		text: TEXT
 		bin: DATA (any binary data)
 		cart: CART_NUMBER,EXPIRED DATA,HOLDER NAME,CVC
+		otp: otpauth://totp/... URI or SECRET[,ALGORITHM,DIGITS,PERIOD]
 	EXTRA: any text
+	GEN_OPTIONS: options of password generation, see 'keeppas gen --help'
 	`,
 		Short: "Add secret on KeepPas server",
 		Long:  `Add secret on KeepPas server.`,
@@ -42,6 +47,10 @@ func newKVCmdAdd(clnt *cliClient) *cobra.Command {
 	addCmd.Flags().StringVarP(&secrt.name, "key", "k", "", "name of secret")
 	addCmd.Flags().StringVarP(&secrt.extra, "extra", "e", "", "extra data of secret")
 	addCmd.Flags().StringVarP(&secrt.delim, "delim", "d", `,`, "values delimiter")
+	addCmd.Flags().BoolVarP(&secrt.generate, "generate", "g", false, "generate password of login secret")
+	addCmd.Flags().BoolVar(&secrt.allowWeak, "allow-weak", false, "store weak or known-breached password")
+	addCmd.Flags().BoolVar(&secrt.offline, "offline", false, "save change in offline journal, it is sent to server later")
+	addGenFlags(addCmd, &secrt.gen)
 
 	return addCmd
 }
@@ -53,42 +62,60 @@ func runAdd(client *cliClient, secret rawSecret, cmd *cobra.Command, args []stri
 		}
 		return
 	}
-	token, err := readToken(client.config.TokenCache, client.logger)
+	// changes are queued in offline journal when server isn't available, defined in cache.go
+	offline := useOffline(client, secret.offline)
+	cache, err := openSession(cmd, client, offline)
 	if err != nil {
 		client.logger.Sugar().Fatal(err)
 	}
-	client.token = token
-	md := metadata.New(map[string]string{"bearer-token": token})
-	cmd.SetContext(metadata.NewOutgoingContext(cmd.Context(), md))
-
-	if err := getUserKey(cmd, client); err != nil {
-		client.logger.Sugar().Fatal(err)
-	}
 	// process request
 	value := strings.Join(args, ` `)
 	if secret.delim != " " {
 		value = strings.Join(args, ``)
 	}
-	req, err := parseValue(client, secret, value)
-	if err != nil {
+	var (
+		passwd  string
+		entropy float64
+	)
+	if secret.generate {
+		if value, passwd, entropy, err = appendPassword(secret, value); err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+	}
+	if err := checkPassword(client, secret, value, os.Stderr); err != nil { // defined in strength.go
 		client.logger.Sugar().Fatal(err)
 	}
-	// process grpc client
-	conn := client.transport(client.config.ServerAddr, client.logger)
-	defer func(l *zap.Logger) {
-		if err := conn.Close(); err != nil {
-			l.Error(err.Error())
-		}
-	}(client.logger)
-	transport := pb.NewKeepPasClient(conn)
-	client.logger.Sugar().Debugf("call add, req: %v", req)
-	// call grpc method
-	resp, err := transport.Add(cmd.Context(), req)
+	req, err := parseValue(client, secret, value)
 	if err != nil {
 		client.logger.Sugar().Fatal(err)
 	}
-	if resp.Error != "" {
-		client.logger.Sugar().Fatal(resp.Error)
+	if offline {
+		op := journalOp{Op: opAdd, Key: req.Key, Type: req.Type, Data: req.Data, DataKey: req.DataKey}
+		if err := queueOp(client.config.OfflineDir, []byte(client.config.UserKey), cache, op); err != nil { // defined in journal.go
+			client.logger.Sugar().Fatal(err)
+		}
+		fmt.Println(offlineQueued)
+	} else {
+		// process grpc client
+		conn := client.transport(client.config.ServerAddr, client.logger)
+		defer func(l *zap.Logger) {
+			if err := conn.Close(); err != nil {
+				l.Error(err.Error())
+			}
+		}(client.logger)
+		transport := pb.NewKeepPasClient(conn)
+		client.logger.Sugar().Debugf("call add, req: %v", req)
+		// call grpc method
+		resp, err := transport.Add(cmd.Context(), req)
+		if err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+		if resp.Error != "" {
+			client.logger.Sugar().Fatal(resp.Error)
+		}
+	}
+	if secret.generate {
+		fmt.Printf("generated password: %s\nentropy: %.1f bits\n", passwd, entropy)
 	}
 }
 
@@ -106,11 +133,10 @@ func parseValue(client *cliClient, rSecret rawSecret, val string) (*pb.BinReques
 		if err != nil {
 			return &request, err
 		}
-		encData, err := crypto.EncryptKey([]byte(client.config.UserKey), rawJSON)
+		request.Data, request.DataKey, err = crypto.SealSecret([]byte(client.config.UserKey), rawJSON, secretAD(client.login, request.Key, request.Type))
 		if err != nil {
 			return &request, err
 		}
-		request.Data = encData
 		return &request, nil
 
 	case "text":
@@ -121,11 +147,10 @@ func parseValue(client *cliClient, rSecret rawSecret, val string) (*pb.BinReques
 		if err != nil {
 			return &request, err
 		}
-		encData, err := crypto.EncryptKey([]byte(client.config.UserKey), rawJSON)
+		request.Data, request.DataKey, err = crypto.SealSecret([]byte(client.config.UserKey), rawJSON, secretAD(client.login, request.Key, request.Type))
 		if err != nil {
 			return &request, err
 		}
-		request.Data = encData
 		return &request, nil
 
 	case "bin":
@@ -136,11 +161,10 @@ func parseValue(client *cliClient, rSecret rawSecret, val string) (*pb.BinReques
 		if err != nil {
 			return &request, err
 		}
-		encData, err := crypto.EncryptKey([]byte(client.config.UserKey), rawJSON)
+		request.Data, request.DataKey, err = crypto.SealSecret([]byte(client.config.UserKey), rawJSON, secretAD(client.login, request.Key, request.Type))
 		if err != nil {
 			return &request, err
 		}
-		request.Data = encData
 		return &request, nil
 
 	case "cart":
@@ -154,11 +178,27 @@ func parseValue(client *cliClient, rSecret rawSecret, val string) (*pb.BinReques
 		if err != nil {
 			return &request, err
 		}
-		encData, err := crypto.EncryptKey([]byte(client.config.UserKey), rawJSON)
+		request.Data, request.DataKey, err = crypto.SealSecret([]byte(client.config.UserKey), rawJSON, secretAD(client.login, request.Key, request.Type))
+		if err != nil {
+			return &request, err
+		}
+		return &request, nil
+
+	case "otp":
+		request.Type = pb.Type_OTP
+		secret, err := parseOTP(val, rSecret.delim)
+		if err != nil {
+			return &request, err
+		}
+		secret.Info = []string{rSecret.extra}
+		rawJSON, err := json.Marshal(secret)
+		if err != nil {
+			return &request, err
+		}
+		request.Data, request.DataKey, err = crypto.SealSecret([]byte(client.config.UserKey), rawJSON, secretAD(client.login, request.Key, request.Type))
 		if err != nil {
 			return &request, err
 		}
-		request.Data = encData
 		return &request, nil
 
 	}
@@ -167,7 +207,8 @@ func parseValue(client *cliClient, rSecret rawSecret, val string) (*pb.BinReques
 
 func parseLogin(val string, delim string) (*types.Login, error) {
 	data := types.Login{}
-	vals := strings.Split(val, delim)
+	// password is the rest of value, so it can contain delimiter
+	vals := strings.SplitN(val, delim, 2)
 	if len(vals) < 2 {
 		return &data, errors.New("not enough fields")
 	}
@@ -201,3 +242,56 @@ func parseCart(val string, delim string) (*types.Cart, error) {
 	// err := json.Unmarshal([]byte(val), &data)
 	return &data, nil
 }
+
+// parseOTP parses otp secret and checks that code can be generated with it.
+func parseOTP(val string, delim string) (*types.OTP, error) {
+	data, err := parseOTPFields(val, delim)
+	if err != nil {
+		return data, err
+	}
+	// check that code can be generated
+	key, err := otpKey(data)
+	if err != nil {
+		return data, err
+	}
+	if _, err := key.CodeAt(0); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+// parseOTPFields parses otpauth:// URI or fields of otp secret delimited by delim.
+func parseOTPFields(val string, delim string) (*types.OTP, error) {
+	if strings.HasPrefix(val, "otpauth://") {
+		key, err := otp.ParseURI(val)
+		if err != nil {
+			return &types.OTP{}, err
+		}
+		return &types.OTP{
+			Secret:    otp.EncodeSecret(key.Secret),
+			Algorithm: key.Algorithm,
+			Digits:    key.Digits,
+			Period:    key.Period,
+			Issuer:    key.Issuer,
+			Account:   key.Account,
+		}, nil
+	}
+	data := types.OTP{Algorithm: otp.DefaultAlgorithm, Digits: otp.DefaultDigits, Period: otp.DefaultPeriod}
+	vals := strings.Split(val, delim)
+	data.Secret = strings.ToUpper(strings.ReplaceAll(vals[0], " ", ""))
+	if len(vals) > 1 && vals[1] != "" {
+		data.Algorithm = strings.ToUpper(vals[1])
+	}
+	var err error
+	if len(vals) > 2 && vals[2] != "" {
+		if data.Digits, err = strconv.Atoi(vals[2]); err != nil {
+			return &data, errors.New("wrong digits")
+		}
+	}
+	if len(vals) > 3 && vals[3] != "" {
+		if data.Period, err = strconv.Atoi(vals[3]); err != nil || data.Period <= 0 {
+			return &data, errors.New("wrong period")
+		}
+	}
+	return &data, nil
+}
@@ -79,6 +79,27 @@ func Test_parseCart(t *testing.T) {
 	})
 }
 
+func Test_parseOTP(t *testing.T) {
+	t.Run("uri", func(t *testing.T) {
+		res, err := parseOTP("otpauth://totp/ACME:john?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&digits=8", ",")
+		require.NoError(t, err)
+		assert.Equal(t, &types.OTP{Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", Algorithm: "SHA1", Digits: 8, Period: 30, Issuer: "ACME", Account: "john"}, res)
+	})
+	t.Run("fields", func(t *testing.T) {
+		res, err := parseOTP("gezd gnbv gy3t qojq,sha256,,60", ",")
+		require.NoError(t, err)
+		assert.Equal(t, &types.OTP{Secret: "GEZDGNBVGY3TQOJQ", Algorithm: "SHA256", Digits: 6, Period: 60}, res)
+	})
+	wrong := []string{"", "1", "GEZDGNBV,MD5", "GEZDGNBV,,x", "GEZDGNBV,,6,0", "GEZDGNBV,,12", "otpauth://totp/john",
+		"otpauth://totp/john?secret=GEZDGNBV&digits=3", "otpauth://totp/john?secret=GEZDGNBV&digits=12"}
+	for _, val := range wrong {
+		t.Run(val, func(t *testing.T) {
+			_, err := parseOTP(val, ",")
+			require.Error(t, err)
+		})
+	}
+}
+
 func Test_parseValue(t *testing.T) {
 	client := cliClient{}
 	client.config.UserKey = "1234567890poiuyt"
@@ -91,6 +112,7 @@ func Test_parseValue(t *testing.T) {
 		{"text", "one two", rawSecret{secretType: "text", name: "text", extra: "l & f"}},
 		{"bin", "one two", rawSecret{secretType: "bin", name: "bin", extra: "l & f"}},
 		{"cart", "vllone_two_one two_123,", rawSecret{secretType: "cart", name: "cart", extra: "l & f", delim: "_"}},
+		{"otp", "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", rawSecret{secretType: "otp", name: "otp", extra: "l & f", delim: ","}},
 	}
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
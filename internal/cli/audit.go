@@ -0,0 +1,102 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/spf13/cobra"
+)
+
+// auditOptions are flags of audit log command
+type auditOptions struct {
+	since   time.Duration
+	limit   int32
+	jsonOut bool
+}
+
+// auditLine is event in output of audit log command
+type auditLine struct {
+	Time   string `json:"time"`
+	Method string `json:"method"`
+	Key    string `json:"key,omitempty"`
+	Code   string `json:"code"`
+	Peer   string `json:"peer,omitempty"`
+}
+
+func newAuditCmd() *cobra.Command {
+	// auditCmd represents the audit command
+	return &cobra.Command{
+		Use:   "audit",
+		Short: "View audit log of your account",
+		Long: `View audit log of your account. Server records every call of your account:
+method, key of secret, result and address of client.`,
+	}
+}
+
+func newAuditCmdLog(clnt *cliClient) *cobra.Command {
+	opts := auditOptions{}
+	// logCmd represents the audit log command
+	logCmd := &cobra.Command{
+		Use:   "log",
+		Short: "Print your audit events",
+		Long: `Print your audit events of last period set by --since, older events are first.
+Default output format is text, you can change output to JSON format with flag -j.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runVault(clnt, cmd, args, 0, func(ctx context.Context, transport pb.KeepPasClient) error {
+				resp, err := transport.GetAuditLog(ctx, &pb.AuditLogRequest{
+					Since: time.Now().Add(-opts.since).UnixMilli(),
+					Limit: opts.limit,
+				})
+				if err != nil {
+					return err
+				}
+				return printAuditLog(resp, opts.jsonOut, os.Stdout)
+			})
+		},
+	}
+	logCmd.Flags().DurationVar(&opts.since, "since", 24*time.Hour, "print events of this last period")
+	logCmd.Flags().Int32Var(&opts.limit, "limit", 0, "max count of last events, 0 means server limit")
+	logCmd.Flags().BoolVarP(&opts.jsonOut, "json", "j", false, "print output in json. Default text format.")
+
+	return logCmd
+}
+
+func printAuditLog(resp *pb.AuditLogResponse, jsonOut bool, out io.Writer) error {
+	lines := make([]auditLine, 0, len(resp.Events))
+	for _, ev := range resp.Events {
+		lines = append(lines, auditLine{
+			Time:   time.UnixMilli(ev.Time).Format(time.RFC3339),
+			Method: path.Base(ev.Method),
+			Key:    ev.Key,
+			Code:   ev.Code,
+			Peer:   ev.Peer,
+		})
+	}
+	if jsonOut {
+		data, err := json.MarshalIndent(lines, "", strings.Repeat(" ", indentCount))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	}
+	if _, err := fmt.Fprintln(out, "===== Audit log ====="); err != nil {
+		return err
+	}
+	for _, l := range lines {
+		if _, err := fmt.Fprintln(out, strings.Join([]string{l.Time, l.Method, l.Key, l.Code, l.Peer}, "  ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_printAuditLog(t *testing.T) {
+	ts := time.Date(2023, 5, 1, 10, 0, 0, 0, time.Local).UnixMilli()
+	stamp := time.UnixMilli(ts).Format(time.RFC3339)
+	resp := pb.AuditLogResponse{Events: []*pb.AuditEvent{
+		{Login: "test", Method: "/gokeepas.KeepPas/Get", Key: "work/vpn", Code: "OK", Peer: "127.0.0.1:5555", Time: ts},
+		{Login: "test", Method: "/gokeepas.KeepPas/LogIn", Code: "Unauthenticated", Peer: "10.0.0.1:4444", Time: ts},
+	}}
+	var out bytes.Buffer
+	require.NoError(t, printAuditLog(&resp, false, &out))
+	assert.Equal(t, "===== Audit log =====\n"+
+		stamp+"  Get  work/vpn  OK  127.0.0.1:5555\n"+
+		stamp+"  LogIn    Unauthenticated  10.0.0.1:4444\n", out.String())
+	out.Reset()
+	require.NoError(t, printAuditLog(&resp, true, &out))
+	assert.JSONEq(t, `[
+		{"time":"`+stamp+`","method":"Get","key":"work/vpn","code":"OK","peer":"127.0.0.1:5555"},
+		{"time":"`+stamp+`","method":"LogIn","code":"Unauthenticated","peer":"10.0.0.1:4444"}
+	]`, out.String())
+}
@@ -0,0 +1,409 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/kek"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	offlineKeyFile     = "key"             // file of user key wrapped by password in offline dir
+	offlineCacheFile   = "secrets"         // file of encrypted local replica of secrets in offline dir
+	offlineDialTimeout = 3 * time.Second   // timeout of server availability check
+	offlineFileMode    = os.FileMode(0600) // mode of offline files, they are readable by owner only
+)
+
+var (
+	// errVaultOffline returns when offline cache is used with vault
+	errVaultOffline = errors.New("offline cache keeps own secrets only")
+	// errNoOfflineCache returns when offline read is requested before login
+	errNoOfflineCache = errors.New("offline cache isn't initialized, login and run 'keeppas sync'")
+)
+
+// offlineKey is user key wrapped by key derived from user password, it allows to open
+// local replica without server.
+type offlineKey struct {
+	Login string `json:"login"`
+	Key   string `json:"key"`
+}
+
+// localCache is local replica of user secrets, secrets stay encrypted by their data keys
+// and whole replica is encrypted by user key.
+type localCache struct {
+	Login   string                  `json:"login"`
+	Seq     int64                   `json:"seq"` // sequence number of last applied server change
+	Secrets map[string]cachedSecret `json:"secrets"`
+}
+
+// cachedSecret is secret as it is got from server
+type cachedSecret struct {
+	Type    pb.Type `json:"type"`
+	Data    []byte  `json:"data"`
+	DataKey string  `json:"datakey,omitempty"`
+}
+
+func newSyncCmd(clnt *cliClient) *cobra.Command {
+	// syncCmd represents the sync command
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync offline cache of secrets",
+		Long: `Sync local encrypted replica of own secrets with KeepPas server. Only changes made after
+previous sync are got, all secrets are got on first sync or when server doesn't keep old changes.
+Commands 'kv get' and 'kv list' read replica when server isn't available or with flag --offline,
+replica is opened by user password.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runSync(clnt, cmd)
+		},
+	}
+
+	return syncCmd
+}
+
+func runSync(client *cliClient, cmd *cobra.Command) {
+	if err := setAuthContext(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := getUserKey(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	userKey := []byte(client.config.UserKey)
+	cache, err := readCache(client.config.OfflineDir, userKey, client.login)
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	transport := pb.NewKeepPasClient(conn)
+	// send changes made offline, defined in journal.go
+	if err := replayJournal(cmd.Context(), client, transport, os.Stderr); err != nil {
+		client.logger.Sugar().Error(err)
+	}
+	applied, err := syncCache(cmd.Context(), transport, cache)
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := writeCache(client.config.OfflineDir, userKey, cache); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	fmt.Printf("synced %d changes, %d secrets in cache\n", applied, len(cache.Secrets))
+}
+
+// offlineDir returns default directory of offline cache: keeppas in user config dir
+func offlineDir(home string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(home, ".keeppas")
+	}
+	return filepath.Join(dir, "keeppas")
+}
+
+// syncCache applies server changes after cache.Seq to cache and returns count of applied
+// changes. All secrets are got again when server asks reset.
+func syncCache(ctx context.Context, transport pb.KeepPasClient, cache *localCache) (int, error) {
+	applied := 0
+	for {
+		resp, err := transport.Changes(ctx, &pb.ChangesRequest{Since: cache.Seq})
+		if err != nil {
+			return applied, err
+		}
+		if resp.Reset_ {
+			// changes made during getting of secrets are applied again by next sync
+			entries, err := listSecrets(ctx, transport, &pb.ListRequest{PageSize: listPageSize})
+			if err != nil {
+				return applied, err
+			}
+			keys := make([]string, 0, len(entries))
+			for _, e := range entries {
+				keys = append(keys, e.Name)
+			}
+			secrets, err := fetchSecrets(ctx, transport, keys)
+			if err != nil {
+				return applied, err
+			}
+			cache.Secrets, cache.Seq = secrets, resp.Seq
+			return applied + len(secrets), nil
+		}
+		// the last change of key wins: true means get secret, false means remove it
+		changed := make(map[string]bool)
+		for _, ch := range resp.Changes {
+			switch ch.Kind {
+			case pb.WatchEvent_ADD, pb.WatchEvent_UPDATE:
+				changed[ch.Key] = true
+			case pb.WatchEvent_RENAME:
+				changed[ch.Key] = false
+				changed[ch.NewKey] = true
+			case pb.WatchEvent_REMOVE:
+				changed[ch.Key] = false
+			}
+		}
+		var keys []string
+		for key, get := range changed {
+			if get {
+				keys = append(keys, key)
+			}
+			delete(cache.Secrets, key)
+		}
+		secrets, err := fetchSecrets(ctx, transport, keys)
+		if err != nil {
+			return applied, err
+		}
+		for key, s := range secrets {
+			cache.Secrets[key] = s
+		}
+		if n := len(resp.Changes); n > 0 {
+			cache.Seq = resp.Changes[n-1].Seq
+			applied += n
+		}
+		if !resp.More {
+			return applied, nil
+		}
+	}
+}
+
+// fetchSecrets gets encrypted secrets of keys by batches, removed secrets are skipped.
+func fetchSecrets(ctx context.Context, transport pb.KeepPasClient, keys []string) (map[string]cachedSecret, error) {
+	out := make(map[string]cachedSecret, len(keys))
+	for start := 0; start < len(keys); start += searchBatch {
+		end := start + searchBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		resp, err := transport.GetMany(ctx, &pb.BatchRequest{Items: batchItems(keys[start:end])})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range resp.Items {
+			switch codes.Code(item.Code) {
+			case codes.OK:
+				out[item.Key] = cachedSecret{Type: item.Secret.Type, Data: item.Secret.Data, DataKey: item.Secret.DataKey}
+			case codes.NotFound:
+			default:
+				return nil, fmt.Errorf("%s: %s", item.Key, item.Error)
+			}
+		}
+	}
+	return out, nil
+}
+
+// cacheAD returns associated data which binds local replica to its owner
+func cacheAD(login string) []byte {
+	return crypto.AssociatedData(login, "offline-cache")
+}
+
+// saveOfflineKey keeps user key wrapped by password in offline dir. Replica of other user
+// is removed, journal of the user is kept until the user logs in again, see journalPath.
+func saveOfflineKey(dir string, login string, password string, userKey []byte) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	if old, err := readOfflineKey(dir); err == nil && old.Login != login {
+		if err := os.Remove(filepath.Join(dir, offlineCacheFile)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	pp, err := kek.NewPassphrase([]byte(password))
+	if err != nil {
+		return err
+	}
+	wrapped, err := pp.Wrap(context.Background(), userKey)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(offlineKey{Login: login, Key: wrapped})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, offlineKeyFile), data)
+}
+
+func readOfflineKey(dir string) (*offlineKey, error) {
+	data, err := os.ReadFile(filepath.Join(dir, offlineKeyFile))
+	if err != nil {
+		return nil, err
+	}
+	key := offlineKey{}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// openOfflineKey returns login and user key unwrapped by password
+func openOfflineKey(dir string, password string) (string, []byte, error) {
+	key, err := readOfflineKey(dir)
+	if os.IsNotExist(err) {
+		return "", nil, errNoOfflineCache
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	pp, err := kek.NewPassphrase([]byte(password))
+	if err != nil {
+		return "", nil, err
+	}
+	userKey, err := pp.Unwrap(context.Background(), key.Key)
+	if err != nil {
+		return "", nil, errors.New("wrong password")
+	}
+	return key.Login, userKey, nil
+}
+
+// readCache decrypts local replica of login secrets, empty replica is returned when it doesn't exist.
+func readCache(dir string, userKey []byte, login string) (*localCache, error) {
+	empty := localCache{Login: login, Secrets: make(map[string]cachedSecret)}
+	data, err := os.ReadFile(filepath.Join(dir, offlineCacheFile))
+	if os.IsNotExist(err) {
+		return &empty, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	plain, err := crypto.DecryptData(userKey, string(data), cacheAD(login))
+	if err != nil {
+		// replica of other user or of old user key is replaced by sync
+		return &empty, nil
+	}
+	cache := localCache{}
+	if err := json.Unmarshal(plain, &cache); err != nil {
+		return nil, err
+	}
+	if cache.Secrets == nil {
+		cache.Secrets = make(map[string]cachedSecret)
+	}
+	return &cache, nil
+}
+
+// writeCache encrypts local replica by user key and replaces file of replica
+func writeCache(dir string, userKey []byte, cache *localCache) error {
+	plain, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	data, err := crypto.EncryptData(userKey, plain, cacheAD(cache.Login))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, offlineCacheFile), []byte(data))
+}
+
+// writeFileAtomic writes data into temporary file and renames it to path, so reader never
+// gets partly written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, offlineFileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// useOffline reports offline cache should be read: flag --offline is set or server isn't
+// available and cache exists.
+func useOffline(client *cliClient, forced bool) bool {
+	if forced {
+		return true
+	}
+	if _, err := os.Stat(filepath.Join(client.config.OfflineDir, offlineCacheFile)); err != nil {
+		return false
+	}
+	conn, err := net.DialTimeout("tcp", client.config.ServerAddr, offlineDialTimeout)
+	if err != nil {
+		client.logger.Sugar().Infof("server isn't available, secrets are read from offline cache: %v", err)
+		return true
+	}
+	if err := conn.Close(); err != nil {
+		client.logger.Sugar().Debug(err)
+	}
+	return false
+}
+
+// openOfflineCache asks user password and opens local replica
+func openOfflineCache(cmd *cobra.Command, client *cliClient) (*localCache, error) {
+	if client.vault != "" {
+		return nil, errVaultOffline
+	}
+	password, err := promptLine(cmd.InOrStdin(), "password: ")
+	if err != nil {
+		return nil, err
+	}
+	login, userKey, err := openOfflineKey(client.config.OfflineDir, password)
+	if err != nil {
+		return nil, err
+	}
+	client.config.UserKey, client.login = string(userKey), login
+	return readCache(client.config.OfflineDir, userKey, login)
+}
+
+// openSession opens local replica in offline mode, otherwise it authenticates on server,
+// gets user key and sends offline changes made before.
+func openSession(cmd *cobra.Command, client *cliClient, offline bool) (*localCache, error) {
+	if offline {
+		return openOfflineCache(cmd, client)
+	}
+	if err := setAuthContext(cmd, client); err != nil {
+		return nil, err
+	}
+	if err := getUserKey(cmd, client); err != nil {
+		return nil, err
+	}
+	replayPending(cmd, client) // defined in journal.go
+	return nil, nil
+}
+
+// cacheItems returns secrets of keys from local replica in form of GetMany response
+func cacheItems(cache *localCache, keys []string) []*pb.BatchItem {
+	items := make([]*pb.BatchItem, len(keys))
+	for i, key := range keys {
+		items[i] = &pb.BatchItem{Key: key}
+		s, ok := cache.Secrets[key]
+		if !ok {
+			items[i].Code, items[i].Error = int32(codes.NotFound), "key isn't in offline cache"
+			continue
+		}
+		items[i].Secret = &pb.GetResponse{Key: key, Type: s.Type, Data: s.Data, DataKey: s.DataKey}
+	}
+	return items
+}
+
+// cacheEntries returns secrets of local replica matched list request sorted by name
+func cacheEntries(cache *localCache, req *pb.ListRequest) []*pb.ListEntry {
+	types := make(map[pb.Type]bool, len(req.Types))
+	for _, t := range req.Types {
+		types[t] = true
+	}
+	var entries []*pb.ListEntry
+	for name, s := range cache.Secrets {
+		if !strings.HasPrefix(name, req.Prefix) || (len(types) > 0 && !types[s.Type]) {
+			continue
+		}
+		entries = append(entries, &pb.ListEntry{Name: name, Type: s.Type, DataKey: s.DataKey != ""})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// fakeChangesClient returns prepared pages of changes and keeps secrets in memory
+type fakeChangesClient struct {
+	pb.KeepPasClient
+	pages   []*pb.ChangesResponse
+	secrets map[string]*pb.GetResponse
+	since   []int64
+}
+
+func (f *fakeChangesClient) Changes(_ context.Context, in *pb.ChangesRequest, _ ...grpc.CallOption) (*pb.ChangesResponse, error) {
+	f.since = append(f.since, in.Since)
+	resp := f.pages[0]
+	f.pages = f.pages[1:]
+	return resp, nil
+}
+
+func (f *fakeChangesClient) List(_ context.Context, _ *pb.ListRequest, _ ...grpc.CallOption) (*pb.ListResponse, error) {
+	resp := pb.ListResponse{}
+	for name, s := range f.secrets {
+		resp.Entries = append(resp.Entries, &pb.ListEntry{Name: name, Type: s.Type})
+	}
+	return &resp, nil
+}
+
+func (f *fakeChangesClient) GetMany(_ context.Context, in *pb.BatchRequest, _ ...grpc.CallOption) (*pb.BatchResponse, error) {
+	resp := pb.BatchResponse{}
+	for _, item := range in.Items {
+		s, ok := f.secrets[item.Key]
+		if !ok {
+			resp.Items = append(resp.Items, &pb.BatchItem{Key: item.Key, Code: int32(codes.NotFound)})
+			continue
+		}
+		resp.Items = append(resp.Items, &pb.BatchItem{Key: item.Key, Secret: s})
+	}
+	return &resp, nil
+}
+
+func Test_syncCache(t *testing.T) {
+	ctx := context.Background()
+	transport := fakeChangesClient{secrets: map[string]*pb.GetResponse{
+		"one":   {Key: "one", Type: pb.Type_TEXT, Data: []byte("enc1"), DataKey: "dk1"},
+		"two":   {Key: "two", Type: pb.Type_LOGIN, Data: []byte("enc2")},
+		"three": {Key: "three", Type: pb.Type_CART, Data: []byte("enc3"), DataKey: "dk3"},
+	}}
+	cache := localCache{Login: "test", Secrets: map[string]cachedSecret{}}
+
+	t.Run("reset", func(t *testing.T) {
+		transport.pages = []*pb.ChangesResponse{{Seq: 7, Reset_: true}}
+		applied, err := syncCache(ctx, &transport, &cache)
+		require.NoError(t, err)
+		assert.Equal(t, 3, applied)
+		assert.Equal(t, int64(7), cache.Seq)
+		assert.Equal(t, cachedSecret{Type: pb.Type_TEXT, Data: []byte("enc1"), DataKey: "dk1"}, cache.Secrets["one"])
+		assert.Len(t, cache.Secrets, 3)
+	})
+	t.Run("deltas", func(t *testing.T) {
+		transport.since = nil
+		transport.secrets["one"] = &pb.GetResponse{Key: "one", Type: pb.Type_TEXT, Data: []byte("enc1.2"), DataKey: "dk1.2"}
+		transport.secrets["four"] = transport.secrets["two"]
+		delete(transport.secrets, "two")
+		delete(transport.secrets, "three")
+		transport.pages = []*pb.ChangesResponse{
+			{Seq: 11, More: true, Changes: []*pb.WatchEvent{
+				{Kind: pb.WatchEvent_UPDATE, Key: "one", Seq: 8},
+				{Kind: pb.WatchEvent_RENAME, Key: "two", NewKey: "four", Seq: 9},
+			}},
+			{Seq: 11, Changes: []*pb.WatchEvent{
+				{Kind: pb.WatchEvent_ADD, Key: "five", Seq: 10},
+				{Kind: pb.WatchEvent_REMOVE, Key: "five", Seq: 11},
+				{Kind: pb.WatchEvent_REMOVE, Key: "three", Seq: 12},
+			}},
+		}
+		applied, err := syncCache(ctx, &transport, &cache)
+		require.NoError(t, err)
+		assert.Equal(t, 5, applied)
+		assert.Equal(t, []int64{7, 9}, transport.since)
+		assert.Equal(t, int64(12), cache.Seq)
+		assert.Equal(t, map[string]cachedSecret{
+			"one":  {Type: pb.Type_TEXT, Data: []byte("enc1.2"), DataKey: "dk1.2"},
+			"four": {Type: pb.Type_LOGIN, Data: []byte("enc2")},
+		}, cache.Secrets)
+	})
+	t.Run("up to date", func(t *testing.T) {
+		transport.pages = []*pb.ChangesResponse{{Seq: 12}}
+		applied, err := syncCache(ctx, &transport, &cache)
+		require.NoError(t, err)
+		assert.Equal(t, 0, applied)
+		assert.Equal(t, int64(12), cache.Seq)
+	})
+}
+
+func Test_offlineKey(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keeppas")
+	userKey := []byte("1234567890poiuyt")
+	require.NoError(t, saveOfflineKey(dir, "test", "secret", userKey))
+	login, key, err := openOfflineKey(dir, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "test", login)
+	assert.Equal(t, userKey, key)
+	_, _, err = openOfflineKey(dir, "wrong")
+	assert.Error(t, err)
+	_, _, err = openOfflineKey(t.TempDir(), "secret")
+	assert.ErrorIs(t, err, errNoOfflineCache)
+
+	t.Run("other user", func(t *testing.T) {
+		require.NoError(t, writeCache(dir, userKey, &localCache{Login: "test"}))
+		require.NoError(t, saveJournal(dir, userKey, &writeJournal{Login: "test", Ops: []journalOp{{Op: opRemove, Key: "one"}}}))
+		require.NoError(t, saveOfflineKey(dir, "test", "secret", userKey))
+		assert.FileExists(t, filepath.Join(dir, offlineCacheFile))
+		require.NoError(t, saveOfflineKey(dir, "other", "secret", userKey))
+		assert.NoFileExists(t, filepath.Join(dir, offlineCacheFile))
+		// journal of other user is empty, changes of the first user wait for the user
+		journal, err := readJournal(dir, []byte("0987654321qwerty"), "other")
+		require.NoError(t, err)
+		assert.Empty(t, journal.Ops)
+		journal, err = readJournal(dir, userKey, "test")
+		require.NoError(t, err)
+		assert.Len(t, journal.Ops, 1)
+	})
+}
+
+func Test_readCache(t *testing.T) {
+	dir := t.TempDir()
+	userKey := []byte("1234567890poiuyt")
+	cache, err := readCache(dir, userKey, "test")
+	require.NoError(t, err)
+	assert.Equal(t, &localCache{Login: "test", Secrets: map[string]cachedSecret{}}, cache)
+
+	cache.Seq = 3
+	cache.Secrets["one"] = cachedSecret{Type: pb.Type_OTP, Data: []byte("enc"), DataKey: "dk"}
+	require.NoError(t, writeCache(dir, userKey, cache))
+	info, err := os.Stat(filepath.Join(dir, offlineCacheFile))
+	require.NoError(t, err)
+	assert.Equal(t, offlineFileMode, info.Mode().Perm())
+	got, err := readCache(dir, userKey, "test")
+	require.NoError(t, err)
+	assert.Equal(t, cache, got)
+
+	// replica of other user isn't opened
+	got, err = readCache(dir, userKey, "other")
+	require.NoError(t, err)
+	assert.Empty(t, got.Secrets)
+}
+
+func Test_cacheEntries(t *testing.T) {
+	cache := localCache{Secrets: map[string]cachedSecret{
+		"work/vpn":  {Type: pb.Type_LOGIN, DataKey: "dk"},
+		"work/card": {Type: pb.Type_CART},
+		"home/wifi": {Type: pb.Type_LOGIN},
+	}}
+	entries := cacheEntries(&cache, &pb.ListRequest{Prefix: "work/"})
+	assert.Equal(t, []*pb.ListEntry{
+		{Name: "work/card", Type: pb.Type_CART},
+		{Name: "work/vpn", Type: pb.Type_LOGIN, DataKey: true},
+	}, entries)
+	entries = cacheEntries(&cache, &pb.ListRequest{Types: []pb.Type{pb.Type_LOGIN}})
+	require.Len(t, entries, 2)
+	assert.Equal(t, "home/wifi", entries[0].Name)
+
+	items := cacheItems(&cache, []string{"work/vpn", "none"})
+	assert.Equal(t, &pb.GetResponse{Key: "work/vpn", Type: pb.Type_LOGIN, DataKey: "dk"}, items[0].Secret)
+	assert.Equal(t, int32(codes.NotFound), items[1].Code)
+}
@@ -71,10 +71,17 @@ func runCp(client *cliClient, delim string, cmd *cobra.Command, args []string) {
 		}
 	}(client.logger)
 	transport := pb.NewKeepPasClient(conn)
+	// secret is bound to its name, so its data key has to be re-wrapped for new name
+	data, dataKey, err := reencryptSecret(cmd, client, transport, values[0], values[1]) // defined in rename.go
+	if err != nil {
+		client.logger.Sugar().Fatalln(err)
+	}
 	// call grpc method
 	resp, err := transport.Copy(cmd.Context(), &pb.BinRequest{
-		Key:    values[0],
-		NewKey: values[1],
+		Key:     values[0],
+		NewKey:  values[1],
+		Data:    data,
+		DataKey: dataKey,
 	})
 	if err != nil {
 		client.logger.Sugar().Fatalln(err)
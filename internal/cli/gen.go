@@ -0,0 +1,90 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hrapovd1/gokeepas/internal/passgen"
+	"github.com/spf13/cobra"
+)
+
+func newGenCmd(clnt *cliClient) *cobra.Command {
+	opts := genOptions{}
+	// genCmd represents the gen command
+	genCmd := &cobra.Command{
+		Use:   "gen [-l LENGTH] [--no-lower] [--no-upper] [--no-digits] [--no-symbols] [--exclude-ambiguous] [-w WORDS [--separator SEP]]",
+		Short: "Generate random password",
+		Long: `Generate random password or diceware passphrase with crypto/rand.
+Password is printed to stdout, its entropy is printed to stderr.
+Passphrase is generated from EFF large wordlist when words count is set with flag -w.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runGen(opts, os.Stdout, os.Stderr); err != nil {
+				clnt.logger.Sugar().Fatal(err)
+			}
+		},
+	}
+	addGenFlags(genCmd, &opts)
+
+	return genCmd
+}
+
+func addGenFlags(cmd *cobra.Command, opts *genOptions) {
+	cmd.Flags().IntVarP(&opts.length, "length", "l", passgen.DefaultLength, "length of generated password")
+	cmd.Flags().BoolVar(&opts.noLower, "no-lower", false, "don't use lower case letters")
+	cmd.Flags().BoolVar(&opts.noUpper, "no-upper", false, "don't use upper case letters")
+	cmd.Flags().BoolVar(&opts.noDigits, "no-digits", false, "don't use digits")
+	cmd.Flags().BoolVar(&opts.noSymbols, "no-symbols", false, "don't use symbols")
+	cmd.Flags().BoolVar(&opts.excludeAmbiguous, "exclude-ambiguous", false, "exclude ambiguous characters like I, l, 1, O, 0")
+	cmd.Flags().BoolVar(&opts.requireEach, "require-each", true, "password contains at least one character of each used class")
+	cmd.Flags().IntVarP(&opts.words, "words", "w", 0, "generate passphrase of WORDS words instead of password")
+	cmd.Flags().StringVar(&opts.separator, "separator", passgen.DefaultSeparator, "separator of passphrase words")
+}
+
+func runGen(opts genOptions, out io.Writer, info io.Writer) error {
+	passwd, entropy, err := opts.generate()
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(out, passwd); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(info, "entropy: %.1f bits\n", entropy)
+	return err
+}
+
+// generate returns password or passphrase by options and its entropy in bits.
+func (opts genOptions) generate() (string, float64, error) {
+	if opts.words > 0 {
+		return passgen.Passphrase(opts.words, opts.separator)
+	}
+	return passgen.Generate(passgen.Policy{
+		Length:           opts.length,
+		Lower:            !opts.noLower,
+		Upper:            !opts.noUpper,
+		Digits:           !opts.noDigits,
+		Symbols:          !opts.noSymbols,
+		ExcludeAmbiguous: opts.excludeAmbiguous,
+		RequireEach:      opts.requireEach,
+	})
+}
+
+// appendPassword adds generated password to LOGIN value of login secret.
+func appendPassword(secret rawSecret, value string) (string, string, float64, error) {
+	if secret.secretType != "login" {
+		return value, "", 0, errors.New("password can be generated for login secrets only")
+	}
+	if strings.Contains(value, secret.delim) {
+		return value, "", 0, errors.New("password is provided with generate flag, provide LOGIN only")
+	}
+	passwd, entropy, err := secret.gen.generate()
+	if err != nil {
+		return value, "", 0, err
+	}
+	return value + secret.delim + passwd, passwd, entropy, nil
+}
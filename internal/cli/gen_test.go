@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_runGen(t *testing.T) {
+	t.Run("password", func(t *testing.T) {
+		var out, info bytes.Buffer
+		opts := genOptions{length: 16, noSymbols: true, requireEach: true}
+		require.NoError(t, runGen(opts, &out, &info))
+		assert.Len(t, strings.TrimSpace(out.String()), 16)
+		assert.Contains(t, info.String(), "entropy: ")
+	})
+	t.Run("passphrase", func(t *testing.T) {
+		var out, info bytes.Buffer
+		opts := genOptions{words: 4, separator: " "}
+		require.NoError(t, runGen(opts, &out, &info))
+		assert.Len(t, strings.Fields(out.String()), 4)
+		assert.Equal(t, "entropy: 51.7 bits\n", info.String())
+	})
+	t.Run("wrong options", func(t *testing.T) {
+		opts := genOptions{length: 10, noLower: true, noUpper: true, noDigits: true, noSymbols: true}
+		require.Error(t, runGen(opts, &bytes.Buffer{}, &bytes.Buffer{}))
+	})
+}
+
+func Test_appendPassword(t *testing.T) {
+	gen := genOptions{length: 12, requireEach: true}
+	t.Run("login", func(t *testing.T) {
+		secret := rawSecret{secretType: "login", delim: ",", gen: gen}
+		value, passwd, entropy, err := appendPassword(secret, "user")
+		require.NoError(t, err)
+		assert.Equal(t, "user,"+passwd, value)
+		assert.Greater(t, entropy, 0.0)
+		login, err := parseLogin(value, secret.delim)
+		require.NoError(t, err)
+		assert.Equal(t, passwd, login.Password)
+	})
+	t.Run("password is provided", func(t *testing.T) {
+		_, _, _, err := appendPassword(rawSecret{secretType: "login", delim: ",", gen: gen}, "user,pass")
+		require.Error(t, err)
+	})
+	t.Run("wrong type", func(t *testing.T) {
+		_, _, _, err := appendPassword(rawSecret{secretType: "text", delim: ",", gen: gen}, "user")
+		require.Error(t, err)
+	})
+}
@@ -16,22 +16,32 @@ import (
 	"github.com/hrapovd1/gokeepas/internal/types"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 )
 
 func newKVCmdGet(clnt *cliClient) *cobra.Command {
 	getOutJSON := false
+	offline := false
 	// getCmd represents the get command
 	getCmd := &cobra.Command{
-		Use:   "get KEY",
+		Use:   "get KEY [KEY...]",
 		Short: "Get secret from KeepPas server",
 		Long: `Get secret from KeepPas server.
-Default output format is text, you can change output to JSON format with flag -j.`,
+Several keys are got by one request, secrets which can't be got are reported and the rest are printed.
+Default output format is text, you can change output to JSON format with flag -j,
+several secrets are printed as one JSON object by keys.
+Secrets are read from offline cache when server isn't available or with flag --offline.`,
 		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) > 0 && useOffline(clnt, offline) {
+				runGetOffline(clnt, getOutJSON, cmd, args)
+				return
+			}
 			runGet(clnt, getOutJSON, cmd, args)
 		},
 	}
 	getCmd.Flags().BoolVarP(&getOutJSON, "json", "j", false, "print output in json. Default text format.")
+	getCmd.Flags().BoolVar(&offline, "offline", false, "read secrets from offline cache, see 'keeppas sync'")
 
 	return getCmd
 }
@@ -54,11 +64,6 @@ func runGet(client *cliClient, jsonOut bool, cmd *cobra.Command, args []string)
 	if err := getUserKey(cmd, client); err != nil {
 		client.logger.Sugar().Fatal(err)
 	}
-	// process request
-	value := strings.Join(args, ``)
-	req := pb.BinRequest{
-		Key: value,
-	}
 	// process grpc client
 	conn := client.transport(client.config.ServerAddr, client.logger)
 	defer func(l *zap.Logger) {
@@ -67,27 +72,120 @@ func runGet(client *cliClient, jsonOut bool, cmd *cobra.Command, args []string)
 		}
 	}(client.logger)
 	transport := pb.NewKeepPasClient(conn)
+	if len(args) > 1 {
+		resp, err := transport.GetMany(cmd.Context(), &pb.BatchRequest{Items: batchItems(args)})
+		if err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+		if err := printValues(resp.Items, jsonOut, client.config.UserKey, client.login, client.logger); err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+		return
+	}
 	// call grpc method
-	resp, err := transport.Get(cmd.Context(), &req)
+	resp, err := transport.Get(cmd.Context(), &pb.BinRequest{Key: args[0]})
 	if err != nil {
 		client.logger.Sugar().Fatal(err)
 	}
-	if err := printValue(resp, jsonOut, client.config.UserKey, client.logger); err != nil {
+	if err := printValue(resp, jsonOut, client.config.UserKey, client.login, client.logger); err != nil {
 		client.logger.Sugar().Fatal(err)
 	}
 }
 
-func printValue(r *pb.GetResponse, jsonFmt bool, key string, l *zap.Logger) error {
+// runGetOffline prints secrets from offline cache
+func runGetOffline(client *cliClient, jsonOut bool, cmd *cobra.Command, args []string) {
+	cache, err := openOfflineCache(cmd, client) // defined in cache.go
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	items := cacheItems(cache, args)
+	if len(args) > 1 {
+		if err := printValues(items, jsonOut, client.config.UserKey, client.login, client.logger); err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+		return
+	}
+	if items[0].Secret == nil {
+		client.logger.Sugar().Fatalf("%s: %s", items[0].Key, items[0].Error)
+	}
+	if err := printValue(items[0].Secret, jsonOut, client.config.UserKey, client.login, client.logger); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+}
+
+func printValue(r *pb.GetResponse, jsonFmt bool, key string, owner string, l *zap.Logger) error {
 	if r.Key == "" || r.Type.String() == "" {
 		l.Sugar().Debug("empty response")
 		return errors.New("empty response")
 	}
-	secret, err := crypto.DecryptKey([]byte(key), string(r.Data))
+	secret, err := crypto.OpenSecret([]byte(key), string(r.Data), r.DataKey, secretAD(owner, r.Key, r.Type))
 	if err != nil {
 		l.Sugar().Debug(err)
-		return err
+		return upgradeHint(err) // defined in upgrade.go
+	}
+	return printSecret(secret, r.Type, jsonFmt, l)
+}
+
+// batchItems returns items of batch request for keys
+func batchItems(keys []string) []*pb.BinRequest {
+	items := make([]*pb.BinRequest, len(keys))
+	for i, key := range keys {
+		items[i] = &pb.BinRequest{Key: key}
+	}
+	return items
+}
+
+// batchError logs failed items of batch response and returns error if any item failed
+func batchError(items []*pb.BatchItem, l *zap.Logger) error {
+	failed := 0
+	for _, item := range items {
+		if codes.Code(item.Code) != codes.OK {
+			l.Sugar().Errorf("%s: %s", item.Key, item.Error)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d keys failed", failed, len(items))
+	}
+	return nil
+}
+
+// printValues prints secrets of GetMany, secrets which can't be decrypted are reported as failed.
+func printValues(items []*pb.BatchItem, jsonFmt bool, key string, owner string, l *zap.Logger) error {
+	secrets := make(map[string]json.RawMessage, len(items))
+	for _, item := range items {
+		if codes.Code(item.Code) != codes.OK {
+			continue
+		}
+		s := item.Secret
+		secret, err := crypto.OpenSecret([]byte(key), string(s.Data), s.DataKey, secretAD(owner, s.Key, s.Type))
+		if err != nil {
+			l.Sugar().Debug(err)
+			item.Code, item.Error = int32(codes.DataLoss), upgradeHint(err).Error()
+			continue
+		}
+		if jsonFmt {
+			secrets[item.Key] = secret
+			continue
+		}
+		fmt.Printf("###### %s ######\n", item.Key)
+		if err := printSecret(secret, s.Type, false, l); err != nil {
+			item.Code, item.Error = int32(codes.DataLoss), err.Error()
+		}
 	}
-	switch r.Type {
+	if jsonFmt && len(secrets) > 0 {
+		out, err := json.MarshalIndent(secrets, "", strings.Repeat(" ", indentCount))
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	}
+	return batchError(items, l)
+}
+
+// printSecret prints decrypted secret according its type
+func printSecret(secret []byte, secretType pb.Type, jsonFmt bool, l *zap.Logger) error {
+	switch secretType {
 	case pb.Type_TEXT:
 		if err := printText(secret, jsonFmt); err != nil {
 			l.Sugar().Error(err)
@@ -108,6 +206,11 @@ func printValue(r *pb.GetResponse, jsonFmt bool, key string, l *zap.Logger) erro
 			l.Sugar().Error(err)
 			return err
 		}
+	case pb.Type_OTP:
+		if err := printOTP(secret, jsonFmt); err != nil {
+			l.Sugar().Error(err)
+			return err
+		}
 	}
 	return nil
 }
@@ -235,3 +338,46 @@ func printCart(data []byte, jsonFmt bool) error {
 	}
 	return nil
 }
+
+func printOTP(data []byte, jsonFmt bool) error {
+	if !jsonFmt {
+		otpData := types.OTP{}
+		if err := json.Unmarshal(data, &otpData); err != nil {
+			return err
+		}
+		fmt.Println("====== OTP ======")
+		fmt.Println("Field       Value")
+		fmt.Println("-----       -----")
+		fmt.Print("secret      ")
+		fmt.Println(otpData.Secret)
+		fmt.Print("algorithm   ")
+		fmt.Println(otpData.Algorithm)
+		fmt.Print("digits      ")
+		fmt.Println(otpData.Digits)
+		fmt.Print("period      ")
+		fmt.Println(otpData.Period)
+		if otpData.Issuer != "" {
+			fmt.Print("issuer      ")
+			fmt.Println(otpData.Issuer)
+		}
+		if otpData.Account != "" {
+			fmt.Print("account     ")
+			fmt.Println(otpData.Account)
+		}
+		if len(otpData.Info) > 0 && otpData.Info[0] != "" {
+			fmt.Println("====== Extra ======")
+			for _, s := range otpData.Info {
+				fmt.Println(s)
+			}
+		}
+		return nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", strings.Repeat(" ", indentCount)); err != nil {
+		return err
+	}
+	if _, err := out.WriteTo(os.Stdout); err != nil {
+		return err
+	}
+	return nil
+}
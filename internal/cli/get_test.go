@@ -4,10 +4,13 @@ import (
 	"testing"
 
 	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
 	pb "github.com/hrapovd1/gokeepas/internal/proto"
 	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
 )
 
 func Test_runGet(t *testing.T) {
@@ -17,6 +20,32 @@ func Test_runGet(t *testing.T) {
 	})
 }
 
+func Test_printValues(t *testing.T) {
+	key := "1234567890poiuyt"
+	data, dataKey, err := crypto.SealSecret([]byte(key), []byte(`{"text":"one"}`), secretAD("test", "one", pb.Type_TEXT))
+	require.NoError(t, err)
+	items := func() []*pb.BatchItem {
+		return []*pb.BatchItem{
+			{Key: "one", Secret: &pb.GetResponse{Key: "one", Type: pb.Type_TEXT, Data: []byte(data), DataKey: dataKey}},
+			{Key: "moved", Secret: &pb.GetResponse{Key: "moved", Type: pb.Type_TEXT, Data: []byte(data), DataKey: dataKey}},
+			{Key: "none", Code: int32(codes.NotFound), Error: "key doesn't exists"},
+		}
+	}
+	for _, jsonFmt := range []bool{false, true} {
+		out := items()
+		err := printValues(out, jsonFmt, key, "test", zap.New(nil))
+		assert.EqualError(t, err, "2 of 3 keys failed")
+		assert.Equal(t, int32(codes.OK), out[0].Code)
+		assert.Equal(t, int32(codes.DataLoss), out[1].Code)
+	}
+	require.NoError(t, printValues(items()[:1], true, key, "test", zap.New(nil)))
+}
+
+func Test_batchError(t *testing.T) {
+	assert.NoError(t, batchError([]*pb.BatchItem{{Key: "one"}}, zap.New(nil)))
+	assert.EqualError(t, batchError([]*pb.BatchItem{{Key: "one"}, {Key: "two", Code: int32(codes.Internal)}}, zap.New(nil)), "1 of 2 keys failed")
+}
+
 func Test_printText(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -109,7 +138,36 @@ func Test_printCart(t *testing.T) {
 	}
 }
 
+func Test_printOTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		jsonFmt  bool
+		positive bool
+	}{
+		{"otpOut", `{"secret":"GEZDGNBV","algorithm":"SHA1","digits":6,"period":30,"issuer":"ACME","account":"john","info":["extra"]}`, false, true},
+		{"otpOutJSON", `{"secret":"GEZDGNBV","algorithm":"SHA1","digits":6,"period":30,"info":["extra"]}`, true, true},
+		{"otpOutWrong", `{"secret":"GEZDGNBV","algorithm":"SHA1","digits":"6"}`, false, false},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			err := printOTP([]byte(tst.data), tst.jsonFmt)
+			if tst.positive {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
 func Test_printValue(t *testing.T) {
+	key := "1234567890poiuyt"
+	sealed := func(secretType pb.Type, secret string) *pb.GetResponse {
+		data, dataKey, err := crypto.SealSecret([]byte(key), []byte(secret), secretAD("", "one", secretType))
+		require.NoError(t, err)
+		return &pb.GetResponse{Key: "one", Type: secretType, Data: []byte(data), DataKey: dataKey}
+	}
 	tests := []struct {
 		name     string
 		resp     *pb.GetResponse
@@ -119,10 +177,11 @@ func Test_printValue(t *testing.T) {
 	}{
 		{"empty", &pb.GetResponse{}, false, "", false},
 		{"wrong encrypt", &pb.GetResponse{Key: "one"}, false, "12345", false},
-		{"text", &pb.GetResponse{Key: "one", Type: pb.Type_TEXT, Data: []byte("6YLGZ63Cgh52q/deEL/IJQ9rsBUWUPhyBpdSsAN0MMPL3db+/61jvzNDU7G3jkvM9jOVaSDNBTXVIzzV")}, false, "1234567890poiuyt", true},
-		{"login", &pb.GetResponse{Key: "one", Type: pb.Type_LOGIN, Data: []byte("KQZI+6vgCmuEGyZl1LIm3NFwNxE42IjYwdjcDXCOgNSep77+Ff62aiJdzx7CwDbMoTX6Adpw76qUld7FMhftjqlvs6P+77G6826BxVZ1")}, false, "1234567890poiuyt", true},
-		{"bin", &pb.GetResponse{Key: "one", Type: pb.Type_BINARY, Data: []byte("K6kRNn73QkRK4SFK1miKvJ1vHlVXMgYHu1/Dm0FT3JkKu1yDEBjXtNzlggwKPEBvdkVOjJa3PUEZdqhpbWFFtcXwFAiW06RgvzYKtMg=")}, false, "1234567890poiuyt", true},
-		{"cart", &pb.GetResponse{Key: "one", Type: pb.Type_CART, Data: []byte("v8CM6OwP0/MkUhJSdHuN3xf9PaZM0zmy1fXcF/oQ63Vq/WytUwQtARKfgvSYEIOAk1EPp6ZH62lz+jERNciyNbN9FKvIBCqWaE+BEDikmhT1LUuZ1f50Pmgo+wvdZ5hApPmFvkWvn46TCObbKoPogZ8=")}, false, "1234567890poiuyt", true},
+		{"text", sealed(pb.Type_TEXT, `{"text":"one", "info":["extra"]}`), false, key, true},
+		{"login", sealed(pb.Type_LOGIN, `{"login":"one","password":"two", "info":["extra"]}`), false, key, true},
+		{"bin", sealed(pb.Type_BINARY, `{"data":"b25lcGFzc3dvcmR0d28=", "info":["extra"]}`), false, key, true},
+		{"cart", sealed(pb.Type_CART, `{"number":"one","expired":"two", "holder": "one two", "cvc": "123", "info":["extra"]}`), false, key, true},
+		{"without data key", &pb.GetResponse{Key: "one", Type: pb.Type_TEXT, Data: []byte("6YLGZ63Cgh52q/deEL/IJQ9rsBUWUPhyBpdSsAN0MMPL3db+/61jvzNDU7G3jkvM9jOVaSDNBTXVIzzV")}, false, key, false},
 	}
 	logConfig := zap.NewProductionConfig()
 	logger, err := logConfig.Build()
@@ -130,14 +189,40 @@ func Test_printValue(t *testing.T) {
 	for _, tst := range tests {
 		t.Run(tst.name, func(t *testing.T) {
 			if tst.positive {
-				require.NoError(t, printValue(tst.resp, tst.jsonFmt, tst.key, logger))
+				require.NoError(t, printValue(tst.resp, tst.jsonFmt, tst.key, "", logger))
 			} else {
-				require.Error(t, printValue(tst.resp, tst.jsonFmt, tst.key, logger))
+				require.Error(t, printValue(tst.resp, tst.jsonFmt, tst.key, "", logger))
 			}
 		})
 	}
 }
 
+func Test_printValue_tampered(t *testing.T) {
+	key := "1234567890poiuyt"
+	data, dataKey, err := crypto.SealSecret([]byte(key), []byte(`{"text":"one"}`), secretAD("test", "one", pb.Type_TEXT))
+	require.NoError(t, err)
+	logger := zap.NewNop()
+	t.Run("right", func(t *testing.T) {
+		require.NoError(t, printValue(&pb.GetResponse{Key: "one", Type: pb.Type_TEXT, Data: []byte(data), DataKey: dataKey}, false, key, "test", logger))
+	})
+	t.Run("other key name", func(t *testing.T) {
+		err := printValue(&pb.GetResponse{Key: "two", Type: pb.Type_TEXT, Data: []byte(data), DataKey: dataKey}, false, key, "test", logger)
+		require.ErrorIs(t, err, crypto.ErrTampered)
+	})
+	t.Run("other type", func(t *testing.T) {
+		err := printValue(&pb.GetResponse{Key: "one", Type: pb.Type_LOGIN, Data: []byte(data), DataKey: dataKey}, false, key, "test", logger)
+		require.ErrorIs(t, err, crypto.ErrTampered)
+	})
+	t.Run("other owner", func(t *testing.T) {
+		err := printValue(&pb.GetResponse{Key: "one", Type: pb.Type_TEXT, Data: []byte(data), DataKey: dataKey}, false, key, "other", logger)
+		require.ErrorIs(t, err, crypto.ErrTampered)
+	})
+	t.Run("removed data key", func(t *testing.T) {
+		err := printValue(&pb.GetResponse{Key: "one", Type: pb.Type_TEXT, Data: []byte(data)}, false, key, "test", logger)
+		require.ErrorIs(t, err, crypto.ErrNoDataKey)
+	})
+}
+
 // func Test_encrypt(t *testing.T) {
 // 	tests := []struct {
 // 		name string
@@ -0,0 +1,381 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	offlineJournalFile = "journal"     // prefix of files of encrypted journals of offline changes by login in offline dir
+	conflictSuffix     = " (conflict)" // suffix of key of local version of secret which can't be merged
+	maxConflictKeys    = 100           // limit of numbered conflict keys of one secret
+	offlineQueued      = "server isn't available, change is saved in offline journal and will be sent on next connection"
+)
+
+// Operations of offline journal
+const (
+	opAdd    = "add"
+	opUpdate = "update"
+	opRemove = "remove"
+)
+
+// journalOp is change of secret made offline, secret is already encrypted
+type journalOp struct {
+	Op      string        `json:"op"`
+	Key     string        `json:"key"`
+	Type    pb.Type       `json:"type,omitempty"`
+	Data    string        `json:"data,omitempty"`
+	DataKey string        `json:"datakey,omitempty"`
+	Base    *cachedSecret `json:"base,omitempty"` // server copy of secret seen by client, nil when it wasn't known
+}
+
+// writeJournal keeps offline changes of user in order they were made
+type writeJournal struct {
+	Login string      `json:"login"`
+	Ops   []journalOp `json:"ops"`
+}
+
+// journalAD returns associated data which binds offline journal to its owner
+func journalAD(login string) []byte {
+	return crypto.AssociatedData(login, "offline-journal")
+}
+
+// journalPath returns path of offline journal of login, journals of other users who logged in
+// the same client stay until they log in again.
+func journalPath(dir string, login string) string {
+	return filepath.Join(dir, offlineJournalFile+"."+url.PathEscape(login))
+}
+
+// readJournal decrypts offline journal of login, empty journal is returned when it doesn't exist.
+func readJournal(dir string, userKey []byte, login string) (*writeJournal, error) {
+	data, err := os.ReadFile(journalPath(dir, login))
+	if os.IsNotExist(err) {
+		return &writeJournal{Login: login}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	plain, err := crypto.DecryptData(userKey, string(data), journalAD(login))
+	if err != nil {
+		return nil, fmt.Errorf("open offline journal: %w", err)
+	}
+	journal := writeJournal{}
+	if err := json.Unmarshal(plain, &journal); err != nil {
+		return nil, err
+	}
+	return &journal, nil
+}
+
+// saveJournal encrypts offline journal by user key, empty journal is removed.
+func saveJournal(dir string, userKey []byte, journal *writeJournal) error {
+	path := journalPath(dir, journal.Login)
+	if len(journal.Ops) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	plain, err := json.Marshal(journal)
+	if err != nil {
+		return err
+	}
+	data, err := crypto.EncryptData(userKey, plain, journalAD(journal.Login))
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, []byte(data))
+}
+
+// queueOp adds offline change into journal and applies it to local replica, so offline
+// reads see it. Base of change is server copy of secret seen before the first offline change of key.
+func queueOp(dir string, userKey []byte, cache *localCache, op journalOp) error {
+	journal, err := readJournal(dir, userKey, cache.Login)
+	if err != nil {
+		return err
+	}
+	queued := false
+	for _, prev := range journal.Ops {
+		if prev.Key == op.Key {
+			op.Base, queued = prev.Base, true
+			break
+		}
+	}
+	if s, ok := cache.Secrets[op.Key]; ok && !queued {
+		op.Base = &s
+	}
+	journal.Ops = append(journal.Ops, op)
+	if err := saveJournal(dir, userKey, journal); err != nil {
+		return err
+	}
+	if op.Op == opRemove {
+		delete(cache.Secrets, op.Key)
+	} else {
+		cache.Secrets[op.Key] = cachedSecret{Type: op.Type, Data: []byte(op.Data), DataKey: op.DataKey}
+	}
+	return writeCache(dir, userKey, cache)
+}
+
+// replayPending sends offline changes of user before online change, so the change is made over
+// them. Errors are only logged, not sent changes stay in journal.
+func replayPending(cmd *cobra.Command, client *cliClient) {
+	if client.vault != "" {
+		// journal keeps own secrets only
+		return
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	if err := replayJournal(cmd.Context(), client, pb.NewKeepPasClient(conn), os.Stderr); err != nil {
+		client.logger.Sugar().Error(err)
+	}
+}
+
+// replayJournal sends offline changes to server in order they were made. Replay stops on
+// the first error, the rest of changes stay in journal.
+func replayJournal(ctx context.Context, client *cliClient, transport pb.KeepPasClient, out io.Writer) error {
+	if _, err := os.Stat(journalPath(client.config.OfflineDir, client.login)); err != nil {
+		return nil
+	}
+	userKey := []byte(client.config.UserKey)
+	journal, err := readJournal(client.config.OfflineDir, userKey, client.login)
+	if err != nil {
+		return err
+	}
+	for len(journal.Ops) > 0 {
+		op := journal.Ops[0]
+		key := op.Key
+		saved, applied, err := replayOp(ctx, client, transport, &op, out)
+		if err == nil {
+			journal.Ops = journal.Ops[1:]
+		}
+		if err == nil && applied {
+			// next changes of key are made over applied one, after conflict they go to
+			// local version saved under conflict key
+			err = rebaseOps(client, journal.Ops, key, op.Key, saved)
+		}
+		if err != nil {
+			if err := saveJournal(client.config.OfflineDir, userKey, journal); err != nil {
+				client.logger.Sugar().Debug(err)
+			}
+			return fmt.Errorf("replay offline change of %s: %w", key, err)
+		}
+	}
+	return saveJournal(client.config.OfflineDir, userKey, journal)
+}
+
+// rebaseOps makes queued changes of key over saved copy of newKey. Data key of change is
+// re-wrapped for newKey name, data of change stays the same.
+func rebaseOps(client *cliClient, ops []journalOp, key string, newKey string, saved *cachedSecret) error {
+	userKey := []byte(client.config.UserKey)
+	for i := range ops {
+		if ops[i].Key != key {
+			continue
+		}
+		if newKey != key && ops[i].DataKey != "" {
+			dataKey, err := crypto.UnwrapDataKey(userKey, ops[i].DataKey, secretAD(client.login, key, ops[i].Type))
+			if err != nil {
+				return err
+			}
+			if ops[i].DataKey, err = crypto.WrapDataKey(userKey, dataKey, secretAD(client.login, newKey, ops[i].Type)); err != nil {
+				return err
+			}
+		}
+		ops[i].Key, ops[i].Base = newKey, saved
+	}
+	return nil
+}
+
+// replayOp applies offline change when server copy of secret isn't changed since client saw it,
+// otherwise change is merged with server copy or is saved under conflict key. It returns server
+// copy of key after change and false when change isn't applied. When change is saved under
+// conflict key, op.Key is set to it and the saved copy is returned.
+func replayOp(ctx context.Context, client *cliClient, transport pb.KeepPasClient, op *journalOp, out io.Writer) (*cachedSecret, bool, error) {
+	var theirs *cachedSecret
+	resp, err := transport.Get(ctx, &pb.BinRequest{Key: op.Key})
+	switch {
+	case status.Code(err) == codes.NotFound:
+	case err != nil:
+		return nil, false, err
+	default:
+		theirs = &cachedSecret{Type: resp.Type, Data: resp.Data, DataKey: resp.DataKey}
+	}
+	req := &pb.BinRequest{Key: op.Key, Type: op.Type, Data: op.Data, DataKey: op.DataKey}
+	saved := &cachedSecret{Type: op.Type, Data: []byte(op.Data), DataKey: op.DataKey}
+	if sameSecret(op.Base, theirs) {
+		switch {
+		case op.Op == opRemove:
+			_, err = transport.Remove(ctx, req)
+			saved = nil
+		case theirs == nil:
+			_, err = transport.Add(ctx, req)
+		default:
+			_, err = transport.Update(ctx, req)
+		}
+		return saved, err == nil, err
+	}
+	// server copy is changed since client saw it
+	switch {
+	case op.Op == opRemove && theirs == nil:
+		return nil, true, nil
+	case op.Op == opRemove:
+		_, err = fmt.Fprintf(out, "%s: isn't removed, it is changed on server\n", op.Key)
+		return nil, false, err
+	case theirs == nil:
+		if _, err := transport.Add(ctx, req); err != nil {
+			return nil, false, err
+		}
+		_, err = fmt.Fprintf(out, "%s: is removed on server, local version is added again\n", op.Key)
+		return saved, true, err
+	}
+	userKey := []byte(client.config.UserKey)
+	merged, ok, err := mergeSecret(userKey, client.login, op, theirs)
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		req.Data, req.DataKey, err = crypto.SealSecret(userKey, merged, secretAD(client.login, op.Key, op.Type))
+		if err != nil {
+			return nil, false, err
+		}
+		if _, err := transport.Update(ctx, req); err != nil {
+			return nil, false, err
+		}
+		_, err = fmt.Fprintf(out, "%s: local changes are merged with server changes\n", op.Key)
+		return &cachedSecret{Type: req.Type, Data: []byte(req.Data), DataKey: req.DataKey}, true, err
+	}
+	// keep both versions
+	ours, err := crypto.OpenSecret(userKey, op.Data, op.DataKey, secretAD(client.login, op.Key, op.Type))
+	if err != nil {
+		return nil, false, err
+	}
+	if req.Key, err = conflictKey(ctx, transport, op.Key); err != nil {
+		return nil, false, err
+	}
+	req.Data, req.DataKey, err = crypto.SealSecret(userKey, ours, secretAD(client.login, req.Key, op.Type))
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err := transport.Add(ctx, req); err != nil {
+		return nil, false, err
+	}
+	_, err = fmt.Fprintf(out, "%s: conflicts with server changes, local version is saved as %s\n", op.Key, req.Key)
+	op.Key = req.Key
+	return &cachedSecret{Type: req.Type, Data: []byte(req.Data), DataKey: req.DataKey}, true, err
+}
+
+// conflictKey returns free key for local version of secret key: "KEY (conflict)", then
+// "KEY (conflict 2)" and so on, so earlier conflict copies and other secrets aren't overwritten.
+func conflictKey(ctx context.Context, transport pb.KeepPasClient, key string) (string, error) {
+	for n := 1; n <= maxConflictKeys; n++ {
+		candidate := key + conflictSuffix
+		if n > 1 {
+			candidate = fmt.Sprintf("%s (conflict %d)", key, n)
+		}
+		_, err := transport.Get(ctx, &pb.BinRequest{Key: candidate})
+		if status.Code(err) == codes.NotFound {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("%s: too many conflict copies", key)
+}
+
+// sameSecret reports a and b are the same ciphertext, nil is missing secret
+func sameSecret(a *cachedSecret, b *cachedSecret) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Type == b.Type && bytes.Equal(a.Data, b.Data) && a.DataKey == b.DataKey
+}
+
+// mergeSecret merges fields of offline change and server copy changed since base, only login,
+// cart and text secrets are merged. It returns false when the same field is changed differently.
+func mergeSecret(userKey []byte, login string, op *journalOp, theirs *cachedSecret) ([]byte, bool, error) {
+	switch op.Type {
+	case pb.Type_LOGIN, pb.Type_CART, pb.Type_TEXT:
+	default:
+		return nil, false, nil
+	}
+	if op.Type != theirs.Type {
+		return nil, false, nil
+	}
+	ad := secretAD(login, op.Key, op.Type)
+	ours, err := crypto.OpenSecret(userKey, op.Data, op.DataKey, ad)
+	if err != nil {
+		return nil, false, err
+	}
+	server, err := crypto.OpenSecret(userKey, string(theirs.Data), theirs.DataKey, ad)
+	if err != nil {
+		return nil, false, err
+	}
+	base := []byte(`{}`)
+	if op.Base != nil && op.Base.Type == op.Type {
+		if base, err = crypto.OpenSecret(userKey, string(op.Base.Data), op.Base.DataKey, ad); err != nil {
+			return nil, false, err
+		}
+	}
+	return mergeFields(base, ours, server)
+}
+
+// mergeFields makes three-way merge of json objects by fields. Field changed on one side only
+// takes changed value, it returns false when field is changed differently on both sides.
+func mergeFields(base []byte, ours []byte, theirs []byte) ([]byte, bool, error) {
+	var b, o, t map[string]json.RawMessage
+	for _, v := range []struct {
+		data []byte
+		out  *map[string]json.RawMessage
+	}{{base, &b}, {ours, &o}, {theirs, &t}} {
+		if err := json.Unmarshal(v.data, v.out); err != nil {
+			return nil, false, err
+		}
+	}
+	merged := make(map[string]json.RawMessage)
+	fields := make(map[string]bool)
+	for _, m := range []map[string]json.RawMessage{b, o, t} {
+		for name := range m {
+			fields[name] = true
+		}
+	}
+	for name := range fields {
+		bv, ov, tv := string(b[name]), string(o[name]), string(t[name])
+		var v string
+		switch {
+		case ov == tv, tv == bv:
+			v = ov
+		case ov == bv:
+			v = tv
+		default:
+			return nil, false, nil
+		}
+		if v != "" {
+			merged[name] = json.RawMessage(v)
+		}
+	}
+	out, err := json.Marshal(merged)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
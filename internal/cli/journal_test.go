@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStoreClient keeps encrypted secrets in memory like server
+type fakeStoreClient struct {
+	pb.KeepPasClient
+	secrets map[string]*pb.GetResponse
+}
+
+func (f *fakeStoreClient) Get(_ context.Context, in *pb.BinRequest, _ ...grpc.CallOption) (*pb.GetResponse, error) {
+	s, ok := f.secrets[in.Key]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "key doesn't exists")
+	}
+	return s, nil
+}
+
+func (f *fakeStoreClient) Add(_ context.Context, in *pb.BinRequest, _ ...grpc.CallOption) (*pb.BinResponse, error) {
+	f.secrets[in.Key] = &pb.GetResponse{Key: in.Key, Type: in.Type, Data: []byte(in.Data), DataKey: in.DataKey}
+	return &pb.BinResponse{}, nil
+}
+
+func (f *fakeStoreClient) Update(ctx context.Context, in *pb.BinRequest, opts ...grpc.CallOption) (*pb.BinResponse, error) {
+	return f.Add(ctx, in, opts...)
+}
+
+func (f *fakeStoreClient) Remove(_ context.Context, in *pb.BinRequest, _ ...grpc.CallOption) (*pb.BinResponse, error) {
+	delete(f.secrets, in.Key)
+	return &pb.BinResponse{}, nil
+}
+
+func Test_replayJournal(t *testing.T) {
+	client := cliClient{logger: zap.New(nil), config: config.Config{UserKey: "1234567890poiuyt", OfflineDir: t.TempDir()}, login: "test"}
+	userKey := []byte(client.config.UserKey)
+	ctx := context.Background()
+	seal := func(key string, secret any) cachedSecret {
+		data, err := json.Marshal(secret)
+		require.NoError(t, err)
+		sealed, dataKey, err := crypto.SealSecret(userKey, data, secretAD("test", key, pb.Type_LOGIN))
+		require.NoError(t, err)
+		return cachedSecret{Type: pb.Type_LOGIN, Data: []byte(sealed), DataKey: dataKey}
+	}
+	open := func(key string, s *pb.GetResponse) types.Login {
+		data, err := crypto.OpenSecret(userKey, string(s.Data), s.DataKey, secretAD("test", key, s.Type))
+		require.NoError(t, err)
+		out := types.Login{}
+		require.NoError(t, json.Unmarshal(data, &out))
+		return out
+	}
+	queue := func(cache *localCache, op string, key string, secret any) {
+		jop := journalOp{Op: op, Key: key}
+		if secret != nil {
+			s := seal(key, secret)
+			jop.Type, jop.Data, jop.DataKey = s.Type, string(s.Data), s.DataKey
+		}
+		require.NoError(t, queueOp(client.config.OfflineDir, userKey, cache, jop))
+	}
+	// server copies seen by client before going offline
+	base := map[string]cachedSecret{
+		"same":     seal("same", types.Login{Login: "a", Password: "p"}),
+		"merge":    seal("merge", types.Login{Login: "a", Password: "p"}),
+		"conflict": seal("conflict", types.Login{Login: "a", Password: "p"}),
+		"removed":  seal("removed", types.Login{Login: "a", Password: "p"}),
+	}
+	transport := fakeStoreClient{secrets: map[string]*pb.GetResponse{}}
+	cache := localCache{Login: "test", Secrets: map[string]cachedSecret{}}
+	for key, s := range base {
+		s := s
+		cache.Secrets[key] = s
+		transport.secrets[key] = &pb.GetResponse{Key: key, Type: s.Type, Data: s.Data, DataKey: s.DataKey}
+	}
+
+	// offline changes
+	queue(&cache, opUpdate, "same", types.Login{Login: "a", Password: "p1"})
+	queue(&cache, opUpdate, "same", types.Login{Login: "a", Password: "p2"})
+	queue(&cache, opUpdate, "merge", types.Login{Login: "a", Password: "p2"})
+	queue(&cache, opUpdate, "conflict", types.Login{Login: "a", Password: "mine"})
+	queue(&cache, opRemove, "removed", nil)
+	queue(&cache, opAdd, "new", types.Login{Login: "n", Password: "p"})
+	journal, err := readJournal(client.config.OfflineDir, userKey, "test")
+	require.NoError(t, err)
+	require.Len(t, journal.Ops, 6)
+	// base of the second change of key is server copy, not the first change
+	assert.Equal(t, base["same"], *journal.Ops[1].Base)
+	assert.Nil(t, journal.Ops[5].Base)
+	_, ok := cache.Secrets["removed"]
+	assert.False(t, ok)
+
+	// changes on server meanwhile
+	changed := func(key string, secret types.Login) {
+		s := seal(key, secret)
+		transport.secrets[key] = &pb.GetResponse{Key: key, Type: s.Type, Data: s.Data, DataKey: s.DataKey}
+	}
+	changed("merge", types.Login{Login: "b", Password: "p"})
+	changed("conflict", types.Login{Login: "a", Password: "theirs"})
+	changed("removed", types.Login{Login: "a", Password: "theirs"})
+	// copy of earlier conflict
+	changed("conflict (conflict)", types.Login{Login: "a", Password: "earlier"})
+
+	var out bytes.Buffer
+	require.NoError(t, replayJournal(ctx, &client, &transport, &out))
+	assert.Equal(t, types.Login{Login: "a", Password: "p2"}, open("same", transport.secrets["same"]))
+	assert.Equal(t, types.Login{Login: "b", Password: "p2"}, open("merge", transport.secrets["merge"]))
+	assert.Equal(t, types.Login{Login: "a", Password: "theirs"}, open("conflict", transport.secrets["conflict"]))
+	assert.Equal(t, types.Login{Login: "a", Password: "earlier"}, open("conflict (conflict)", transport.secrets["conflict (conflict)"]))
+	assert.Equal(t, types.Login{Login: "a", Password: "mine"}, open("conflict (conflict 2)", transport.secrets["conflict (conflict 2)"]))
+	assert.Equal(t, types.Login{Login: "a", Password: "theirs"}, open("removed", transport.secrets["removed"]))
+	assert.Equal(t, types.Login{Login: "n", Password: "p"}, open("new", transport.secrets["new"]))
+	assert.Equal(t, `merge: local changes are merged with server changes
+conflict: conflicts with server changes, local version is saved as conflict (conflict 2)
+removed: isn't removed, it is changed on server
+`, out.String())
+	assert.NoFileExists(t, journalPath(client.config.OfflineDir, "test"))
+}
+
+func Test_replayJournal_conflictTwice(t *testing.T) {
+	client := cliClient{logger: zap.New(nil), config: config.Config{UserKey: "1234567890poiuyt", OfflineDir: t.TempDir()}, login: "test"}
+	userKey := []byte(client.config.UserKey)
+	seal := func(key string, secret string) cachedSecret {
+		data, dataKey, err := crypto.SealSecret(userKey, []byte(secret), secretAD("test", key, pb.Type_BINARY))
+		require.NoError(t, err)
+		return cachedSecret{Type: pb.Type_BINARY, Data: []byte(data), DataKey: dataKey}
+	}
+	base := seal("key", `{"data":"base"}`)
+	cache := localCache{Login: "test", Secrets: map[string]cachedSecret{"key": base}}
+	store := fakeStoreClient{secrets: map[string]*pb.GetResponse{}}
+	for _, v := range []string{"one", "two"} {
+		s := seal("key", `{"data":"`+v+`"}`)
+		op := journalOp{Op: opUpdate, Key: "key", Type: s.Type, Data: string(s.Data), DataKey: s.DataKey}
+		require.NoError(t, queueOp(client.config.OfflineDir, userKey, &cache, op))
+	}
+	theirs := seal("key", `{"data":"theirs"}`)
+	store.secrets["key"] = &pb.GetResponse{Key: "key", Type: theirs.Type, Data: theirs.Data, DataKey: theirs.DataKey}
+
+	var out bytes.Buffer
+	require.NoError(t, replayJournal(context.Background(), &client, &store, &out))
+	// the second change goes to conflict copy made by the first one
+	assert.Equal(t, "key: conflicts with server changes, local version is saved as key (conflict)\n", out.String())
+	assert.Len(t, store.secrets, 2)
+	assert.Equal(t, theirs.Data, store.secrets["key"].Data)
+	s := store.secrets["key (conflict)"]
+	data, err := crypto.OpenSecret(userKey, string(s.Data), s.DataKey, secretAD("test", "key (conflict)", s.Type))
+	require.NoError(t, err)
+	assert.Equal(t, `{"data":"two"}`, string(data))
+	assert.NoFileExists(t, journalPath(client.config.OfflineDir, "test"))
+}
+
+func Test_mergeFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		ours   string
+		theirs string
+		want   string
+		ok     bool
+	}{
+		{"both sides", `{"login":"a","password":"p"}`, `{"login":"a","password":"p2"}`, `{"login":"b","password":"p"}`, `{"login":"b","password":"p2"}`, true},
+		{"same change", `{"text":"a"}`, `{"text":"b"}`, `{"text":"b"}`, `{"text":"b"}`, true},
+		{"conflict", `{"text":"a"}`, `{"text":"b"}`, `{"text":"c"}`, ``, false},
+		{"no base", `{}`, `{"text":"b","info":[""]}`, `{"text":"b","info":["x"]}`, ``, false},
+		{"added field", `{"number":"1"}`, `{"number":"1","cvc":"123"}`, `{"number":"1"}`, `{"cvc":"123","number":"1"}`, true},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			got, ok, err := mergeFields([]byte(tst.base), []byte(tst.ours), []byte(tst.theirs))
+			require.NoError(t, err)
+			assert.Equal(t, tst.ok, ok)
+			if ok {
+				assert.JSONEq(t, tst.want, string(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+const knownKeysFile = "known_keys" // file of pinned public keys of other users in offline dir
+
+// knownKeys are fingerprints of public keys of other users by server address and login,
+// key is pinned on first use and server can't substitute it later.
+type knownKeys map[string]map[string]string
+
+func newAccountCmdFingerprint(clnt *cliClient) *cobra.Command {
+	// fingerprintCmd represents the account fingerprint command
+	fingerprintCmd := &cobra.Command{
+		Use:   "fingerprint",
+		Short: "Print fingerprint of sharing public key",
+		Long: `Print fingerprint of own public key which other users seal shared secrets and vault keys to.
+Fingerprint is computed from private key locally, send it to other users by trusted channel,
+so they can check it with flag --fingerprint of 'kv share' and 'vault invite'.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runFingerprint(clnt, cmd)
+		},
+	}
+
+	return fingerprintCmd
+}
+
+func runFingerprint(client *cliClient, cmd *cobra.Command) {
+	if err := setAuthContext(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := getUserKey(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	transport := pb.NewKeepPasClient(conn)
+	_, priv, err := ensureKeyPair(cmd.Context(), client, transport) // defined in share.go
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// public key of server isn't trusted
+	pub, err := crypto.SharePublicKey(priv)
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	fmt.Println(crypto.Fingerprint(pub))
+}
+
+// trustedPublicKey returns public key of user login got from server and checks its fingerprint.
+// Key of unknown user is pinned on first use, warning with its fingerprint is written to out.
+// Pinned key which doesn't match is rejected unless expected fingerprint is given.
+func trustedPublicKey(ctx context.Context, client *cliClient, transport pb.KeepPasClient, login string, fingerprint string, out io.Writer) ([]byte, error) {
+	resp, err := transport.GetPublicKey(ctx, &pb.BinRequest{Key: login})
+	if err != nil {
+		return nil, err
+	}
+	pub, err := base64.StdEncoding.DecodeString(resp.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	got := crypto.Fingerprint(pub)
+	if fingerprint != "" && fingerprint != got {
+		return nil, fmt.Errorf("public key of %s has fingerprint %s, not %s", login, got, fingerprint)
+	}
+	path := filepath.Join(client.config.OfflineDir, knownKeysFile)
+	known, err := readKnownKeys(path)
+	if err != nil {
+		return nil, err
+	}
+	pinned, ok := known[client.config.ServerAddr][login]
+	switch {
+	case pinned == got:
+		return pub, nil
+	case ok && fingerprint == "":
+		return nil, fmt.Errorf("public key of %s is changed: fingerprint %s, pinned %s. "+
+			"Server can substitute the key, check new fingerprint with the user and pass it with --fingerprint", login, got, pinned)
+	case !ok && fingerprint == "":
+		if _, err := fmt.Fprintf(out, "public key of %s is pinned on first use, fingerprint %s\n"+
+			"check it with output of 'keeppas account fingerprint' of the user\n", login, got); err != nil {
+			return nil, err
+		}
+	}
+	if known[client.config.ServerAddr] == nil {
+		known[client.config.ServerAddr] = make(map[string]string)
+	}
+	known[client.config.ServerAddr][login] = got
+	return pub, writeKnownKeys(path, known)
+}
+
+func readKnownKeys(path string) (knownKeys, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return knownKeys{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	known := knownKeys{}
+	if err := json.Unmarshal(data, &known); err != nil {
+		return nil, fmt.Errorf("known keys file %s: %w", path, err)
+	}
+	return known, nil
+}
+
+func writeKnownKeys(path string, known knownKeys) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(known)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data) // defined in cache.go
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_trustedPublicKey(t *testing.T) {
+	ctx := context.Background()
+	client := cliClient{logger: zap.New(nil), config: config.Config{ServerAddr: "localhost:5000", OfflineDir: t.TempDir()}}
+	pub, _, err := crypto.GenShareKeyPair()
+	require.NoError(t, err)
+	other, _, err := crypto.GenShareKeyPair()
+	require.NoError(t, err)
+	transport := fakeShareClient{keyPairs: map[string]*pb.KeyPair{"bob": {PublicKey: base64.StdEncoding.EncodeToString(pub)}}}
+	var out bytes.Buffer
+	t.Run("wrong fingerprint", func(t *testing.T) {
+		_, err := trustedPublicKey(ctx, &client, &transport, "bob", crypto.Fingerprint(other), &out)
+		assert.Error(t, err)
+	})
+	t.Run("first use", func(t *testing.T) {
+		res, err := trustedPublicKey(ctx, &client, &transport, "bob", "", &out)
+		require.NoError(t, err)
+		assert.Equal(t, pub, res)
+		assert.Contains(t, out.String(), crypto.Fingerprint(pub))
+	})
+	t.Run("pinned", func(t *testing.T) {
+		out.Reset()
+		res, err := trustedPublicKey(ctx, &client, &transport, "bob", "", &out)
+		require.NoError(t, err)
+		assert.Equal(t, pub, res)
+		assert.Empty(t, out.String())
+	})
+	transport.keyPairs["bob"] = &pb.KeyPair{PublicKey: base64.StdEncoding.EncodeToString(other)}
+	t.Run("substituted", func(t *testing.T) {
+		_, err := trustedPublicKey(ctx, &client, &transport, "bob", "", &out)
+		assert.ErrorContains(t, err, "is changed")
+	})
+	t.Run("other server", func(t *testing.T) {
+		srv := client
+		srv.config.ServerAddr = "other:5000"
+		res, err := trustedPublicKey(ctx, &srv, &transport, "bob", "", &out)
+		require.NoError(t, err)
+		assert.Equal(t, other, res)
+	})
+	t.Run("checked new key", func(t *testing.T) {
+		res, err := trustedPublicKey(ctx, &client, &transport, "bob", crypto.Fingerprint(other), &out)
+		require.NoError(t, err)
+		assert.Equal(t, other, res)
+		_, err = trustedPublicKey(ctx, &client, &transport, "bob", "", &out)
+		assert.NoError(t, err)
+	})
+}
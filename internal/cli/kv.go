@@ -7,10 +7,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func newKVCmd() *cobra.Command {
+func newKVCmd(clnt *cliClient) *cobra.Command {
 	// kvCmd represents the kv command
-	return &cobra.Command{
-		Use:   "kv",
+	kvCmd := &cobra.Command{
+		Use:   "kv [--vault VAULT]",
 		Short: "Manage secrets",
 	}
+	kvCmd.PersistentFlags().StringVar(&clnt.vault, "vault", "", "team vault, e.g. team/infra. Default own secrets.")
+	return kvCmd
 }
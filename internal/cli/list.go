@@ -4,8 +4,12 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strings"
 
 	pb "github.com/hrapovd1/gokeepas/internal/proto"
@@ -14,24 +18,58 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
+// listPageSize is count of secrets got by one List request
+const listPageSize = 500
+
+// listOptions keeps flags of list command
+type listOptions struct {
+	jsonOut bool
+	prefix  string
+	types   []string
+	sortBy  string
+	offline bool
+}
+
+// listItem is secret in json output of list command
+type listItem struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	DataKey bool   `json:"datakey"`
+}
+
 func newKVCmdList(clnt *cliClient) *cobra.Command {
-	getOutJSON := false
+	opts := listOptions{}
 	// listCmd represents the list command
 	listCmd := &cobra.Command{
 		Use:   "list",
 		Short: "Get list of secret keys from KeepPas server",
-		Long: `Get list of secret keys from KeepPas server.
-Default output format is text, you can change output to JSON format with flag -j.`,
+		Long: `Get list of secret keys with their types from KeepPas server.
+Secrets can be filtered by name prefix with flag -p and by types with flag -t, e.g. -t login,cart.
+Secrets are sorted by name, flag -s type sorts them by type and name.
+Default output format is text, you can change output to JSON format with flag -j.
+Secrets are read from offline cache when server isn't available or with flag --offline.`,
 		Run: func(cmd *cobra.Command, args []string) {
-			runList(clnt, getOutJSON, cmd)
+			if useOffline(clnt, opts.offline) {
+				runListOffline(clnt, opts, cmd)
+				return
+			}
+			runList(clnt, opts, cmd)
 		},
 	}
-	listCmd.Flags().BoolVarP(&getOutJSON, "json", "j", false, "print output in json. Default text format.")
+	listCmd.Flags().BoolVarP(&opts.jsonOut, "json", "j", false, "print output in json. Default text format.")
+	listCmd.Flags().StringVarP(&opts.prefix, "prefix", "p", "", "list only keys with prefix")
+	listCmd.Flags().StringSliceVarP(&opts.types, "type", "t", nil, "list only secrets of types: text, binary, login, cart, otp")
+	listCmd.Flags().StringVarP(&opts.sortBy, "sort", "s", "name", "sort secrets by: name, type")
+	listCmd.Flags().BoolVar(&opts.offline, "offline", false, "list secrets of offline cache, see 'keeppas sync'")
 
 	return listCmd
 }
 
-func runList(client *cliClient, jsonOut bool, cmd *cobra.Command) {
+func runList(client *cliClient, opts listOptions, cmd *cobra.Command) {
+	req, err := listRequest(opts)
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
 	token, err := readToken(client.config.TokenCache, client.logger)
 	if err != nil {
 		client.logger.Sugar().Fatal(err)
@@ -44,8 +82,6 @@ func runList(client *cliClient, jsonOut bool, cmd *cobra.Command) {
 	if err := getUserKey(cmd, client); err != nil {
 		client.logger.Sugar().Fatal(err)
 	}
-	// process request
-	req := pb.BinRequest{}
 	// process grpc client
 	conn := client.transport(client.config.ServerAddr, client.logger)
 	defer func(l *zap.Logger) {
@@ -55,58 +91,97 @@ func runList(client *cliClient, jsonOut bool, cmd *cobra.Command) {
 	}(client.logger)
 	transport := pb.NewKeepPasClient(conn)
 	// call grpc method
-	resp, err := transport.List(cmd.Context(), &req)
+	entries, err := listSecrets(cmd.Context(), transport, req)
 	if err != nil {
 		client.logger.Sugar().Fatal(err)
 	}
-	if err := printList(resp, jsonOut, client.logger); err != nil {
+	sortEntries(entries, opts.sortBy)
+	if err := printList(entries, opts.jsonOut, os.Stdout); err != nil {
 		client.logger.Sugar().Fatal(err)
 	}
 }
 
-func printList(resp *pb.ListResponse, jsonOut bool, log *zap.Logger) error {
-	if !jsonOut {
-		keys := strings.Split(resp.Keys, `','`)
-		keysCount := len(keys)
-		log.Sugar().Debugf("keys count: %v", keysCount)
-		fmt.Println("===== Keys ======")
-		for i, key := range keys {
-			if i == 0 {
-				if len(key) > 0 {
-					key = string([]rune(key)[1:])
-				}
-			}
-			if i == keysCount-1 {
-				if len(key) > 0 {
-					rKey := []rune(key)
-					key = string(rKey[:len(rKey)-1])
-				}
-			}
-			fmt.Println(key)
+// runListOffline prints secrets of offline cache
+func runListOffline(client *cliClient, opts listOptions, cmd *cobra.Command) {
+	req, err := listRequest(opts)
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	cache, err := openOfflineCache(cmd, client) // defined in cache.go
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	entries := cacheEntries(cache, req)
+	sortEntries(entries, opts.sortBy)
+	if err := printList(entries, opts.jsonOut, os.Stdout); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+}
+
+// listRequest checks flags of list command and returns request of first page
+func listRequest(opts listOptions) (*pb.ListRequest, error) {
+	req := pb.ListRequest{Prefix: opts.prefix, PageSize: listPageSize}
+	for _, t := range opts.types {
+		secretType, ok := pb.Type_value[strings.ToUpper(t)]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %s", t)
 		}
-	} else {
-		keys := strings.Split(resp.Keys, `','`)
-		keysCount := len(keys)
-		for i, key := range keys {
-			if i == 0 {
-				if len(key) > 0 {
-					key = string([]rune(key)[1:])
-				}
-			}
-			if i == keysCount-1 {
-				if len(key) > 0 {
-					rKey := []rune(key)
-					key = string(rKey[:len(rKey)-1])
-				}
-			}
-			keys[i] = key
+		req.Types = append(req.Types, pb.Type(secretType))
+	}
+	switch opts.sortBy {
+	case "name", "type":
+	default:
+		return nil, fmt.Errorf("unknown sort %s, use name or type", opts.sortBy)
+	}
+	return &req, nil
+}
+
+// listSecrets returns secrets of all pages of list
+func listSecrets(ctx context.Context, transport pb.KeepPasClient, req *pb.ListRequest) ([]*pb.ListEntry, error) {
+	var entries []*pb.ListEntry
+	for {
+		resp, err := transport.List(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, resp.Entries...)
+		if resp.NextPageToken == "" {
+			return entries, nil
+		}
+		req.PageToken = resp.NextPageToken
+	}
+}
+
+// sortEntries sorts secrets by name or by type and name, server returns them sorted by name
+func sortEntries(entries []*pb.ListEntry, sortBy string) {
+	if sortBy != "type" {
+		return
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Type.String() < entries[j].Type.String()
+	})
+}
+
+func printList(entries []*pb.ListEntry, jsonOut bool, out io.Writer) error {
+	if jsonOut {
+		items := make([]listItem, 0, len(entries))
+		for _, e := range entries {
+			items = append(items, listItem{Name: e.Name, Type: e.Type.String(), DataKey: e.DataKey})
 		}
-		out, err := json.MarshalIndent(keys, ``, strings.Repeat(` `, indentCount))
+		data, err := json.MarshalIndent(items, ``, strings.Repeat(` `, indentCount))
 		if err != nil {
-			log.Sugar().Debug(err)
 			return err
 		}
-		fmt.Println(string(out))
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	}
+	if _, err := fmt.Fprintln(out, "===== Keys ======"); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if _, err := fmt.Fprintln(out, e.Name+"  "+e.Type.String()); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -1,28 +1,65 @@
 package cli
 
 import (
+	"bytes"
+	"context"
 	"testing"
 
 	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
+// fakeListClient returns secrets by pages of two
+type fakeListClient struct {
+	pb.KeepPasClient
+	pages map[string]*pb.ListResponse
+	reqs  []*pb.ListRequest
+}
+
+func (f *fakeListClient) List(_ context.Context, in *pb.ListRequest, _ ...grpc.CallOption) (*pb.ListResponse, error) {
+	f.reqs = append(f.reqs, &pb.ListRequest{Prefix: in.Prefix, Types: in.Types, PageSize: in.PageSize, PageToken: in.PageToken})
+	return f.pages[in.PageToken], nil
+}
+
+func Test_listRequest(t *testing.T) {
+	req, err := listRequest(listOptions{prefix: "db/", types: []string{"login", "OTP"}, sortBy: "name"})
+	require.NoError(t, err)
+	assert.Equal(t, "db/", req.Prefix)
+	assert.Equal(t, []pb.Type{pb.Type_LOGIN, pb.Type_OTP}, req.Types)
+	assert.Equal(t, int32(listPageSize), req.PageSize)
+	_, err = listRequest(listOptions{types: []string{"note"}, sortBy: "name"})
+	assert.Error(t, err)
+	_, err = listRequest(listOptions{sortBy: "size"})
+	assert.Error(t, err)
+}
+
+func Test_listSecrets(t *testing.T) {
+	transport := fakeListClient{pages: map[string]*pb.ListResponse{
+		"":   {Entries: []*pb.ListEntry{{Name: "a", Type: pb.Type_TEXT}, {Name: "b", Type: pb.Type_LOGIN}}, NextPageToken: "Yg"},
+		"Yg": {Entries: []*pb.ListEntry{{Name: "c','d", Type: pb.Type_CART}}},
+	}}
+	entries, err := listSecrets(context.Background(), &transport, &pb.ListRequest{Prefix: "p", PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "c','d", entries[2].Name)
+	require.Len(t, transport.reqs, 2)
+	assert.Equal(t, &pb.ListRequest{Prefix: "p", PageSize: 2, PageToken: "Yg"}, transport.reqs[1])
+
+	sortEntries(entries, "type")
+	assert.Equal(t, []string{"c','d", "b", "a"}, []string{entries[0].Name, entries[1].Name, entries[2].Name})
+}
+
 func Test_printList(t *testing.T) {
-	tests := []struct {
-		name    string
-		resp    *pb.ListResponse
-		jsonFmt bool
-	}{
-		{"empty", &pb.ListResponse{Keys: ""}, false},
-		{"empty json", &pb.ListResponse{Keys: ""}, true},
-		{"text out", &pb.ListResponse{Keys: "'one','two'"}, false},
-		{"json out", &pb.ListResponse{Keys: "'one','two'"}, true},
-	}
-	var logger = zap.New(nil)
-	for _, tst := range tests {
-		t.Run(tst.name, func(t *testing.T) {
-			require.NoError(t, printList(tst.resp, tst.jsonFmt, logger))
-		})
-	}
+	entries := []*pb.ListEntry{{Name: "one", Type: pb.Type_TEXT, DataKey: true}, {Name: "t','wo", Type: pb.Type_LOGIN}}
+	var out bytes.Buffer
+	require.NoError(t, printList(entries, false, &out))
+	assert.Equal(t, "===== Keys ======\none  TEXT\nt','wo  LOGIN\n", out.String())
+	out.Reset()
+	require.NoError(t, printList(entries, true, &out))
+	assert.JSONEq(t, `[{"name":"one","type":"TEXT","datakey":true},{"name":"t','wo","type":"LOGIN","datakey":false}]`, out.String())
+	out.Reset()
+	require.NoError(t, printList(nil, true, &out))
+	assert.Equal(t, "[]\n", out.String())
 }
@@ -7,11 +7,15 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	pb "github.com/hrapovd1/gokeepas/internal/proto"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func newLoginCmd(clnt *cliClient) *cobra.Command {
@@ -27,6 +31,7 @@ func newLoginCmd(clnt *cliClient) *cobra.Command {
 	}
 	loginCmd.Flags().StringVarP(&opts.user, "username", "u", "", "login of user")
 	loginCmd.Flags().StringVarP(&opts.password, "password", "p", "", "password of user")
+	loginCmd.Flags().StringVarP(&opts.otp, "otp", "o", "", "one-time code or recovery code when 2FA is enabled")
 
 	return loginCmd
 }
@@ -47,10 +52,19 @@ func runLogin(client *cliClient, options loginOptions, cmd *cobra.Command) {
 	}(client.logger)
 	transport := pb.NewKeepPasClient(conn)
 	// call grpc method
-	resp, err := transport.LogIn(cmd.Context(), &pb.AuthRequest{
+	req := pb.AuthRequest{
 		Login:    options.user,
 		Password: options.password,
-	})
+		Otp:      options.otp,
+	}
+	resp, err := transport.LogIn(cmd.Context(), &req)
+	if status.Code(err) == codes.FailedPrecondition && req.Otp == "" {
+		// 2FA is enabled, ask one-time code
+		if req.Otp, err = promptLine(cmd.InOrStdin(), "one-time code: "); err != nil {
+			client.logger.Sugar().Fatalln(err)
+		}
+		resp, err = transport.LogIn(cmd.Context(), &req)
+	}
 	if err != nil {
 		client.logger.Sugar().Fatalln(err)
 	}
@@ -60,6 +74,13 @@ func runLogin(client *cliClient, options loginOptions, cmd *cobra.Command) {
 	if err := writeToken(resp.AuthToken, client.config.TokenCache, client.logger); err != nil {
 		client.logger.Sugar().Infof("write token error: %v", err)
 	}
+	if err := writeToken(resp.RefreshToken, client.config.TokenCache+refreshCacheSuffix, client.logger); err != nil {
+		client.logger.Sugar().Infof("write refresh token error: %v", err)
+	}
+	initKeyPair(cmd, client, transport, resp) // defined in share.go
+	if err := saveOfflineKey(client.config.OfflineDir, resp.Login, options.password, resp.SymmKey); err != nil {
+		client.logger.Sugar().Infof("write offline key error: %v", err)
+	}
 	fmt.Println("login success")
 }
 
@@ -116,3 +137,16 @@ func readToken(path string, l *zap.Logger) (string, error) {
 	}
 	return scanner.Text(), nil
 }
+
+// promptLine prints prompt and reads one line from in
+func promptLine(in io.Reader, prompt string) (string, error) {
+	fmt.Print(prompt)
+	scanner := bufio.NewScanner(in)
+	if ok := scanner.Scan(); !ok {
+		if scanner.Err() != nil {
+			return "", scanner.Err()
+		}
+		return "", io.EOF
+	}
+	return strings.TrimSpace(scanner.Text()), nil
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/hrapovd1/gokeepas/internal/crypto"
@@ -67,3 +68,15 @@ func Test_runLogin(t *testing.T) {
 		runLogin(&client, options, &cmd)
 	})
 }
+
+func Test_promptLine(t *testing.T) {
+	t.Run("right", func(t *testing.T) {
+		result, err := promptLine(strings.NewReader(" 123456 \n"), "code: ")
+		require.NoError(t, err)
+		assert.Equal(t, "123456", result)
+	})
+	t.Run("empty input", func(t *testing.T) {
+		_, err := promptLine(strings.NewReader(""), "code: ")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,59 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func newLogoutCmd(clnt *cliClient) *cobra.Command {
+	// logoutCmd represents the logout command
+	logoutCmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Logout from KeepPas server",
+		Long:  `Logout from KeepPas server, it revokes current session on the server and removes tokens from cache.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runLogout(clnt, cmd)
+		},
+	}
+
+	return logoutCmd
+}
+
+func runLogout(client *cliClient, cmd *cobra.Command) {
+	refresh, err := readToken(client.config.TokenCache+refreshCacheSuffix, client.logger)
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	transport := pb.NewKeepPasClient(conn)
+	// call grpc method
+	resp, err := transport.LogOut(cmd.Context(), &pb.RefreshRequest{RefreshToken: refresh})
+	if err != nil {
+		client.logger.Sugar().Fatalln(err)
+	}
+	client.logger.Sugar().Debug(resp)
+	removeTokens(client.config.TokenCache, client.logger)
+	fmt.Println("logout success")
+}
+
+// removeTokens deletes token cache files
+func removeTokens(path string, l *zap.Logger) {
+	for _, p := range []string{path, path + refreshCacheSuffix} {
+		if err := os.Remove(p); err != nil {
+			l.Sugar().Debug(err)
+		}
+	}
+}
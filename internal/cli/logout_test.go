@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_removeTokens(t *testing.T) {
+	logger := zap.New(nil)
+	tmpFile, err := os.CreateTemp("/tmp", "token")
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	require.NoError(t, writeToken("refresh", tmpFile.Name()+refreshCacheSuffix, logger))
+	removeTokens(tmpFile.Name(), logger)
+	_, err = os.Stat(tmpFile.Name())
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(tmpFile.Name() + refreshCacheSuffix)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,84 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func newOperatorCmd() *cobra.Command {
+	// operatorCmd represents the operator command
+	return &cobra.Command{
+		Use:   "operator",
+		Short: "Manage KeepPas server",
+	}
+}
+
+func newOperatorCmdUnseal(clnt *cliClient) *cobra.Command {
+	// unsealCmd represents the operator unseal command
+	return &cobra.Command{
+		Use:   "unseal",
+		Short: "Provide master key share to sealed server",
+		Long: `Provide master key share to sealed server, the share is asked in stdin so it doesn't get in shell history.
+Server is unsealed when threshold of shares from 'keeppas-server init' is provided.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runSeal(clnt, cmd, true)
+		},
+	}
+}
+
+func newOperatorCmdSeal(clnt *cliClient) *cobra.Command {
+	// sealCmd represents the operator seal command
+	return &cobra.Command{
+		Use:   "seal",
+		Short: "Wipe master key from server memory",
+		Long: `Wipe master key from server memory, server rejects requests until it is unsealed again.
+Any master key share is required, it is asked in stdin.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runSeal(clnt, cmd, false)
+		},
+	}
+}
+
+func runSeal(client *cliClient, cmd *cobra.Command, unseal bool) {
+	share, err := promptLine(cmd.InOrStdin(), "key share: ") // defined in login.go
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	transport := pb.NewKeepPasClient(conn)
+	// call grpc method
+	var resp *pb.SealStatus
+	if unseal {
+		resp, err = transport.Unseal(cmd.Context(), &pb.KeyShare{Share: share})
+	} else {
+		resp, err = transport.Seal(cmd.Context(), &pb.KeyShare{Share: share})
+	}
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := printSealStatus(resp, cmd.OutOrStdout()); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+}
+
+func printSealStatus(resp *pb.SealStatus, out io.Writer) error {
+	if !resp.Sealed {
+		_, err := fmt.Fprintln(out, "server is unsealed")
+		return err
+	}
+	_, err := fmt.Fprintf(out, "server is sealed, key shares: %d/%d\n", resp.Progress, resp.Threshold)
+	return err
+}
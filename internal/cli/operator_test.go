@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_printSealStatus(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, printSealStatus(&pb.SealStatus{Sealed: true, Threshold: 3, Progress: 1}, &out))
+	assert.Equal(t, "server is sealed, key shares: 1/3\n", out.String())
+	out.Reset()
+	require.NoError(t, printSealStatus(&pb.SealStatus{Threshold: 3, Progress: 3}, &out))
+	assert.Equal(t, "server is unsealed\n", out.String())
+}
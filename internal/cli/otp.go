@@ -0,0 +1,138 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/otp"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type otpCode struct {
+	Code      string `json:"code"`
+	Remaining int    `json:"remaining"` // seconds while code is valid
+}
+
+func newKVCmdOTP(clnt *cliClient) *cobra.Command {
+	getOutJSON := false
+	// otpCmd represents the otp command
+	otpCmd := &cobra.Command{
+		Use:   "otp KEY",
+		Short: "Get current one-time code of otp secret",
+		Long: `Get current one-time code of otp secret from KeepPas server and seconds while it is valid.
+Default output format is text, you can change output to JSON format with flag -j.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runOTP(clnt, getOutJSON, cmd, args)
+		},
+	}
+	otpCmd.Flags().BoolVarP(&getOutJSON, "json", "j", false, "print output in json. Default text format.")
+
+	return otpCmd
+}
+
+func runOTP(client *cliClient, jsonOut bool, cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		if err := cmd.Help(); err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+		return
+	}
+	if err := setAuthContext(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := getUserKey(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	transport := pb.NewKeepPasClient(conn)
+	// call grpc method
+	resp, err := transport.Get(cmd.Context(), &pb.BinRequest{Key: strings.Join(args, ``)})
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	code, err := genOTPCode(resp, client.config.UserKey, client.login, time.Now())
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := printOTPCode(code, jsonOut); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+}
+
+// genOTPCode decrypts otp secret and returns its code for time t
+func genOTPCode(r *pb.GetResponse, key string, owner string, t time.Time) (*otpCode, error) {
+	if r.Type != pb.Type_OTP {
+		return nil, errors.New("secret isn't otp type")
+	}
+	secret, err := crypto.OpenSecret([]byte(key), string(r.Data), r.DataKey, secretAD(owner, r.Key, r.Type))
+	if err != nil {
+		return nil, upgradeHint(err) // defined in upgrade.go
+	}
+	otpData := types.OTP{}
+	if err := json.Unmarshal(secret, &otpData); err != nil {
+		return nil, err
+	}
+	otpKey, err := otpKey(&otpData)
+	if err != nil {
+		return nil, err
+	}
+	code, err := otpKey.Code(t)
+	if err != nil {
+		return nil, err
+	}
+	return &otpCode{Code: code, Remaining: otpKey.Remaining(t)}, nil
+}
+
+// otpKey converts otp secret to code generator
+func otpKey(data *types.OTP) (*otp.Key, error) {
+	seed, err := otp.DecodeSecret(data.Secret)
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) == 0 {
+		return nil, errors.New("empty otp secret")
+	}
+	return &otp.Key{
+		Secret:    seed,
+		Algorithm: data.Algorithm,
+		Digits:    data.Digits,
+		Period:    data.Period,
+		Issuer:    data.Issuer,
+		Account:   data.Account,
+	}, nil
+}
+
+func printOTPCode(code *otpCode, jsonFmt bool) error {
+	if !jsonFmt {
+		fmt.Println("====== OTP ======")
+		fmt.Println("Field       Value")
+		fmt.Println("-----       -----")
+		fmt.Print("code        ")
+		fmt.Println(code.Code)
+		fmt.Print("remaining   ")
+		fmt.Println(code.Remaining)
+		return nil
+	}
+	out, err := json.MarshalIndent(code, ``, strings.Repeat(` `, indentCount))
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
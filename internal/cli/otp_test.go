@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_runOTP(t *testing.T) {
+	client := cliClient{logger: zap.New(nil), config: config.Config{}}
+	t.Run("empty args", func(t *testing.T) {
+		runOTP(&client, false, &cobra.Command{}, []string{})
+	})
+}
+
+func Test_genOTPCode(t *testing.T) {
+	key := "1234567890poiuyt"
+	// RFC 6238 test seed "12345678901234567890"
+	data, dataKey, err := crypto.SealSecret([]byte(key), []byte(`{"secret":"GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ","algorithm":"SHA1","digits":8,"period":30}`), secretAD("test", "one", pb.Type_OTP))
+	require.NoError(t, err)
+	t.Run("right", func(t *testing.T) {
+		code, err := genOTPCode(&pb.GetResponse{Key: "one", Type: pb.Type_OTP, Data: []byte(data), DataKey: dataKey}, key, "test", time.Unix(59, 0))
+		require.NoError(t, err)
+		assert.Equal(t, &otpCode{Code: "94287082", Remaining: 1}, code)
+	})
+	t.Run("wrong type", func(t *testing.T) {
+		_, err := genOTPCode(&pb.GetResponse{Key: "one", Type: pb.Type_TEXT, Data: []byte(data), DataKey: dataKey}, key, "test", time.Now())
+		require.Error(t, err)
+	})
+	t.Run("wrong key", func(t *testing.T) {
+		_, err := genOTPCode(&pb.GetResponse{Key: "one", Type: pb.Type_OTP, Data: []byte(data), DataKey: dataKey}, "0987654321poiuyt", "test", time.Now())
+		require.Error(t, err)
+	})
+}
+
+func Test_printOTPCode(t *testing.T) {
+	code := otpCode{Code: "123456", Remaining: 10}
+	require.NoError(t, printOTPCode(&code, false))
+	require.NoError(t, printOTPCode(&code, true))
+}
@@ -4,6 +4,8 @@ Package cli contents methods and types for KeepPas cli client.
 package cli
 
 import (
+	"fmt"
+
 	pb "github.com/hrapovd1/gokeepas/internal/proto"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -11,15 +13,23 @@ import (
 )
 
 func newKVCmdRm(clnt *cliClient) *cobra.Command {
+	offline := false
 	// rmCmd represents the remove command
 	rmCmd := &cobra.Command{
-		Use:   "remove KEY",
+		Use:   "remove KEY [KEY...]",
 		Short: "Remove secret on KeepPas server",
-		Long:  `Remove secret on KeepPas server, always return ok.`,
+		Long: `Remove secret on KeepPas server, always return ok for missing key.
+Several keys are removed by one request, keys which can't be removed are reported.
+When server isn't available or with flag --offline removal is saved in offline journal.`,
 		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) > 0 && useOffline(clnt, offline) {
+				runRmOffline(clnt, cmd, args)
+				return
+			}
 			runRm(clnt, cmd, args)
 		},
 	}
+	rmCmd.Flags().BoolVar(&offline, "offline", false, "save removal in offline journal, it is sent to server later")
 	return rmCmd
 }
 
@@ -41,6 +51,7 @@ func runRm(client *cliClient, cmd *cobra.Command, args []string) {
 	if err := getUserKey(cmd, client); err != nil {
 		client.logger.Sugar().Fatal(err)
 	}
+	replayPending(cmd, client) // defined in journal.go
 	// process grpc client
 	conn := client.transport(client.config.ServerAddr, client.logger)
 	defer func(l *zap.Logger) {
@@ -49,6 +60,16 @@ func runRm(client *cliClient, cmd *cobra.Command, args []string) {
 		}
 	}(client.logger)
 	transport := pb.NewKeepPasClient(conn)
+	if len(args) > 1 {
+		resp, err := transport.RemoveMany(cmd.Context(), &pb.BatchRequest{Items: batchItems(args)})
+		if err != nil {
+			client.logger.Sugar().Fatalln(err)
+		}
+		if err := batchError(resp.Items, client.logger); err != nil {
+			client.logger.Sugar().Fatalln(err)
+		}
+		return
+	}
 	// call grpc method
 	resp, err := transport.Remove(cmd.Context(), &pb.BinRequest{
 		Key: args[0],
@@ -58,3 +79,17 @@ func runRm(client *cliClient, cmd *cobra.Command, args []string) {
 	}
 	client.logger.Sugar().Debug(resp)
 }
+
+// runRmOffline queues removal of keys in offline journal
+func runRmOffline(client *cliClient, cmd *cobra.Command, args []string) {
+	cache, err := openOfflineCache(cmd, client) // defined in cache.go
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	for _, key := range args {
+		if err := queueOp(client.config.OfflineDir, []byte(client.config.UserKey), cache, journalOp{Op: opRemove, Key: key}); err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+	}
+	fmt.Println(offlineQueued)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/hrapovd1/gokeepas/internal/crypto"
 	pb "github.com/hrapovd1/gokeepas/internal/proto"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -68,13 +69,39 @@ func runRename(client *cliClient, dlmtr string, cmd *cobra.Command, args []strin
 		}
 	}(client.logger)
 	transport := pb.NewKeepPasClient(conn)
+	// secret is bound to its name, so its data key has to be re-wrapped for new name
+	data, dataKey, err := reencryptSecret(cmd, client, transport, values[0], values[1])
+	if err != nil {
+		client.logger.Sugar().Fatalln(err)
+	}
 	// call grpc method
 	resp, err := transport.Rename(cmd.Context(), &pb.BinRequest{
-		Key:    values[0],
-		NewKey: values[1],
+		Key:     values[0],
+		NewKey:  values[1],
+		Data:    data,
+		DataKey: dataKey,
 	})
 	if err != nil {
 		client.logger.Sugar().Fatalln(err)
 	}
 	client.logger.Sugar().Debug(resp)
 }
+
+// reencryptSecret reads secret oldKey and returns its data key re-wrapped for newKey name.
+// Secret without data key isn't renamed, it has to be upgraded first.
+func reencryptSecret(cmd *cobra.Command, client *cliClient, transport pb.KeepPasClient, oldKey string, newKey string) (string, string, error) {
+	resp, err := transport.Get(cmd.Context(), &pb.BinRequest{Key: oldKey})
+	if err != nil {
+		return "", "", err
+	}
+	userKey := []byte(client.config.UserKey)
+	if resp.DataKey == "" {
+		return "", "", upgradeHint(crypto.ErrNoDataKey) // defined in upgrade.go
+	}
+	dataKey, err := crypto.UnwrapDataKey(userKey, resp.DataKey, secretAD(client.login, oldKey, resp.Type))
+	if err != nil {
+		return "", "", err
+	}
+	wrapped, err := crypto.WrapDataKey(userKey, dataKey, secretAD(client.login, newKey, resp.Type))
+	return "", wrapped, err
+}
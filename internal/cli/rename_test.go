@@ -1,15 +1,60 @@
 package cli
 
 import (
+	"context"
 	"testing"
 
+	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
 	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
+// fakeKeepPasClient returns prepared response on Get call
+type fakeKeepPasClient struct {
+	pb.KeepPasClient
+	getResp *pb.GetResponse
+}
+
+func (f fakeKeepPasClient) Get(_ context.Context, in *pb.BinRequest, _ ...grpc.CallOption) (*pb.GetResponse, error) {
+	return &pb.GetResponse{Key: in.Key, Type: f.getResp.Type, Data: f.getResp.Data, DataKey: f.getResp.DataKey}, nil
+}
+
 func Test_runRename(t *testing.T) {
 	t.Run("simple", func(t *testing.T) {
 		client := cliClient{logger: zap.New(nil)}
 		runRename(&client, " ", &cobra.Command{}, []string{})
 	})
 }
+
+func Test_reencryptSecret(t *testing.T) {
+	client := cliClient{logger: zap.New(nil), config: config.Config{UserKey: "1234567890poiuyt"}, login: "test"}
+	userKey := []byte(client.config.UserKey)
+	cmd := cobra.Command{}
+	cmd.SetContext(context.Background())
+	t.Run("data key", func(t *testing.T) {
+		data, dataKey, err := crypto.SealSecret(userKey, []byte(`{"text":"one"}`), secretAD("test", "old", pb.Type_TEXT))
+		require.NoError(t, err)
+		transport := fakeKeepPasClient{getResp: &pb.GetResponse{Type: pb.Type_TEXT, Data: []byte(data), DataKey: dataKey}}
+		newData, newDataKey, err := reencryptSecret(&cmd, &client, transport, "old", "new")
+		require.NoError(t, err)
+		// only data key is re-wrapped
+		assert.Empty(t, newData)
+		result, err := crypto.OpenSecret(userKey, data, newDataKey, secretAD("test", "new", pb.Type_TEXT))
+		require.NoError(t, err)
+		assert.Equal(t, `{"text":"one"}`, string(result))
+		_, err = crypto.OpenSecret(userKey, data, newDataKey, secretAD("test", "old", pb.Type_TEXT))
+		require.ErrorIs(t, err, crypto.ErrTampered)
+	})
+	t.Run("legacy", func(t *testing.T) {
+		data, err := crypto.EncryptData(userKey, []byte(`{"text":"one"}`), secretAD("test", "old", pb.Type_TEXT))
+		require.NoError(t, err)
+		transport := fakeKeepPasClient{getResp: &pb.GetResponse{Type: pb.Type_TEXT, Data: []byte(data)}}
+		_, _, err = reencryptSecret(&cmd, &client, transport, "old", "new")
+		require.ErrorIs(t, err, crypto.ErrNoDataKey)
+	})
+}
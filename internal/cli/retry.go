@@ -0,0 +1,65 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	retryAfterMsHeader = "retry-after-ms"       // header of server with milliseconds to wait before retry of limited call
+	limitRetries       = 4                      // count of retries of call rejected by server limits
+	limitBaseDelay     = 100 * time.Millisecond // delay of first retry, it doubles with each next retry
+	limitMaxDelay      = 10 * time.Second       // call isn't retried when server asks to wait longer
+)
+
+// retryAfter returns time to wait from retry-after-ms header of server.
+func retryAfter(header metadata.MD) (time.Duration, bool) {
+	vals := header.Get(retryAfterMsHeader)
+	if len(vals) == 0 {
+		return 0, false
+	}
+	ms, err := strconv.ParseInt(vals[0], 10, 64)
+	if err != nil || ms < 0 {
+		return 0, false
+	}
+	return time.Duration(ms) * time.Millisecond, true
+}
+
+// retryLimited is client interceptor which retries call rejected by rate or concurrency limits
+// of server. It waits time from retry-after-ms header but not less than exponential backoff.
+// Rejection without the header, like login lockout, isn't retried.
+func retryLimited(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	for attempt := 0; ; attempt++ {
+		var header metadata.MD
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&header))...)
+		if status.Code(err) != codes.ResourceExhausted || attempt == limitRetries {
+			return err
+		}
+		wait, ok := retryAfter(header)
+		if !ok {
+			return err
+		}
+		if backoff := limitBaseDelay << attempt; wait < backoff {
+			wait = backoff
+		}
+		if wait > limitMaxDelay {
+			return err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
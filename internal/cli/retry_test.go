@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// limitedInvoker rejects first calls with header, other calls succeed
+func limitedInvoker(rejects int, header metadata.MD, calls *int) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		*calls++
+		if *calls > rejects {
+			return nil
+		}
+		for _, o := range opts {
+			if h, ok := o.(grpc.HeaderCallOption); ok {
+				*h.HeaderAddr = header
+			}
+		}
+		return status.Error(codes.ResourceExhausted, "rate limit of read calls is exceeded")
+	}
+}
+
+func Test_retryAfter(t *testing.T) {
+	wait, ok := retryAfter(metadata.Pairs(retryAfterMsHeader, "250"))
+	assert.True(t, ok)
+	assert.Equal(t, 250*time.Millisecond, wait)
+	_, ok = retryAfter(metadata.Pairs(retryAfterMsHeader, "soon"))
+	assert.False(t, ok)
+	_, ok = retryAfter(metadata.MD{})
+	assert.False(t, ok)
+}
+
+func Test_retryLimited(t *testing.T) {
+	t.Run("retried", func(t *testing.T) {
+		calls := 0
+		start := time.Now()
+		err := retryLimited(context.Background(), "/gokeepas.KeepPas/Get", nil, nil, nil,
+			limitedInvoker(1, metadata.Pairs(retryAfterMsHeader, "10"), &calls))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+		// backoff is longer than hint of server
+		assert.GreaterOrEqual(t, time.Since(start), limitBaseDelay)
+	})
+	t.Run("lockout", func(t *testing.T) {
+		calls := 0
+		err := retryLimited(context.Background(), "/gokeepas.KeepPas/LogIn", nil, nil, nil,
+			limitedInvoker(1, metadata.Pairs("retry-after", "60"), &calls))
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("long wait", func(t *testing.T) {
+		calls := 0
+		err := retryLimited(context.Background(), "/gokeepas.KeepPas/Get", nil, nil, nil,
+			limitedInvoker(1, metadata.Pairs(retryAfterMsHeader, "60000"), &calls))
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("canceled", func(t *testing.T) {
+		calls := 0
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := retryLimited(ctx, "/gokeepas.KeepPas/Get", nil, nil, nil,
+			limitedInvoker(1, metadata.Pairs(retryAfterMsHeader, "10"), &calls))
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, 1, calls)
+	})
+}
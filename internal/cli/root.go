@@ -12,11 +12,15 @@ import (
 	"time"
 
 	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
 	pb "github.com/hrapovd1/gokeepas/internal/proto"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/spf13/cobra"
 )
@@ -43,23 +47,29 @@ func NewRootCmd() *cobra.Command {
 		srvAddr string // for persistent flag
 		dbg     bool   // for persistent flag
 		tcache  string // for persistent flag
+		breach  string // for persistent flag
+		offline string // for persistent flag
 		client  = cliClient{}
 	)
 
 	rootCmd.PersistentFlags().BoolVar(&dbg, "debug", false, "Turn on debug messages output.")
 	rootCmd.PersistentFlags().StringVarP(&srvAddr, "server", "s", "localhost:5000", "ip/dns:port")
 	rootCmd.PersistentFlags().StringVarP(&tcache, "cache", "c", home+"/.keeppas.token", "token cache")
+	rootCmd.PersistentFlags().StringVar(&breach, "breach-file", "", "HIBP SHA-1 file or directory of range files to check passwords against")
+	rootCmd.PersistentFlags().StringVar(&offline, "offline-dir", offlineDir(home), "directory of offline cache of secrets")
 	cobra.OnInitialize(func() {
 		client.config.LogLevel = config.LoggerConfig(dbg)
 		client.config.ServerAddr = srvAddr
 		client.config.TokenCache = tcache
+		client.config.BreachFile = breach
+		client.config.OfflineDir = offline
 		client.logger = loggerConfig(client.config.LogLevel)
 		client.transport = newGRPCConnection
 	})
 
 	rootCmd.SetVersionTemplate(version + " Build at " + BuildTime + "\n")
 
-	kvCmd := newKVCmd()
+	kvCmd := newKVCmd(&client)
 	kvCmd.AddCommand(newKVCmdAdd(&client))
 	kvCmd.AddCommand(newKVCmdCP(&client))
 	kvCmd.AddCommand(newKVCmdGet(&client))
@@ -67,11 +77,47 @@ func NewRootCmd() *cobra.Command {
 	kvCmd.AddCommand(newKVCmdRename(&client))
 	kvCmd.AddCommand(newKVCmdUpdate(&client))
 	kvCmd.AddCommand(newKVCmdList(&client))
+	kvCmd.AddCommand(newKVCmdOTP(&client))
+	kvCmd.AddCommand(newKVCmdSearch(&client))
+	kvCmd.AddCommand(newKVCmdWatch(&client))
+	kvCmd.AddCommand(newKVCmdShare(&client))
+	kvCmd.AddCommand(newKVCmdUnshare(&client))
+	kvCmd.AddCommand(newKVCmdShared(&client))
+	kvCmd.AddCommand(newKVCmdUpgrade(&client))
 
 	rootCmd.AddCommand(newSignupCmd(&client))
 	rootCmd.AddCommand(newLoginCmd(&client))
+	rootCmd.AddCommand(newLogoutCmd(&client))
+	rootCmd.AddCommand(newGenCmd(&client))
+	rootCmd.AddCommand(newSyncCmd(&client))
 	rootCmd.AddCommand(kvCmd)
 
+	twoFACmd := newTwoFACmd()
+	twoFACmd.AddCommand(newTwoFACmdEnable(&client))
+	twoFACmd.AddCommand(newTwoFACmdDisable(&client))
+	accountCmd := newAccountCmd()
+	accountCmd.AddCommand(twoFACmd)
+	accountCmd.AddCommand(newAccountCmdFingerprint(&client))
+	rootCmd.AddCommand(accountCmd)
+
+	vaultCmd := newVaultCmd()
+	vaultCmd.AddCommand(newVaultCmdCreate(&client))
+	vaultCmd.AddCommand(newVaultCmdList(&client))
+	vaultCmd.AddCommand(newVaultCmdMembers(&client))
+	vaultCmd.AddCommand(newVaultCmdInvite(&client))
+	vaultCmd.AddCommand(newVaultCmdRole(&client))
+	vaultCmd.AddCommand(newVaultCmdRemove(&client))
+	rootCmd.AddCommand(vaultCmd)
+
+	auditCmd := newAuditCmd()
+	auditCmd.AddCommand(newAuditCmdLog(&client))
+	rootCmd.AddCommand(auditCmd)
+
+	operatorCmd := newOperatorCmd()
+	operatorCmd.AddCommand(newOperatorCmdUnseal(&client))
+	operatorCmd.AddCommand(newOperatorCmdSeal(&client))
+	rootCmd.AddCommand(operatorCmd)
+
 	return rootCmd
 }
 
@@ -88,6 +134,18 @@ func loggerConfig(lvl zapcore.Level) *zap.Logger {
 	return logger
 }
 
+// setAuthContext puts bearer token from cache in cmd context
+func setAuthContext(cmd *cobra.Command, clnt *cliClient) error {
+	token, err := readToken(clnt.config.TokenCache, clnt.logger)
+	if err != nil {
+		return err
+	}
+	clnt.token = token
+	md := metadata.New(map[string]string{"bearer-token": token})
+	cmd.SetContext(metadata.NewOutgoingContext(cmd.Context(), md))
+	return nil
+}
+
 func getUserKey(cmd *cobra.Command, clnt *cliClient) error {
 	// process grpc client
 	conn := clnt.transport(clnt.config.ServerAddr, clnt.logger)
@@ -99,6 +157,15 @@ func getUserKey(cmd *cobra.Command, clnt *cliClient) error {
 	transport := pb.NewKeepPasClient(conn)
 	// call grpc method
 	resp, err := transport.GetKey(cmd.Context(), &pb.BinRequest{})
+	if status.Code(err) == codes.Unauthenticated {
+		// bearer token is expired, try to refresh it and repeat
+		clnt.logger.Sugar().Debug(err)
+		if err := refreshToken(cmd, clnt, transport); err != nil {
+			clnt.logger.Sugar().Debug(err)
+			return errors.New("session is expired, please login again")
+		}
+		resp, err = transport.GetKey(cmd.Context(), &pb.BinRequest{})
+	}
 	if err != nil {
 		clnt.logger.Sugar().Debug(err)
 		return err
@@ -108,6 +175,38 @@ func getUserKey(cmd *cobra.Command, clnt *cliClient) error {
 		return errors.New(resp.Error)
 	}
 	clnt.config.UserKey = string(resp.SymmKey)
+	clnt.login = resp.Login
+	if clnt.vault != "" {
+		// kv commands work with vault secrets by vault key
+		return useVault(cmd, clnt, transport) // defined in vault.go
+	}
+	return nil
+}
+
+// secretAD returns associated data which binds secret ciphertext to its owner, name and type
+func secretAD(owner string, name string, secretType pb.Type) []byte {
+	return crypto.AssociatedData(owner, name, secretType.String())
+}
+
+// refreshToken rotates session tokens in cache and puts new bearer token in cmd context
+func refreshToken(cmd *cobra.Command, clnt *cliClient, transport pb.KeepPasClient) error {
+	refresh, err := readToken(clnt.config.TokenCache+refreshCacheSuffix, clnt.logger)
+	if err != nil {
+		return err
+	}
+	resp, err := transport.Refresh(cmd.Context(), &pb.RefreshRequest{RefreshToken: refresh})
+	if err != nil {
+		return err
+	}
+	if err := writeToken(resp.AuthToken, clnt.config.TokenCache, clnt.logger); err != nil {
+		return err
+	}
+	if err := writeToken(resp.RefreshToken, clnt.config.TokenCache+refreshCacheSuffix, clnt.logger); err != nil {
+		return err
+	}
+	clnt.token = resp.AuthToken
+	md := metadata.New(map[string]string{"bearer-token": resp.AuthToken})
+	cmd.SetContext(metadata.NewOutgoingContext(cmd.Context(), md))
 	return nil
 }
 
@@ -139,7 +238,7 @@ func newGRPCConnection(srvAddr string, l *zap.Logger) *grpc.ClientConn {
 
 	// grpc client
 	creds := credentials.NewClientTLSFromCert(crtPool, "")
-	conn, err := grpc.Dial(srvAddr, grpc.WithTransportCredentials(creds))
+	conn, err := grpc.Dial(srvAddr, grpc.WithTransportCredentials(creds), grpc.WithUnaryInterceptor(retryLimited))
 	if err != nil {
 		l.Fatal(err.Error())
 	}
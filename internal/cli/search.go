@@ -0,0 +1,388 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	searchBatch   = 100 // count of secrets got by one GetMany request
+	searchWorkers = 4   // count of parallel GetMany requests
+)
+
+// Scores of term match, the best match of term in secret fields is taken.
+const (
+	scoreExact       = 100 // field equals term
+	scoreWordPrefix  = 80  // word of field starts with term
+	scoreSubstring   = 60  // field contains term
+	scoreTypo        = 40  // word of field differs from term by one letter
+	scoreSubsequence = 20  // letters of term are in field in the same order
+	nameBonus        = 10  // match in key name is ranked higher
+)
+
+// searchOptions keeps flags of search command
+type searchOptions struct {
+	jsonOut   bool
+	sensitive bool
+}
+
+// searchField is searchable field of decrypted secret
+type searchField struct {
+	name  string
+	value string
+}
+
+// searchResult is found secret, it keeps names of matched fields only, not their values
+type searchResult struct {
+	Key    string   `json:"key"`
+	Type   string   `json:"type"`
+	Score  int      `json:"score"`
+	Fields []string `json:"fields"`
+}
+
+func newKVCmdSearch(clnt *cliClient) *cobra.Command {
+	opts := searchOptions{}
+	// searchCmd represents the search command
+	searchCmd := &cobra.Command{
+		Use:   "search QUERY",
+		Short: "Search secrets by names and fields",
+		Long: `Search secrets by names and fields. Server keeps only encrypted secrets, so client gets
+and decrypts all secrets and searches them locally. Key names, logins, texts, card holders,
+otp issuers and accounts and extra info are searched, passwords, card numbers, CVC and otp seeds
+are searched only with flag --include-sensitive. Every word of query must match, words match
+fuzzy: by prefix, substring, one typo or letters in the same order. Found secrets are ranked by
+match, only names of matched fields are printed.
+Default output format is text, you can change output to JSON format with flag -j.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runSearch(clnt, opts, cmd, args)
+		},
+	}
+	searchCmd.Flags().BoolVarP(&opts.jsonOut, "json", "j", false, "print output in json. Default text format.")
+	searchCmd.Flags().BoolVar(&opts.sensitive, "include-sensitive", false, "search passwords, card numbers, CVC and otp seeds too")
+
+	return searchCmd
+}
+
+func runSearch(client *cliClient, opts searchOptions, cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		if err := cmd.Help(); err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+		return
+	}
+	if err := setAuthContext(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := getUserKey(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	transport := pb.NewKeepPasClient(conn)
+	results, err := searchSecrets(cmd.Context(), client, transport, strings.Join(args, " "), opts.sensitive)
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := printSearch(results, opts.jsonOut, os.Stdout); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+}
+
+// searchSecrets gets and decrypts all secrets and returns ones matched query sorted by rank.
+func searchSecrets(ctx context.Context, client *cliClient, transport pb.KeepPasClient, query string, sensitive bool) ([]searchResult, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+	entries, err := listSecrets(ctx, transport, &pb.ListRequest{PageSize: listPageSize})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		results  []searchResult
+		firstErr error
+	)
+	sem := make(chan struct{}, searchWorkers)
+	for start := 0; start < len(entries); start += searchBatch {
+		end := start + searchBatch
+		if end > len(entries) {
+			end = len(entries)
+		}
+		keys := make([]string, 0, end-start)
+		for _, e := range entries[start:end] {
+			keys = append(keys, e.Name)
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			found, err := searchBatchSecrets(ctx, client, transport, keys, terms, sensitive)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			results = append(results, found...)
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Key < results[j].Key
+	})
+	return results, nil
+}
+
+// searchBatchSecrets gets secrets keys by one request, decrypts and matches them.
+// Secrets which can't be got or decrypted are skipped.
+func searchBatchSecrets(ctx context.Context, client *cliClient, transport pb.KeepPasClient, keys []string, terms []string, sensitive bool) ([]searchResult, error) {
+	resp, err := transport.GetMany(ctx, &pb.BatchRequest{Items: batchItems(keys)})
+	if err != nil {
+		return nil, err
+	}
+	var out []searchResult
+	for _, item := range resp.Items {
+		if codes.Code(item.Code) != codes.OK {
+			client.logger.Sugar().Debugf("%s: %s", item.Key, item.Error)
+			continue
+		}
+		s := item.Secret
+		secret, err := crypto.OpenSecret([]byte(client.config.UserKey), string(s.Data), s.DataKey, secretAD(client.login, s.Key, s.Type))
+		if err != nil {
+			client.logger.Sugar().Debugf("%s: %v", item.Key, err)
+			continue
+		}
+		fields, err := searchFields(s.Type, secret, sensitive)
+		if err != nil {
+			client.logger.Sugar().Debugf("%s: %v", item.Key, err)
+			continue
+		}
+		fields = append([]searchField{{name: "name", value: s.Key}}, fields...)
+		if score, matched := matchSecret(terms, fields); score > 0 {
+			out = append(out, searchResult{Key: s.Key, Type: s.Type.String(), Score: score, Fields: matched})
+		}
+	}
+	return out, nil
+}
+
+// searchFields returns searchable fields of decrypted secret, sensitive fields are returned
+// only when sensitive is true. Binary data isn't searched.
+func searchFields(secretType pb.Type, secret []byte, sensitive bool) ([]searchField, error) {
+	var fields []searchField
+	var info []string
+	switch secretType {
+	case pb.Type_LOGIN:
+		s := types.Login{}
+		if err := json.Unmarshal(secret, &s); err != nil {
+			return nil, err
+		}
+		fields = append(fields, searchField{"login", s.Login})
+		if sensitive {
+			fields = append(fields, searchField{"password", s.Password})
+		}
+		info = s.Info
+	case pb.Type_TEXT:
+		s := types.Text{}
+		if err := json.Unmarshal(secret, &s); err != nil {
+			return nil, err
+		}
+		fields = append(fields, searchField{"text", s.Text})
+		info = s.Info
+	case pb.Type_BINARY:
+		s := types.Binary{}
+		if err := json.Unmarshal(secret, &s); err != nil {
+			return nil, err
+		}
+		info = s.Info
+	case pb.Type_CART:
+		s := types.Cart{}
+		if err := json.Unmarshal(secret, &s); err != nil {
+			return nil, err
+		}
+		fields = append(fields, searchField{"holder", s.Holder}, searchField{"expired", s.Expired})
+		if sensitive {
+			fields = append(fields, searchField{"number", s.Number}, searchField{"cvc", s.CVC})
+		}
+		info = s.Info
+	case pb.Type_OTP:
+		s := types.OTP{}
+		if err := json.Unmarshal(secret, &s); err != nil {
+			return nil, err
+		}
+		fields = append(fields, searchField{"issuer", s.Issuer}, searchField{"account", s.Account})
+		if sensitive {
+			fields = append(fields, searchField{"secret", s.Secret})
+		}
+		info = s.Info
+	}
+	for _, s := range info {
+		fields = append(fields, searchField{"info", s})
+	}
+	return fields, nil
+}
+
+// matchSecret returns rank of secret and names of matched fields, every term must match
+// some field otherwise rank is 0.
+func matchSecret(terms []string, fields []searchField) (int, []string) {
+	total := 0
+	matched := make(map[string]bool)
+	for _, term := range terms {
+		best, bestField := 0, ""
+		for _, f := range fields {
+			score := matchScore(term, strings.ToLower(f.value))
+			if score > 0 && f.name == "name" {
+				score += nameBonus
+			}
+			if score > best {
+				best, bestField = score, f.name
+			}
+		}
+		if best == 0 {
+			return 0, nil
+		}
+		total += best
+		matched[bestField] = true
+	}
+	names := make([]string, 0, len(matched))
+	// keep order of fields in secret
+	for _, f := range fields {
+		if matched[f.name] {
+			names = append(names, f.name)
+			delete(matched, f.name)
+		}
+	}
+	return total, names
+}
+
+// matchScore returns score of lower case term in lower case text, 0 means no match.
+func matchScore(term string, text string) int {
+	if text == "" {
+		return 0
+	}
+	if text == term {
+		return scoreExact
+	}
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		if strings.HasPrefix(w, term) {
+			return scoreWordPrefix
+		}
+	}
+	if strings.Contains(text, term) {
+		return scoreSubstring
+	}
+	if len([]rune(term)) >= 4 {
+		for _, w := range words {
+			if editDistance(term, w) <= 1 {
+				return scoreTypo
+			}
+		}
+	}
+	if isSubsequence(term, text) {
+		return scoreSubsequence
+	}
+	return 0
+}
+
+// isSubsequence reports all letters of term are in text in the same order
+func isSubsequence(term string, text string) bool {
+	rest := []rune(term)
+	for _, r := range text {
+		if len(rest) == 0 {
+			break
+		}
+		if r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return len(rest) == 0
+}
+
+// editDistance returns Levenshtein distance of a and b
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func minInt(values ...int) int {
+	out := values[0]
+	for _, v := range values[1:] {
+		if v < out {
+			out = v
+		}
+	}
+	return out
+}
+
+func printSearch(results []searchResult, jsonOut bool, out io.Writer) error {
+	if jsonOut {
+		if results == nil {
+			results = []searchResult{}
+		}
+		data, err := json.MarshalIndent(results, "", strings.Repeat(" ", indentCount))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	}
+	if _, err := fmt.Fprintln(out, "===== Found ======"); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if _, err := fmt.Fprintln(out, r.Key+"  "+r.Type+"  "+strings.Join(r.Fields, ",")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// fakeSearchClient keeps encrypted secrets in memory
+type fakeSearchClient struct {
+	pb.KeepPasClient
+	mu      sync.Mutex
+	secrets map[string]*pb.GetResponse
+	calls   int
+}
+
+func (f *fakeSearchClient) List(_ context.Context, _ *pb.ListRequest, _ ...grpc.CallOption) (*pb.ListResponse, error) {
+	resp := pb.ListResponse{}
+	for name, s := range f.secrets {
+		resp.Entries = append(resp.Entries, &pb.ListEntry{Name: name, Type: s.Type})
+	}
+	return &resp, nil
+}
+
+func (f *fakeSearchClient) GetMany(_ context.Context, in *pb.BatchRequest, _ ...grpc.CallOption) (*pb.BatchResponse, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	resp := pb.BatchResponse{}
+	for _, item := range in.Items {
+		s, ok := f.secrets[item.Key]
+		if !ok {
+			resp.Items = append(resp.Items, &pb.BatchItem{Key: item.Key, Code: int32(codes.NotFound)})
+			continue
+		}
+		resp.Items = append(resp.Items, &pb.BatchItem{Key: item.Key, Secret: s})
+	}
+	return &resp, nil
+}
+
+func Test_searchSecrets(t *testing.T) {
+	client := cliClient{logger: zap.New(nil), config: config.Config{UserKey: "1234567890poiuyt"}, login: "test"}
+	transport := fakeSearchClient{secrets: map[string]*pb.GetResponse{}}
+	add := func(key string, secretType pb.Type, secret string) {
+		data, dataKey, err := crypto.SealSecret([]byte(client.config.UserKey), []byte(secret), secretAD("test", key, secretType))
+		require.NoError(t, err)
+		transport.secrets[key] = &pb.GetResponse{Key: key, Type: secretType, Data: []byte(data), DataKey: dataKey}
+	}
+	add("work/vpn", pb.Type_LOGIN, `{"login":"jdoe","password":"hunter2","info":["office network"]}`)
+	add("mail", pb.Type_LOGIN, `{"login":"john.doe@example.com","password":"vpnpass"}`)
+	add("bank", pb.Type_CART, `{"number":"4111111111111111","holder":"JOHN DOE","cvc":"123"}`)
+	add("notes", pb.Type_TEXT, `{"text":"wifi password is in the vpn login"}`)
+	for i := 0; i < 2*searchBatch; i++ {
+		add(fmt.Sprintf("filler%d", i), pb.Type_TEXT, `{"text":"nothing"}`)
+	}
+	ctx := context.Background()
+
+	t.Run("ranked", func(t *testing.T) {
+		results, err := searchSecrets(ctx, &client, &transport, "vpn", false)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, searchResult{Key: "work/vpn", Type: "LOGIN", Score: scoreWordPrefix + nameBonus, Fields: []string{"name"}}, results[0])
+		assert.Equal(t, "notes", results[1].Key)
+		assert.Equal(t, []string{"text"}, results[1].Fields)
+	})
+	t.Run("all terms", func(t *testing.T) {
+		results, err := searchSecrets(ctx, &client, &transport, "JDOE office", false)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, []string{"login", "info"}, results[0].Fields)
+	})
+	t.Run("sensitive", func(t *testing.T) {
+		results, err := searchSecrets(ctx, &client, &transport, "hunter2", false)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+		results, err = searchSecrets(ctx, &client, &transport, "hunter2", true)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, []string{"password"}, results[0].Fields)
+		results, err = searchSecrets(ctx, &client, &transport, "4111", false)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+	t.Run("typo", func(t *testing.T) {
+		results, err := searchSecrets(ctx, &client, &transport, "offce", false)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, scoreTypo, results[0].Score)
+	})
+	t.Run("batches", func(t *testing.T) {
+		transport.calls = 0
+		_, err := searchSecrets(ctx, &client, &transport, "nothing", false)
+		require.NoError(t, err)
+		assert.Equal(t, 3, transport.calls)
+	})
+	t.Run("empty query", func(t *testing.T) {
+		_, err := searchSecrets(ctx, &client, &transport, " ", false)
+		assert.Error(t, err)
+	})
+}
+
+func Test_matchScore(t *testing.T) {
+	tests := []struct {
+		term  string
+		text  string
+		score int
+	}{
+		{"vpn", "vpn", scoreExact},
+		{"net", "office network", scoreWordPrefix},
+		{"work", "network", scoreSubstring},
+		{"netwrk", "office network", scoreTypo},
+		{"ofnt", "office network", scoreSubsequence},
+		{"xyz", "office network", 0},
+		{"vpn", "", 0},
+	}
+	for _, tst := range tests {
+		assert.Equal(t, tst.score, matchScore(tst.term, tst.text), tst.term)
+	}
+}
+
+func Test_printSearch(t *testing.T) {
+	var out bytes.Buffer
+	results := []searchResult{{Key: "work/vpn", Type: "LOGIN", Score: 110, Fields: []string{"name", "login"}}}
+	require.NoError(t, printSearch(results, false, &out))
+	assert.Equal(t, "===== Found ======\nwork/vpn  LOGIN  name,login\n", out.String())
+	out.Reset()
+	require.NoError(t, printSearch(nil, true, &out))
+	assert.Equal(t, "[]\n", out.String())
+}
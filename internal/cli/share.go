@@ -0,0 +1,364 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// errVaultShare returns when share commands are used with vault
+var errVaultShare = errors.New("vault secrets can't be shared, invite user into vault instead")
+
+// sharedItem is shared secret in list output
+type sharedItem struct {
+	Owner    string `json:"owner"`
+	Key      string `json:"key"`
+	Type     string `json:"type"`
+	ReadOnly bool   `json:"readonly"`
+}
+
+func newKVCmdShare(clnt *cliClient) *cobra.Command {
+	opts := shareOptions{}
+	// shareCmd represents the share command
+	shareCmd := &cobra.Command{
+		Use:   "share KEY --to USER [--read-only] [--fingerprint FINGERPRINT]",
+		Short: "Share secret with other user",
+		Long: `Share secret with other user of KeepPas server.
+Data key of secret is encrypted with public key of the user, so server never sees it.
+Shared secret is a copy, share it again after update. Recipient can't save read-only secret as own one.
+Public key of recipient is pinned on first use, set its fingerprint got from the recipient by trusted
+channel with flag --fingerprint to check it.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runShare(clnt, opts, cmd, args)
+		},
+	}
+	shareCmd.Flags().StringVar(&opts.to, "to", "", "login of recipient")
+	shareCmd.Flags().BoolVar(&opts.readOnly, "read-only", false, "recipient can only read secret")
+	shareCmd.Flags().StringVar(&opts.fingerprint, "fingerprint", "", "expected fingerprint of recipient public key")
+
+	return shareCmd
+}
+
+func newKVCmdUnshare(clnt *cliClient) *cobra.Command {
+	opts := shareOptions{}
+	// unshareCmd represents the unshare command
+	unshareCmd := &cobra.Command{
+		Use:   "unshare KEY [--to USER]",
+		Short: "Revoke shared secret",
+		Long:  `Revoke secret shared with user, or with all users if --to isn't provided.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runUnshare(clnt, opts, cmd, args)
+		},
+	}
+	unshareCmd.Flags().StringVar(&opts.to, "to", "", "login of recipient")
+
+	return unshareCmd
+}
+
+func newKVCmdShared(clnt *cliClient) *cobra.Command {
+	opts := shareOptions{}
+	// sharedCmd represents the shared command
+	sharedCmd := &cobra.Command{
+		Use:   "shared [OWNER/KEY [--save KEY]]",
+		Short: "Get secrets shared with you",
+		Long: `Get list of secrets shared with you by other users or one of them.
+Default output format is text, you can change output to JSON format with flag -j.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runShared(clnt, opts, cmd, args)
+		},
+	}
+	sharedCmd.Flags().BoolVarP(&opts.jsonOut, "json", "j", false, "print output in json. Default text format.")
+	sharedCmd.Flags().StringVar(&opts.save, "save", "", "save shared secret as own secret KEY")
+
+	return sharedCmd
+}
+
+func runShare(client *cliClient, opts shareOptions, cmd *cobra.Command, args []string) {
+	if client.vault != "" {
+		client.logger.Sugar().Fatal(errVaultShare)
+	}
+	if len(args) == 0 || opts.to == "" {
+		if err := cmd.Help(); err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+		return
+	}
+	if err := setAuthContext(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := getUserKey(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	transport := pb.NewKeepPasClient(conn)
+	key := strings.Join(args, ``)
+	if err := shareSecret(cmd.Context(), client, transport, key, opts); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	fmt.Printf("secret %s is shared with %s\n", key, opts.to)
+}
+
+// shareSecret seals data key of secret to public key of recipient, encrypted data is shared as is.
+// Secret without data key is shared with new data key.
+func shareSecret(ctx context.Context, client *cliClient, transport pb.KeepPasClient, key string, opts shareOptions) error {
+	resp, err := transport.Get(ctx, &pb.BinRequest{Key: key})
+	if err != nil {
+		return err
+	}
+	data, dataKey, err := shareDataKey(client, key, resp)
+	if err != nil {
+		return err
+	}
+	pub, err := trustedPublicKey(ctx, client, transport, opts.to, opts.fingerprint, os.Stderr) // defined in knownkeys.go
+	if err != nil {
+		return err
+	}
+	sealed, err := crypto.SealShare(pub, dataKey, secretAD(client.login, key, resp.Type))
+	if err != nil {
+		return err
+	}
+	_, err = transport.Share(ctx, &pb.ShareRequest{
+		Key:       key,
+		Recipient: opts.to,
+		Data:      data,
+		DataKey:   sealed,
+		Type:      resp.Type,
+		ReadOnly:  opts.readOnly,
+	})
+	return err
+}
+
+// shareDataKey returns encrypted data of secret and its plain data key.
+func shareDataKey(client *cliClient, key string, resp *pb.GetResponse) (string, []byte, error) {
+	userKey := []byte(client.config.UserKey)
+	ad := secretAD(client.login, key, resp.Type)
+	if resp.DataKey == "" {
+		return "", nil, upgradeHint(crypto.ErrNoDataKey) // defined in upgrade.go
+	}
+	dataKey, err := crypto.UnwrapDataKey(userKey, resp.DataKey, ad)
+	return string(resp.Data), dataKey, err
+}
+
+func runUnshare(client *cliClient, opts shareOptions, cmd *cobra.Command, args []string) {
+	if client.vault != "" {
+		client.logger.Sugar().Fatal(errVaultShare)
+	}
+	if len(args) == 0 {
+		if err := cmd.Help(); err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+		return
+	}
+	if err := setAuthContext(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// getUserKey refreshes expired token
+	if err := getUserKey(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	transport := pb.NewKeepPasClient(conn)
+	if _, err := transport.Unshare(cmd.Context(), &pb.ShareRequest{Key: strings.Join(args, ``), Recipient: opts.to}); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+}
+
+func runShared(client *cliClient, opts shareOptions, cmd *cobra.Command, args []string) {
+	if client.vault != "" {
+		client.logger.Sugar().Fatal(errVaultShare)
+	}
+	if err := setAuthContext(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := getUserKey(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	transport := pb.NewKeepPasClient(conn)
+	resp, err := transport.ListShared(cmd.Context(), &pb.BinRequest{})
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if len(args) == 0 {
+		if err := printShared(resp, opts.jsonOut, os.Stdout); err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+		return
+	}
+	shared, secret, err := openShared(cmd.Context(), client, transport, resp, strings.Join(args, ``))
+	if err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if opts.save != "" {
+		if err := saveShared(cmd.Context(), client, transport, shared, secret, opts.save); err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+		return
+	}
+	if err := printSecret(secret, shared.Type, opts.jsonOut, client.logger); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+}
+
+func printShared(resp *pb.SharedList, jsonOut bool, out io.Writer) error {
+	items := make([]sharedItem, 0, len(resp.Secrets))
+	for _, s := range resp.Secrets {
+		items = append(items, sharedItem{Owner: s.Owner, Key: s.Key, Type: s.Type.String(), ReadOnly: s.ReadOnly})
+	}
+	if jsonOut {
+		data, err := json.MarshalIndent(items, "", strings.Repeat(" ", indentCount))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	}
+	if _, err := fmt.Fprintln(out, "===== Shared ======"); err != nil {
+		return err
+	}
+	for _, item := range items {
+		line := item.Owner + "/" + item.Key + "  " + item.Type
+		if item.ReadOnly {
+			line += "  read-only"
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openShared finds shared secret OWNER/KEY in list and decrypts it with user keypair.
+func openShared(ctx context.Context, client *cliClient, transport pb.KeepPasClient, list *pb.SharedList, name string) (*pb.SharedSecret, []byte, error) {
+	for _, s := range list.Secrets {
+		if s.Owner+"/"+s.Key != name {
+			continue
+		}
+		pub, priv, err := ensureKeyPair(ctx, client, transport)
+		if err != nil {
+			return nil, nil, err
+		}
+		secret, err := openSharedData(pub, priv, s)
+		if err != nil {
+			return nil, nil, err
+		}
+		return s, secret, nil
+	}
+	return nil, nil, fmt.Errorf("shared secret %s doesn't exist", name)
+}
+
+// openSharedData decrypts data key of shared secret with user keypair and data with it.
+// Secret shared before data keys is sealed to user keypair itself.
+func openSharedData(pub []byte, priv []byte, s *pb.SharedSecret) ([]byte, error) {
+	ad := secretAD(s.Owner, s.Key, s.Type)
+	if s.DataKey == "" {
+		return crypto.OpenShare(pub, priv, s.Data, ad)
+	}
+	dataKey, err := crypto.OpenShare(pub, priv, s.DataKey, ad)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.OpenData(dataKey, s.Data)
+}
+
+// saveShared keeps copy of shared secret as own secret key.
+func saveShared(ctx context.Context, client *cliClient, transport pb.KeepPasClient, shared *pb.SharedSecret, secret []byte, key string) error {
+	if shared.ReadOnly {
+		return errors.New("secret is shared read-only, it can't be saved")
+	}
+	data, dataKey, err := crypto.SealSecret([]byte(client.config.UserKey), secret, secretAD(client.login, key, shared.Type))
+	if err != nil {
+		return err
+	}
+	_, err = transport.Add(ctx, &pb.BinRequest{Key: key, Type: shared.Type, Data: data, DataKey: dataKey})
+	return err
+}
+
+// ensureKeyPair returns user keypair for sharing, it creates new keypair on server when user doesn't have it.
+func ensureKeyPair(ctx context.Context, client *cliClient, transport pb.KeepPasClient) ([]byte, []byte, error) {
+	resp, err := transport.GetKeyPair(ctx, &pb.BinRequest{})
+	if status.Code(err) == codes.NotFound {
+		var pub, priv []byte
+		pub, priv, err = createKeyPair(ctx, client, transport)
+		if status.Code(err) != codes.AlreadyExists {
+			return pub, priv, err
+		}
+		// keypair is created by other client meanwhile
+		resp, err = transport.GetKeyPair(ctx, &pb.BinRequest{})
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	pub, err := base64.StdEncoding.DecodeString(resp.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	priv, err := crypto.DecryptData([]byte(client.config.UserKey), resp.PrivateKey, keyPairAD(client.login))
+	if err != nil {
+		return nil, nil, err
+	}
+	return pub, priv, nil
+}
+
+// createKeyPair generates user keypair and saves it on server with private key encrypted by user key.
+func createKeyPair(ctx context.Context, client *cliClient, transport pb.KeepPasClient) ([]byte, []byte, error) {
+	pub, priv, err := crypto.GenShareKeyPair()
+	if err != nil {
+		return nil, nil, err
+	}
+	encPriv, err := crypto.EncryptData([]byte(client.config.UserKey), priv, keyPairAD(client.login))
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = transport.SetKeyPair(ctx, &pb.KeyPair{PublicKey: base64.StdEncoding.EncodeToString(pub), PrivateKey: encPriv})
+	return pub, priv, err
+}
+
+// initKeyPair creates user keypair after login, so other users can share secrets with the user.
+func initKeyPair(cmd *cobra.Command, client *cliClient, transport pb.KeepPasClient, resp *pb.AuthResponse) {
+	client.config.UserKey = string(resp.SymmKey)
+	client.login = resp.Login
+	md := metadata.New(map[string]string{"bearer-token": resp.AuthToken})
+	if _, _, err := ensureKeyPair(metadata.NewOutgoingContext(cmd.Context(), md), client, transport); err != nil {
+		client.logger.Sugar().Infof("sharing keypair error: %v", err)
+	}
+}
+
+// keyPairAD returns associated data which binds encrypted private key to its owner
+func keyPairAD(login string) []byte {
+	return crypto.AssociatedData(login, "keypair")
+}
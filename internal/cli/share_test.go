@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeShareClient keeps keypairs, shares and added secrets in memory
+type fakeShareClient struct {
+	pb.KeepPasClient
+	login    string // login of caller
+	getResp  *pb.GetResponse
+	keyPairs map[string]*pb.KeyPair
+	shared   []*pb.SharedSecret
+	added    *pb.BinRequest
+}
+
+func (f *fakeShareClient) Get(_ context.Context, in *pb.BinRequest, _ ...grpc.CallOption) (*pb.GetResponse, error) {
+	return &pb.GetResponse{Key: in.Key, Type: f.getResp.Type, Data: f.getResp.Data, DataKey: f.getResp.DataKey}, nil
+}
+
+func (f *fakeShareClient) GetKeyPair(_ context.Context, _ *pb.BinRequest, _ ...grpc.CallOption) (*pb.KeyPair, error) {
+	kp, ok := f.keyPairs[f.login]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "keypair doesn't exist")
+	}
+	return kp, nil
+}
+
+func (f *fakeShareClient) SetKeyPair(_ context.Context, in *pb.KeyPair, _ ...grpc.CallOption) (*pb.BinResponse, error) {
+	f.keyPairs[f.login] = in
+	return &pb.BinResponse{}, nil
+}
+
+func (f *fakeShareClient) GetPublicKey(_ context.Context, in *pb.BinRequest, _ ...grpc.CallOption) (*pb.KeyPair, error) {
+	return &pb.KeyPair{PublicKey: f.keyPairs[in.Key].PublicKey}, nil
+}
+
+func (f *fakeShareClient) Share(_ context.Context, in *pb.ShareRequest, _ ...grpc.CallOption) (*pb.BinResponse, error) {
+	f.shared = append(f.shared, &pb.SharedSecret{Owner: f.login, Key: in.Key, Type: in.Type, Data: in.Data, DataKey: in.DataKey, ReadOnly: in.ReadOnly})
+	return &pb.BinResponse{}, nil
+}
+
+func (f *fakeShareClient) Add(_ context.Context, in *pb.BinRequest, _ ...grpc.CallOption) (*pb.BinResponse, error) {
+	f.added = in
+	return &pb.BinResponse{}, nil
+}
+
+func Test_shareSecret(t *testing.T) {
+	ctx := context.Background()
+	alice := cliClient{logger: zap.New(nil), config: config.Config{UserKey: "1234567890poiuyt", OfflineDir: t.TempDir()}, login: "alice"}
+	bob := cliClient{logger: zap.New(nil), config: config.Config{UserKey: "0987654321qwerty"}, login: "bob"}
+	data, dataKey, err := crypto.SealSecret([]byte(alice.config.UserKey), []byte(`{"text":"one"}`), secretAD("alice", "key", pb.Type_TEXT))
+	require.NoError(t, err)
+	transport := fakeShareClient{getResp: &pb.GetResponse{Type: pb.Type_TEXT, Data: []byte(data), DataKey: dataKey}, keyPairs: map[string]*pb.KeyPair{}}
+
+	// recipient gets keypair on login
+	transport.login = "bob"
+	pub, priv, err := ensureKeyPair(ctx, &bob, &transport)
+	require.NoError(t, err)
+	pub2, priv2, err := ensureKeyPair(ctx, &bob, &transport)
+	require.NoError(t, err)
+	assert.Equal(t, pub, pub2)
+	assert.Equal(t, priv, priv2)
+
+	transport.login = "alice"
+	require.NoError(t, shareSecret(ctx, &alice, &transport, "key", shareOptions{to: "bob", readOnly: true}))
+	require.NoError(t, shareSecret(ctx, &alice, &transport, "key", shareOptions{to: "bob", fingerprint: crypto.Fingerprint(pub)}))
+	require.Len(t, transport.shared, 2)
+
+	transport.login = "bob"
+	list := &pb.SharedList{Secrets: transport.shared[:1]}
+	t.Run("open", func(t *testing.T) {
+		shared, secret, err := openShared(ctx, &bob, &transport, list, "alice/key")
+		require.NoError(t, err)
+		assert.Equal(t, `{"text":"one"}`, string(secret))
+		assert.Error(t, saveShared(ctx, &bob, &transport, shared, secret, "mine"))
+	})
+	t.Run("save", func(t *testing.T) {
+		shared, secret, err := openShared(ctx, &bob, &transport, &pb.SharedList{Secrets: transport.shared[1:]}, "alice/key")
+		require.NoError(t, err)
+		require.NoError(t, saveShared(ctx, &bob, &transport, shared, secret, "mine"))
+		saved, err := crypto.OpenSecret([]byte(bob.config.UserKey), transport.added.Data, transport.added.DataKey, secretAD("bob", "mine", pb.Type_TEXT))
+		require.NoError(t, err)
+		assert.Equal(t, `{"text":"one"}`, string(saved))
+	})
+	t.Run("tampered", func(t *testing.T) {
+		moved := &pb.SharedSecret{Owner: "alice", Key: "other", Type: pb.Type_TEXT, Data: transport.shared[0].Data, DataKey: transport.shared[0].DataKey}
+		_, _, err := openShared(ctx, &bob, &transport, &pb.SharedList{Secrets: []*pb.SharedSecret{moved}}, "alice/other")
+		require.ErrorIs(t, err, crypto.ErrTampered)
+	})
+	t.Run("not found", func(t *testing.T) {
+		_, _, err := openShared(ctx, &bob, &transport, list, "alice/none")
+		require.Error(t, err)
+	})
+}
+
+func Test_printShared(t *testing.T) {
+	list := pb.SharedList{Secrets: []*pb.SharedSecret{{Owner: "alice", Key: "key", Type: pb.Type_LOGIN, ReadOnly: true}}}
+	var out bytes.Buffer
+	require.NoError(t, printShared(&list, false, &out))
+	assert.Equal(t, "===== Shared ======\nalice/key  LOGIN  read-only\n", out.String())
+	out.Reset()
+	require.NoError(t, printShared(&list, true, &out))
+	assert.JSONEq(t, `[{"owner":"alice","key":"key","type":"LOGIN","readonly":true}]`, out.String())
+}
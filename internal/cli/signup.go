@@ -9,6 +9,8 @@ import (
 	pb "github.com/hrapovd1/gokeepas/internal/proto"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func newSignupCmd(clnt *cliClient) *cobra.Command {
@@ -46,11 +48,15 @@ func runSignup(client *cliClient, options loginOptions, cmd *cobra.Command) {
 	}(client.logger)
 	transport := pb.NewKeepPasClient(conn)
 	// call grpc method
-	resp, err := transport.SignUp(cmd.Context(), &pb.AuthRequest{
+	req := pb.AuthRequest{
 		Login:    options.user,
 		Password: options.password,
-	})
+	}
+	resp, err := transport.SignUp(cmd.Context(), &req)
 	client.logger.Sugar().Debugf("resp: %v, err: %v", resp, err)
+	if status.Code(err) == codes.AlreadyExists {
+		client.logger.Sugar().Fatalf("user %s already exists, use 'keeppas login'", options.user)
+	}
 	if err != nil {
 		client.logger.Sugar().Fatalln(err)
 	}
@@ -60,5 +66,12 @@ func runSignup(client *cliClient, options loginOptions, cmd *cobra.Command) {
 	if err := writeToken(resp.AuthToken, client.config.TokenCache, client.logger); err != nil {
 		client.logger.Sugar().Infof("write token error: %v", err)
 	}
+	if err := writeToken(resp.RefreshToken, client.config.TokenCache+refreshCacheSuffix, client.logger); err != nil {
+		client.logger.Sugar().Infof("write refresh token error: %v", err)
+	}
+	initKeyPair(cmd, client, transport, resp) // defined in share.go
+	if err := saveOfflineKey(client.config.OfflineDir, resp.Login, options.password, resp.SymmKey); err != nil {
+		client.logger.Sugar().Infof("write offline key error: %v", err)
+	}
 	fmt.Println("login success")
 }
@@ -0,0 +1,48 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hrapovd1/gokeepas/internal/strength"
+)
+
+const minPasswordScore = 2 // passwords with lower score are refused without --allow-weak
+
+// checkPassword prints strength of login secret password and refuses weak or known-breached password.
+func checkPassword(client *cliClient, secret rawSecret, value string, out io.Writer) error {
+	if secret.secretType != "login" {
+		return nil
+	}
+	login, err := parseLogin(value, secret.delim)
+	if err != nil {
+		return nil // parseValue reports wrong value
+	}
+	res := strength.Estimate(login.Password, login.Login, secret.name)
+	msg := fmt.Sprintf("password strength: %d/4 (%s)", res.Score, res)
+	if res.Warning != "" {
+		msg += ": " + res.Warning
+	}
+	if _, err := fmt.Fprintln(out, msg); err != nil {
+		return err
+	}
+	breached := 0
+	if client.config.BreachFile != "" {
+		if breached, err = strength.Breached(client.config.BreachFile, login.Password); err != nil {
+			return fmt.Errorf("breached passwords check: %w", err)
+		}
+	}
+	if breached > 0 {
+		if _, err := fmt.Fprintf(out, "password is found %d time(s) in breached passwords\n", breached); err != nil {
+			return err
+		}
+	}
+	if (res.Score < minPasswordScore || breached > 0) && !secret.allowWeak {
+		return errors.New("password is weak or known-breached, use --allow-weak to store it anyway")
+	}
+	return nil
+}
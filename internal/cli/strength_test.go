@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_checkPassword(t *testing.T) {
+	client := cliClient{logger: zap.New(nil), config: config.Config{}}
+	secret := rawSecret{secretType: "login", name: "site", delim: ","}
+	t.Run("not login", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, checkPassword(&client, rawSecret{secretType: "text"}, "123456", &out))
+		assert.Empty(t, out.String())
+	})
+	t.Run("strong", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, checkPassword(&client, secret, "user,x7#Kq2!mZp9@vR", &out))
+		assert.Equal(t, "password strength: 4/4 (very unguessable)\n", out.String())
+	})
+	t.Run("weak", func(t *testing.T) {
+		var out bytes.Buffer
+		require.Error(t, checkPassword(&client, secret, "user,123456", &out))
+		assert.Contains(t, out.String(), "password strength: 0/4")
+		weak := secret
+		weak.allowWeak = true
+		require.NoError(t, checkPassword(&client, weak, "user,123456", &out))
+	})
+	t.Run("breached", func(t *testing.T) {
+		passwd := "x7#Kq2!mZp9@vR"
+		path := filepath.Join(t.TempDir(), "pwned.txt")
+		require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf("%X:3\n", sha1.Sum([]byte(passwd)))), 0o600))
+		brClient := cliClient{logger: zap.New(nil), config: config.Config{BreachFile: path}}
+		var out bytes.Buffer
+		require.Error(t, checkPassword(&brClient, secret, "user,"+passwd, &out))
+		assert.Contains(t, out.String(), "found 3 time(s)")
+		brClient.config.BreachFile = filepath.Join(t.TempDir(), "none")
+		require.Error(t, checkPassword(&brClient, secret, "user,"+passwd, &out))
+	})
+}
@@ -0,0 +1,119 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func newTwoFACmdEnable(clnt *cliClient) *cobra.Command {
+	// enableCmd represents the account 2fa enable command
+	enableCmd := &cobra.Command{
+		Use:   "enable",
+		Short: "Enable two-factor authentication",
+		Long: `Enable two-factor authentication with time-based one-time codes (TOTP).
+It prints provisioning URI for authenticator app and asks current code for confirmation.
+After confirmation it prints single-use recovery codes, keep them in safe place.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runTwoFAEnable(clnt, cmd)
+		},
+	}
+	return enableCmd
+}
+
+func newTwoFACmdDisable(clnt *cliClient) *cobra.Command {
+	// disableCmd represents the account 2fa disable command
+	disableCmd := &cobra.Command{
+		Use:   "disable [CODE]",
+		Short: "Disable two-factor authentication",
+		Long: `Disable two-factor authentication.
+CODE is one-time code or recovery code, it is asked if isn't provided.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runTwoFADisable(clnt, cmd, args)
+		},
+	}
+	return disableCmd
+}
+
+func runTwoFAEnable(client *cliClient, cmd *cobra.Command) {
+	if err := setAuthContext(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := getUserKey(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	transport := pb.NewKeepPasClient(conn)
+	// call grpc method
+	resp, err := transport.Enable2FA(cmd.Context(), &pb.BinRequest{})
+	if err != nil {
+		client.logger.Sugar().Fatalln(err)
+	}
+	fmt.Println("Add this URI in your authenticator app:")
+	fmt.Printf("\n\t%s\n\n", resp.Uri)
+	code, err := promptLine(cmd.InOrStdin(), "one-time code: ")
+	if err != nil {
+		client.logger.Sugar().Fatalln(err)
+	}
+	resp, err = transport.Confirm2FA(cmd.Context(), &pb.TwoFARequest{Code: code})
+	if err != nil {
+		client.logger.Sugar().Fatalln(err)
+	}
+	if err := printRecoveryCodes(resp.RecoveryCodes, os.Stdout); err != nil {
+		client.logger.Sugar().Fatalln(err)
+	}
+}
+
+func runTwoFADisable(client *cliClient, cmd *cobra.Command, args []string) {
+	if err := setAuthContext(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := getUserKey(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	var code string
+	if len(args) > 0 {
+		code = args[0]
+	} else {
+		var err error
+		if code, err = promptLine(cmd.InOrStdin(), "one-time code: "); err != nil {
+			client.logger.Sugar().Fatalln(err)
+		}
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	transport := pb.NewKeepPasClient(conn)
+	// call grpc method
+	resp, err := transport.Disable2FA(cmd.Context(), &pb.TwoFARequest{Code: code})
+	if err != nil {
+		client.logger.Sugar().Fatalln(err)
+	}
+	client.logger.Sugar().Debug(resp)
+	fmt.Println("2FA disabled")
+}
+
+func printRecoveryCodes(codes []string, out io.Writer) error {
+	lines := append([]string{"2FA enabled", "===== Recovery codes ======"}, codes...)
+	lines = append(lines, "Each code can be used once instead of one-time code, keep them in safe place.")
+	_, err := fmt.Fprintln(out, strings.Join(lines, "\n"))
+	return err
+}
@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_printRecoveryCodes(t *testing.T) {
+	t.Run("simple", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, printRecoveryCodes([]string{"abcde-fghij", "klmno-pqrst"}, &out))
+		assert.Equal(t, "2FA enabled\n===== Recovery codes ======\nabcde-fghij\nklmno-pqrst\n"+
+			"Each code can be used once instead of one-time code, keep them in safe place.\n", out.String())
+	})
+}
@@ -4,29 +4,32 @@ Package cli contents methods and types for KeepPas cli client.
 package cli
 
 import (
+	"fmt"
+	"os"
 	"strings"
 
 	pb "github.com/hrapovd1/gokeepas/internal/proto"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
-	"google.golang.org/grpc/metadata"
 )
 
 func newKVCmdUpdate(clnt *cliClient) *cobra.Command {
 	secrt := rawSecret{delim: `,`}
 	// updCmd represents the update command
 	updCmd := &cobra.Command{
-		Use: `update [-d DELIM] -t TYPE [-e EXTRA] -k KEY VALUE_FIELDS
+		Use: `update [-d DELIM] -t TYPE [-e EXTRA] [-g [GEN_OPTIONS]] -k KEY VALUE_FIELDS
 
 	DELIM: values delimiter, ',' is default
-	Allowed TYPE: login | text | bin | cart
+	Allowed TYPE: login | text | bin | cart | otp
 	KEY: name of secret
 	VALUE_FIELDS:
-		login: LOGIN,PASSWORD
+		login: LOGIN,PASSWORD or LOGIN with -g flag
 		text: TEXT
 		bin: DATA (any binary data)
 		cart: CART_NUMBER,EXPIRED DATA,HOLDER NAME,CVC
+		otp: otpauth://totp/... URI or SECRET[,ALGORITHM,DIGITS,PERIOD]
 	EXTRA: any text
+	GEN_OPTIONS: options of password generation, see 'keeppas gen --help'
 	`,
 		Short: "Update secret on KeepPas server",
 		Long: `Update secret on KeepPas server.
@@ -41,6 +44,10 @@ It rewrite all existed values of secret, if you don't provide existed field(s),
 	updCmd.Flags().StringVarP(&secrt.name, "key", "k", "", "name of secret")
 	updCmd.Flags().StringVarP(&secrt.extra, "extra", "e", "", "extra data of secret")
 	updCmd.Flags().StringVarP(&secrt.delim, "delim", "d", `,`, "values delimiter")
+	updCmd.Flags().BoolVarP(&secrt.generate, "generate", "g", false, "generate password of login secret")
+	updCmd.Flags().BoolVar(&secrt.allowWeak, "allow-weak", false, "store weak or known-breached password")
+	updCmd.Flags().BoolVar(&secrt.offline, "offline", false, "save change in offline journal, it is sent to server later")
+	addGenFlags(updCmd, &secrt.gen)
 
 	return updCmd
 }
@@ -52,38 +59,56 @@ func runUpd(client *cliClient, secret rawSecret, cmd *cobra.Command, args []stri
 		}
 		return
 	}
-	token, err := readToken(client.config.TokenCache, client.logger)
+	// changes are queued in offline journal when server isn't available, defined in cache.go
+	offline := useOffline(client, secret.offline)
+	cache, err := openSession(cmd, client, offline)
 	if err != nil {
 		client.logger.Sugar().Fatal(err)
 	}
-	client.token = token
-	md := metadata.New(map[string]string{"bearer-token": token})
-	cmd.SetContext(metadata.NewOutgoingContext(cmd.Context(), md))
-
-	if err := getUserKey(cmd, client); err != nil {
-		client.logger.Sugar().Fatal(err)
-	}
 	// process request
 	value := strings.Join(args, ` `)
+	var (
+		passwd  string
+		entropy float64
+	)
+	if secret.generate {
+		if value, passwd, entropy, err = appendPassword(secret, value); err != nil { // defined in gen.go
+			client.logger.Sugar().Fatal(err)
+		}
+	}
+	if err := checkPassword(client, secret, value, os.Stderr); err != nil { // defined in strength.go
+		client.logger.Sugar().Fatal(err)
+	}
 	req, err := parseValue(client, secret, value) // defined in add.go
 	if err != nil {
 		client.logger.Sugar().Fatal(err)
 	}
-	// process grpc client
-	conn := client.transport(client.config.ServerAddr, client.logger)
-	defer func(l *zap.Logger) {
-		if err := conn.Close(); err != nil {
-			l.Error(err.Error())
+	if offline {
+		op := journalOp{Op: opUpdate, Key: req.Key, Type: req.Type, Data: req.Data, DataKey: req.DataKey}
+		if err := queueOp(client.config.OfflineDir, []byte(client.config.UserKey), cache, op); err != nil { // defined in journal.go
+			client.logger.Sugar().Fatal(err)
+		}
+		fmt.Println(offlineQueued)
+	} else {
+		// process grpc client
+		conn := client.transport(client.config.ServerAddr, client.logger)
+		defer func(l *zap.Logger) {
+			if err := conn.Close(); err != nil {
+				l.Error(err.Error())
+			}
+		}(client.logger)
+		transport := pb.NewKeepPasClient(conn)
+		client.logger.Sugar().Debugf("call add, req: %v", req)
+		// call grpc method
+		resp, err := transport.Update(cmd.Context(), req)
+		if err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+		if resp.Error != "" {
+			client.logger.Sugar().Fatal(resp.Error)
 		}
-	}(client.logger)
-	transport := pb.NewKeepPasClient(conn)
-	client.logger.Sugar().Debugf("call add, req: %v", req)
-	// call grpc method
-	resp, err := transport.Update(cmd.Context(), req)
-	if err != nil {
-		client.logger.Sugar().Fatal(err)
 	}
-	if resp.Error != "" {
-		client.logger.Sugar().Fatal(resp.Error)
+	if secret.generate {
+		fmt.Printf("generated password: %s\nentropy: %.1f bits\n", passwd, entropy)
 	}
 }
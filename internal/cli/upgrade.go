@@ -0,0 +1,118 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+func newKVCmdUpgrade(clnt *cliClient) *cobra.Command {
+	// upgradeCmd represents the upgrade command
+	upgradeCmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Encrypt old secrets with data keys",
+		Long: `Encrypt old secrets with data keys. Secrets written before data keys were encrypted
+with user key directly, other commands don't read them. The command decrypts such secrets,
+encrypts them again with their own data keys and saves them on server.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runUpgrade(clnt, cmd)
+		},
+	}
+	return upgradeCmd
+}
+
+func runUpgrade(client *cliClient, cmd *cobra.Command) {
+	if err := setAuthContext(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := getUserKey(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	transport := pb.NewKeepPasClient(conn)
+	if err := upgradeSecrets(cmd.Context(), client, transport, os.Stdout); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+}
+
+// upgradeSecrets encrypts secrets without data key again with their own data keys.
+func upgradeSecrets(ctx context.Context, client *cliClient, transport pb.KeepPasClient, out io.Writer) error {
+	entries, err := listSecrets(ctx, transport, &pb.ListRequest{PageSize: listPageSize})
+	if err != nil {
+		return err
+	}
+	upgraded := 0
+	for _, e := range entries {
+		if e.DataKey {
+			continue
+		}
+		if err := upgradeSecret(ctx, client, transport, e.Name); err != nil {
+			return fmt.Errorf("%s: %w", e.Name, err)
+		}
+		if _, err := fmt.Fprintf(out, "%s: upgraded\n", e.Name); err != nil {
+			return err
+		}
+		upgraded++
+	}
+	_, err = fmt.Fprintf(out, "%d secrets upgraded\n", upgraded)
+	return err
+}
+
+// upgradeSecret reads secret key encrypted with user key and saves it with new data key.
+// Wrapped data key of other secret is ciphertext of the same key too, so decrypted secret
+// has to be json, random data key isn't accepted as secret.
+func upgradeSecret(ctx context.Context, client *cliClient, transport pb.KeepPasClient, key string) error {
+	resp, err := transport.Get(ctx, &pb.BinRequest{Key: key})
+	if err != nil {
+		return err
+	}
+	if resp.DataKey != "" {
+		return nil
+	}
+	userKey := []byte(client.config.UserKey)
+	ad := secretAD(client.login, key, resp.Type)
+	secret, err := crypto.OpenLegacySecret(userKey, string(resp.Data), ad)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(secret) {
+		return crypto.ErrTampered
+	}
+	req := pb.BinRequest{Key: key, Type: resp.Type}
+	if req.Data, req.DataKey, err = crypto.SealSecret(userKey, secret, ad); err != nil {
+		return err
+	}
+	upd, err := transport.Update(ctx, &req)
+	if err != nil {
+		return err
+	}
+	if upd.Error != "" {
+		return errors.New(upd.Error)
+	}
+	return nil
+}
+
+// upgradeHint adds to error of secret without data key how to upgrade it
+func upgradeHint(err error) error {
+	if errors.Is(err, crypto.ErrNoDataKey) {
+		return fmt.Errorf("%w, upgrade it with 'keeppas kv upgrade'", err)
+	}
+	return err
+}
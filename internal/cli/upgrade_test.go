@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+func (f *fakeStoreClient) List(_ context.Context, _ *pb.ListRequest, _ ...grpc.CallOption) (*pb.ListResponse, error) {
+	resp := pb.ListResponse{}
+	for key, s := range f.secrets {
+		resp.Entries = append(resp.Entries, &pb.ListEntry{Name: key, Type: s.Type, DataKey: s.DataKey != ""})
+	}
+	sort.Slice(resp.Entries, func(i, j int) bool { return resp.Entries[i].Name < resp.Entries[j].Name })
+	return &resp, nil
+}
+
+func Test_upgradeSecrets(t *testing.T) {
+	client := cliClient{logger: zap.New(nil), config: config.Config{UserKey: "1234567890poiuyt"}, login: "test"}
+	userKey := []byte(client.config.UserKey)
+	ctx := context.Background()
+	legacy, err := crypto.EncryptData(userKey, []byte(`{"text":"old"}`), secretAD("test", "old", pb.Type_TEXT))
+	require.NoError(t, err)
+	data, dataKey, err := crypto.SealSecret(userKey, []byte(`{"text":"new"}`), secretAD("test", "new", pb.Type_TEXT))
+	require.NoError(t, err)
+
+	t.Run("upgrade", func(t *testing.T) {
+		transport := fakeStoreClient{secrets: map[string]*pb.GetResponse{
+			"old": {Key: "old", Type: pb.Type_TEXT, Data: []byte(legacy)},
+			"new": {Key: "new", Type: pb.Type_TEXT, Data: []byte(data), DataKey: dataKey},
+		}}
+		out := bytes.Buffer{}
+		require.NoError(t, upgradeSecrets(ctx, &client, &transport, &out))
+		assert.Equal(t, "old: upgraded\n1 secrets upgraded\n", out.String())
+		s := transport.secrets["old"]
+		require.NotEmpty(t, s.DataKey)
+		secret, err := crypto.OpenSecret(userKey, string(s.Data), s.DataKey, secretAD("test", "old", pb.Type_TEXT))
+		require.NoError(t, err)
+		assert.Equal(t, `{"text":"old"}`, string(secret))
+		// secret with data key isn't changed
+		assert.Equal(t, dataKey, transport.secrets["new"].DataKey)
+	})
+	t.Run("wrapped data key as secret", func(t *testing.T) {
+		// wrapped data key of other secret is ciphertext of user key too
+		wrapped, err := crypto.WrapDataKey(userKey, make([]byte, crypto.DataKeyLength), secretAD("test", "old", pb.Type_TEXT))
+		require.NoError(t, err)
+		transport := fakeStoreClient{secrets: map[string]*pb.GetResponse{
+			"old": {Key: "old", Type: pb.Type_TEXT, Data: []byte(wrapped)},
+		}}
+		err = upgradeSecrets(ctx, &client, &transport, &bytes.Buffer{})
+		require.ErrorIs(t, err, crypto.ErrTampered)
+		assert.Empty(t, transport.secrets["old"].DataKey)
+	})
+}
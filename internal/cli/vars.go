@@ -11,6 +11,7 @@ import (
 
 const indentCount = 4
 const version = "1.0"
+const refreshCacheSuffix = ".refresh" // suffix of file with refresh token near token cache
 
 type cliTransport func(string, *zap.Logger) *grpc.ClientConn
 
@@ -18,12 +19,29 @@ type cliClient struct {
 	config    config.Config
 	transport cliTransport
 	token     string
+	login     string // owner of secrets, it is returned by server with user key
+	vault     string // team vault which kv commands work with, own secrets when it is empty
 	logger    *zap.Logger
 }
 
 type loginOptions struct {
 	user     string
 	password string
+	otp      string
+}
+
+type shareOptions struct {
+	to          string // recipient login
+	readOnly    bool
+	jsonOut     bool
+	save        string // key to save shared secret as own one
+	fingerprint string // expected fingerprint of recipient public key
+}
+
+type vaultOptions struct {
+	role        string // role of invited member
+	jsonOut     bool
+	fingerprint string // expected fingerprint of invited user public key
 }
 
 type rawSecret struct {
@@ -31,6 +49,22 @@ type rawSecret struct {
 	name       string
 	extra      string
 	delim      string
+	generate   bool       // generate password of login secret
+	gen        genOptions // options of password generation
+	allowWeak  bool       // store weak or breached password
+	offline    bool       // queue change in offline journal
+}
+
+type genOptions struct {
+	length           int
+	noLower          bool
+	noUpper          bool
+	noDigits         bool
+	noSymbols        bool
+	excludeAmbiguous bool
+	requireEach      bool
+	words            int // count of passphrase words, password is generated when it is 0
+	separator        string
 }
 
 var BuildTime string
@@ -0,0 +1,387 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+const firstVaultVersion = 1 // version of key of new vault
+
+func newVaultCmd() *cobra.Command {
+	// vaultCmd represents the vault command
+	return &cobra.Command{
+		Use:   "vault",
+		Short: "Manage team vaults",
+		Long: `Manage team vaults shared by several users.
+Vault key is sealed to public key of each member, so server never sees it.
+Use 'keeppas kv --vault VAULT ...' to work with vault secrets.`,
+	}
+}
+
+func newVaultCmdCreate(clnt *cliClient) *cobra.Command {
+	// createCmd represents the vault create command
+	return &cobra.Command{
+		Use:   "create VAULT",
+		Short: "Create team vault",
+		Long:  `Create team vault, you become its admin. Vault name may contain '/', e.g. team/infra.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runVault(clnt, cmd, args, 1, func(ctx context.Context, transport pb.KeepPasClient) error {
+				return createVault(ctx, clnt, transport, args[0])
+			})
+		},
+	}
+}
+
+func newVaultCmdList(clnt *cliClient) *cobra.Command {
+	opts := vaultOptions{}
+	// listCmd represents the vault list command
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "Get list of your vaults",
+		Long: `Get list of vaults where you are member with your role.
+Default output format is text, you can change output to JSON format with flag -j.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runVault(clnt, cmd, args, 0, func(ctx context.Context, transport pb.KeepPasClient) error {
+				resp, err := transport.ListVaults(ctx, &pb.BinRequest{})
+				if err != nil {
+					return err
+				}
+				return printVaults(resp, opts.jsonOut, os.Stdout)
+			})
+		},
+	}
+	listCmd.Flags().BoolVarP(&opts.jsonOut, "json", "j", false, "print output in json. Default text format.")
+
+	return listCmd
+}
+
+func newVaultCmdMembers(clnt *cliClient) *cobra.Command {
+	opts := vaultOptions{}
+	// membersCmd represents the vault members command
+	membersCmd := &cobra.Command{
+		Use:   "members VAULT",
+		Short: "Get list of vault members",
+		Long: `Get list of vault members with their roles.
+Default output format is text, you can change output to JSON format with flag -j.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runVault(clnt, cmd, args, 1, func(ctx context.Context, transport pb.KeepPasClient) error {
+				resp, err := transport.ListVaultMembers(ctx, &pb.VaultRequest{Vault: args[0]})
+				if err != nil {
+					return err
+				}
+				return printMembers(resp, opts.jsonOut, os.Stdout)
+			})
+		},
+	}
+	membersCmd.Flags().BoolVarP(&opts.jsonOut, "json", "j", false, "print output in json. Default text format.")
+
+	return membersCmd
+}
+
+func newVaultCmdInvite(clnt *cliClient) *cobra.Command {
+	opts := vaultOptions{}
+	// inviteCmd represents the vault invite command
+	inviteCmd := &cobra.Command{
+		Use:   "invite VAULT USER [--role ROLE] [--fingerprint FINGERPRINT]",
+		Short: "Invite user into vault",
+		Long: `Invite user into vault, only vault admin can do it.
+Allowed ROLE: admin | write | read, write is default.
+Public key of user is pinned on first use, set its fingerprint got from the user by trusted
+channel with flag --fingerprint to check it.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runVault(clnt, cmd, args, 2, func(ctx context.Context, transport pb.KeepPasClient) error {
+				return inviteMember(ctx, clnt, transport, args[0], args[1], opts)
+			})
+		},
+	}
+	inviteCmd.Flags().StringVar(&opts.role, "role", types.VaultRoleWrite, "role of user in vault")
+	inviteCmd.Flags().StringVar(&opts.fingerprint, "fingerprint", "", "expected fingerprint of user public key")
+
+	return inviteCmd
+}
+
+func newVaultCmdRole(clnt *cliClient) *cobra.Command {
+	// roleCmd represents the vault role command
+	return &cobra.Command{
+		Use:   "role VAULT USER ROLE",
+		Short: "Change role of vault member",
+		Long: `Change role of vault member, only vault admin can do it.
+Allowed ROLE: admin | write | read.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runVault(clnt, cmd, args, 3, func(ctx context.Context, transport pb.KeepPasClient) error {
+				_, err := transport.ChangeVaultRole(ctx, &pb.VaultMemberRequest{
+					Vault:  args[0],
+					Member: &pb.VaultMember{Login: args[1], Role: args[2]},
+				})
+				return err
+			})
+		},
+	}
+}
+
+func newVaultCmdRemove(clnt *cliClient) *cobra.Command {
+	// removeCmd represents the vault remove command
+	return &cobra.Command{
+		Use:   "remove VAULT USER",
+		Short: "Remove member from vault",
+		Long: `Remove member from vault, only vault admin can do it.
+Vault key is rotated: all vault secrets are re-encrypted with new key,
+which is sealed to remaining members only.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			runVault(clnt, cmd, args, 2, func(ctx context.Context, transport pb.KeepPasClient) error {
+				return removeMember(ctx, clnt, transport, args[0], args[1])
+			})
+		},
+	}
+}
+
+// runVault checks count of arguments, authenticates client and calls fn with grpc transport.
+func runVault(client *cliClient, cmd *cobra.Command, args []string, argsCount int, fn func(context.Context, pb.KeepPasClient) error) {
+	if len(args) != argsCount {
+		if err := cmd.Help(); err != nil {
+			client.logger.Sugar().Fatal(err)
+		}
+		return
+	}
+	if err := setAuthContext(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := getUserKey(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	if err := fn(cmd.Context(), pb.NewKeepPasClient(conn)); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+}
+
+// useVault replaces user key by vault key, so kv commands encrypt and decrypt vault secrets,
+// and puts vault with version of its key in cmd context for server.
+func useVault(cmd *cobra.Command, clnt *cliClient, transport pb.KeepPasClient) error {
+	key, version, err := openVaultKey(cmd.Context(), clnt, transport, clnt.vault)
+	if err != nil {
+		return err
+	}
+	clnt.config.UserKey = string(key)
+	clnt.login = vaultOwner(clnt.vault)
+	cmd.SetContext(metadata.AppendToOutgoingContext(cmd.Context(), "vault", clnt.vault, "vault-version", strconv.FormatInt(version, 10)))
+	return nil
+}
+
+// openVaultKey returns vault key and its version, the key is opened by user keypair.
+func openVaultKey(ctx context.Context, client *cliClient, transport pb.KeepPasClient, vault string) ([]byte, int64, error) {
+	resp, err := transport.GetVaultKey(ctx, &pb.VaultRequest{Vault: vault})
+	if err != nil {
+		return nil, 0, err
+	}
+	pub, priv, err := ensureKeyPair(ctx, client, transport)
+	if err != nil {
+		return nil, 0, err
+	}
+	key, err := crypto.OpenShare(pub, priv, resp.VaultKey, vaultKeyAD(vault, client.login, resp.Version))
+	if err != nil {
+		return nil, 0, err
+	}
+	return key, resp.Version, nil
+}
+
+// sealVaultKey seals vault key to public key of user login, the key is checked by fingerprint.
+// Own public key is computed from private key, so server can't substitute it.
+func sealVaultKey(ctx context.Context, client *cliClient, transport pb.KeepPasClient, vault string, login string, fingerprint string, version int64, key []byte) (string, error) {
+	var pub []byte
+	if login == client.login {
+		_, priv, err := ensureKeyPair(ctx, client, transport)
+		if err != nil {
+			return "", err
+		}
+		if pub, err = crypto.SharePublicKey(priv); err != nil {
+			return "", err
+		}
+	} else {
+		var err error
+		if pub, err = trustedPublicKey(ctx, client, transport, login, fingerprint, os.Stderr); err != nil { // defined in knownkeys.go
+			return "", err
+		}
+	}
+	return crypto.SealShare(pub, key, vaultKeyAD(vault, login, version))
+}
+
+// createVault generates vault key and creates vault with the key sealed to user.
+func createVault(ctx context.Context, client *cliClient, transport pb.KeepPasClient, vault string) error {
+	key, err := crypto.GenSymmKey(crypto.SymmKeyLength)
+	if err != nil {
+		return err
+	}
+	pub, _, err := ensureKeyPair(ctx, client, transport)
+	if err != nil {
+		return err
+	}
+	sealed, err := crypto.SealShare(pub, key, vaultKeyAD(vault, client.login, firstVaultVersion))
+	if err != nil {
+		return err
+	}
+	_, err = transport.CreateVault(ctx, &pb.VaultRequest{Vault: vault, VaultKey: sealed})
+	return err
+}
+
+// inviteMember seals vault key to public key of user and adds the user in vault.
+func inviteMember(ctx context.Context, client *cliClient, transport pb.KeepPasClient, vault string, user string, opts vaultOptions) error {
+	key, version, err := openVaultKey(ctx, client, transport, vault)
+	if err != nil {
+		return err
+	}
+	sealed, err := sealVaultKey(ctx, client, transport, vault, user, opts.fingerprint, version, key)
+	if err != nil {
+		return err
+	}
+	_, err = transport.AddVaultMember(ctx, &pb.VaultMemberRequest{
+		Vault:   vault,
+		Member:  &pb.VaultMember{Login: user, Role: opts.role, VaultKey: sealed},
+		Version: version,
+	})
+	return err
+}
+
+// removeMember removes user from vault and rotates vault key: data keys of secrets are re-wrapped
+// with new key and it is sealed to remaining members.
+func removeMember(ctx context.Context, client *cliClient, transport pb.KeepPasClient, vault string, user string) error {
+	oldKey, version, err := openVaultKey(ctx, client, transport, vault)
+	if err != nil {
+		return err
+	}
+	members, err := transport.ListVaultMembers(ctx, &pb.VaultRequest{Vault: vault})
+	if err != nil {
+		return err
+	}
+	secrets, err := transport.GetVaultSecrets(ctx, &pb.VaultRequest{Vault: vault})
+	if err != nil {
+		return err
+	}
+	if members.Version != version || secrets.Version != version {
+		return fmt.Errorf("key of vault %s is rotated meanwhile, repeat", vault)
+	}
+	newKey, err := crypto.GenSymmKey(crypto.SymmKeyLength)
+	if err != nil {
+		return err
+	}
+	req := pb.VaultRotateRequest{Vault: vault, Removed: user, Version: version}
+	for _, m := range members.Members {
+		if m.Login == user {
+			continue
+		}
+		sealed, err := sealVaultKey(ctx, client, transport, vault, m.Login, "", version+1, newKey)
+		if err != nil {
+			return err
+		}
+		req.Members = append(req.Members, &pb.VaultMember{Login: m.Login, VaultKey: sealed})
+	}
+	if len(req.Members) == len(members.Members) {
+		return fmt.Errorf("user %s isn't member of vault %s", user, vault)
+	}
+	owner := vaultOwner(vault)
+	for _, s := range secrets.Secrets {
+		secret, err := rewrapVaultSecret(oldKey, newKey, secretAD(owner, s.Key, s.Type), s)
+		if err != nil {
+			return fmt.Errorf("secret %s: %w", s.Key, err)
+		}
+		req.Secrets = append(req.Secrets, secret)
+	}
+	_, err = transport.RemoveVaultMember(ctx, &req)
+	return err
+}
+
+// rewrapVaultSecret re-wraps data key of vault secret with new vault key, data stays the same.
+// Secret without data key has to be upgraded before. Removed member could read secrets
+// before removal, so their data keys aren't changed.
+func rewrapVaultSecret(oldKey []byte, newKey []byte, ad []byte, s *pb.GetResponse) (*pb.BinRequest, error) {
+	out := pb.BinRequest{Key: s.Key, Type: s.Type, Data: string(s.Data)}
+	var err error
+	if s.DataKey == "" {
+		return nil, upgradeHint(crypto.ErrNoDataKey) // defined in upgrade.go
+	}
+	dataKey, err := crypto.UnwrapDataKey(oldKey, s.DataKey, ad)
+	if err != nil {
+		return nil, err
+	}
+	out.DataKey, err = crypto.WrapDataKey(newKey, dataKey, ad)
+	return &out, err
+}
+
+func printVaults(resp *pb.VaultList, jsonOut bool, out io.Writer) error {
+	if jsonOut {
+		items := make([]map[string]string, 0, len(resp.Vaults))
+		for _, v := range resp.Vaults {
+			items = append(items, map[string]string{"vault": v.Vault, "role": v.Role})
+		}
+		data, err := json.MarshalIndent(items, "", strings.Repeat(" ", indentCount))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	}
+	if _, err := fmt.Fprintln(out, "===== Vaults ======"); err != nil {
+		return err
+	}
+	for _, v := range resp.Vaults {
+		if _, err := fmt.Fprintln(out, v.Vault+"  "+v.Role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printMembers(resp *pb.VaultMembers, jsonOut bool, out io.Writer) error {
+	if jsonOut {
+		items := make([]map[string]string, 0, len(resp.Members))
+		for _, m := range resp.Members {
+			items = append(items, map[string]string{"login": m.Login, "role": m.Role})
+		}
+		data, err := json.MarshalIndent(items, "", strings.Repeat(" ", indentCount))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	}
+	if _, err := fmt.Fprintln(out, "===== Members ======"); err != nil {
+		return err
+	}
+	for _, m := range resp.Members {
+		if _, err := fmt.Fprintln(out, m.Login+"  "+m.Role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// vaultOwner returns owner of vault secrets in their associated data, it never equals user login.
+func vaultOwner(vault string) string {
+	return "/vaults/" + vault
+}
+
+// vaultKeyAD returns associated data which binds sealed vault key to vault, member and key version
+func vaultKeyAD(vault string, login string, version int64) []byte {
+	return crypto.AssociatedData("vault", vault, login, strconv.FormatInt(version, 10))
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"sort"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeVaultClient keeps one vault in memory
+type fakeVaultClient struct {
+	fakeShareClient
+	version int64
+	roles   map[string]string
+	keys    map[string]string
+	secrets []*pb.GetResponse
+}
+
+func (f *fakeVaultClient) CreateVault(_ context.Context, in *pb.VaultRequest, _ ...grpc.CallOption) (*pb.BinResponse, error) {
+	f.version = firstVaultVersion
+	f.roles = map[string]string{f.login: "admin"}
+	f.keys = map[string]string{f.login: in.VaultKey}
+	return &pb.BinResponse{}, nil
+}
+
+func (f *fakeVaultClient) GetVaultKey(_ context.Context, _ *pb.VaultRequest, _ ...grpc.CallOption) (*pb.VaultKey, error) {
+	key, ok := f.keys[f.login]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "not member")
+	}
+	return &pb.VaultKey{VaultKey: key, Version: f.version, Role: f.roles[f.login]}, nil
+}
+
+func (f *fakeVaultClient) AddVaultMember(_ context.Context, in *pb.VaultMemberRequest, _ ...grpc.CallOption) (*pb.BinResponse, error) {
+	f.roles[in.Member.Login] = in.Member.Role
+	f.keys[in.Member.Login] = in.Member.VaultKey
+	return &pb.BinResponse{}, nil
+}
+
+func (f *fakeVaultClient) ListVaultMembers(_ context.Context, _ *pb.VaultRequest, _ ...grpc.CallOption) (*pb.VaultMembers, error) {
+	out := pb.VaultMembers{Version: f.version}
+	for login, role := range f.roles {
+		out.Members = append(out.Members, &pb.VaultMember{Login: login, Role: role})
+	}
+	sort.Slice(out.Members, func(i, j int) bool { return out.Members[i].Login < out.Members[j].Login })
+	return &out, nil
+}
+
+func (f *fakeVaultClient) GetVaultSecrets(_ context.Context, _ *pb.VaultRequest, _ ...grpc.CallOption) (*pb.VaultSecrets, error) {
+	return &pb.VaultSecrets{Secrets: f.secrets, Version: f.version}, nil
+}
+
+func (f *fakeVaultClient) RemoveVaultMember(_ context.Context, in *pb.VaultRotateRequest, _ ...grpc.CallOption) (*pb.BinResponse, error) {
+	delete(f.roles, in.Removed)
+	f.keys = map[string]string{}
+	for _, m := range in.Members {
+		f.keys[m.Login] = m.VaultKey
+	}
+	f.secrets = nil
+	for _, s := range in.Secrets {
+		f.secrets = append(f.secrets, &pb.GetResponse{Key: s.Key, Type: s.Type, Data: []byte(s.Data), DataKey: s.DataKey})
+	}
+	f.version++
+	return &pb.BinResponse{}, nil
+}
+
+func Test_vaultMembership(t *testing.T) {
+	ctx := context.Background()
+	alice := cliClient{logger: zap.New(nil), config: config.Config{UserKey: "1234567890poiuyt", OfflineDir: t.TempDir()}, login: "alice"}
+	bob := cliClient{logger: zap.New(nil), config: config.Config{UserKey: "0987654321qwerty"}, login: "bob"}
+	transport := fakeVaultClient{fakeShareClient: fakeShareClient{keyPairs: map[string]*pb.KeyPair{}}}
+
+	transport.login = "bob"
+	_, _, err := ensureKeyPair(ctx, &bob, &transport)
+	require.NoError(t, err)
+
+	transport.login = "alice"
+	require.NoError(t, createVault(ctx, &alice, &transport, "team/infra"))
+	require.NoError(t, inviteMember(ctx, &alice, &transport, "team/infra", "bob", vaultOptions{role: "write"}))
+	aliceKey, version, err := openVaultKey(ctx, &alice, &transport, "team/infra")
+	require.NoError(t, err)
+	assert.Equal(t, int64(firstVaultVersion), version)
+
+	transport.login = "bob"
+	bobKey, _, err := openVaultKey(ctx, &bob, &transport, "team/infra")
+	require.NoError(t, err)
+	assert.Equal(t, aliceKey, bobKey)
+	// bob writes vault secret
+	data, dataKey, err := crypto.SealSecret(bobKey, []byte(`{"text":"one"}`), secretAD(vaultOwner("team/infra"), "db", pb.Type_TEXT))
+	require.NoError(t, err)
+	transport.secrets = []*pb.GetResponse{{Key: "db", Type: pb.Type_TEXT, Data: []byte(data), DataKey: dataKey}}
+
+	transport.login = "alice"
+	t.Run("substituted key", func(t *testing.T) {
+		bobPair := transport.keyPairs["bob"]
+		defer func() { transport.keyPairs["bob"] = bobPair }()
+		pub, _, err := crypto.GenShareKeyPair()
+		require.NoError(t, err)
+		transport.keyPairs["bob"] = &pb.KeyPair{PublicKey: base64.StdEncoding.EncodeToString(pub)}
+		assert.ErrorContains(t, inviteMember(ctx, &alice, &transport, "team/infra", "bob", vaultOptions{role: "read"}), "is changed")
+	})
+	t.Run("not member", func(t *testing.T) {
+		assert.Error(t, removeMember(ctx, &alice, &transport, "team/infra", "carol"))
+	})
+	t.Run("remove", func(t *testing.T) {
+		require.NoError(t, removeMember(ctx, &alice, &transport, "team/infra", "bob"))
+		newKey, version, err := openVaultKey(ctx, &alice, &transport, "team/infra")
+		require.NoError(t, err)
+		assert.Equal(t, int64(firstVaultVersion+1), version)
+		assert.NotEqual(t, aliceKey, newKey)
+		secret, err := crypto.OpenSecret(newKey, string(transport.secrets[0].Data), transport.secrets[0].DataKey, secretAD(vaultOwner("team/infra"), "db", pb.Type_TEXT))
+		require.NoError(t, err)
+		assert.Equal(t, `{"text":"one"}`, string(secret))
+		assert.Equal(t, data, string(transport.secrets[0].Data))
+		_, err = crypto.OpenSecret(aliceKey, string(transport.secrets[0].Data), transport.secrets[0].DataKey, secretAD(vaultOwner("team/infra"), "db", pb.Type_TEXT))
+		assert.Error(t, err)
+
+		transport.login = "bob"
+		_, _, err = openVaultKey(ctx, &bob, &transport, "team/infra")
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func Test_printVaults(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, printVaults(&pb.VaultList{Vaults: []*pb.VaultInfo{{Vault: "team/infra", Role: "read"}}}, false, &out))
+	assert.Equal(t, "===== Vaults ======\nteam/infra  read\n", out.String())
+	out.Reset()
+	require.NoError(t, printMembers(&pb.VaultMembers{Members: []*pb.VaultMember{{Login: "alice", Role: "admin"}}}, true, &out))
+	assert.JSONEq(t, `[{"login":"alice","role":"admin"}]`, out.String())
+}
@@ -0,0 +1,127 @@
+/*
+Package cli contents methods and types for KeepPas cli client.
+*/
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// watchRetryDelay is pause before reconnect of interrupted watch
+const watchRetryDelay = 5 * time.Second
+
+// watchLine is event in output of watch command
+type watchLine struct {
+	Kind   string `json:"kind"`
+	Key    string `json:"key"`
+	NewKey string `json:"newkey,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Time   string `json:"time"`
+}
+
+func newKVCmdWatch(clnt *cliClient) *cobra.Command {
+	// watchCmd represents the watch command
+	watchCmd := &cobra.Command{
+		Use:   "watch [PREFIX]",
+		Short: "Print changes of secrets",
+		Long: `Print changes of secrets with name PREFIX or all secrets as JSON lines, one line per
+add, update, rename or remove of secret. Values of secrets aren't printed. Command works until
+it is interrupted, lost connection is restored and expired session is refreshed automatically.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runWatch(clnt, cmd, args)
+		},
+	}
+
+	return watchCmd
+}
+
+func runWatch(client *cliClient, cmd *cobra.Command, args []string) {
+	prefix := ""
+	if len(args) > 0 {
+		prefix = args[0]
+	}
+	if err := setAuthContext(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	if err := getUserKey(cmd, client); err != nil {
+		client.logger.Sugar().Fatal(err)
+	}
+	// process grpc client
+	conn := client.transport(client.config.ServerAddr, client.logger)
+	defer func(l *zap.Logger) {
+		if err := conn.Close(); err != nil {
+			l.Error(err.Error())
+		}
+	}(client.logger)
+	transport := pb.NewKeepPasClient(conn)
+	for {
+		err := watchSecrets(cmd.Context(), transport, prefix, os.Stdout)
+		switch status.Code(err) {
+		case codes.Unavailable:
+			client.logger.Sugar().Debug(err)
+			time.Sleep(watchRetryDelay)
+		case codes.Unauthenticated:
+			// getUserKey refreshes session and vault key
+			client.logger.Sugar().Debug(err)
+			if err := getUserKey(cmd, client); err != nil {
+				client.logger.Sugar().Fatal(err)
+			}
+		case codes.Canceled:
+			return
+		default:
+			client.logger.Sugar().Fatal(err)
+		}
+	}
+}
+
+// watchSecrets prints events of watch stream until stream is broken. End of stream by server
+// is returned as Unavailable error, so watch can be repeated.
+func watchSecrets(ctx context.Context, transport pb.KeepPasClient, prefix string, out io.Writer) error {
+	stream, err := transport.Watch(ctx, &pb.WatchRequest{Prefix: prefix})
+	if err != nil {
+		return err
+	}
+	for {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			return status.Error(codes.Unavailable, "watch is closed by server")
+		}
+		if err != nil {
+			return err
+		}
+		if err := printWatchEvent(ev, out); err != nil {
+			return err
+		}
+	}
+}
+
+func printWatchEvent(ev *pb.WatchEvent, out io.Writer) error {
+	line := watchLine{
+		Kind:   ev.Kind.String(),
+		Key:    ev.Key,
+		NewKey: ev.NewKey,
+		Time:   time.UnixMilli(ev.Time).Format(time.RFC3339),
+	}
+	// type of removed secret isn't known
+	if ev.Kind != pb.WatchEvent_REMOVE {
+		line.Type = ev.Type.String()
+	}
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeWatchClient returns prepared events and then error
+type fakeWatchClient struct {
+	pb.KeepPasClient
+	events []*pb.WatchEvent
+	err    error
+	prefix string
+}
+
+func (f *fakeWatchClient) Watch(_ context.Context, in *pb.WatchRequest, _ ...grpc.CallOption) (pb.KeepPas_WatchClient, error) {
+	f.prefix = in.Prefix
+	return &fakeWatchStream{events: f.events, err: f.err}, nil
+}
+
+type fakeWatchStream struct {
+	grpc.ClientStream
+	events []*pb.WatchEvent
+	err    error
+}
+
+func (f *fakeWatchStream) Recv() (*pb.WatchEvent, error) {
+	if len(f.events) == 0 {
+		return nil, f.err
+	}
+	ev := f.events[0]
+	f.events = f.events[1:]
+	return ev, nil
+}
+
+func Test_watchSecrets(t *testing.T) {
+	ts := time.Date(2023, 5, 1, 10, 0, 0, 0, time.Local).UnixMilli()
+	stamp := time.UnixMilli(ts).Format(time.RFC3339)
+	t.Run("events", func(t *testing.T) {
+		var out bytes.Buffer
+		transport := fakeWatchClient{
+			events: []*pb.WatchEvent{
+				{Kind: pb.WatchEvent_ADD, Key: "work/vpn", Type: pb.Type_LOGIN, Time: ts},
+				{Kind: pb.WatchEvent_RENAME, Key: "work/vpn", NewKey: "work/vpn2", Type: pb.Type_LOGIN, Time: ts},
+				{Kind: pb.WatchEvent_REMOVE, Key: "work/vpn2", Time: ts},
+			},
+			err: status.Error(codes.Unauthenticated, "session is expired"),
+		}
+		err := watchSecrets(context.Background(), &transport, "work/", &out)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Equal(t, "work/", transport.prefix)
+		assert.Equal(t, `{"kind":"ADD","key":"work/vpn","type":"LOGIN","time":"`+stamp+`"}
+{"kind":"RENAME","key":"work/vpn","newkey":"work/vpn2","type":"LOGIN","time":"`+stamp+`"}
+{"kind":"REMOVE","key":"work/vpn2","time":"`+stamp+`"}
+`, out.String())
+	})
+	t.Run("closed by server", func(t *testing.T) {
+		var out bytes.Buffer
+		err := watchSecrets(context.Background(), &fakeWatchClient{err: io.EOF}, "", &out)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Empty(t, out.String())
+	})
+}
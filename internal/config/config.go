@@ -4,9 +4,13 @@ Package config contents type and methods for server and client configuration
 package config
 
 import (
-	"fmt"
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
 
-	"github.com/hrapovd1/gokeepas/internal/crypto"
 	"github.com/spf13/pflag"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -19,36 +23,94 @@ type Config struct {
 	ServerKey  []byte
 	UserKey    string
 	TokenCache string // path to file with cli user token
+	BreachFile string // path to HIBP file or directory of breached password hashes
+	OfflineDir string // path to directory of cli offline cache of secrets
 	LogLevel   zapcore.Level
+	KeyShares  int           // count of master key shares in server init
+	Threshold  int           // count of master key shares to unseal server
+	JournalKey string        // base64 public key of security journal checkpoints printed by server init
+	TLSCert    string        // path to server tls certificate, it is generated when empty
+	TLSKey     string        // path to server tls private key
+	TokenTTL   time.Duration // live time of jwt tokens
+	RefreshTTL time.Duration // live time of refresh tokens and sessions
+	KEK        string        // provider of key encryption key for user keys: master, file, passphrase or kms
+	KEKFile    string        // path to key file of file provider
+	KEKPass    []byte        // passphrase of passphrase provider
+	KEKAddress string        // address of kms provider service
+	AuditSink  string        // sink of audit log: storage or file
+	AuditFile  string        // path to JSON-lines file of file audit sink
+	Metrics    string        // address of http listener of Prometheus metrics, disabled when empty
+	Reflection bool          // enables grpc reflection service
+	LockLogin  int           // failed logins of user in a row before lockout, 0 disables it
+	LockIP     int           // failed logins from client address in a row before lockout, 0 disables it
+	LockTime   time.Duration // time of lockout, failed logins are forgotten after it
+	RateRead   float64       // read calls per second of user, 0 disables limit
+	RateWrite  float64       // write calls per second of user, 0 disables limit
+	RateList   float64       // list calls per second of user, 0 disables limit
+	MaxFlight  int           // max concurrent calls of user, 0 disables limit
 }
 
-// NewServerConf generates server configuration according flags
+// NewServerConf generates server configuration from YAML file, KEEPPAS_* environment variables
+// and flags, later sources override earlier ones. See loadServerConf.
 func NewServerConf() (*Config, error) {
+	return loadServerConf(os.Args[1:], os.LookupEnv, os.Stdin)
+}
+
+// NewJournalConf generates configuration of server journal verify command according args.
+// Master key isn't needed, signatures of checkpoints are checked by public key of journal.
+func NewJournalConf(args []string) (*Config, error) {
 	conf := &Config{}
-	var dbg bool
-	var addr string
-	var dsn string
-	var srvKey string
-	pflag.BoolVar(&dbg, "debug", false, "Run server with debug logging")
-	pflag.StringVarP(&addr, "address", "a", ":5000", "Server ADDRESS:PORT")
-	pflag.StringVarP(&dsn, "redisDSN", "d", "redis://localhost:6379/0", "Redis DB address, format: 'redis://<user>:<pass>@<ip/dns>:<port>/<db>', default: redis://localhost:6379/0 ")
-	pflag.StringVarP(&srvKey, "masterkey", "k", "", "Server encryption master key. If it isn't provided, server will generates new key and prints in stdout. You need to use the same key for existed DB.")
-	pflag.Parse()
-
-	conf.LogLevel = LoggerConfig(dbg)
-	conf.ServerAddr = addr
-	conf.DBdsn = dsn
-	conf.ServerKey = []byte(srvKey)
-
-	if len(conf.ServerKey) == 0 {
-		srvKey, err := crypto.GenServerKey(crypto.SymmKeyLength)
-		if err != nil {
-			return conf, err
-		}
-		conf.ServerKey = []byte(srvKey)
-		fmt.Printf("\t!!!! Not found server key, generate new: \n\n\t\t'%v'\t\n\n\tPlease remember and provide it in the next run with this db !!!\n", srvKey)
+	flags := pflag.NewFlagSet("journal verify", pflag.ContinueOnError)
+	dsn, ok := os.LookupEnv(envPrefix + "REDIS_DSN")
+	if !ok {
+		dsn = defaultDSN
+	}
+	flags.StringVarP(&conf.DBdsn, "redisDSN", "d", dsn, "Redis DB address, format: 'redis://<user>:<pass>@<ip/dns>:<port>/<db>'")
+	flags.StringVar(&conf.JournalKey, "journal-key", os.Getenv(envPrefix+"JOURNAL_KEY"), "public key of journal printed by server init")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
 	}
+	if conf.JournalKey == "" {
+		return nil, errors.New("journal key isn't set, pass public key printed by server init with --journal-key")
+	}
+	conf.LogLevel = zap.InfoLevel
+	return conf, nil
+}
 
+// NewUnlockConf generates configuration of server unlock command, it accepts the same settings
+// as server to connect to its storage.
+func NewUnlockConf(args []string, stdin io.Reader) (*Config, error) {
+	return loadServerConf(args, os.LookupEnv, stdin)
+}
+
+// NewInitConf generates configuration of server init command according args.
+// With --key-stdin existed master key is read from stdin, otherwise new key is generated.
+func NewInitConf(args []string, stdin io.Reader) (*Config, error) {
+	conf := &Config{}
+	flags := pflag.NewFlagSet("init", pflag.ContinueOnError)
+	var keyStdin bool
+	dsn, ok := os.LookupEnv(envPrefix + "REDIS_DSN")
+	if !ok {
+		dsn = defaultDSN
+	}
+	flags.StringVarP(&conf.DBdsn, "redisDSN", "d", dsn, "Redis DB address, format: 'redis://<user>:<pass>@<ip/dns>:<port>/<db>'")
+	flags.IntVar(&conf.KeyShares, "shares", 5, "count of master key shares")
+	flags.IntVar(&conf.Threshold, "threshold", 3, "count of master key shares to unseal server")
+	flags.BoolVar(&keyStdin, "key-stdin", false, "split existed master key read from stdin instead of new one")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	conf.LogLevel = zap.InfoLevel
+	if keyStdin {
+		key, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		conf.ServerKey = []byte(strings.TrimRight(key, "\r\n"))
+		if len(conf.ServerKey) == 0 {
+			return nil, errors.New("master key in stdin is empty")
+		}
+	}
 	return conf, nil
 }
 
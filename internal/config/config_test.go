@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,10 +16,36 @@ func TestNewServerConf(t *testing.T) {
 	assert.Equal(t, "redis://localhost:6379/0", conf.DBdsn)
 	assert.Equal(t, ":5000", conf.ServerAddr)
 	assert.Equal(t, zapcore.Level(0), conf.LogLevel)
-	assert.NotEmpty(t, conf.ServerKey)
+	// server starts sealed without master key
+	assert.Empty(t, conf.ServerKey)
+}
+
+func TestNewInitConf(t *testing.T) {
+	conf, err := NewInitConf([]string{"--shares", "3", "--threshold", "2"}, strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, 3, conf.KeyShares)
+	assert.Equal(t, 2, conf.Threshold)
+	assert.Empty(t, conf.ServerKey)
+
+	conf, err = NewInitConf([]string{"--key-stdin"}, strings.NewReader("masterkey\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("masterkey"), conf.ServerKey)
+	assert.Equal(t, 5, conf.KeyShares)
+
+	_, err = NewInitConf([]string{"--key-stdin"}, strings.NewReader(""))
+	assert.Error(t, err)
 }
 
 func TestLoggerConfig(t *testing.T) {
 	assert.Equal(t, zap.InfoLevel, LoggerConfig(false))
 	assert.Equal(t, zap.DebugLevel, LoggerConfig(true))
 }
+
+func TestNewJournalConf(t *testing.T) {
+	conf, err := NewJournalConf([]string{"--journal-key", "a2V5"})
+	require.NoError(t, err)
+	assert.Equal(t, "a2V5", conf.JournalKey)
+	assert.Equal(t, defaultDSN, conf.DBdsn)
+	_, err = NewJournalConf(nil)
+	assert.Error(t, err)
+}
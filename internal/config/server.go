@@ -0,0 +1,437 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/audit"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/kek"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envPrefix      = "KEEPPAS_" // prefix of server environment variables
+	defaultAddress = ":5000"
+	defaultDSN     = "redis://localhost:6379/0"
+	// default of brute-force protection of login, thresholds are 0 and protection is disabled
+	defaultLockTime = 15 * time.Minute
+)
+
+// ValidationError contains errors of every misconfigured field.
+type ValidationError []error
+
+func (ve ValidationError) Error() string {
+	msgs := make([]string, 0, len(ve))
+	for _, err := range ve {
+		msgs = append(msgs, err.Error())
+	}
+	return "wrong configuration: " + strings.Join(msgs, "; ")
+}
+
+// serverFile is YAML file of server configuration, durations are written like "30m" or "720h".
+// Master key can't be written in the file, only path to file with it.
+type serverFile struct {
+	Address       string `yaml:"address"`
+	RedisDSN      string `yaml:"redis_dsn"`
+	LogLevel      string `yaml:"log_level"`
+	TLSCert       string `yaml:"tls_cert"`
+	TLSKey        string `yaml:"tls_key"`
+	TokenTTL      string `yaml:"token_ttl"`
+	RefreshTTL    string `yaml:"refresh_ttl"`
+	MasterKeyFile string `yaml:"master_key_file"`
+	KEK           string `yaml:"kek_provider"`
+	KEKFile       string `yaml:"kek_file"`
+	KEKPassFile   string `yaml:"kek_passphrase_file"`
+	KEKAddress    string `yaml:"kek_address"`
+	AuditSink     string `yaml:"audit_sink"`
+	AuditFile     string `yaml:"audit_file"`
+	MetricsAddr   string `yaml:"metrics_address"`
+	Reflection    string `yaml:"reflection"`
+	LockLogin     string `yaml:"lockout_threshold"`
+	LockIP        string `yaml:"lockout_ip_threshold"`
+	LockTime      string `yaml:"lockout_duration"`
+	RateRead      string `yaml:"rate_limit_read"`
+	RateWrite     string `yaml:"rate_limit_write"`
+	RateList      string `yaml:"rate_limit_list"`
+	MaxFlight     string `yaml:"max_inflight"`
+}
+
+// serverSetting binds one setting to its flag and environment variable
+type serverSetting struct {
+	flag string
+	env  string
+	val  *string
+}
+
+// loadServerConf builds server configuration from defaults, YAML file, environment variables
+// and args, each next source overrides previous one. Path to YAML file is set by --config flag
+// or KEEPPAS_CONFIG variable. Master key is read from one of sources: file (--masterkey-file,
+// KEEPPAS_MASTER_KEY_FILE or master_key_file), stdin (--masterkey-stdin), KEEPPAS_MASTER_KEY
+// variable or deprecated -k flag. Without master key server starts sealed. Passphrase of kek
+// provider is read from file or KEEPPAS_KEK_PASSPHRASE variable.
+// All found errors are returned at once as ValidationError.
+func loadServerConf(args []string, lookupEnv func(string) (string, bool), stdin io.Reader) (*Config, error) {
+	settings := serverFile{
+		Address:    defaultAddress,
+		RedisDSN:   defaultDSN,
+		LogLevel:   zapcore.InfoLevel.String(),
+		TokenTTL:   crypto.ExpireDuration.String(),
+		RefreshTTL: crypto.RefreshExpireDuration.String(),
+		KEK:        kek.ProviderMaster,
+		AuditSink:  audit.SinkStorage,
+		Reflection: "false",
+		LockLogin:  "0",
+		LockIP:     "0",
+		LockTime:   defaultLockTime.String(),
+		RateRead:   "0", // per-user limits are disabled by default
+		RateWrite:  "0",
+		RateList:   "0",
+		MaxFlight:  "0",
+	}
+	var (
+		dbg      bool
+		confPath string
+		flagKey  string
+		keyStdin bool
+		flagVals serverFile
+	)
+	flags := pflag.NewFlagSet("keeppas-server", pflag.ContinueOnError)
+	flags.StringVarP(&confPath, "config", "f", "", "path to YAML configuration file, env: KEEPPAS_CONFIG")
+	flags.BoolVar(&dbg, "debug", false, "Run server with debug logging, the same as --log-level debug")
+	flags.StringVarP(&flagVals.Address, "address", "a", defaultAddress, "Server ADDRESS:PORT, env: KEEPPAS_ADDRESS")
+	flags.StringVarP(&flagVals.RedisDSN, "redisDSN", "d", defaultDSN, "Redis DB address, format: 'redis://<user>:<pass>@<ip/dns>:<port>/<db>', env: KEEPPAS_REDIS_DSN")
+	flags.StringVar(&flagVals.LogLevel, "log-level", settings.LogLevel, "debug | info | warn | error, env: KEEPPAS_LOG_LEVEL")
+	flags.StringVar(&flagVals.TLSCert, "tls-cert", "", "path to tls certificate, it is generated at start when empty, env: KEEPPAS_TLS_CERT")
+	flags.StringVar(&flagVals.TLSKey, "tls-key", "", "path to tls private key, env: KEEPPAS_TLS_KEY")
+	flags.StringVar(&flagVals.TokenTTL, "token-ttl", settings.TokenTTL, "live time of auth tokens, env: KEEPPAS_TOKEN_TTL")
+	flags.StringVar(&flagVals.RefreshTTL, "refresh-ttl", settings.RefreshTTL, "live time of refresh tokens, env: KEEPPAS_REFRESH_TTL")
+	flags.StringVar(&flagVals.MasterKeyFile, "masterkey-file", "", "path to file with server master key, env: KEEPPAS_MASTER_KEY_FILE")
+	flags.BoolVar(&keyStdin, "masterkey-stdin", false, "read server master key from stdin")
+	flags.StringVar(&flagVals.KEK, "kek", kek.ProviderMaster, "provider of key encryption key for user keys: master | file | passphrase | kms, env: KEEPPAS_KEK_PROVIDER")
+	flags.StringVar(&flagVals.KEKFile, "kek-file", "", "path to key file of file kek provider, env: KEEPPAS_KEK_FILE")
+	flags.StringVar(&flagVals.KEKPassFile, "kek-passphrase-file", "", "path to file with passphrase of passphrase kek provider, env: KEEPPAS_KEK_PASSPHRASE_FILE")
+	flags.StringVar(&flagVals.KEKAddress, "kek-address", "", "address of kms kek provider: 'unix:///path' or 'http(s)://host:port', env: KEEPPAS_KEK_ADDRESS")
+	flags.StringVar(&flagVals.AuditSink, "audit-sink", audit.SinkStorage, "sink of audit log: storage | file, env: KEEPPAS_AUDIT_SINK")
+	flags.StringVar(&flagVals.AuditFile, "audit-file", "", "path to JSON-lines file of file audit sink, env: KEEPPAS_AUDIT_FILE")
+	flags.StringVar(&flagVals.MetricsAddr, "metrics-address", "", "ADDRESS:PORT of http listener of Prometheus metrics, disabled when empty, env: KEEPPAS_METRICS_ADDRESS")
+	flags.Bool("reflection", false, "enable grpc reflection for debugging with grpcurl, env: KEEPPAS_REFLECTION")
+	flags.StringVar(&flagVals.LockLogin, "lockout-threshold", settings.LockLogin, "failed logins of user in a row before lockout, 0 disables, env: KEEPPAS_LOCKOUT_THRESHOLD")
+	flags.StringVar(&flagVals.LockIP, "lockout-ip-threshold", settings.LockIP, "failed logins from client address in a row before lockout, 0 disables, env: KEEPPAS_LOCKOUT_IP_THRESHOLD")
+	flags.StringVar(&flagVals.LockTime, "lockout-duration", settings.LockTime, "time of lockout, failed logins are forgotten after it, env: KEEPPAS_LOCKOUT_DURATION")
+	flags.StringVar(&flagVals.RateRead, "rate-limit-read", settings.RateRead, "read calls per second of user, 0 disables limit, env: KEEPPAS_RATE_LIMIT_READ")
+	flags.StringVar(&flagVals.RateWrite, "rate-limit-write", settings.RateWrite, "write calls per second of user, 0 disables limit, env: KEEPPAS_RATE_LIMIT_WRITE")
+	flags.StringVar(&flagVals.RateList, "rate-limit-list", settings.RateList, "list calls per second of user, 0 disables limit, env: KEEPPAS_RATE_LIMIT_LIST")
+	flags.StringVar(&flagVals.MaxFlight, "max-inflight", settings.MaxFlight, "max concurrent calls of user, 0 disables limit, env: KEEPPAS_MAX_INFLIGHT")
+	flags.StringVarP(&flagKey, "masterkey", "k", "", "Server encryption master key, deprecated: it is visible in process list, use --masterkey-file or KEEPPAS_MASTER_KEY.")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	var errs ValidationError
+	if !flags.Changed("config") {
+		confPath, _ = lookupEnv(envPrefix + "CONFIG")
+	}
+	if confPath != "" {
+		if err := readServerFile(confPath, &settings); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, s := range []serverSetting{
+		{"address", "ADDRESS", &settings.Address},
+		{"redisDSN", "REDIS_DSN", &settings.RedisDSN},
+		{"log-level", "LOG_LEVEL", &settings.LogLevel},
+		{"tls-cert", "TLS_CERT", &settings.TLSCert},
+		{"tls-key", "TLS_KEY", &settings.TLSKey},
+		{"token-ttl", "TOKEN_TTL", &settings.TokenTTL},
+		{"refresh-ttl", "REFRESH_TTL", &settings.RefreshTTL},
+		{"masterkey-file", "MASTER_KEY_FILE", &settings.MasterKeyFile},
+		{"kek", "KEK_PROVIDER", &settings.KEK},
+		{"kek-file", "KEK_FILE", &settings.KEKFile},
+		{"kek-passphrase-file", "KEK_PASSPHRASE_FILE", &settings.KEKPassFile},
+		{"kek-address", "KEK_ADDRESS", &settings.KEKAddress},
+		{"audit-sink", "AUDIT_SINK", &settings.AuditSink},
+		{"audit-file", "AUDIT_FILE", &settings.AuditFile},
+		{"metrics-address", "METRICS_ADDRESS", &settings.MetricsAddr},
+		{"reflection", "REFLECTION", &settings.Reflection},
+		{"lockout-threshold", "LOCKOUT_THRESHOLD", &settings.LockLogin},
+		{"lockout-ip-threshold", "LOCKOUT_IP_THRESHOLD", &settings.LockIP},
+		{"lockout-duration", "LOCKOUT_DURATION", &settings.LockTime},
+		{"rate-limit-read", "RATE_LIMIT_READ", &settings.RateRead},
+		{"rate-limit-write", "RATE_LIMIT_WRITE", &settings.RateWrite},
+		{"rate-limit-list", "RATE_LIMIT_LIST", &settings.RateList},
+		{"max-inflight", "MAX_INFLIGHT", &settings.MaxFlight},
+	} {
+		if val, ok := lookupEnv(envPrefix + s.env); ok {
+			*s.val = val
+		}
+		if flags.Changed(s.flag) {
+			*s.val = flags.Lookup(s.flag).Value.String()
+		}
+	}
+	if dbg {
+		settings.LogLevel = zapcore.DebugLevel.String()
+	}
+
+	conf := &Config{
+		ServerAddr: settings.Address,
+		DBdsn:      settings.RedisDSN,
+		TLSCert:    settings.TLSCert,
+		TLSKey:     settings.TLSKey,
+		KEK:        settings.KEK,
+		KEKFile:    settings.KEKFile,
+		KEKAddress: settings.KEKAddress,
+		AuditSink:  settings.AuditSink,
+		AuditFile:  settings.AuditFile,
+		Metrics:    settings.MetricsAddr,
+	}
+	errs = append(errs, validateServerFile(&settings, conf)...)
+	envPass, _ := lookupEnv(envPrefix + "KEK_PASSPHRASE")
+	pass, err := readKEKPass(settings.KEK, settings.KEKPassFile, envPass)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	conf.KEKPass = pass
+	envKey, _ := lookupEnv(envPrefix + "MASTER_KEY")
+	key, err := readMasterKey(flagKey, envKey, settings.MasterKeyFile, keyStdin, stdin)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	conf.ServerKey = key
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return conf, nil
+}
+
+// readServerFile reads YAML file over settings, unknown fields are errors.
+func readServerFile(path string, settings *serverFile) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(settings); err != nil && err != io.EOF {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// validateServerFile checks settings and fills conf, it returns error for each wrong setting.
+func validateServerFile(settings *serverFile, conf *Config) ValidationError {
+	var errs ValidationError
+	if _, _, err := net.SplitHostPort(settings.Address); err != nil {
+		errs = append(errs, fmt.Errorf("address: %w", err))
+	}
+	if u, err := url.Parse(settings.RedisDSN); err != nil {
+		errs = append(errs, fmt.Errorf("redis dsn: %w", err))
+	} else if u.Scheme != "redis" && u.Scheme != "rediss" && u.Scheme != "unix" {
+		errs = append(errs, fmt.Errorf("redis dsn: unsupported scheme %q", u.Scheme))
+	}
+	level, err := zapcore.ParseLevel(settings.LogLevel)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("log level: %w", err))
+	}
+	conf.LogLevel = level
+	if (settings.TLSCert == "") != (settings.TLSKey == "") {
+		errs = append(errs, fmt.Errorf("tls: both certificate and key must be set"))
+	}
+	for _, path := range []string{settings.TLSCert, settings.TLSKey} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			errs = append(errs, fmt.Errorf("tls: %w", err))
+		}
+	}
+	if conf.TokenTTL, err = parseTTL("token ttl", settings.TokenTTL); err != nil {
+		errs = append(errs, err)
+	}
+	if conf.RefreshTTL, err = parseTTL("refresh ttl", settings.RefreshTTL); err != nil {
+		errs = append(errs, err)
+	}
+	if conf.TokenTTL > 0 && conf.RefreshTTL > 0 && conf.RefreshTTL <= conf.TokenTTL {
+		errs = append(errs, fmt.Errorf("refresh ttl: it must be longer than token ttl %v", conf.TokenTTL))
+	}
+	errs = append(errs, validateKEK(settings)...)
+	switch settings.AuditSink {
+	case audit.SinkStorage:
+	case audit.SinkFile:
+		if settings.AuditFile == "" {
+			errs = append(errs, fmt.Errorf("audit file: it is required by file sink"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("audit sink: unknown sink %q", settings.AuditSink))
+	}
+	if settings.MetricsAddr != "" {
+		if _, _, err := net.SplitHostPort(settings.MetricsAddr); err != nil {
+			errs = append(errs, fmt.Errorf("metrics address: %w", err))
+		}
+	}
+	if conf.Reflection, err = strconv.ParseBool(settings.Reflection); err != nil {
+		errs = append(errs, fmt.Errorf("reflection: %w", err))
+	}
+	if conf.LockLogin, err = parseThreshold("lockout threshold", settings.LockLogin); err != nil {
+		errs = append(errs, err)
+	}
+	if conf.LockIP, err = parseThreshold("lockout ip threshold", settings.LockIP); err != nil {
+		errs = append(errs, err)
+	}
+	if conf.LockTime, err = parseTTL("lockout duration", settings.LockTime); err != nil {
+		errs = append(errs, err)
+	}
+	if conf.RateRead, err = parseRate("rate limit read", settings.RateRead); err != nil {
+		errs = append(errs, err)
+	}
+	if conf.RateWrite, err = parseRate("rate limit write", settings.RateWrite); err != nil {
+		errs = append(errs, err)
+	}
+	if conf.RateList, err = parseRate("rate limit list", settings.RateList); err != nil {
+		errs = append(errs, err)
+	}
+	if conf.MaxFlight, err = parseThreshold("max inflight", settings.MaxFlight); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// validateKEK checks settings of chosen kek provider
+func validateKEK(settings *serverFile) ValidationError {
+	var errs ValidationError
+	switch settings.KEK {
+	case kek.ProviderMaster, kek.ProviderPassphrase:
+	case kek.ProviderFile:
+		if settings.KEKFile == "" {
+			errs = append(errs, fmt.Errorf("kek file: it is required by file provider"))
+		} else if _, err := os.Stat(settings.KEKFile); err != nil {
+			errs = append(errs, fmt.Errorf("kek file: %w", err))
+		}
+	case kek.ProviderKMS:
+		u, err := url.Parse(settings.KEKAddress)
+		switch {
+		case settings.KEKAddress == "":
+			errs = append(errs, fmt.Errorf("kek address: it is required by kms provider"))
+		case err != nil:
+			errs = append(errs, fmt.Errorf("kek address: %w", err))
+		case u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "unix":
+			errs = append(errs, fmt.Errorf("kek address: unsupported scheme %q", u.Scheme))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("kek provider: unknown provider %q", settings.KEK))
+	}
+	return errs
+}
+
+// readKEKPass returns passphrase of passphrase provider from file or environment variable.
+func readKEKPass(provider string, path string, envPass string) ([]byte, error) {
+	if provider != kek.ProviderPassphrase {
+		return nil, nil
+	}
+	if path != "" && envPass != "" {
+		return nil, fmt.Errorf("kek passphrase: only one source is allowed, got file and %sKEK_PASSPHRASE", envPrefix)
+	}
+	pass := []byte(envPass)
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("kek passphrase: %w", err)
+		}
+		pass = bytes.TrimRight(data, "\r\n")
+	}
+	if len(pass) == 0 {
+		return nil, fmt.Errorf("kek passphrase: it is required by passphrase provider")
+	}
+	return pass, nil
+}
+
+func parseTTL(name string, val string) (time.Duration, error) {
+	ttl, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	if ttl <= 0 {
+		return 0, fmt.Errorf("%s: it must be positive", name)
+	}
+	return ttl, nil
+}
+
+// parseThreshold parses count of failed attempts, 0 means lockout is disabled.
+func parseThreshold(name string, val string) (int, error) {
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("%s: it must not be negative", name)
+	}
+	return n, nil
+}
+
+// parseRate parses limit of calls per second, 0 means calls aren't limited.
+func parseRate(name string, val string) (float64, error) {
+	rate, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	if rate < 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return 0, fmt.Errorf("%s: it must be finite and not negative", name)
+	}
+	return rate, nil
+}
+
+// readMasterKey returns master key from the only provided source, empty key means sealed start.
+func readMasterKey(flagKey string, envKey string, path string, fromStdin bool, stdin io.Reader) ([]byte, error) {
+	sources := make([]string, 0, 4)
+	if flagKey != "" {
+		sources = append(sources, "--masterkey flag")
+	}
+	if envKey != "" {
+		sources = append(sources, envPrefix+"MASTER_KEY")
+	}
+	if path != "" {
+		sources = append(sources, "master key file")
+	}
+	if fromStdin {
+		sources = append(sources, "stdin")
+	}
+	if len(sources) > 1 {
+		return nil, fmt.Errorf("master key: only one source is allowed, got %s", strings.Join(sources, ", "))
+	}
+	var key []byte
+	switch {
+	case flagKey != "":
+		key = []byte(flagKey)
+	case envKey != "":
+		key = []byte(envKey)
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("master key: %w", err)
+		}
+		key = data
+	case fromStdin:
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("master key: %w", err)
+		}
+		key = data
+	default:
+		return nil, nil
+	}
+	key = bytes.TrimRight(key, "\r\n")
+	if len(key) == 0 {
+		return nil, fmt.Errorf("master key: it is empty")
+	}
+	return key, nil
+}
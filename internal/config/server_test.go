@@ -0,0 +1,242 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func envFrom(vals map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		val, ok := vals[name]
+		return val, ok
+	}
+}
+
+func writeFile(t *testing.T, name string, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+	return path
+}
+
+func Test_loadServerConfDefaults(t *testing.T) {
+	conf, err := loadServerConf(nil, envFrom(nil), strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, ":5000", conf.ServerAddr)
+	assert.Equal(t, "redis://localhost:6379/0", conf.DBdsn)
+	assert.Equal(t, zap.InfoLevel, conf.LogLevel)
+	assert.Equal(t, crypto.ExpireDuration, conf.TokenTTL)
+	assert.Equal(t, crypto.RefreshExpireDuration, conf.RefreshTTL)
+	assert.Empty(t, conf.ServerKey)
+}
+
+func Test_loadServerConfPrecedence(t *testing.T) {
+	path := writeFile(t, "keeppas.yaml", `
+address: ":6000"
+redis_dsn: redis://db:6379/1
+log_level: warn
+token_ttl: 10m
+refresh_ttl: 24h
+`)
+	env := envFrom(map[string]string{
+		"KEEPPAS_CONFIG":    path,
+		"KEEPPAS_ADDRESS":   ":7000",
+		"KEEPPAS_TOKEN_TTL": "15m",
+	})
+	conf, err := loadServerConf([]string{"-a", ":8000", "--refresh-ttl", "48h"}, env, strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, ":8000", conf.ServerAddr)        // flag over env and file
+	assert.Equal(t, "redis://db:6379/1", conf.DBdsn) // file over default
+	assert.Equal(t, zap.WarnLevel, conf.LogLevel)
+	assert.Equal(t, 15*time.Minute, conf.TokenTTL) // env over file
+	assert.Equal(t, 48*time.Hour, conf.RefreshTTL)
+
+	conf, err = loadServerConf([]string{"--debug"}, env, strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, zap.DebugLevel, conf.LogLevel)
+}
+
+func Test_loadServerConfMasterKey(t *testing.T) {
+	keyFile := writeFile(t, "master.key", "fileKey\n")
+	tests := []struct {
+		name  string
+		args  []string
+		env   map[string]string
+		stdin string
+		key   string
+		err   bool
+	}{
+		{"file", []string{"--masterkey-file", keyFile}, nil, "", "fileKey", false},
+		{"file from env", nil, map[string]string{"KEEPPAS_MASTER_KEY_FILE": keyFile}, "", "fileKey", false},
+		{"env", nil, map[string]string{"KEEPPAS_MASTER_KEY": "envKey"}, "", "envKey", false},
+		{"stdin", []string{"--masterkey-stdin"}, nil, "stdinKey\n", "stdinKey", false},
+		{"flag", []string{"-k", "flagKey"}, nil, "", "flagKey", false},
+		{"two sources", []string{"--masterkey-stdin"}, map[string]string{"KEEPPAS_MASTER_KEY": "envKey"}, "stdinKey", "", true},
+		{"empty stdin", []string{"--masterkey-stdin"}, nil, "\n", "", true},
+		{"missed file", []string{"--masterkey-file", keyFile + ".none"}, nil, "", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf, err := loadServerConf(test.args, envFrom(test.env), strings.NewReader(test.stdin))
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []byte(test.key), conf.ServerKey)
+		})
+	}
+}
+
+func Test_loadServerConfErrors(t *testing.T) {
+	env := envFrom(map[string]string{
+		"KEEPPAS_ADDRESS":     "no-port",
+		"KEEPPAS_REDIS_DSN":   "http://db",
+		"KEEPPAS_LOG_LEVEL":   "loud",
+		"KEEPPAS_TOKEN_TTL":   "soon",
+		"KEEPPAS_REFRESH_TTL": "-1h",
+		"KEEPPAS_TLS_CERT":    "/none/cert.pem",
+	})
+	_, err := loadServerConf(nil, env, strings.NewReader(""))
+	var ve ValidationError
+	require.True(t, errors.As(err, &ve))
+	// every field is reported at once
+	assert.Len(t, ve, 7)
+	for _, field := range []string{"address", "redis dsn", "log level", "token ttl", "refresh ttl", "tls"} {
+		assert.Contains(t, err.Error(), field)
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		path := writeFile(t, "keeppas.yaml", "master_key: secret\n")
+		_, err := loadServerConf([]string{"--config", path}, envFrom(nil), strings.NewReader(""))
+		assert.Error(t, err)
+	})
+}
+
+func Test_loadServerConfKEK(t *testing.T) {
+	keyFile := writeFile(t, "kek.key", "0123456789abcdef0123456789abcdef\n")
+	passFile := writeFile(t, "kek.pass", "passphrase\n")
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		err  bool
+	}{
+		{"default", nil, nil, false},
+		{"file", []string{"--kek", "file", "--kek-file", keyFile}, nil, false},
+		{"file without path", []string{"--kek", "file"}, nil, true},
+		{"passphrase file", []string{"--kek", "passphrase", "--kek-passphrase-file", passFile}, nil, false},
+		{"passphrase env", nil, map[string]string{"KEEPPAS_KEK_PROVIDER": "passphrase", "KEEPPAS_KEK_PASSPHRASE": "passphrase"}, false},
+		{"passphrase two sources", []string{"--kek", "passphrase", "--kek-passphrase-file", passFile}, map[string]string{"KEEPPAS_KEK_PASSPHRASE": "passphrase"}, true},
+		{"passphrase missed", []string{"--kek", "passphrase"}, nil, true},
+		{"kms", nil, map[string]string{"KEEPPAS_KEK_PROVIDER": "kms", "KEEPPAS_KEK_ADDRESS": "unix:///run/kms.sock"}, false},
+		{"kms wrong address", []string{"--kek", "kms", "--kek-address", "ftp://kms"}, nil, true},
+		{"unknown", []string{"--kek", "vault"}, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf, err := loadServerConf(test.args, envFrom(test.env), strings.NewReader(""))
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if conf.KEK == "passphrase" {
+				assert.Equal(t, []byte("passphrase"), conf.KEKPass)
+			}
+		})
+	}
+}
+
+func Test_loadServerConfAudit(t *testing.T) {
+	conf, err := loadServerConf(nil, envFrom(nil), strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, "storage", conf.AuditSink)
+
+	conf, err = loadServerConf([]string{"--audit-sink", "file"}, envFrom(map[string]string{"KEEPPAS_AUDIT_FILE": "/var/log/keeppas/audit.log"}), strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, "file", conf.AuditSink)
+	assert.Equal(t, "/var/log/keeppas/audit.log", conf.AuditFile)
+
+	_, err = loadServerConf([]string{"--audit-sink", "file"}, envFrom(nil), strings.NewReader(""))
+	assert.ErrorContains(t, err, "audit file")
+	_, err = loadServerConf([]string{"--audit-sink", "syslog"}, envFrom(nil), strings.NewReader(""))
+	assert.ErrorContains(t, err, "audit sink")
+}
+
+func Test_loadServerConfMetrics(t *testing.T) {
+	conf, err := loadServerConf(nil, envFrom(nil), strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, conf.Metrics)
+
+	conf, err = loadServerConf(nil, envFrom(map[string]string{"KEEPPAS_METRICS_ADDRESS": "127.0.0.1:9090"}), strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:9090", conf.Metrics)
+
+	_, err = loadServerConf([]string{"--metrics-address", "9090"}, envFrom(nil), strings.NewReader(""))
+	assert.ErrorContains(t, err, "metrics address")
+}
+
+func Test_loadServerConfReflection(t *testing.T) {
+	conf, err := loadServerConf(nil, envFrom(nil), strings.NewReader(""))
+	require.NoError(t, err)
+	assert.False(t, conf.Reflection)
+
+	conf, err = loadServerConf([]string{"--reflection"}, envFrom(nil), strings.NewReader(""))
+	require.NoError(t, err)
+	assert.True(t, conf.Reflection)
+
+	conf, err = loadServerConf(nil, envFrom(map[string]string{"KEEPPAS_REFLECTION": "true"}), strings.NewReader(""))
+	require.NoError(t, err)
+	assert.True(t, conf.Reflection)
+
+	_, err = loadServerConf(nil, envFrom(map[string]string{"KEEPPAS_REFLECTION": "maybe"}), strings.NewReader(""))
+	assert.ErrorContains(t, err, "reflection")
+}
+
+func Test_loadServerConfLockout(t *testing.T) {
+	conf, err := loadServerConf(nil, envFrom(nil), strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, 0, conf.LockLogin)
+	assert.Equal(t, 0, conf.LockIP)
+	assert.Equal(t, 15*time.Minute, conf.LockTime)
+
+	conf, err = loadServerConf([]string{"--lockout-threshold", "5", "--lockout-duration", "1h"}, envFrom(map[string]string{"KEEPPAS_LOCKOUT_IP_THRESHOLD": "50"}), strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, 5, conf.LockLogin)
+	assert.Equal(t, 50, conf.LockIP)
+	assert.Equal(t, time.Hour, conf.LockTime)
+
+	_, err = loadServerConf([]string{"--lockout-threshold", "-1", "--lockout-ip-threshold", "many", "--lockout-duration", "0s"}, envFrom(nil), strings.NewReader(""))
+	assert.ErrorContains(t, err, "lockout threshold")
+	assert.ErrorContains(t, err, "lockout ip threshold")
+	assert.ErrorContains(t, err, "lockout duration")
+}
+
+func Test_loadServerConfRateLimits(t *testing.T) {
+	conf, err := loadServerConf(nil, envFrom(nil), strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, conf.RateRead)
+	assert.Equal(t, 0.0, conf.RateWrite)
+	assert.Equal(t, 0.0, conf.RateList)
+	assert.Equal(t, 0, conf.MaxFlight)
+
+	conf, err = loadServerConf([]string{"--rate-limit-list", "0.5", "--max-inflight", "16"}, envFrom(map[string]string{"KEEPPAS_RATE_LIMIT_READ": "100"}), strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, conf.RateRead)
+	assert.Equal(t, 0.5, conf.RateList)
+	assert.Equal(t, 16, conf.MaxFlight)
+
+	_, err = loadServerConf([]string{"--rate-limit-write", "-1", "--rate-limit-read", "fast", "--max-inflight", "-2"}, envFrom(nil), strings.NewReader(""))
+	assert.ErrorContains(t, err, "rate limit write")
+	assert.ErrorContains(t, err, "rate limit read")
+	assert.ErrorContains(t, err, "max inflight")
+}
@@ -5,33 +5,50 @@ package crypto
 
 import (
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"io"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/hrapovd1/gokeepas/internal/types"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
 )
 
 const (
-	hashSalt       = "oerwtOUFHsa.sd!df56s"
-	expireDuration = 30 * time.Minute // jwt token live time
+	hashSalt              = "oerwtOUFHsa.sd!df56s"
+	ExpireDuration        = 30 * time.Minute    // default jwt token live time
+	RefreshExpireDuration = 30 * 24 * time.Hour // default refresh token live time
+
+	sessionIDLength    = 16 // length of session id in bytes
+	refreshTokenLength = 32 // length of random part of refresh token in bytes
+
+	cipherMagic     = 'K' // first byte of versioned ciphertext header
+	cipherVersion1  = 1   // AES-GCM with associated data
+	cipherHeaderLen = 2   // magic + version
+
+	ShareKeyLength = 32 // length of X25519 keys of users
 
 	alphabet      = 61
-	SymmKeyLength = 24 // length of user symmetric key
+	SymmKeyLength = 32 // length of user symmetric key
 )
 
+// ErrTampered returns when ciphertext doesn't match data it is bound to with associated data.
+var ErrTampered = errors.New("secret integrity check failed: data doesn't match its key name or type")
+
 // GenX509KeyPair generates the TLS keypair for the server
 // https://gist.github.com/shivakar/cd52b5594d4912fbeb46
 func GenX509KeyPair() (tls.Certificate, error) {
@@ -96,47 +113,134 @@ func GenServerKey(n int) (string, error) {
 	return string(out), nil
 }
 
-// EncryptKey encrypt data with symmKey
+// EncryptKey encrypt data with symmKey, output is envelope of DefaultAlgorithm without associated data.
 func EncryptKey(symmKey []byte, data []byte) (string, error) {
-	// encrypt data
-	cphr, err := aes.NewCipher(symmKey)
+	return EncryptData(symmKey, data, nil)
+}
+
+// DecryptKey decrypt data of EncryptKey with symmKey, legacy ciphertext without header is decrypted too.
+// Ciphertext with envelope header isn't tried as legacy one, so it can't be substituted by legacy one.
+func DecryptKey(symm []byte, data string) ([]byte, error) {
+	encData, err := base64.StdEncoding.DecodeString(data)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	gcm, err := cipher.NewGCM(cphr)
-	if err != nil {
-		return "", err
+	env, ok := parseEnvelope(encData)
+	if !ok {
+		return openLegacy(symm, encData)
 	}
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
+	return env.open(symm, nil)
+}
+
+// AssociatedData joins fields which ciphertext is bound to, each field is prefixed by its length.
+func AssociatedData(fields ...string) []byte {
+	out := make([]byte, 0)
+	for _, f := range fields {
+		out = binary.AppendUvarint(out, uint64(len(f)))
+		out = append(out, f...)
 	}
-	dataEnc := gcm.Seal(nonce, nonce, data, nil)
+	return out
+}
 
-	return base64.StdEncoding.EncodeToString(dataEnc), nil
+// EncryptData encrypt data with symmKey and binds it to associated data ad.
+// Output is base64 of envelope of DefaultAlgorithm, see envelope.go.
+func EncryptData(symmKey []byte, data []byte, ad []byte) (string, error) {
+	out, err := sealEnvelope(DefaultAlgorithm, symmKey, data, ad)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
 }
 
-// DecryptKey decrypt data with symmKey
-func DecryptKey(symm []byte, data string) ([]byte, error) {
-	encJSON, err := base64.StdEncoding.DecodeString(data)
+// DecryptData decrypt data with symmKey and checks it is bound to associated data ad.
+// Ciphertext of version 1 and legacy ciphertext of EncryptKey are decrypted too. Ciphertext with
+// header isn't tried as legacy one, so it can't be substituted by ciphertext without associated data.
+func DecryptData(symmKey []byte, data string, ad []byte) ([]byte, error) {
+	encData, err := base64.StdEncoding.DecodeString(data)
 	if err != nil {
 		return nil, err
 	}
-	// Decrypt symm key from db
-	chpr, err := aes.NewCipher(symm)
+	if env, ok := parseEnvelope(encData); ok {
+		out, err := env.open(symmKey, ad)
+		if err != nil && !errors.Is(err, ErrWrongKey) {
+			return nil, ErrTampered
+		}
+		return out, err
+	}
+	if len(encData) >= cipherHeaderLen && encData[0] == cipherMagic && encData[1] == cipherVersion1 {
+		out, err := openV1(symmKey, encData, ad)
+		if err != nil {
+			return nil, ErrTampered
+		}
+		return out, nil
+	}
+	out, err := openLegacy(symmKey, encData)
 	if err != nil {
-		return nil, err
+		return nil, ErrTampered
 	}
-	gcmDecrypt, err := cipher.NewGCM(chpr)
+	return out, nil
+}
+
+// GenShareKeyPair generates X25519 keypair of user for sharing secrets, it returns public and private keys.
+func GenShareKeyPair() ([]byte, []byte, error) {
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pub[:], priv[:], nil
+}
+
+// SharePublicKey returns X25519 public key of private key priv.
+func SharePublicKey(priv []byte) ([]byte, error) {
+	if len(priv) != ShareKeyLength {
+		return nil, errors.New("wrong private key length")
+	}
+	return curve25519.X25519(priv, curve25519.Basepoint)
+}
+
+// Fingerprint returns SHA-256 fingerprint of public key, users compare it out of band
+// to be sure that the key isn't substituted by server.
+func Fingerprint(pub []byte) string {
+	sum := sha256.Sum256(pub)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// SealShare encrypts data to recipient's public key with anonymous sealed box and binds it to associated data ad.
+// Sealed box has no associated data, so ad is sealed together with data and checked by OpenShare.
+func SealShare(pub []byte, data []byte, ad []byte) (string, error) {
+	if len(pub) != ShareKeyLength {
+		return "", errors.New("wrong public key length")
+	}
+	msg := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(ad)+len(data)), uint64(len(ad)))
+	msg = append(append(msg, ad...), data...)
+	out, err := box.SealAnonymous(nil, msg, (*[ShareKeyLength]byte)(pub), rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// OpenShare decrypts data of SealShare with recipient's keypair and checks it is bound to associated data ad.
+func OpenShare(pub []byte, priv []byte, data string, ad []byte) ([]byte, error) {
+	if len(pub) != ShareKeyLength || len(priv) != ShareKeyLength {
+		return nil, errors.New("wrong keypair length")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(data)
 	if err != nil {
 		return nil, err
 	}
-	nonceSize := gcmDecrypt.NonceSize()
-	if len(encJSON) < nonceSize {
-		return nil, errors.New("len(encJSON) < nonceSize")
+	msg, ok := box.OpenAnonymous(nil, sealed, (*[ShareKeyLength]byte)(pub), (*[ShareKeyLength]byte)(priv))
+	if !ok {
+		return nil, ErrTampered
+	}
+	adLen, n := binary.Uvarint(msg)
+	if n <= 0 || adLen > uint64(len(msg)-n) {
+		return nil, ErrTampered
+	}
+	if subtle.ConstantTimeCompare(msg[n:n+int(adLen)], ad) != 1 {
+		return nil, ErrTampered
 	}
-	nonce, encDataJSON := encJSON[:nonceSize], encJSON[nonceSize:]
-	return gcmDecrypt.Open(nil, nonce, encDataJSON, nil)
+	return msg[n+int(adLen):], nil
 }
 
 // HashPasswd return hash of password
@@ -148,8 +252,8 @@ func HashPasswd(_ context.Context, passwd []byte) (string, error) {
 	return fmt.Sprintf("%x", pwdHash.Sum(nil)), nil
 }
 
-// GetToken generate jwt session token for user
-func GetToken(_ context.Context, login string, passwd string, userData types.StorageModel, key []byte) (string, error) {
+// GetToken generate jwt session token for user, token expires after ttl
+func GetToken(_ context.Context, login string, passwd string, sid string, userData types.StorageModel, key []byte, ttl time.Duration) (string, error) {
 	pwdHash := sha1.New()
 	pwdHash.Write([]byte(passwd))
 	pwdHash.Write([]byte(hashSalt))
@@ -157,12 +261,18 @@ func GetToken(_ context.Context, login string, passwd string, userData types.Sto
 	if userData.PassHash != password {
 		return "", fmt.Errorf("wrong login or password")
 	}
+	return NewToken(login, sid, key, ttl)
+}
+
+// NewToken generate jwt token for user session sid without password check, token expires after ttl
+func NewToken(login string, sid string, key []byte, ttl time.Duration) (string, error) {
 	token := jwt.NewWithClaims(
 		jwt.SigningMethodHS256,
 		&types.Claims{
 			Login: login,
 			RegisteredClaims: jwt.RegisteredClaims{
-				ExpiresAt: jwt.NewNumericDate(time.Now().Add(expireDuration)),
+				ID:        sid,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 				IssuedAt:  jwt.NewNumericDate(time.Now()),
 			},
 		})
@@ -172,6 +282,15 @@ func GetToken(_ context.Context, login string, passwd string, userData types.Sto
 
 // CheckToken checks jwt token is provided by cli
 func CheckToken(tkn string, key []byte) (string, error) {
+	claims, err := ParseToken(tkn, key)
+	if err != nil {
+		return "", err
+	}
+	return claims.Login, nil
+}
+
+// ParseToken checks jwt token and returns its claims
+func ParseToken(tkn string, key []byte) (*types.Claims, error) {
 	token, err := jwt.ParseWithClaims(
 		tkn,
 		&types.Claims{},
@@ -183,12 +302,53 @@ func CheckToken(tkn string, key []byte) (string, error) {
 		},
 	)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if claims, ok := token.Claims.(*types.Claims); ok && token.Valid {
-		return claims.Login, nil
+		return claims, nil
 	}
 
-	return "", errors.New("token wrong")
+	return nil, errors.New("token wrong")
+}
+
+// GenSessionID return random id of user session
+func GenSessionID() (string, error) {
+	sid, err := GenSymmKey(sessionIDLength)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sid), nil
+}
+
+// GenRefreshToken return new random refresh token for session sid: "<sid>.<random>"
+func GenRefreshToken(sid string) (string, error) {
+	secret, err := GenSymmKey(refreshTokenLength)
+	if err != nil {
+		return "", err
+	}
+	return sid + "." + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// ParseRefreshToken returns session id from refresh token
+func ParseRefreshToken(tkn string) (string, error) {
+	sid, secret, ok := strings.Cut(tkn, ".")
+	if !ok || len(sid) != 2*sessionIDLength || secret == "" {
+		return "", errors.New("wrong refresh token")
+	}
+	if _, err := hex.DecodeString(sid); err != nil {
+		return "", errors.New("wrong refresh token")
+	}
+	return sid, nil
+}
+
+// HashToken return hash of refresh token for keeping in storage
+func HashToken(tkn string) string {
+	tknHash := sha256.Sum256([]byte(tkn))
+	return hex.EncodeToString(tknHash[:])
+}
+
+// EqualHash compares two hashes in constant time
+func EqualHash(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
 }
@@ -37,7 +37,11 @@ func TestEncryptKey(t *testing.T) {
 	require.NoError(t, err)
 	encData, err := base64.StdEncoding.DecodeString(result)
 	require.NoError(t, err)
-	assert.Equal(t, 33, len(encData))
+	// header + key id + XChaCha20 nonce + data + tag
+	assert.Equal(t, 4+4+24+5+16, len(encData))
+	out, err := DecryptKey(symmKey, result)
+	require.NoError(t, err)
+	assert.Equal(t, data, out)
 }
 
 func TestDecryptKey(t *testing.T) {
@@ -48,6 +52,50 @@ func TestDecryptKey(t *testing.T) {
 	assert.Equal(t, []byte("12345"), result)
 }
 
+func TestEncryptData(t *testing.T) {
+	symmKey := []byte(`qwcsposfJOshf.34jswo_sdf`)
+	data := []byte("12345")
+	ad := AssociatedData("test", "key", "TEXT")
+	result, err := EncryptData(symmKey, data, ad)
+	require.NoError(t, err)
+	encData, err := base64.StdEncoding.DecodeString(result)
+	require.NoError(t, err)
+	assert.Equal(t, 4+4+24+5+16, len(encData))
+	assert.Equal(t, []byte{cipherMagic, cipherVersion2, byte(DefaultAlgorithm), keyIDLength}, encData[:envHeaderLen])
+}
+
+func TestDecryptData(t *testing.T) {
+	symmKey := []byte(`qwcsposfJOshf.34jswo_sdf`)
+	ad := AssociatedData("test", "key", "TEXT")
+	encStr, err := EncryptData(symmKey, []byte("12345"), ad)
+	require.NoError(t, err)
+	t.Run("right", func(t *testing.T) {
+		result, err := DecryptData(symmKey, encStr, ad)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("12345"), result)
+	})
+	t.Run("legacy", func(t *testing.T) {
+		result, err := DecryptData(symmKey, "aV6TS1ylt+Y0UrlimwY0lwqdZeZh1w5f1+wFOvY4eZPv", ad)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("12345"), result)
+	})
+	t.Run("tampered", func(t *testing.T) {
+		for _, wrongAD := range [][]byte{
+			AssociatedData("test", "key1", "TEXT"),
+			AssociatedData("test", "key", "LOGIN"),
+			AssociatedData("other", "key", "TEXT"),
+			AssociatedData("testkey", "", "TEXT"),
+		} {
+			_, err := DecryptData(symmKey, encStr, wrongAD)
+			require.ErrorIs(t, err, ErrTampered)
+		}
+	})
+	t.Run("short", func(t *testing.T) {
+		_, err := DecryptData(symmKey, base64.StdEncoding.EncodeToString([]byte{cipherMagic, cipherVersion1, 1}), ad)
+		require.Error(t, err)
+	})
+}
+
 func TestHashPasswd(t *testing.T) {
 	passwd := []byte("sdfwerJ.45fj")
 	passwdHash := "2cec73172dedd21e866ce3ec51011065d36656fc"
@@ -64,13 +112,13 @@ func TestGetToken(t *testing.T) {
 	}
 	key := []byte("12345Tre.wq")
 	t.Run("right", func(t *testing.T) {
-		token, err := GetToken(context.Background(), login, passwd, userData, key)
+		token, err := GetToken(context.Background(), login, passwd, "sid", userData, key, ExpireDuration)
 		require.NoError(t, err)
 		assert.NotEmpty(t, token)
 
 	})
 	t.Run("wrong", func(t *testing.T) {
-		token, err := GetToken(context.Background(), login, "", userData, key)
+		token, err := GetToken(context.Background(), login, "", "sid", userData, key, ExpireDuration)
 		require.Error(t, err)
 		assert.Empty(t, token)
 	})
@@ -84,7 +132,7 @@ func TestCheckToken(t *testing.T) {
 		PassHash: "2cec73172dedd21e866ce3ec51011065d36656fc",
 	}
 	key := []byte("12345Tre.wq")
-	token, err := GetToken(context.Background(), login, passwd, userData, key)
+	token, err := GetToken(context.Background(), login, passwd, "sid", userData, key, ExpireDuration)
 	require.NoError(t, err)
 	t.Run("right", func(t *testing.T) {
 		result, err := CheckToken(token, key)
@@ -105,3 +153,92 @@ func TestCheckToken(t *testing.T) {
 	})
 
 }
+
+func TestParseToken(t *testing.T) {
+	key := []byte("12345Tre.wq")
+	token, err := NewToken("test", "sid", key, ExpireDuration)
+	require.NoError(t, err)
+	t.Run("right", func(t *testing.T) {
+		claims, err := ParseToken(token, key)
+		require.NoError(t, err)
+		assert.Equal(t, "test", claims.Login)
+		assert.Equal(t, "sid", claims.ID)
+	})
+	t.Run("wrong key", func(t *testing.T) {
+		claims, err := ParseToken(token, []byte("wrong"))
+		require.Error(t, err)
+		assert.Nil(t, claims)
+	})
+}
+
+func TestRefreshToken(t *testing.T) {
+	sid, err := GenSessionID()
+	require.NoError(t, err)
+	assert.Equal(t, 2*sessionIDLength, len(sid))
+	token, err := GenRefreshToken(sid)
+	require.NoError(t, err)
+	t.Run("right", func(t *testing.T) {
+		result, err := ParseRefreshToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, sid, result)
+	})
+	t.Run("wrong", func(t *testing.T) {
+		for _, tkn := range []string{"", sid, sid + ".", "zz" + token[2:], "abc.def"} {
+			_, err := ParseRefreshToken(tkn)
+			require.Error(t, err, tkn)
+		}
+	})
+	t.Run("hash", func(t *testing.T) {
+		assert.True(t, EqualHash(HashToken(token), HashToken(token)))
+		assert.False(t, EqualHash(HashToken(token), HashToken(sid)))
+	})
+}
+
+func TestSealShare(t *testing.T) {
+	pub, priv, err := GenShareKeyPair()
+	require.NoError(t, err)
+	ad := AssociatedData("owner", "key", "TEXT")
+	sealed, err := SealShare(pub, []byte("secret"), ad)
+	require.NoError(t, err)
+	t.Run("right", func(t *testing.T) {
+		data, err := OpenShare(pub, priv, sealed, ad)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("secret"), data)
+	})
+	t.Run("wrong ad", func(t *testing.T) {
+		_, err := OpenShare(pub, priv, sealed, AssociatedData("owner", "other", "TEXT"))
+		assert.ErrorIs(t, err, ErrTampered)
+	})
+	t.Run("wrong keypair", func(t *testing.T) {
+		pub2, priv2, err := GenShareKeyPair()
+		require.NoError(t, err)
+		_, err = OpenShare(pub2, priv2, sealed, ad)
+		assert.ErrorIs(t, err, ErrTampered)
+	})
+	t.Run("wrong key length", func(t *testing.T) {
+		_, err := SealShare(pub[:10], []byte("secret"), ad)
+		assert.Error(t, err)
+		_, err = OpenShare(pub, priv[:10], sealed, ad)
+		assert.Error(t, err)
+	})
+}
+
+func TestSharePublicKey(t *testing.T) {
+	pub, priv, err := GenShareKeyPair()
+	require.NoError(t, err)
+	res, err := SharePublicKey(priv)
+	require.NoError(t, err)
+	assert.Equal(t, pub, res)
+	_, err = SharePublicKey(priv[:10])
+	assert.Error(t, err)
+}
+
+func TestFingerprint(t *testing.T) {
+	pub, _, err := GenShareKeyPair()
+	require.NoError(t, err)
+	pub2, _, err := GenShareKeyPair()
+	require.NoError(t, err)
+	assert.Regexp(t, `^SHA256:[A-Za-z0-9+/]{43}$`, Fingerprint(pub))
+	assert.Equal(t, Fingerprint(pub), Fingerprint(pub))
+	assert.NotEqual(t, Fingerprint(pub), Fingerprint(pub2))
+}
@@ -0,0 +1,67 @@
+package crypto
+
+import "errors"
+
+// DataKeyLength is length of random data key of each secret
+const DataKeyLength = 32
+
+// ErrNoDataKey returns when secret doesn't have data key, it was written before data keys.
+var ErrNoDataKey = errors.New("secret doesn't have data key")
+
+// Secrets are encrypted with their own random data key, data key is wrapped by user key and
+// bound to associated data of secret. So user key rotation and sharing re-wrap only small data
+// key and secret data stays the same. Data isn't bound to associated data itself, swap of data
+// between secrets is detected because data key of other secret doesn't decrypt it.
+
+// SealSecret encrypts secret with new data key, it returns encrypted secret and data key
+// wrapped by key and bound to associated data ad.
+func SealSecret(key []byte, secret []byte, ad []byte) (string, string, error) {
+	dataKey, err := GenSymmKey(DataKeyLength)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := EncryptData(dataKey, secret, nil)
+	if err != nil {
+		return "", "", err
+	}
+	wrapped, err := WrapDataKey(key, dataKey, ad)
+	if err != nil {
+		return "", "", err
+	}
+	return data, wrapped, nil
+}
+
+// OpenSecret decrypts secret of SealSecret. Secret without data key isn't decrypted and
+// ErrNoDataKey is returned, see OpenLegacySecret.
+func OpenSecret(key []byte, data string, wrapped string, ad []byte) ([]byte, error) {
+	if wrapped == "" {
+		return nil, ErrNoDataKey
+	}
+	dataKey, err := UnwrapDataKey(key, wrapped, ad)
+	if err != nil {
+		return nil, err
+	}
+	return OpenData(dataKey, data)
+}
+
+// OpenLegacySecret decrypts secret which was encrypted with key directly before data keys.
+// It is used only by explicit upgrade of such secrets: wrapped data key of other secret is
+// ciphertext of the same key and associated data, so storage could pass it as legacy secret.
+func OpenLegacySecret(key []byte, data string, ad []byte) ([]byte, error) {
+	return DecryptData(key, data, ad)
+}
+
+// WrapDataKey encrypts data key with key and binds it to associated data ad.
+func WrapDataKey(key []byte, dataKey []byte, ad []byte) (string, error) {
+	return EncryptData(key, dataKey, ad)
+}
+
+// UnwrapDataKey decrypts data key wrapped by key and checks it is bound to associated data ad.
+func UnwrapDataKey(key []byte, wrapped string, ad []byte) ([]byte, error) {
+	return DecryptData(key, wrapped, ad)
+}
+
+// OpenData decrypts secret data with its data key.
+func OpenData(dataKey []byte, data string) ([]byte, error) {
+	return DecryptData(dataKey, data, nil)
+}
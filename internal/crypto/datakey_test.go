@@ -0,0 +1,54 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealSecret(t *testing.T) {
+	key := []byte("qwcsposfJOshf.34jswo_sdf")
+	ad := []byte("user\x00key\x00TEXT")
+	data, wrapped, err := SealSecret(key, []byte("secret"), ad)
+	require.NoError(t, err)
+
+	t.Run("open", func(t *testing.T) {
+		out, err := OpenSecret(key, data, wrapped, ad)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("secret"), out)
+	})
+	t.Run("re-wrap", func(t *testing.T) {
+		// key rotation changes only wrapped data key
+		dataKey, err := UnwrapDataKey(key, wrapped, ad)
+		require.NoError(t, err)
+		newKey := []byte("0123456789abcdef0123456789abcdef")
+		rewrapped, err := WrapDataKey(newKey, dataKey, ad)
+		require.NoError(t, err)
+		out, err := OpenSecret(newKey, data, rewrapped, ad)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("secret"), out)
+	})
+	t.Run("other ad", func(t *testing.T) {
+		_, err := OpenSecret(key, data, wrapped, []byte("user\x00other\x00TEXT"))
+		assert.ErrorIs(t, err, ErrTampered)
+	})
+	t.Run("swapped data", func(t *testing.T) {
+		other, _, err := SealSecret(key, []byte("other"), ad)
+		require.NoError(t, err)
+		_, err = OpenSecret(key, other, wrapped, ad)
+		assert.Error(t, err)
+	})
+	t.Run("legacy", func(t *testing.T) {
+		legacy, err := EncryptData(key, []byte("secret"), ad)
+		require.NoError(t, err)
+		out, err := OpenLegacySecret(key, legacy, ad)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("secret"), out)
+	})
+	t.Run("removed data key", func(t *testing.T) {
+		// wrapped data key can't be passed as legacy secret
+		_, err := OpenSecret(key, wrapped, "", ad)
+		assert.ErrorIs(t, err, ErrNoDataKey)
+	})
+}
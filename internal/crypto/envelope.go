@@ -0,0 +1,241 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Ciphertext envelope of version 2, it is base64 encoded:
+//
+//	magic 'K' | version 2 | algorithm | key id length | key id | nonce | ciphertext and tag
+//
+// Magic, version, algorithm and key id length are one byte each. Header (magic..key id) is
+// authenticated together with associated data of caller. Algorithm key is derived from caller
+// key by HKDF-SHA256 with algorithm name in info, so key of any length from 16 bytes works with
+// every algorithm. Key id is HKDF of caller key with "key id" info, it tells wrong key from
+// tampered ciphertext and doesn't reveal the key.
+//
+// Older formats are still decrypted:
+//
+//	version 1: 'K' | 1 | 12 bytes nonce | AES-GCM ciphertext, header is authenticated with ad
+//	legacy:    12 bytes nonce | AES-GCM ciphertext without associated data, key is used as is
+//
+// Legacy ciphertext can start with the same bytes as header, so it is tried when header ones fail.
+
+// Algorithm is AEAD algorithm of envelope
+type Algorithm byte
+
+// Algorithms of envelope, ids are kept in ciphertext and must not be changed.
+const (
+	AlgAES256GCM         Algorithm = 1
+	AlgXChaCha20Poly1305 Algorithm = 2
+
+	// DefaultAlgorithm is used for new ciphertext
+	DefaultAlgorithm = AlgXChaCha20Poly1305
+)
+
+const (
+	cipherVersion2 = 2  // envelope with algorithm and key id
+	envHeaderLen   = 4  // magic + version + algorithm + key id length
+	keyIDLength    = 4  // length of key id in bytes
+	minKeyLength   = 16 // minimal length of caller key
+	derivedKeyLen  = 32 // length of algorithm key
+)
+
+// ErrWrongKey returns when ciphertext is encrypted with another key.
+var ErrWrongKey = errors.New("secret is encrypted with another key")
+
+// String returns name of algorithm, it is part of key derivation info.
+func (alg Algorithm) String() string {
+	switch alg {
+	case AlgAES256GCM:
+		return "AES-256-GCM"
+	case AlgXChaCha20Poly1305:
+		return "XChaCha20-Poly1305"
+	}
+	return fmt.Sprintf("Algorithm(%d)", byte(alg))
+}
+
+func (alg Algorithm) nonceSize() int {
+	switch alg {
+	case AlgAES256GCM:
+		return 12
+	case AlgXChaCha20Poly1305:
+		return chacha20poly1305.NonceSizeX
+	}
+	return 0
+}
+
+// aead returns cipher of algorithm with key derived from caller key
+func (alg Algorithm) aead(key []byte) (cipher.AEAD, error) {
+	subKey, err := deriveKey(key, "keeppas "+alg.String(), derivedKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	switch alg {
+	case AlgAES256GCM:
+		block, err := aes.NewCipher(subKey)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case AlgXChaCha20Poly1305:
+		return chacha20poly1305.NewX(subKey)
+	}
+	return nil, fmt.Errorf("unknown algorithm %v", alg)
+}
+
+func deriveKey(key []byte, info string, n int) ([]byte, error) {
+	if len(key) < minKeyLength {
+		return nil, fmt.Errorf("key must be at least %d bytes", minKeyLength)
+	}
+	out := make([]byte, n)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, nil, []byte(info)), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SigningKey derives ed25519 key from master key, it signs checkpoints of security journal.
+func SigningKey(key []byte) (ed25519.PrivateKey, error) {
+	seed, err := deriveKey(key, "keeppas security journal", ed25519.SeedSize)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// keyID returns id of caller key kept in envelope
+func keyID(key []byte) ([]byte, error) {
+	return deriveKey(key, "keeppas key id", keyIDLength)
+}
+
+// sealEnvelope encrypts data with key by algorithm alg and binds it to associated data ad.
+func sealEnvelope(alg Algorithm, key []byte, data []byte, ad []byte) ([]byte, error) {
+	aead, err := alg.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	id, err := keyID(key)
+	if err != nil {
+		return nil, err
+	}
+	headerLen := envHeaderLen + len(id)
+	out := make([]byte, headerLen+aead.NonceSize(), headerLen+aead.NonceSize()+len(data)+aead.Overhead())
+	out[0], out[1], out[2], out[3] = cipherMagic, cipherVersion2, byte(alg), byte(len(id))
+	copy(out[envHeaderLen:], id)
+	nonce := out[headerLen:]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, nonce, data, append(out[:headerLen:headerLen], ad...)), nil
+}
+
+// envelope is parsed ciphertext of version 2
+type envelope struct {
+	alg        Algorithm
+	header     []byte
+	keyID      []byte
+	nonce      []byte
+	ciphertext []byte
+}
+
+// parseEnvelope splits ciphertext of version 2 in parts, it returns false for other formats.
+func parseEnvelope(raw []byte) (*envelope, bool) {
+	if len(raw) < envHeaderLen || raw[0] != cipherMagic || raw[1] != cipherVersion2 {
+		return nil, false
+	}
+	alg := Algorithm(raw[2])
+	headerLen := envHeaderLen + int(raw[3])
+	nonceSize := alg.nonceSize()
+	if nonceSize == 0 || len(raw) < headerLen+nonceSize {
+		return nil, false
+	}
+	return &envelope{
+		alg:        alg,
+		header:     raw[:headerLen:headerLen],
+		keyID:      raw[envHeaderLen:headerLen],
+		nonce:      raw[headerLen : headerLen+nonceSize],
+		ciphertext: raw[headerLen+nonceSize:],
+	}, true
+}
+
+// open decrypts envelope with key and checks it is bound to associated data ad.
+func (env *envelope) open(key []byte, ad []byte) ([]byte, error) {
+	id, err := keyID(key)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(id, env.keyID) {
+		return nil, ErrWrongKey
+	}
+	aead, err := env.alg.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	out, err := aead.Open(nil, env.nonce, env.ciphertext, append(env.header, ad...))
+	if err != nil {
+		return nil, ErrTampered
+	}
+	return out, nil
+}
+
+// openV1 decrypts ciphertext of version 1 and checks it is bound to associated data ad.
+func openV1(key []byte, raw []byte, ad []byte) ([]byte, error) {
+	if len(raw) < cipherHeaderLen || raw[0] != cipherMagic || raw[1] != cipherVersion1 {
+		return nil, ErrTampered
+	}
+	cphr, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(cphr)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < cipherHeaderLen+gcm.NonceSize() {
+		return nil, ErrTampered
+	}
+	header := raw[:cipherHeaderLen:cipherHeaderLen]
+	nonce := raw[cipherHeaderLen : cipherHeaderLen+gcm.NonceSize()]
+	return gcm.Open(nil, nonce, raw[cipherHeaderLen+gcm.NonceSize():], append(header, ad...))
+}
+
+// openLegacy decrypts ciphertext without header
+func openLegacy(key []byte, raw []byte) ([]byte, error) {
+	cphr, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(cphr)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := gcm.NonceSize()
+	if len(raw) < nonceSize {
+		return nil, errors.New("len(encJSON) < nonceSize")
+	}
+	return gcm.Open(nil, raw[:nonceSize], raw[nonceSize:], nil)
+}
+
+// Outdated reports ciphertext isn't envelope of DefaultAlgorithm, it should be re-encrypted
+// when it is written next time.
+func Outdated(data string) bool {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return true
+	}
+	env, ok := parseEnvelope(raw)
+	return !ok || env.alg != DefaultAlgorithm
+}
@@ -0,0 +1,151 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sealV1 returns ciphertext of version 1 which was written by EncryptData before envelope
+func sealV1(t testing.TB, key []byte, data []byte, ad []byte) string {
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	out := make([]byte, cipherHeaderLen+gcm.NonceSize())
+	out[0], out[1] = cipherMagic, cipherVersion1
+	_, err = rand.Read(out[cipherHeaderLen:])
+	require.NoError(t, err)
+	out = gcm.Seal(out, out[cipherHeaderLen:], data, append(out[:cipherHeaderLen:cipherHeaderLen], ad...))
+	return base64.StdEncoding.EncodeToString(out)
+}
+
+func TestEnvelope(t *testing.T) {
+	key := []byte(`qwcsposfJOshf.34jswo_sdf`)
+	ad := AssociatedData("test", "key", "TEXT")
+	for _, alg := range []Algorithm{AlgAES256GCM, AlgXChaCha20Poly1305} {
+		t.Run(alg.String(), func(t *testing.T) {
+			raw, err := sealEnvelope(alg, key, []byte("12345"), ad)
+			require.NoError(t, err)
+			data := base64.StdEncoding.EncodeToString(raw)
+			out, err := DecryptData(key, data, ad)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("12345"), out)
+			assert.Equal(t, alg != DefaultAlgorithm, Outdated(data))
+
+			_, err = DecryptData([]byte(`other key of the same length`), data, ad)
+			assert.ErrorIs(t, err, ErrWrongKey)
+			_, err = DecryptData(key, data, AssociatedData("test", "key", "LOGIN"))
+			assert.ErrorIs(t, err, ErrTampered)
+			// algorithm id is authenticated
+			raw[2] = byte(AlgAES256GCM + AlgXChaCha20Poly1305 - alg)
+			_, err = DecryptData(key, base64.StdEncoding.EncodeToString(raw), ad)
+			assert.ErrorIs(t, err, ErrTampered)
+		})
+	}
+	t.Run("version 1", func(t *testing.T) {
+		data := sealV1(t, key, []byte("12345"), ad)
+		assert.True(t, Outdated(data))
+		out, err := DecryptData(key, data, ad)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("12345"), out)
+		_, err = DecryptData(key, data, AssociatedData("test", "key1", "TEXT"))
+		assert.ErrorIs(t, err, ErrTampered)
+	})
+	t.Run("legacy", func(t *testing.T) {
+		legacy := "aV6TS1ylt+Y0UrlimwY0lwqdZeZh1w5f1+wFOvY4eZPv"
+		assert.True(t, Outdated(legacy))
+		out, err := DecryptKey(key, legacy)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("12345"), out)
+	})
+	t.Run("legacy with header", func(t *testing.T) {
+		block, err := aes.NewCipher(key)
+		require.NoError(t, err)
+		gcm, err := cipher.NewGCM(block)
+		require.NoError(t, err)
+		// legacy ciphertext which nonce looks like header doesn't replace new one
+		for _, header := range [][]byte{
+			{cipherMagic, cipherVersion2, byte(DefaultAlgorithm), 0},
+			{cipherMagic, cipherVersion1},
+		} {
+			nonce := make([]byte, gcm.NonceSize())
+			copy(nonce, header)
+			raw := gcm.Seal(append([]byte{}, nonce...), nonce, []byte("12345"), nil)
+			data := base64.StdEncoding.EncodeToString(raw)
+			_, err = DecryptData(key, data, ad)
+			assert.Error(t, err)
+			if header[1] == cipherVersion2 {
+				_, err = DecryptKey(key, data)
+				assert.Error(t, err)
+			}
+		}
+	})
+	t.Run("short key", func(t *testing.T) {
+		_, err := EncryptData([]byte("short"), []byte("12345"), ad)
+		assert.Error(t, err)
+	})
+	t.Run("unknown algorithm", func(t *testing.T) {
+		_, err := sealEnvelope(Algorithm(9), key, []byte("12345"), ad)
+		assert.Error(t, err)
+		_, ok := parseEnvelope([]byte{cipherMagic, cipherVersion2, 9, 0, 1, 2, 3})
+		assert.False(t, ok)
+	})
+}
+
+func FuzzDecryptData(f *testing.F) {
+	key := []byte(`qwcsposfJOshf.34jswo_sdf`)
+	ad := AssociatedData("test", "key", "TEXT")
+	for _, alg := range []Algorithm{AlgAES256GCM, AlgXChaCha20Poly1305} {
+		raw, err := sealEnvelope(alg, key, []byte("12345"), ad)
+		require.NoError(f, err)
+		f.Add(raw)
+	}
+	v1, err := base64.StdEncoding.DecodeString(sealV1(f, key, []byte("12345"), ad))
+	require.NoError(f, err)
+	f.Add(v1)
+	f.Add([]byte{cipherMagic, cipherVersion2, byte(AlgXChaCha20Poly1305), 255})
+	f.Add([]byte{})
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		data := base64.StdEncoding.EncodeToString(raw)
+		// arbitrary input must not panic, decrypted data must be one of sealed seeds
+		out, err := DecryptData(key, data, ad)
+		if err == nil {
+			assert.Equal(t, []byte("12345"), out)
+		}
+		_, _ = DecryptKey(key, data)
+		_ = Outdated(data)
+	})
+}
+
+func TestSigningKey(t *testing.T) {
+	key, err := SigningKey([]byte("wfgxRxAwTILuvwpqD3JSgqnE"))
+	require.NoError(t, err)
+	again, err := SigningKey([]byte("wfgxRxAwTILuvwpqD3JSgqnE"))
+	require.NoError(t, err)
+	assert.Equal(t, key, again)
+	other, err := SigningKey([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	assert.NotEqual(t, key.Public(), other.Public())
+	_, err = SigningKey([]byte("short"))
+	assert.Error(t, err)
+}
+
+func FuzzEnvelopeRoundTrip(f *testing.F) {
+	f.Add([]byte(`qwcsposfJOshf.34jswo_sdf`), []byte("12345"), []byte("ad"), byte(AlgXChaCha20Poly1305))
+	f.Add([]byte(`0123456789abcdef`), []byte{}, []byte{}, byte(AlgAES256GCM))
+	f.Fuzz(func(t *testing.T, key []byte, data []byte, ad []byte, alg byte) {
+		raw, err := sealEnvelope(Algorithm(alg), key, data, ad)
+		if err != nil {
+			return
+		}
+		out, err := DecryptData(key, base64.StdEncoding.EncodeToString(raw), ad)
+		require.NoError(t, err)
+		assert.Equal(t, string(data), string(out))
+	})
+}
@@ -0,0 +1,161 @@
+/*
+Package journal contents hash chain of security journal of server.
+
+Each record keeps sha256 of previous record, so changed or removed record breaks the chain.
+Server periodically adds checkpoint record signed by ed25519 key derived from master key,
+checkpoint proves all records up to it were written by server which has master key.
+Public key of checkpoints is printed at server init, journal is verified only by it.
+*/
+package journal
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/types"
+)
+
+// Kinds of security events
+const (
+	KindInit           = "init"             // master key is split in shares
+	KindMasterKeyCheck = "master_key_check" // master key is checked against hash in db
+	KindUnseal         = "unseal"           // operator sent key share to unseal server
+	KindSeal           = "seal"             // operator sealed server
+	KindSignUp         = "signup"           // new user is created
+	KindLoginFailed    = "login_failed"     // login is rejected
+	KindLockout        = "lockout"          // login or client address is locked after failed logins
+	KindUnlock         = "unlock"           // admin removed lockout
+	KindCheckpoint     = "checkpoint"       // signed hash of chain
+)
+
+// Hash returns hex sha256 of record without hash and signature.
+func Hash(e types.JournalEntry) string {
+	e.Hash, e.Sig = "", ""
+	data, _ := json.Marshal(e) // struct of strings and ints is always marshaled
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// link fills number, previous hash and hash of record e which follows prev, prev is nil for
+// the first record.
+func link(prev *types.JournalEntry, e *types.JournalEntry) {
+	e.Seq, e.Prev = 1, ""
+	if prev != nil {
+		e.Seq, e.Prev = prev.Seq+1, prev.Hash
+	}
+	e.Hash = Hash(*e)
+}
+
+// Event returns func which makes record of event after last record of journal,
+// it is passed to storage AppendJournal.
+func Event(kind string, login string, detail string) func(*types.JournalEntry) (*types.JournalEntry, error) {
+	return func(prev *types.JournalEntry) (*types.JournalEntry, error) {
+		e := types.JournalEntry{Kind: kind, Login: login, Detail: detail, Time: time.Now().UnixMilli()}
+		link(prev, &e)
+		return &e, nil
+	}
+}
+
+// Checkpoint returns func which makes checkpoint signed by key after last record of journal.
+// It returns nil record when journal is empty or already ends with checkpoint.
+func Checkpoint(key ed25519.PrivateKey) func(*types.JournalEntry) (*types.JournalEntry, error) {
+	return func(prev *types.JournalEntry) (*types.JournalEntry, error) {
+		if prev == nil || prev.Kind == KindCheckpoint {
+			return nil, nil
+		}
+		e := types.JournalEntry{Kind: KindCheckpoint, Time: time.Now().UnixMilli()}
+		link(prev, &e)
+		e.Sig = base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(e.Hash)))
+		return &e, nil
+	}
+}
+
+// Report is result of journal verification.
+type Report struct {
+	Entries     int64    // count of checked records
+	Last        int64    // number of last record
+	Checkpoints int64    // count of checkpoints with valid signature
+	Signed      int64    // number of last checkpoint with valid signature
+	Unsigned    int64    // count of records after last checkpoint with valid signature, next checkpoint signs them
+	Problems    []string // found gaps, modifications, wrong signatures and mismatch of head
+}
+
+// Verifier checks records of journal in order of numbers.
+type Verifier struct {
+	pub    ed25519.PublicKey
+	prev   *types.JournalEntry
+	report Report
+}
+
+// NewVerifier returns verifier of journal which checks signatures of checkpoints by pub.
+func NewVerifier(pub ed25519.PublicKey) *Verifier {
+	return &Verifier{pub: pub}
+}
+
+// Add checks next record of journal.
+func (v *Verifier) Add(e types.JournalEntry) {
+	v.report.Entries++
+	want, prevHash := int64(1), ""
+	if v.prev != nil {
+		want, prevHash = v.prev.Seq+1, v.prev.Hash
+	}
+	switch {
+	case e.Seq > want:
+		v.problem("records %d-%d are missing", want, e.Seq-1)
+	case e.Seq < want:
+		v.problem("record %d is duplicated or out of order", e.Seq)
+	case e.Prev != prevHash:
+		v.problem("record %d doesn't follow record %d", e.Seq, e.Seq-1)
+	}
+	if Hash(e) != e.Hash {
+		v.problem("record %d is modified", e.Seq)
+	}
+	if e.Kind == KindCheckpoint {
+		sig, err := base64.StdEncoding.DecodeString(e.Sig)
+		if err != nil || !ed25519.Verify(v.pub, []byte(e.Hash), sig) {
+			v.problem("checkpoint %d has wrong signature", e.Seq)
+		} else {
+			v.report.Checkpoints++
+			v.report.Signed = e.Seq
+		}
+	}
+	if e.Seq >= want {
+		v.prev = &e
+		v.report.Last = e.Seq
+	}
+}
+
+// Head checks that head of journal, which next record follows, is the last checked record,
+// head is empty when journal is empty. Otherwise records after the last one were removed.
+func (v *Verifier) Head(head types.JournalEntry) {
+	switch {
+	case v.prev == nil && head.Seq != 0:
+		v.problem("head of journal is record %d, but journal is empty", head.Seq)
+	case v.prev != nil && head.Seq == 0:
+		v.problem("head of journal is missing")
+	case v.prev != nil && (head.Seq != v.prev.Seq || head.Hash != v.prev.Hash):
+		v.problem("head of journal is record %d, but last record is %d", head.Seq, v.prev.Seq)
+	}
+}
+
+// Report returns result of checked records. Records after the last checkpoint aren't a problem,
+// checkpoints are periodic, but they are counted as unsigned. Empty journal is a problem, init
+// always writes the first record.
+func (v *Verifier) Report() Report {
+	report := v.report
+	report.Problems = append([]string(nil), v.report.Problems...)
+	report.Unsigned = report.Last - report.Signed
+	if report.Entries == 0 {
+		report.Problems = append(report.Problems, "journal is empty")
+	}
+	return report
+}
+
+func (v *Verifier) problem(format string, args ...any) {
+	v.report.Problems = append(v.report.Problems, fmt.Sprintf(format, args...))
+}
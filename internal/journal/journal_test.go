@@ -0,0 +1,129 @@
+package journal
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chain returns journal of count events with checkpoint at the end
+func chain(t *testing.T, key ed25519.PrivateKey, count int) []types.JournalEntry {
+	var out []types.JournalEntry
+	var prev *types.JournalEntry
+	for i := 0; i < count; i++ {
+		e, err := Event(KindLoginFailed, "test", "wrong password")(prev)
+		require.NoError(t, err)
+		out = append(out, *e)
+		prev = e
+	}
+	e, err := Checkpoint(key)(prev)
+	require.NoError(t, err)
+	return append(out, *e)
+}
+
+func verify(pub ed25519.PublicKey, entries []types.JournalEntry) Report {
+	v := NewVerifier(pub)
+	for _, e := range entries {
+		v.Add(e)
+	}
+	return v.Report()
+}
+
+func TestVerifier(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	t.Run("valid", func(t *testing.T) {
+		entries := chain(t, key, 3)
+		assert.Equal(t, int64(1), entries[0].Seq)
+		assert.Empty(t, entries[0].Prev)
+		assert.Equal(t, entries[2].Hash, entries[3].Prev)
+		assert.Equal(t, Report{Entries: 4, Last: 4, Checkpoints: 1, Signed: 4}, verify(pub, entries))
+	})
+	t.Run("modified", func(t *testing.T) {
+		entries := chain(t, key, 3)
+		entries[1].Login = "other"
+		assert.Equal(t, []string{"record 2 is modified"}, verify(pub, entries).Problems)
+	})
+	t.Run("rehashed", func(t *testing.T) {
+		entries := chain(t, key, 3)
+		entries[1].Detail = "ok"
+		entries[1].Hash = Hash(entries[1])
+		assert.Equal(t, []string{"record 3 doesn't follow record 2"}, verify(pub, entries).Problems)
+	})
+	t.Run("removed", func(t *testing.T) {
+		entries := chain(t, key, 4)
+		entries = append(entries[:1], entries[3:]...)
+		report := verify(pub, entries)
+		assert.Equal(t, []string{"records 2-3 are missing"}, report.Problems)
+		assert.Equal(t, int64(5), report.Last)
+	})
+	t.Run("duplicated", func(t *testing.T) {
+		entries := chain(t, key, 2)
+		entries = append(entries[:2], entries[1:]...)
+		assert.Equal(t, []string{"record 2 is duplicated or out of order"}, verify(pub, entries).Problems)
+	})
+	t.Run("wrong signature", func(t *testing.T) {
+		_, other, err := ed25519.GenerateKey(nil)
+		require.NoError(t, err)
+		entries := chain(t, other, 1)
+		report := verify(pub, entries)
+		assert.Equal(t, []string{"checkpoint 2 has wrong signature"}, report.Problems)
+		assert.Zero(t, report.Checkpoints)
+	})
+	t.Run("unsigned records", func(t *testing.T) {
+		entries := chain(t, key, 1)
+		for i := 0; i < 2; i++ {
+			e, err := Event(KindSignUp, "test", "")(&entries[len(entries)-1])
+			require.NoError(t, err)
+			entries = append(entries, *e)
+		}
+		// valid chain isn't broken before next checkpoint
+		assert.Equal(t, Report{Entries: 4, Last: 4, Checkpoints: 1, Signed: 2, Unsigned: 2}, verify(pub, entries))
+	})
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, []string{"journal is empty"}, verify(pub, nil).Problems)
+	})
+}
+
+func TestVerifier_Head(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	entries := chain(t, key, 3)
+	tests := []struct {
+		name    string
+		entries []types.JournalEntry
+		head    types.JournalEntry
+		want    []string
+	}{
+		{"last record", entries, entries[3], nil},
+		{"removed tail", entries[:3], entries[3], []string{"head of journal is record 4, but last record is 3"}},
+		{"missing head", entries, types.JournalEntry{}, []string{"head of journal is missing"}},
+		{"removed journal", nil, entries[3], []string{"head of journal is record 4, but journal is empty", "journal is empty"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier(pub)
+			for _, e := range tt.entries {
+				v.Add(e)
+			}
+			v.Head(tt.head)
+			assert.Equal(t, tt.want, v.Report().Problems)
+		})
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	e, err := Checkpoint(key)(nil)
+	require.NoError(t, err)
+	assert.Nil(t, e)
+	entries := chain(t, key, 1)
+	e, err = Checkpoint(key)(&entries[1])
+	require.NoError(t, err)
+	assert.Nil(t, e)
+}
@@ -0,0 +1,107 @@
+/*
+Package kek contents providers of key encryption key, they wrap and unwrap user symmetric keys.
+
+Keys wrapped by master key or key file are plain base64 of AES-GCM ciphertext, like keys
+wrapped before providers. Other providers prefix wrapped keys by their name, so key wrapped
+by one provider isn't passed to another one.
+*/
+package kek
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+)
+
+// Names of providers
+const (
+	ProviderMaster     = "master"     // server master key, default
+	ProviderFile       = "file"       // key from local file
+	ProviderPassphrase = "passphrase" // key derived from passphrase
+	ProviderKMS        = "kms"        // external KMS-style service
+)
+
+var (
+	// ErrNoKey returns when key encryption key isn't available, e.g. server is sealed.
+	ErrNoKey = errors.New("key encryption key isn't available")
+	// ErrFormat returns when wrapped key belongs to another provider.
+	ErrFormat = errors.New("wrapped key belongs to another key encryption key provider")
+)
+
+// Provider wraps and unwraps user keys with key encryption key.
+type Provider interface {
+	// Name returns name of provider, it is one of Provider* constants.
+	Name() string
+	// Wrap encrypts user key, result is kept in storage.
+	Wrap(ctx context.Context, key []byte) (string, error)
+	// Unwrap decrypts user key wrapped by Wrap.
+	Unwrap(ctx context.Context, wrapped string) ([]byte, error)
+}
+
+// Kind returns name of provider which wrapped key.
+func Kind(wrapped string) string {
+	for _, name := range []string{ProviderPassphrase, ProviderKMS} {
+		if strings.HasPrefix(wrapped, name+":") {
+			return name
+		}
+	}
+	return ProviderMaster
+}
+
+// MasterKey wraps keys with AES-GCM by static key.
+type MasterKey struct {
+	name string
+	key  func() []byte
+}
+
+// NewMasterKey returns provider of server master key, key returns empty key while server is sealed.
+func NewMasterKey(key func() []byte) *MasterKey {
+	return &MasterKey{name: ProviderMaster, key: key}
+}
+
+// NewKeyFile returns provider of key read from local file, trailing newline is ignored.
+// Key length must be 16, 24 or 32 bytes.
+func NewKeyFile(path string) (*MasterKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("kek file: %w", err)
+	}
+	key := bytes.TrimRight(data, "\r\n")
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("kek file: key length must be 16, 24 or 32 bytes, got %d", len(key))
+	}
+	return &MasterKey{name: ProviderFile, key: func() []byte { return key }}, nil
+}
+
+// Name implements Provider
+func (mk *MasterKey) Name() string {
+	return mk.name
+}
+
+// Wrap implements Provider
+func (mk *MasterKey) Wrap(_ context.Context, key []byte) (string, error) {
+	kek := mk.key()
+	if len(kek) == 0 {
+		return "", ErrNoKey
+	}
+	return crypto.EncryptKey(kek, key)
+}
+
+// Unwrap implements Provider
+func (mk *MasterKey) Unwrap(_ context.Context, wrapped string) ([]byte, error) {
+	if Kind(wrapped) != ProviderMaster {
+		return nil, ErrFormat
+	}
+	kek := mk.key()
+	if len(kek) == 0 {
+		return nil, ErrNoKey
+	}
+	return crypto.DecryptKey(kek, wrapped)
+}
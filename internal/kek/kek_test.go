@@ -0,0 +1,144 @@
+package kek
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var userKey = []byte("0123456789abcdefghijklmn")
+
+func TestKind(t *testing.T) {
+	assert.Equal(t, ProviderMaster, Kind("c29tZQ=="))
+	assert.Equal(t, ProviderPassphrase, Kind("passphrase:salt:data"))
+	assert.Equal(t, ProviderKMS, Kind("kms:data"))
+}
+
+func TestMasterKey(t *testing.T) {
+	ctx := context.Background()
+	var key []byte
+	mk := NewMasterKey(func() []byte { return key })
+	t.Run("sealed", func(t *testing.T) {
+		_, err := mk.Wrap(ctx, userKey)
+		assert.ErrorIs(t, err, ErrNoKey)
+	})
+	key = []byte("wfgxRxAwTILuvwpqD3JSgqnE")
+	t.Run("legacy", func(t *testing.T) {
+		// keys wrapped before providers
+		wrapped, err := crypto.EncryptKey(key, userKey)
+		require.NoError(t, err)
+		out, err := mk.Unwrap(ctx, wrapped)
+		require.NoError(t, err)
+		assert.Equal(t, userKey, out)
+	})
+	t.Run("round trip", func(t *testing.T) {
+		wrapped, err := mk.Wrap(ctx, userKey)
+		require.NoError(t, err)
+		out, err := crypto.DecryptKey(key, wrapped)
+		require.NoError(t, err)
+		assert.Equal(t, userKey, out)
+	})
+	t.Run("other format", func(t *testing.T) {
+		_, err := mk.Unwrap(ctx, "kms:data")
+		assert.ErrorIs(t, err, ErrFormat)
+	})
+}
+
+func TestNewKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kek")
+	require.NoError(t, os.WriteFile(path, []byte("0123456789abcdef0123456789abcdef\n"), 0o600))
+	kf, err := NewKeyFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, ProviderFile, kf.Name())
+	wrapped, err := kf.Wrap(context.Background(), userKey)
+	require.NoError(t, err)
+	out, err := kf.Unwrap(context.Background(), wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, userKey, out)
+
+	require.NoError(t, os.WriteFile(path, []byte("short"), 0o600))
+	_, err = NewKeyFile(path)
+	assert.Error(t, err)
+	_, err = NewKeyFile(filepath.Join(dir, "none"))
+	assert.Error(t, err)
+}
+
+func TestPassphrase(t *testing.T) {
+	ctx := context.Background()
+	pp, err := NewPassphrase([]byte("correct horse battery staple"))
+	require.NoError(t, err)
+	wrapped, err := pp.Wrap(ctx, userKey)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(wrapped, "passphrase:"))
+
+	// after restart salt of new keys changes, old keys are still unwrapped
+	restarted, err := NewPassphrase([]byte("correct horse battery staple"))
+	require.NoError(t, err)
+	out, err := restarted.Unwrap(ctx, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, userKey, out)
+
+	other, err := NewPassphrase([]byte("other"))
+	require.NoError(t, err)
+	_, err = other.Unwrap(ctx, wrapped)
+	assert.Error(t, err)
+
+	_, err = pp.Unwrap(ctx, "passphrase:bad")
+	assert.ErrorIs(t, err, ErrFormat)
+	_, err = pp.Unwrap(ctx, "c29tZQ==")
+	assert.ErrorIs(t, err, ErrFormat)
+	_, err = NewPassphrase(nil)
+	assert.Error(t, err)
+}
+
+func TestKMS(t *testing.T) {
+	stub, err := NewKMSStub([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	ctx := context.Background()
+	roundTrip := func(t *testing.T, kms *KMS) {
+		wrapped, err := kms.Wrap(ctx, userKey)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(wrapped, "kms:"))
+		out, err := kms.Unwrap(ctx, wrapped)
+		require.NoError(t, err)
+		assert.Equal(t, userKey, out)
+		_, err = kms.Unwrap(ctx, "kms:broken")
+		assert.Error(t, err)
+		_, err = kms.Unwrap(ctx, "c29tZQ==")
+		assert.ErrorIs(t, err, ErrFormat)
+	}
+	t.Run("http", func(t *testing.T) {
+		srv := httptest.NewServer(stub)
+		defer srv.Close()
+		kms, err := NewKMS(srv.URL)
+		require.NoError(t, err)
+		roundTrip(t, kms)
+	})
+	t.Run("unix", func(t *testing.T) {
+		sock := filepath.Join(t.TempDir(), "kms.sock")
+		listen, err := net.Listen("unix", sock)
+		require.NoError(t, err)
+		srv := &http.Server{Handler: stub}
+		go func() { _ = srv.Serve(listen) }()
+		defer srv.Close()
+		kms, err := NewKMS("unix://" + sock)
+		require.NoError(t, err)
+		roundTrip(t, kms)
+	})
+	t.Run("wrong address", func(t *testing.T) {
+		_, err := NewKMS("ftp://kms")
+		assert.Error(t, err)
+		_, err = NewKMS("unix://")
+		assert.Error(t, err)
+	})
+}
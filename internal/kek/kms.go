@@ -0,0 +1,174 @@
+package kek
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+)
+
+// KMS protocol: POST /v1/wrap with {"plaintext": base64} returns {"ciphertext": string},
+// POST /v1/unwrap with {"ciphertext": string} returns {"plaintext": base64}.
+// Errors are returned with non 200 status and {"error": string}.
+const (
+	kmsWrapPath   = "/v1/wrap"
+	kmsUnwrapPath = "/v1/unwrap"
+	kmsTimeout    = 10 * time.Second
+	kmsMaxBody    = 64 * 1024
+)
+
+type kmsMessage struct {
+	Plaintext  []byte `json:"plaintext,omitempty"`
+	Ciphertext string `json:"ciphertext,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// KMS wraps keys by external KMS-style service over HTTP or unix socket.
+// Ciphertext of service is kept as "kms:<ciphertext>".
+type KMS struct {
+	base   string
+	client *http.Client
+}
+
+// NewKMS returns provider of KMS service at addr: "http://host:port", "https://host:port"
+// or "unix:///path/to/socket".
+func NewKMS(addr string) (*KMS, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("kms address: %w", err)
+	}
+	kms := KMS{client: &http.Client{Timeout: kmsTimeout}}
+	switch u.Scheme {
+	case "http", "https":
+		kms.base = strings.TrimRight(addr, "/")
+	case "unix":
+		if u.Path == "" {
+			return nil, errors.New("kms address: empty socket path")
+		}
+		dialer := net.Dialer{}
+		kms.client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", u.Path)
+			},
+		}
+		kms.base = "http://kms"
+	default:
+		return nil, fmt.Errorf("kms address: unsupported scheme %q", u.Scheme)
+	}
+	return &kms, nil
+}
+
+// Name implements Provider
+func (kms *KMS) Name() string {
+	return ProviderKMS
+}
+
+// Wrap implements Provider
+func (kms *KMS) Wrap(ctx context.Context, key []byte) (string, error) {
+	resp, err := kms.call(ctx, kmsWrapPath, kmsMessage{Plaintext: key})
+	if err != nil {
+		return "", err
+	}
+	if resp.Ciphertext == "" {
+		return "", errors.New("kms: empty ciphertext")
+	}
+	return ProviderKMS + ":" + resp.Ciphertext, nil
+}
+
+// Unwrap implements Provider
+func (kms *KMS) Unwrap(ctx context.Context, wrapped string) ([]byte, error) {
+	if Kind(wrapped) != ProviderKMS {
+		return nil, ErrFormat
+	}
+	resp, err := kms.call(ctx, kmsUnwrapPath, kmsMessage{Ciphertext: strings.TrimPrefix(wrapped, ProviderKMS+":")})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
+
+func (kms *KMS) call(ctx context.Context, path string, msg kmsMessage) (*kmsMessage, error) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, kms.base+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := kms.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("kms: %w", err)
+	}
+	defer resp.Body.Close()
+	out := kmsMessage{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, kmsMaxBody)).Decode(&out); err != nil {
+		return nil, fmt.Errorf("kms: %s: %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("kms: %s: %s", resp.Status, out.Error)
+	}
+	return &out, nil
+}
+
+// KMSStub is stand-in of KMS service for tests and local runs, it wraps keys by AES-GCM with its own key.
+type KMSStub struct {
+	key []byte
+}
+
+// NewKMSStub returns KMS stand-in with key of 16, 24 or 32 bytes.
+func NewKMSStub(key []byte) (*KMSStub, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("kms stub: key length must be 16, 24 or 32 bytes, got %d", len(key))
+	}
+	return &KMSStub{key: key}, nil
+}
+
+// ServeHTTP implements http.Handler with KMS protocol
+func (ks *KMSStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeKMS(w, http.StatusMethodNotAllowed, kmsMessage{Error: "method not allowed"})
+		return
+	}
+	msg := kmsMessage{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, kmsMaxBody)).Decode(&msg); err != nil {
+		writeKMS(w, http.StatusBadRequest, kmsMessage{Error: "wrong request"})
+		return
+	}
+	switch r.URL.Path {
+	case kmsWrapPath:
+		out, err := crypto.EncryptKey(ks.key, msg.Plaintext)
+		if err != nil {
+			writeKMS(w, http.StatusInternalServerError, kmsMessage{Error: "wrap failed"})
+			return
+		}
+		writeKMS(w, http.StatusOK, kmsMessage{Ciphertext: out})
+	case kmsUnwrapPath:
+		out, err := crypto.DecryptKey(ks.key, msg.Ciphertext)
+		if err != nil {
+			writeKMS(w, http.StatusBadRequest, kmsMessage{Error: "unwrap failed"})
+			return
+		}
+		writeKMS(w, http.StatusOK, kmsMessage{Plaintext: out})
+	default:
+		writeKMS(w, http.StatusNotFound, kmsMessage{Error: "not found"})
+	}
+}
+
+func writeKMS(w http.ResponseWriter, code int, msg kmsMessage) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(msg)
+}
@@ -0,0 +1,94 @@
+package kek
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2id parameters of passphrase key derivation
+const (
+	saltLength   = 16
+	argonTime    = 1
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	argonKeyLen  = 32
+)
+
+// Passphrase wraps keys with key derived from passphrase by argon2id.
+// Salt is kept in every wrapped key: "passphrase:<salt>:<ciphertext>". New keys are wrapped
+// with salt generated at start, keys derived for other salts are cached.
+type Passphrase struct {
+	pass []byte
+	salt string
+	mu   sync.Mutex
+	keys map[string][]byte // derived keys by base64 salt
+}
+
+// NewPassphrase returns provider of key derived from passphrase.
+func NewPassphrase(pass []byte) (*Passphrase, error) {
+	if len(pass) == 0 {
+		return nil, errors.New("kek passphrase is empty")
+	}
+	salt, err := crypto.GenSymmKey(saltLength)
+	if err != nil {
+		return nil, err
+	}
+	return &Passphrase{
+		pass: pass,
+		salt: base64.RawStdEncoding.EncodeToString(salt),
+		keys: make(map[string][]byte),
+	}, nil
+}
+
+// Name implements Provider
+func (pp *Passphrase) Name() string {
+	return ProviderPassphrase
+}
+
+// deriveKey returns key for base64 salt
+func (pp *Passphrase) deriveKey(salt string) ([]byte, error) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	if key, ok := pp.keys[salt]; ok {
+		return key, nil
+	}
+	rawSalt, err := base64.RawStdEncoding.DecodeString(salt)
+	if err != nil || len(rawSalt) != saltLength {
+		return nil, ErrFormat
+	}
+	key := argon2.IDKey(pp.pass, rawSalt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	pp.keys[salt] = key
+	return key, nil
+}
+
+// Wrap implements Provider
+func (pp *Passphrase) Wrap(_ context.Context, key []byte) (string, error) {
+	kek, err := pp.deriveKey(pp.salt)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := crypto.EncryptKey(kek, key)
+	if err != nil {
+		return "", err
+	}
+	return ProviderPassphrase + ":" + pp.salt + ":" + wrapped, nil
+}
+
+// Unwrap implements Provider
+func (pp *Passphrase) Unwrap(_ context.Context, wrapped string) ([]byte, error) {
+	salt, data, ok := strings.Cut(strings.TrimPrefix(wrapped, ProviderPassphrase+":"), ":")
+	if Kind(wrapped) != ProviderPassphrase || !ok {
+		return nil, ErrFormat
+	}
+	kek, err := pp.deriveKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.DecryptKey(kek, data)
+}
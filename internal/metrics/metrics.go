@@ -0,0 +1,195 @@
+/*
+Package metrics contents Prometheus metrics of KeepPas server.
+
+Interceptors count rpc calls and their latencies by method and status code, storage wrapper
+measures latencies of storage methods. Active sessions and statistics of Redis connection pool
+are read from storage on each scrape.
+*/
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	namespace      = "keeppas"
+	collectTimeout = 5 * time.Second // max time of reading storage stats on scrape
+)
+
+// Results of login attempts
+const (
+	LoginSuccess = "success"
+	LoginFailure = "failure"
+	LoginLocked  = "locked" // rejected because of lockout
+)
+
+// RedisStats is storage which reports its sessions and connection pool.
+type RedisStats interface {
+	CountSessions(context.Context) (int64, error)
+	PoolStats() *redis.PoolStats
+}
+
+// Metrics keeps metrics of server, methods of nil Metrics do nothing.
+type Metrics struct {
+	registry        *prometheus.Registry
+	rpcs            *prometheus.CounterVec
+	rpcDuration     *prometheus.HistogramVec
+	logins          *prometheus.CounterVec
+	storageDuration *prometheus.HistogramVec
+	storageErrors   *prometheus.CounterVec
+}
+
+// New returns metrics registered in own registry with go runtime and process metrics.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		rpcs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rpc_requests_total",
+			Help:      "Count of handled rpc calls by method and status code.",
+		}, []string{"method", "code"}),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rpc_duration_seconds",
+			Help:      "Latency of rpc calls by method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Count of login attempts by result: success, failure or locked.",
+		}, []string{"result"}),
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "storage_duration_seconds",
+			Help:      "Latency of storage operations by method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		storageErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "storage_errors_total",
+			Help:      "Count of failed storage operations by method.",
+		}, []string{"method"}),
+	}
+	m.registry.MustRegister(
+		m.rpcs, m.rpcDuration, m.logins, m.storageDuration, m.storageErrors,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// RegisterRedis adds active sessions and connection pool stats of stor to metrics.
+func (m *Metrics) RegisterRedis(stor RedisStats) error {
+	if m == nil {
+		return nil
+	}
+	return m.registry.Register(newRedisCollector(stor))
+}
+
+// Handler returns http handler of metrics in Prometheus text format. Failed metric doesn't
+// break the whole scrape, other metrics are still returned.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+}
+
+// ObserveLogin counts login attempt with result LoginSuccess, LoginFailure or LoginLocked.
+func (m *Metrics) ObserveLogin(result string) {
+	if m == nil {
+		return
+	}
+	m.logins.WithLabelValues(result).Inc()
+}
+
+// observeStorage records latency of storage method started at start.
+func (m *Metrics) observeStorage(method string, start time.Time, err error) {
+	m.storageDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.storageErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// observeRPC records result and latency of rpc call started at start.
+func (m *Metrics) observeRPC(method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	m.rpcs.WithLabelValues(method, code).Inc()
+	m.rpcDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
+
+// UnaryInterceptor measures unary rpc calls, it goes first in chain to count rejected calls too.
+func (m *Metrics) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	m.observeRPC(info.FullMethod, start, err)
+	return resp, err
+}
+
+// StreamInterceptor measures stream rpc calls, latency is the whole live time of stream.
+func (m *Metrics) StreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	m.observeRPC(info.FullMethod, start, err)
+	return err
+}
+
+// redisCollector reads active sessions and pool stats from storage on each scrape
+type redisCollector struct {
+	stor      RedisStats
+	sessions  *prometheus.Desc
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	timeouts  *prometheus.Desc
+	totalConn *prometheus.Desc
+	idleConn  *prometheus.Desc
+	staleConn *prometheus.Desc
+}
+
+func newRedisCollector(stor RedisStats) *redisCollector {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, nil, nil)
+	}
+	return &redisCollector{
+		stor:      stor,
+		sessions:  desc("sessions_active", "Count of active user sessions."),
+		hits:      desc("redis_pool_hits_total", "Count of times free connection was found in the pool."),
+		misses:    desc("redis_pool_misses_total", "Count of times free connection was not found in the pool."),
+		timeouts:  desc("redis_pool_timeouts_total", "Count of times a wait timeout occurred."),
+		totalConn: desc("redis_pool_connections", "Count of connections in the pool."),
+		idleConn:  desc("redis_pool_idle_connections", "Count of idle connections in the pool."),
+		staleConn: desc("redis_pool_stale_connections_total", "Count of stale connections removed from the pool."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *redisCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.sessions, c.hits, c.misses, c.timeouts, c.totalConn, c.idleConn, c.staleConn} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *redisCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+	if count, err := c.stor.CountSessions(ctx); err != nil {
+		ch <- prometheus.NewInvalidMetric(c.sessions, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(c.sessions, prometheus.GaugeValue, float64(count))
+	}
+	stats := c.stor.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.totalConn, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConn, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConn, prometheus.CounterValue, float64(stats.StaleConns))
+}
@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/storage"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeStats reports fixed sessions and pool stats
+type fakeStats struct {
+	sessions int64
+	err      error
+}
+
+func (f fakeStats) CountSessions(context.Context) (int64, error) {
+	return f.sessions, f.err
+}
+
+func (f fakeStats) PoolStats() *redis.PoolStats {
+	return &redis.PoolStats{Hits: 5, Misses: 2, TotalConns: 3, IdleConns: 1}
+}
+
+// fakeStorage implements only Get, other methods panic
+type fakeStorage struct {
+	storage.Storage
+	err error
+}
+
+func (f fakeStorage) Get(context.Context, string, *types.StorageModel) error {
+	return f.err
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_UnaryInterceptor(t *testing.T) {
+	m := New()
+	info := &grpc.UnaryServerInfo{FullMethod: "/gokeepas.KeepPas/Get"}
+	_, err := m.UnaryInterceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "key doesn't exists")
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = m.UnaryInterceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.rpcs.WithLabelValues("/gokeepas.KeepPas/Get", "NotFound")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.rpcs.WithLabelValues("/gokeepas.KeepPas/Get", "OK")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.rpcDuration))
+}
+
+func TestMetrics_StreamInterceptor(t *testing.T) {
+	m := New()
+	info := &grpc.StreamServerInfo{FullMethod: "/gokeepas.KeepPas/Watch"}
+	err := m.StreamInterceptor(nil, nil, info, func(srv any, ss grpc.ServerStream) error {
+		return status.Error(codes.Unauthenticated, "wrong token")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.rpcs.WithLabelValues("/gokeepas.KeepPas/Watch", "Unauthenticated")))
+}
+
+func TestMetrics_ObserveLogin(t *testing.T) {
+	var empty *Metrics
+	assert.NotPanics(t, func() { empty.ObserveLogin(LoginSuccess) })
+	m := New()
+	m.ObserveLogin(LoginSuccess)
+	m.ObserveLogin(LoginFailure)
+	m.ObserveLogin(LoginFailure)
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.logins.WithLabelValues(LoginSuccess)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.logins.WithLabelValues(LoginFailure)))
+}
+
+func TestStorage(t *testing.T) {
+	stor := fakeStorage{err: errors.New("broken")}
+	assert.Equal(t, storage.Storage(stor), Storage(nil, stor))
+
+	m := New()
+	wrapped := Storage(m, stor)
+	assert.Error(t, wrapped.Get(context.Background(), "key", &types.StorageModel{}))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.storageDuration))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.storageErrors.WithLabelValues("Get")))
+}
+
+func TestMetrics_RegisterRedis(t *testing.T) {
+	t.Run("right", func(t *testing.T) {
+		m := New()
+		require.NoError(t, m.RegisterRedis(fakeStats{sessions: 3}))
+		body := scrape(t, m)
+		assert.Contains(t, body, "keeppas_sessions_active 3")
+		assert.Contains(t, body, "keeppas_redis_pool_hits_total 5")
+		assert.Contains(t, body, "keeppas_redis_pool_connections 3")
+	})
+	t.Run("sessions error", func(t *testing.T) {
+		m := New()
+		require.NoError(t, m.RegisterRedis(fakeStats{err: errors.New("broken")}))
+		body := scrape(t, m)
+		assert.NotContains(t, body, "keeppas_sessions_active")
+		assert.Contains(t, body, "keeppas_redis_pool_misses_total 2")
+	})
+}
@@ -0,0 +1,370 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/storage"
+	"github.com/hrapovd1/gokeepas/internal/types"
+)
+
+// instrumentedStorage measures latency of each method of wrapped storage
+type instrumentedStorage struct {
+	storage.Storage
+	m *Metrics
+}
+
+// Storage returns stor which records latencies of its methods in m, stor is returned as is
+// when m is nil.
+func Storage(m *Metrics, stor storage.Storage) storage.Storage {
+	if m == nil {
+		return stor
+	}
+	return instrumentedStorage{Storage: stor, m: m}
+}
+
+// firstErr returns first error of batch operation
+func firstErr(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s instrumentedStorage) Add(ctx context.Context, key string, data *types.StorageModel) error {
+	start := time.Now()
+	err := s.Storage.Add(ctx, key, data)
+	s.m.observeStorage("Add", start, err)
+	return err
+}
+
+func (s instrumentedStorage) Get(ctx context.Context, key string, data *types.StorageModel) error {
+	start := time.Now()
+	err := s.Storage.Get(ctx, key, data)
+	s.m.observeStorage("Get", start, err)
+	return err
+}
+
+func (s instrumentedStorage) Remove(ctx context.Context, key string) error {
+	start := time.Now()
+	err := s.Storage.Remove(ctx, key)
+	s.m.observeStorage("Remove", start, err)
+	return err
+}
+
+func (s instrumentedStorage) Update(ctx context.Context, key string, data *types.StorageModel) error {
+	start := time.Now()
+	err := s.Storage.Update(ctx, key, data)
+	s.m.observeStorage("Update", start, err)
+	return err
+}
+
+func (s instrumentedStorage) Copy(ctx context.Context, src string, dst string) error {
+	start := time.Now()
+	err := s.Storage.Copy(ctx, src, dst)
+	s.m.observeStorage("Copy", start, err)
+	return err
+}
+
+func (s instrumentedStorage) GetMany(ctx context.Context, keys []string) ([]types.StorageModel, []error) {
+	start := time.Now()
+	data, errs := s.Storage.GetMany(ctx, keys)
+	s.m.observeStorage("GetMany", start, firstErr(errs))
+	return data, errs
+}
+
+func (s instrumentedStorage) AddMany(ctx context.Context, keys []string, data []types.StorageModel) []error {
+	start := time.Now()
+	errs := s.Storage.AddMany(ctx, keys, data)
+	s.m.observeStorage("AddMany", start, firstErr(errs))
+	return errs
+}
+
+func (s instrumentedStorage) RemoveMany(ctx context.Context, keys []string) []error {
+	start := time.Now()
+	errs := s.Storage.RemoveMany(ctx, keys)
+	s.m.observeStorage("RemoveMany", start, firstErr(errs))
+	return errs
+}
+
+func (s instrumentedStorage) PublishEvent(ctx context.Context, prefix string, ev *types.Event) error {
+	start := time.Now()
+	err := s.Storage.PublishEvent(ctx, prefix, ev)
+	s.m.observeStorage("PublishEvent", start, err)
+	return err
+}
+
+func (s instrumentedStorage) AppendChange(ctx context.Context, prefix string, ev *types.Event) error {
+	start := time.Now()
+	err := s.Storage.AppendChange(ctx, prefix, ev)
+	s.m.observeStorage("AppendChange", start, err)
+	return err
+}
+
+func (s instrumentedStorage) MarkChangeGap(ctx context.Context, prefix string) error {
+	start := time.Now()
+	err := s.Storage.MarkChangeGap(ctx, prefix)
+	s.m.observeStorage("MarkChangeGap", start, err)
+	return err
+}
+
+func (s instrumentedStorage) ListChanges(ctx context.Context, prefix string, since int64, limit int) (*types.Changes, error) {
+	start := time.Now()
+	res, err := s.Storage.ListChanges(ctx, prefix, since, limit)
+	s.m.observeStorage("ListChanges", start, err)
+	return res, err
+}
+
+func (s instrumentedStorage) AppendAudit(ctx context.Context, login string, ev *types.AuditEvent) error {
+	start := time.Now()
+	err := s.Storage.AppendAudit(ctx, login, ev)
+	s.m.observeStorage("AppendAudit", start, err)
+	return err
+}
+
+func (s instrumentedStorage) ListAudit(ctx context.Context, login string, since int64, limit int) ([]types.AuditEvent, error) {
+	start := time.Now()
+	res, err := s.Storage.ListAudit(ctx, login, since, limit)
+	s.m.observeStorage("ListAudit", start, err)
+	return res, err
+}
+
+func (s instrumentedStorage) AppendJournal(ctx context.Context, fn func(*types.JournalEntry) (*types.JournalEntry, error)) error {
+	start := time.Now()
+	err := s.Storage.AppendJournal(ctx, fn)
+	s.m.observeStorage("AppendJournal", start, err)
+	return err
+}
+
+func (s instrumentedStorage) ListJournal(ctx context.Context, since int64, limit int) ([]types.JournalEntry, error) {
+	start := time.Now()
+	res, err := s.Storage.ListJournal(ctx, since, limit)
+	s.m.observeStorage("ListJournal", start, err)
+	return res, err
+}
+
+func (s instrumentedStorage) GetJournalHead(ctx context.Context, head *types.JournalEntry) error {
+	start := time.Now()
+	err := s.Storage.GetJournalHead(ctx, head)
+	s.m.observeStorage("GetJournalHead", start, err)
+	return err
+}
+
+func (s instrumentedStorage) SubscribeEvents(ctx context.Context, prefix string) (<-chan types.Event, func() error, error) {
+	start := time.Now()
+	events, unsubscribe, err := s.Storage.SubscribeEvents(ctx, prefix)
+	s.m.observeStorage("SubscribeEvents", start, err)
+	return events, unsubscribe, err
+}
+
+func (s instrumentedStorage) Ping(ctx context.Context, key []byte) error {
+	start := time.Now()
+	err := s.Storage.Ping(ctx, key)
+	s.m.observeStorage("Ping", start, err)
+	return err
+}
+
+func (s instrumentedStorage) ListSecrets(ctx context.Context, prefix string, filter types.ListFilter) ([]types.SecretInfo, bool, error) {
+	start := time.Now()
+	secrets, more, err := s.Storage.ListSecrets(ctx, prefix, filter)
+	s.m.observeStorage("ListSecrets", start, err)
+	return secrets, more, err
+}
+
+func (s instrumentedStorage) AddSession(ctx context.Context, sid string, sess *types.Session, ttl time.Duration) error {
+	start := time.Now()
+	err := s.Storage.AddSession(ctx, sid, sess, ttl)
+	s.m.observeStorage("AddSession", start, err)
+	return err
+}
+
+func (s instrumentedStorage) GetSession(ctx context.Context, sid string, sess *types.Session) error {
+	start := time.Now()
+	err := s.Storage.GetSession(ctx, sid, sess)
+	s.m.observeStorage("GetSession", start, err)
+	return err
+}
+
+func (s instrumentedStorage) RotateSession(ctx context.Context, sid string, oldHash string, sess *types.Session, ttl time.Duration) error {
+	start := time.Now()
+	err := s.Storage.RotateSession(ctx, sid, oldHash, sess, ttl)
+	s.m.observeStorage("RotateSession", start, err)
+	return err
+}
+
+func (s instrumentedStorage) RemoveSession(ctx context.Context, sid string) error {
+	start := time.Now()
+	err := s.Storage.RemoveSession(ctx, sid)
+	s.m.observeStorage("RemoveSession", start, err)
+	return err
+}
+
+func (s instrumentedStorage) Get2FA(ctx context.Context, login string, tf *types.TwoFactor) error {
+	start := time.Now()
+	err := s.Storage.Get2FA(ctx, login, tf)
+	s.m.observeStorage("Get2FA", start, err)
+	return err
+}
+
+func (s instrumentedStorage) Update2FA(ctx context.Context, login string, fn func(*types.TwoFactor) error) error {
+	start := time.Now()
+	err := s.Storage.Update2FA(ctx, login, fn)
+	s.m.observeStorage("Update2FA", start, err)
+	return err
+}
+
+func (s instrumentedStorage) Remove2FA(ctx context.Context, login string) error {
+	start := time.Now()
+	err := s.Storage.Remove2FA(ctx, login)
+	s.m.observeStorage("Remove2FA", start, err)
+	return err
+}
+
+func (s instrumentedStorage) GetLockout(ctx context.Context, subject string, lo *types.Lockout) error {
+	start := time.Now()
+	err := s.Storage.GetLockout(ctx, subject, lo)
+	s.m.observeStorage("GetLockout", start, err)
+	return err
+}
+
+func (s instrumentedStorage) UpdateLockout(ctx context.Context, subject string, fn func(*types.Lockout) error, ttl time.Duration) error {
+	start := time.Now()
+	err := s.Storage.UpdateLockout(ctx, subject, fn, ttl)
+	s.m.observeStorage("UpdateLockout", start, err)
+	return err
+}
+
+func (s instrumentedStorage) RemoveLockout(ctx context.Context, subject string) error {
+	start := time.Now()
+	err := s.Storage.RemoveLockout(ctx, subject)
+	s.m.observeStorage("RemoveLockout", start, err)
+	return err
+}
+
+func (s instrumentedStorage) TakeRateToken(ctx context.Context, subject string, rate float64, burst int) (time.Duration, error) {
+	start := time.Now()
+	res, err := s.Storage.TakeRateToken(ctx, subject, rate, burst)
+	s.m.observeStorage("TakeRateToken", start, err)
+	return res, err
+}
+
+func (s instrumentedStorage) AcquireInFlight(ctx context.Context, login string, limit int, ttl time.Duration) (string, error) {
+	start := time.Now()
+	res, err := s.Storage.AcquireInFlight(ctx, login, limit, ttl)
+	s.m.observeStorage("AcquireInFlight", start, err)
+	return res, err
+}
+
+func (s instrumentedStorage) ReleaseInFlight(ctx context.Context, login string, slot string) error {
+	start := time.Now()
+	err := s.Storage.ReleaseInFlight(ctx, login, slot)
+	s.m.observeStorage("ReleaseInFlight", start, err)
+	return err
+}
+
+func (s instrumentedStorage) SetKeyPair(ctx context.Context, login string, kp *types.KeyPair) error {
+	start := time.Now()
+	err := s.Storage.SetKeyPair(ctx, login, kp)
+	s.m.observeStorage("SetKeyPair", start, err)
+	return err
+}
+
+func (s instrumentedStorage) GetKeyPair(ctx context.Context, login string, kp *types.KeyPair) error {
+	start := time.Now()
+	err := s.Storage.GetKeyPair(ctx, login, kp)
+	s.m.observeStorage("GetKeyPair", start, err)
+	return err
+}
+
+func (s instrumentedStorage) AddShare(ctx context.Context, recipient string, share *types.Share) error {
+	start := time.Now()
+	err := s.Storage.AddShare(ctx, recipient, share)
+	s.m.observeStorage("AddShare", start, err)
+	return err
+}
+
+func (s instrumentedStorage) ListShares(ctx context.Context, recipient string) ([]types.Share, error) {
+	start := time.Now()
+	res, err := s.Storage.ListShares(ctx, recipient)
+	s.m.observeStorage("ListShares", start, err)
+	return res, err
+}
+
+func (s instrumentedStorage) RemoveShare(ctx context.Context, owner string, key string, recipient string) error {
+	start := time.Now()
+	err := s.Storage.RemoveShare(ctx, owner, key, recipient)
+	s.m.observeStorage("RemoveShare", start, err)
+	return err
+}
+
+func (s instrumentedStorage) CreateVault(ctx context.Context, vault string, admin *types.VaultMember) error {
+	start := time.Now()
+	err := s.Storage.CreateVault(ctx, vault, admin)
+	s.m.observeStorage("CreateVault", start, err)
+	return err
+}
+
+func (s instrumentedStorage) GetVault(ctx context.Context, vault string, v *types.Vault) error {
+	start := time.Now()
+	err := s.Storage.GetVault(ctx, vault, v)
+	s.m.observeStorage("GetVault", start, err)
+	return err
+}
+
+func (s instrumentedStorage) GetVaultMember(ctx context.Context, vault string, login string) (*types.VaultMember, error) {
+	start := time.Now()
+	res, err := s.Storage.GetVaultMember(ctx, vault, login)
+	s.m.observeStorage("GetVaultMember", start, err)
+	return res, err
+}
+
+func (s instrumentedStorage) SetVaultMember(ctx context.Context, vault string, member *types.VaultMember) error {
+	start := time.Now()
+	err := s.Storage.SetVaultMember(ctx, vault, member)
+	s.m.observeStorage("SetVaultMember", start, err)
+	return err
+}
+
+func (s instrumentedStorage) ListVaultMembers(ctx context.Context, vault string) ([]types.VaultMember, error) {
+	start := time.Now()
+	res, err := s.Storage.ListVaultMembers(ctx, vault)
+	s.m.observeStorage("ListVaultMembers", start, err)
+	return res, err
+}
+
+func (s instrumentedStorage) ListUserVaults(ctx context.Context, login string) ([]string, error) {
+	start := time.Now()
+	res, err := s.Storage.ListUserVaults(ctx, login)
+	s.m.observeStorage("ListUserVaults", start, err)
+	return res, err
+}
+
+func (s instrumentedStorage) ListVaultSecrets(ctx context.Context, vault string) (map[string]types.StorageModel, error) {
+	start := time.Now()
+	res, err := s.Storage.ListVaultSecrets(ctx, vault)
+	s.m.observeStorage("ListVaultSecrets", start, err)
+	return res, err
+}
+
+func (s instrumentedStorage) RotateVault(ctx context.Context, vault string, rot *types.VaultRotation) error {
+	start := time.Now()
+	err := s.Storage.RotateVault(ctx, vault, rot)
+	s.m.observeStorage("RotateVault", start, err)
+	return err
+}
+
+func (s instrumentedStorage) GetSealConfig(ctx context.Context, cfg *types.SealConfig) error {
+	start := time.Now()
+	err := s.Storage.GetSealConfig(ctx, cfg)
+	s.m.observeStorage("GetSealConfig", start, err)
+	return err
+}
+
+func (s instrumentedStorage) InitSeal(ctx context.Context, cfg *types.SealConfig, keyHash []byte) error {
+	start := time.Now()
+	err := s.Storage.InitSeal(ctx, cfg, keyHash)
+	s.m.observeStorage("InitSeal", start, err)
+	return err
+}
@@ -0,0 +1,233 @@
+/*
+Package otp contents types and methods for time-based one-time passwords (RFC 6238).
+*/
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultAlgorithm = "SHA1" // default HMAC algorithm of authenticator apps
+	DefaultDigits    = 6      // default length of code
+	DefaultPeriod    = 30     // default live time of code in seconds
+
+	secretLength = 20 // length of generated secret in bytes
+)
+
+// b32 is base32 encoding of secrets in provisioning URIs.
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Key implements parameters of TOTP generator.
+type Key struct {
+	Secret    []byte
+	Algorithm string // SHA1 | SHA256 | SHA512
+	Digits    int
+	Period    int // seconds
+	Issuer    string
+	Account   string
+}
+
+// NewKey generates new key with random secret and default parameters.
+func NewKey(issuer string, account string) (*Key, error) {
+	secret := make([]byte, secretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return &Key{
+		Secret:    secret,
+		Algorithm: DefaultAlgorithm,
+		Digits:    DefaultDigits,
+		Period:    DefaultPeriod,
+		Issuer:    issuer,
+		Account:   account,
+	}, nil
+}
+
+// EncodeSecret returns secret in base32 without padding.
+func EncodeSecret(secret []byte) string {
+	return b32.EncodeToString(secret)
+}
+
+// DecodeSecret decodes base32 secret, it ignores case, spaces and padding.
+func DecodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	return b32.DecodeString(strings.TrimRight(secret, "="))
+}
+
+// Step returns number of time step for t.
+func (k Key) Step(t time.Time) int64 {
+	return t.Unix() / int64(k.period())
+}
+
+// Remaining returns seconds while code for t is valid.
+func (k Key) Remaining(t time.Time) int {
+	return k.period() - int(t.Unix()%int64(k.period()))
+}
+
+// Code returns code for time t.
+func (k Key) Code(t time.Time) (string, error) {
+	return k.CodeAt(k.Step(t))
+}
+
+// CodeAt returns code for time step.
+func (k Key) CodeAt(step int64) (string, error) {
+	newHash, err := hashFunc(k.Algorithm)
+	if err != nil {
+		return "", err
+	}
+	digits := k.Digits
+	if digits == 0 {
+		digits = DefaultDigits
+	}
+	if digits < 6 || digits > 10 {
+		return "", fmt.Errorf("wrong code length: %d", digits)
+	}
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+	mac := hmac.New(newHash, k.Secret)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	// dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	bin := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint64(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, uint64(bin)%mod), nil
+}
+
+// Validate checks code for time t allowing skew steps before and after it.
+// It returns time step of matched code.
+func (k Key) Validate(code string, t time.Time, skew int) (int64, bool) {
+	cur := k.Step(t)
+	for i := -skew; i <= skew; i++ {
+		step := cur + int64(i)
+		expected, err := k.CodeAt(step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// URI returns otpauth:// provisioning URI of key for authenticator apps.
+func (k Key) URI() string {
+	label := k.Account
+	if k.Issuer != "" {
+		label = k.Issuer + ":" + k.Account
+	}
+	params := url.Values{}
+	params.Set("secret", EncodeSecret(k.Secret))
+	if k.Issuer != "" {
+		params.Set("issuer", k.Issuer)
+	}
+	params.Set("algorithm", k.algorithm())
+	params.Set("digits", strconv.Itoa(k.Digits))
+	params.Set("period", strconv.Itoa(k.period()))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + label,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
+
+// ParseURI parses otpauth://totp/ provisioning URI.
+func ParseURI(uri string) (*Key, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "otpauth" {
+		return nil, errors.New("wrong URI scheme: " + u.Scheme)
+	}
+	if u.Host != "totp" {
+		return nil, errors.New("unsupported OTP type: " + u.Host)
+	}
+	params := u.Query()
+	key := Key{
+		Algorithm: DefaultAlgorithm,
+		Digits:    DefaultDigits,
+		Period:    DefaultPeriod,
+		Issuer:    params.Get("issuer"),
+	}
+	key.Secret, err = DecodeSecret(params.Get("secret"))
+	if err != nil {
+		return nil, fmt.Errorf("wrong secret: %w", err)
+	}
+	if len(key.Secret) == 0 {
+		return nil, errors.New("empty secret")
+	}
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		if key.Issuer == "" {
+			key.Issuer = issuer
+		}
+		key.Account = strings.TrimSpace(account)
+	} else {
+		key.Account = label
+	}
+	if algo := params.Get("algorithm"); algo != "" {
+		if _, err := hashFunc(algo); err != nil {
+			return nil, err
+		}
+		key.Algorithm = strings.ToUpper(algo)
+	}
+	if digits := params.Get("digits"); digits != "" {
+		if key.Digits, err = strconv.Atoi(digits); err != nil {
+			return nil, fmt.Errorf("wrong digits: %w", err)
+		}
+	}
+	if period := params.Get("period"); period != "" {
+		if key.Period, err = strconv.Atoi(period); err != nil || key.Period <= 0 {
+			return nil, fmt.Errorf("wrong period: %v", period)
+		}
+	}
+	return &key, nil
+}
+
+func (k Key) period() int {
+	if k.Period <= 0 {
+		return DefaultPeriod
+	}
+	return k.Period
+}
+
+func (k Key) algorithm() string {
+	if k.Algorithm == "" {
+		return DefaultAlgorithm
+	}
+	return strings.ToUpper(k.Algorithm)
+}
+
+func hashFunc(algo string) (func() hash.Hash, error) {
+	switch strings.ToUpper(algo) {
+	case "", "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA512":
+		return sha512.New, nil
+	}
+	return nil, errors.New("unknown algorithm: " + algo)
+}
@@ -0,0 +1,111 @@
+package otp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// test vectors from RFC 6238 appendix B
+func TestKey_Code(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		algo   string
+		time   int64
+		code   string
+	}{
+		{"sha1 59", "12345678901234567890", "SHA1", 59, "94287082"},
+		{"sha1 1111111109", "12345678901234567890", "SHA1", 1111111109, "07081804"},
+		{"sha1 2000000000", "12345678901234567890", "SHA1", 2000000000, "69279037"},
+		{"sha256 59", "12345678901234567890123456789012", "SHA256", 59, "46119246"},
+		{"sha512 59", "1234567890123456789012345678901234567890123456789012345678901234", "SHA512", 59, "90693936"},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			key := Key{Secret: []byte(tst.secret), Algorithm: tst.algo, Digits: 8, Period: 30}
+			code, err := key.Code(time.Unix(tst.time, 0))
+			require.NoError(t, err)
+			assert.Equal(t, tst.code, code)
+		})
+	}
+	t.Run("wrong algorithm", func(t *testing.T) {
+		_, err := Key{Secret: []byte("1"), Algorithm: "MD5"}.Code(time.Now())
+		require.Error(t, err)
+	})
+}
+
+func TestKey_Validate(t *testing.T) {
+	key, err := NewKey("KeepPas", "test")
+	require.NoError(t, err)
+	now := time.Now()
+	prev, err := key.Code(now.Add(-30 * time.Second))
+	require.NoError(t, err)
+	t.Run("previous step", func(t *testing.T) {
+		step, ok := key.Validate(prev, now, 1)
+		assert.True(t, ok)
+		assert.Equal(t, key.Step(now)-1, step)
+	})
+	t.Run("out of skew", func(t *testing.T) {
+		_, ok := key.Validate(prev, now, 0)
+		assert.False(t, ok)
+	})
+}
+
+func TestKey_URI(t *testing.T) {
+	key := Key{Secret: []byte("12345678901234567890"), Digits: 6, Issuer: "KeepPas", Account: "test"}
+	uri := key.URI()
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/KeepPas:test?"))
+	assert.Contains(t, uri, "secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+	assert.Contains(t, uri, "period=30")
+}
+
+func TestDecodeSecret(t *testing.T) {
+	secret, err := DecodeSecret("gezd gnbv gy3t qojq gezd gnbv gy3t qojq")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("12345678901234567890"), secret)
+	assert.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", EncodeSecret(secret))
+}
+
+func TestParseURI(t *testing.T) {
+	t.Run("right", func(t *testing.T) {
+		key, err := ParseURI("otpauth://totp/ACME%20Co:john@example.com?secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ&issuer=ACME%20Co&algorithm=SHA256&digits=8&period=60")
+		require.NoError(t, err)
+		assert.Equal(t, "ACME Co", key.Issuer)
+		assert.Equal(t, "john@example.com", key.Account)
+		assert.Equal(t, "SHA256", key.Algorithm)
+		assert.Equal(t, 8, key.Digits)
+		assert.Equal(t, 60, key.Period)
+	})
+	t.Run("defaults", func(t *testing.T) {
+		key, err := ParseURI("otpauth://totp/john?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+		require.NoError(t, err)
+		assert.Equal(t, "john", key.Account)
+		assert.Equal(t, Key{Secret: []byte("12345678901234567890"), Algorithm: DefaultAlgorithm, Digits: DefaultDigits, Period: DefaultPeriod, Account: "john"}, *key)
+	})
+	t.Run("round trip", func(t *testing.T) {
+		key, err := NewKey("KeepPas", "test")
+		require.NoError(t, err)
+		parsed, err := ParseURI(key.URI())
+		require.NoError(t, err)
+		assert.Equal(t, key, parsed)
+	})
+	wrong := []string{
+		"https://totp/john?secret=GEZDGNBV",
+		"otpauth://hotp/john?secret=GEZDGNBV",
+		"otpauth://totp/john?secret=1",
+		"otpauth://totp/john",
+		"otpauth://totp/john?secret=GEZDGNBV&algorithm=MD5",
+		"otpauth://totp/john?secret=GEZDGNBV&digits=x",
+		"otpauth://totp/john?secret=GEZDGNBV&period=0",
+	}
+	for _, uri := range wrong {
+		t.Run(uri, func(t *testing.T) {
+			_, err := ParseURI(uri)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Package passgen contents types and methods for generation of random passwords and passphrases.
+*/
+package passgen
+
+import (
+	"bufio"
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+)
+
+const (
+	DefaultLength    = 20  // default length of password
+	DefaultSeparator = "-" // default separator of words in passphrase
+
+	lowerChars     = "abcdefghijklmnopqrstuvwxyz"
+	upperChars     = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars     = "0123456789"
+	symbolChars    = "!#$%&()*+-./:;<=>?@[]^_{}~"
+	ambiguousChars = "Il1O0o|`'\""
+
+	maxAttempts = 1000 // limit of attempts to generate password with all required classes
+)
+
+// wordlist is EFF large wordlist for dice-generated passphrases:
+// https://www.eff.org/dice, licensed by Electronic Frontier Foundation under CC BY 3.0 US.
+//
+//go:embed eff_large_wordlist.txt
+var wordlist string
+
+var words = parseWordlist(wordlist)
+
+// Policy implements rules of password generation.
+type Policy struct {
+	Length           int
+	Lower            bool
+	Upper            bool
+	Digits           bool
+	Symbols          bool
+	ExcludeAmbiguous bool
+	RequireEach      bool // password contains at least one char of each enabled class
+}
+
+// DefaultPolicy returns policy with all classes enabled and required.
+func DefaultPolicy() Policy {
+	return Policy{
+		Length:      DefaultLength,
+		Lower:       true,
+		Upper:       true,
+		Digits:      true,
+		Symbols:     true,
+		RequireEach: true,
+	}
+}
+
+// classes returns chars of enabled classes.
+func (p Policy) classes() []string {
+	out := make([]string, 0)
+	for _, cls := range []struct {
+		enabled bool
+		chars   string
+	}{
+		{p.Lower, lowerChars},
+		{p.Upper, upperChars},
+		{p.Digits, digitChars},
+		{p.Symbols, symbolChars},
+	} {
+		if !cls.enabled {
+			continue
+		}
+		chars := cls.chars
+		if p.ExcludeAmbiguous {
+			chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(ambiguousChars, r) {
+					return -1
+				}
+				return r
+			}, chars)
+		}
+		out = append(out, chars)
+	}
+	return out
+}
+
+// Generate returns random password by policy and its entropy in bits.
+func Generate(p Policy) (string, float64, error) {
+	classes := p.classes()
+	if len(classes) == 0 {
+		return "", 0, errors.New("no character classes enabled")
+	}
+	if p.Length <= 0 {
+		return "", 0, errors.New("password length must be positive")
+	}
+	if p.RequireEach && p.Length < len(classes) {
+		return "", 0, errors.New("password length is less than count of required classes")
+	}
+	chars := []rune(strings.Join(classes, ""))
+	out := make([]rune, p.Length)
+	// passwords without some required class are rejected, so result is uniform over allowed passwords
+	for i := 0; i < maxAttempts; i++ {
+		for j := range out {
+			n, err := randInt(len(chars))
+			if err != nil {
+				return "", 0, err
+			}
+			out[j] = chars[n]
+		}
+		if !p.RequireEach || hasEach(string(out), classes) {
+			return string(out), Entropy(p), nil
+		}
+	}
+	return "", 0, errors.New("can't generate password with all required classes")
+}
+
+// Entropy returns entropy in bits of password generated by policy.
+func Entropy(p Policy) float64 {
+	classes := p.classes()
+	if len(classes) == 0 || p.Length <= 0 {
+		return 0
+	}
+	if !p.RequireEach {
+		return float64(p.Length) * math.Log2(float64(len(strings.Join(classes, ""))))
+	}
+	// count of passwords with all classes by inclusion-exclusion principle
+	total := new(big.Int)
+	for mask := 0; mask < 1<<len(classes); mask++ {
+		size, excluded := 0, 0
+		for i, cls := range classes {
+			if mask&(1<<i) != 0 {
+				excluded++
+				continue
+			}
+			size += len(cls)
+		}
+		n := new(big.Int).Exp(big.NewInt(int64(size)), big.NewInt(int64(p.Length)), nil)
+		if excluded%2 == 1 {
+			total.Sub(total, n)
+		} else {
+			total.Add(total, n)
+		}
+	}
+	return log2(total)
+}
+
+// Passphrase returns passphrase of count random words joined by sep and its entropy in bits.
+func Passphrase(count int, sep string) (string, float64, error) {
+	if count <= 0 {
+		return "", 0, errors.New("words count must be positive")
+	}
+	out := make([]string, count)
+	for i := range out {
+		n, err := randInt(len(words))
+		if err != nil {
+			return "", 0, err
+		}
+		out[i] = words[n]
+	}
+	return strings.Join(out, sep), PassphraseEntropy(count), nil
+}
+
+// PassphraseEntropy returns entropy in bits of passphrase of count words.
+func PassphraseEntropy(count int) float64 {
+	return float64(count) * math.Log2(float64(len(words)))
+}
+
+func hasEach(passwd string, classes []string) bool {
+	for _, cls := range classes {
+		if !strings.ContainsAny(passwd, cls) {
+			return false
+		}
+	}
+	return true
+}
+
+// randInt returns uniform random number in [0, n).
+func randInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
+
+// log2 returns binary logarithm of big positive number.
+func log2(n *big.Int) float64 {
+	if n.Sign() <= 0 {
+		return 0
+	}
+	f, _ := new(big.Float).SetInt(n).Float64()
+	if !math.IsInf(f, 0) {
+		return math.Log2(f)
+	}
+	shift := n.BitLen() - 64
+	f, _ = new(big.Float).SetInt(new(big.Int).Rsh(n, uint(shift))).Float64()
+	return math.Log2(f) + float64(shift)
+}
+
+// parseWordlist returns words of "<dice rolls>\t<word>" lines.
+func parseWordlist(list string) []string {
+	out := make([]string, 0)
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 {
+			out = append(out, fields[len(fields)-1])
+		}
+	}
+	return out
+}
+
+// Words returns copy of embedded passphrase wordlist.
+func Words() []string {
+	return append([]string(nil), words...)
+}
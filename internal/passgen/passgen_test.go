@@ -0,0 +1,74 @@
+package passgen
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		p := DefaultPolicy()
+		passwd, entropy, err := Generate(p)
+		require.NoError(t, err)
+		assert.Len(t, passwd, DefaultLength)
+		assert.True(t, hasEach(passwd, p.classes()))
+		assert.Greater(t, entropy, 120.0)
+	})
+	t.Run("digits only", func(t *testing.T) {
+		passwd, entropy, err := Generate(Policy{Length: 10, Digits: true})
+		require.NoError(t, err)
+		assert.Len(t, passwd, 10)
+		assert.Empty(t, strings.Trim(passwd, digitChars))
+		assert.InDelta(t, 10*math.Log2(10), entropy, 1e-9)
+	})
+	t.Run("exclude ambiguous", func(t *testing.T) {
+		p := DefaultPolicy()
+		p.Length = 200
+		p.ExcludeAmbiguous = true
+		passwd, _, err := Generate(p)
+		require.NoError(t, err)
+		assert.False(t, strings.ContainsAny(passwd, ambiguousChars))
+	})
+	t.Run("required classes in short password", func(t *testing.T) {
+		p := DefaultPolicy()
+		p.Length = 4
+		for i := 0; i < 20; i++ {
+			passwd, _, err := Generate(p)
+			require.NoError(t, err)
+			assert.True(t, hasEach(passwd, p.classes()))
+		}
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, _, err := Generate(Policy{Length: 10})
+		require.Error(t, err)
+		_, _, err = Generate(Policy{Digits: true})
+		require.Error(t, err)
+		_, _, err = Generate(Policy{Length: 1, Digits: true, Lower: true, RequireEach: true})
+		require.Error(t, err)
+	})
+}
+
+func TestEntropy(t *testing.T) {
+	// passwords of 2 chars with lower and digit: 2*26*10 of 36^2
+	p := Policy{Length: 2, Lower: true, Digits: true, RequireEach: true}
+	assert.InDelta(t, math.Log2(520), Entropy(p), 1e-9)
+	p.RequireEach = false
+	assert.InDelta(t, 2*math.Log2(36), Entropy(p), 1e-9)
+	// big numbers don't overflow float64
+	p = Policy{Length: 300, Lower: true, Upper: true, Digits: true, Symbols: true, RequireEach: true}
+	assert.InDelta(t, 300*math.Log2(88), Entropy(p), 1e-6)
+}
+
+func TestPassphrase(t *testing.T) {
+	assert.Len(t, words, 7776)
+	phrase, entropy, err := Passphrase(5, " ")
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(phrase, " "), 5)
+	assert.InDelta(t, 5*math.Log2(7776), entropy, 1e-9)
+	_, _, err = Passphrase(0, " ")
+	require.Error(t, err)
+}
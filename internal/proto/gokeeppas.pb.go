@@ -2,7 +2,7 @@
 // versions:
 // 	protoc-gen-go v1.30.0
 // 	protoc        v3.15.8
-// source: internal/proto/gokeepas.proto
+// source: internal/proto/gokeeppas.proto
 
 package proto
 
@@ -27,6 +27,7 @@ const (
 	Type_BINARY Type = 1
 	Type_LOGIN  Type = 2
 	Type_CART   Type = 3
+	Type_OTP    Type = 4
 )
 
 // Enum value maps for Type.
@@ -36,12 +37,14 @@ var (
 		1: "BINARY",
 		2: "LOGIN",
 		3: "CART",
+		4: "OTP",
 	}
 	Type_value = map[string]int32{
 		"TEXT":   0,
 		"BINARY": 1,
 		"LOGIN":  2,
 		"CART":   3,
+		"OTP":    4,
 	}
 )
 
@@ -56,11 +59,11 @@ func (x Type) String() string {
 }
 
 func (Type) Descriptor() protoreflect.EnumDescriptor {
-	return file_internal_proto_gokeepas_proto_enumTypes[0].Descriptor()
+	return file_internal_proto_gokeeppas_proto_enumTypes[0].Descriptor()
 }
 
 func (Type) Type() protoreflect.EnumType {
-	return &file_internal_proto_gokeepas_proto_enumTypes[0]
+	return &file_internal_proto_gokeeppas_proto_enumTypes[0]
 }
 
 func (x Type) Number() protoreflect.EnumNumber {
@@ -69,7 +72,59 @@ func (x Type) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use Type.Descriptor instead.
 func (Type) EnumDescriptor() ([]byte, []int) {
-	return file_internal_proto_gokeepas_proto_rawDescGZIP(), []int{0}
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{0}
+}
+
+type WatchEvent_Kind int32
+
+const (
+	WatchEvent_ADD    WatchEvent_Kind = 0
+	WatchEvent_UPDATE WatchEvent_Kind = 1
+	WatchEvent_RENAME WatchEvent_Kind = 2
+	WatchEvent_REMOVE WatchEvent_Kind = 3
+)
+
+// Enum value maps for WatchEvent_Kind.
+var (
+	WatchEvent_Kind_name = map[int32]string{
+		0: "ADD",
+		1: "UPDATE",
+		2: "RENAME",
+		3: "REMOVE",
+	}
+	WatchEvent_Kind_value = map[string]int32{
+		"ADD":    0,
+		"UPDATE": 1,
+		"RENAME": 2,
+		"REMOVE": 3,
+	}
+)
+
+func (x WatchEvent_Kind) Enum() *WatchEvent_Kind {
+	p := new(WatchEvent_Kind)
+	*p = x
+	return p
+}
+
+func (x WatchEvent_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WatchEvent_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_internal_proto_gokeeppas_proto_enumTypes[1].Descriptor()
+}
+
+func (WatchEvent_Kind) Type() protoreflect.EnumType {
+	return &file_internal_proto_gokeeppas_proto_enumTypes[1]
+}
+
+func (x WatchEvent_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WatchEvent_Kind.Descriptor instead.
+func (WatchEvent_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{27, 0}
 }
 
 type AuthRequest struct {
@@ -79,12 +134,13 @@ type AuthRequest struct {
 
 	Login    string `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`       // client login in tls connection
 	Password string `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"` // client password in tls connection
+	Otp      string `protobuf:"bytes,3,opt,name=otp,proto3" json:"otp,omitempty"`           // one-time code or recovery code, required when 2FA is enabled
 }
 
 func (x *AuthRequest) Reset() {
 	*x = AuthRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_gokeepas_proto_msgTypes[0]
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[0]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -97,7 +153,7 @@ func (x *AuthRequest) String() string {
 func (*AuthRequest) ProtoMessage() {}
 
 func (x *AuthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_gokeepas_proto_msgTypes[0]
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[0]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -110,7 +166,7 @@ func (x *AuthRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AuthRequest.ProtoReflect.Descriptor instead.
 func (*AuthRequest) Descriptor() ([]byte, []int) {
-	return file_internal_proto_gokeepas_proto_rawDescGZIP(), []int{0}
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{0}
 }
 
 func (x *AuthRequest) GetLogin() string {
@@ -127,20 +183,29 @@ func (x *AuthRequest) GetPassword() string {
 	return ""
 }
 
+func (x *AuthRequest) GetOtp() string {
+	if x != nil {
+		return x.Otp
+	}
+	return ""
+}
+
 type AuthResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SymmKey   []byte `protobuf:"bytes,1,opt,name=symmKey,proto3" json:"symmKey,omitempty"`     // client's symmetric key for encrypt secrets
-	AuthToken string `protobuf:"bytes,2,opt,name=authToken,proto3" json:"authToken,omitempty"` // client's authentication token
-	Error     string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	SymmKey      []byte `protobuf:"bytes,1,opt,name=symmKey,proto3" json:"symmKey,omitempty"`     // client's symmetric key for encrypt secrets
+	AuthToken    string `protobuf:"bytes,2,opt,name=authToken,proto3" json:"authToken,omitempty"` // client's authentication token
+	Error        string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	RefreshToken string `protobuf:"bytes,4,opt,name=refreshToken,proto3" json:"refreshToken,omitempty"` // client's long-lived token for refresh authToken
+	Login        string `protobuf:"bytes,5,opt,name=login,proto3" json:"login,omitempty"`               // client login, owner of secrets
 }
 
 func (x *AuthResponse) Reset() {
 	*x = AuthResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_gokeepas_proto_msgTypes[1]
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[1]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -153,7 +218,7 @@ func (x *AuthResponse) String() string {
 func (*AuthResponse) ProtoMessage() {}
 
 func (x *AuthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_gokeepas_proto_msgTypes[1]
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[1]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -166,7 +231,7 @@ func (x *AuthResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AuthResponse.ProtoReflect.Descriptor instead.
 func (*AuthResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_gokeepas_proto_rawDescGZIP(), []int{1}
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *AuthResponse) GetSymmKey() []byte {
@@ -190,34 +255,45 @@ func (x *AuthResponse) GetError() string {
 	return ""
 }
 
-type BinRequest struct {
+func (x *AuthResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *AuthResponse) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+type RefreshRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Data   string `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`                      // encrypted data with symm key
-	Key    string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`                        // key of value
-	Type   Type   `protobuf:"varint,3,opt,name=type,proto3,enum=gokeepas.Type" json:"type,omitempty"` // type of value
-	NewKey string `protobuf:"bytes,4,opt,name=newKey,proto3" json:"newKey,omitempty"`                  // new key value
+	RefreshToken string `protobuf:"bytes,1,opt,name=refreshToken,proto3" json:"refreshToken,omitempty"` // refresh token of client session
 }
 
-func (x *BinRequest) Reset() {
-	*x = BinRequest{}
+func (x *RefreshRequest) Reset() {
+	*x = RefreshRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_gokeepas_proto_msgTypes[2]
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *BinRequest) String() string {
+func (x *RefreshRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BinRequest) ProtoMessage() {}
+func (*RefreshRequest) ProtoMessage() {}
 
-func (x *BinRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_gokeepas_proto_msgTypes[2]
+func (x *RefreshRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -228,64 +304,153 @@ func (x *BinRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BinRequest.ProtoReflect.Descriptor instead.
-func (*BinRequest) Descriptor() ([]byte, []int) {
-	return file_internal_proto_gokeepas_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use RefreshRequest.ProtoReflect.Descriptor instead.
+func (*RefreshRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *BinRequest) GetData() string {
+func (x *RefreshRequest) GetRefreshToken() string {
 	if x != nil {
-		return x.Data
+		return x.RefreshToken
 	}
 	return ""
 }
 
-func (x *BinRequest) GetKey() string {
+type KeyShare struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Share string `protobuf:"bytes,1,opt,name=share,proto3" json:"share,omitempty"` // Shamir share of server master key, base64
+}
+
+func (x *KeyShare) Reset() {
+	*x = KeyShare{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KeyShare) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyShare) ProtoMessage() {}
+
+func (x *KeyShare) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyShare.ProtoReflect.Descriptor instead.
+func (*KeyShare) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *KeyShare) GetShare() string {
 	if x != nil {
-		return x.Key
+		return x.Share
 	}
 	return ""
 }
 
-func (x *BinRequest) GetType() Type {
+type SealStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sealed    bool  `protobuf:"varint,1,opt,name=sealed,proto3" json:"sealed,omitempty"`       // server doesn't have master key
+	Threshold int32 `protobuf:"varint,2,opt,name=threshold,proto3" json:"threshold,omitempty"` // count of shares to unseal server
+	Progress  int32 `protobuf:"varint,3,opt,name=progress,proto3" json:"progress,omitempty"`   // count of accepted shares
+}
+
+func (x *SealStatus) Reset() {
+	*x = SealStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SealStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SealStatus) ProtoMessage() {}
+
+func (x *SealStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SealStatus.ProtoReflect.Descriptor instead.
+func (*SealStatus) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SealStatus) GetSealed() bool {
 	if x != nil {
-		return x.Type
+		return x.Sealed
 	}
-	return Type_TEXT
+	return false
 }
 
-func (x *BinRequest) GetNewKey() string {
+func (x *SealStatus) GetThreshold() int32 {
 	if x != nil {
-		return x.NewKey
+		return x.Threshold
 	}
-	return ""
+	return 0
 }
 
-type BinResponse struct {
+func (x *SealStatus) GetProgress() int32 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+type TwoFARequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Error string `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"` // one-time code or recovery code
 }
 
-func (x *BinResponse) Reset() {
-	*x = BinResponse{}
+func (x *TwoFARequest) Reset() {
+	*x = TwoFARequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_gokeepas_proto_msgTypes[3]
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *BinResponse) String() string {
+func (x *TwoFARequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BinResponse) ProtoMessage() {}
+func (*TwoFARequest) ProtoMessage() {}
 
-func (x *BinResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_gokeepas_proto_msgTypes[3]
+func (x *TwoFARequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -296,45 +461,44 @@ func (x *BinResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BinResponse.ProtoReflect.Descriptor instead.
-func (*BinResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_gokeepas_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use TwoFARequest.ProtoReflect.Descriptor instead.
+func (*TwoFARequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *BinResponse) GetError() string {
+func (x *TwoFARequest) GetCode() string {
 	if x != nil {
-		return x.Error
+		return x.Code
 	}
 	return ""
 }
 
-type GetResponse struct {
+type TwoFAResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`                      // encrypted data with symm key
-	Key  string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`                        // key of value
-	Type Type   `protobuf:"varint,3,opt,name=type,proto3,enum=gokeepas.Type" json:"type,omitempty"` // type of value
+	Uri           string   `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`                     // otpauth:// provisioning URI
+	RecoveryCodes []string `protobuf:"bytes,2,rep,name=recoveryCodes,proto3" json:"recoveryCodes,omitempty"` // single-use recovery codes
 }
 
-func (x *GetResponse) Reset() {
-	*x = GetResponse{}
+func (x *TwoFAResponse) Reset() {
+	*x = TwoFAResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_gokeepas_proto_msgTypes[4]
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetResponse) String() string {
+func (x *TwoFAResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetResponse) ProtoMessage() {}
+func (*TwoFAResponse) ProtoMessage() {}
 
-func (x *GetResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_gokeepas_proto_msgTypes[4]
+func (x *TwoFAResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -345,57 +509,110 @@ func (x *GetResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
-func (*GetResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_gokeepas_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use TwoFAResponse.ProtoReflect.Descriptor instead.
+func (*TwoFAResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *GetResponse) GetData() []byte {
+func (x *TwoFAResponse) GetUri() string {
 	if x != nil {
-		return x.Data
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *TwoFAResponse) GetRecoveryCodes() []string {
+	if x != nil {
+		return x.RecoveryCodes
 	}
 	return nil
 }
 
-func (x *GetResponse) GetKey() string {
+type KeyPair struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PublicKey  string `protobuf:"bytes,1,opt,name=publicKey,proto3" json:"publicKey,omitempty"`   // X25519 public key, base64
+	PrivateKey string `protobuf:"bytes,2,opt,name=privateKey,proto3" json:"privateKey,omitempty"` // X25519 private key encrypted with user symm key
+}
+
+func (x *KeyPair) Reset() {
+	*x = KeyPair{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KeyPair) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyPair) ProtoMessage() {}
+
+func (x *KeyPair) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyPair.ProtoReflect.Descriptor instead.
+func (*KeyPair) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *KeyPair) GetPublicKey() string {
 	if x != nil {
-		return x.Key
+		return x.PublicKey
 	}
 	return ""
 }
 
-func (x *GetResponse) GetType() Type {
+func (x *KeyPair) GetPrivateKey() string {
 	if x != nil {
-		return x.Type
+		return x.PrivateKey
 	}
-	return Type_TEXT
+	return ""
 }
 
-type ListResponse struct {
+type ShareRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Keys string `protobuf:"bytes,1,opt,name=keys,proto3" json:"keys,omitempty"` // list keys separated new line
+	Key       string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`                       // key of shared secret
+	Recipient string `protobuf:"bytes,2,opt,name=recipient,proto3" json:"recipient,omitempty"`           // login of recipient, empty in Unshare means all recipients
+	Data      string `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`                     // secret sealed to recipient's public key
+	Type      Type   `protobuf:"varint,4,opt,name=type,proto3,enum=gokeepas.Type" json:"type,omitempty"` // type of secret
+	ReadOnly  bool   `protobuf:"varint,5,opt,name=readOnly,proto3" json:"readOnly,omitempty"`            // recipient can't save shared secret as own one
+	DataKey   string `protobuf:"bytes,6,opt,name=dataKey,proto3" json:"dataKey,omitempty"`               // data key of secret sealed to recipient's public key, data is encrypted with it
 }
 
-func (x *ListResponse) Reset() {
-	*x = ListResponse{}
+func (x *ShareRequest) Reset() {
+	*x = ShareRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_internal_proto_gokeepas_proto_msgTypes[5]
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ListResponse) String() string {
+func (x *ShareRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListResponse) ProtoMessage() {}
+func (*ShareRequest) ProtoMessage() {}
 
-func (x *ListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_internal_proto_gokeepas_proto_msgTypes[5]
+func (x *ShareRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -406,180 +623,2504 @@ func (x *ListResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
-func (*ListResponse) Descriptor() ([]byte, []int) {
-	return file_internal_proto_gokeepas_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use ShareRequest.ProtoReflect.Descriptor instead.
+func (*ShareRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *ListResponse) GetKeys() string {
+func (x *ShareRequest) GetKey() string {
 	if x != nil {
-		return x.Keys
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ShareRequest) GetRecipient() string {
+	if x != nil {
+		return x.Recipient
 	}
 	return ""
 }
 
-var File_internal_proto_gokeepas_proto protoreflect.FileDescriptor
+func (x *ShareRequest) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
 
-var file_internal_proto_gokeepas_proto_rawDesc = []byte{
-	0x0a, 0x1e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x2f, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x12, 0x09, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x22, 0x3f, 0x0a, 0x0b, 0x41,
-	0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f,
-	0x67, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e,
-	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x22, 0x5c, 0x0a, 0x0c,
-	0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07,
-	0x73, 0x79, 0x6d, 0x6d, 0x4b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x73,
-	0x79, 0x6d, 0x6d, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x75, 0x74, 0x68, 0x54, 0x6f,
-	0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x75, 0x74, 0x68, 0x54,
-	0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x6f, 0x0a, 0x0a, 0x42, 0x69,
-	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x10, 0x0a, 0x03,
-	0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x23,
-	0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x67,
-	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74,
-	0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6e, 0x65, 0x77, 0x4b, 0x65, 0x79, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x06, 0x6e, 0x65, 0x77, 0x4b, 0x65, 0x79, 0x22, 0x23, 0x0a, 0x0b, 0x42,
-	0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72,
-	0x72, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
-	0x22, 0x58, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64,
-	0x61, 0x74, 0x61, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x23, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e,
-	0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x22, 0x0a, 0x0c, 0x4c, 0x69,
-	0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x65,
-	0x79, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x2a, 0x31,
-	0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x54, 0x45, 0x58, 0x54, 0x10, 0x00,
-	0x12, 0x0a, 0x0a, 0x06, 0x42, 0x49, 0x4e, 0x41, 0x52, 0x59, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05,
-	0x4c, 0x4f, 0x47, 0x49, 0x4e, 0x10, 0x02, 0x12, 0x08, 0x0a, 0x04, 0x43, 0x41, 0x52, 0x54, 0x10,
-	0x03, 0x32, 0xbe, 0x04, 0x0a, 0x07, 0x4b, 0x65, 0x65, 0x70, 0x50, 0x61, 0x73, 0x12, 0x39, 0x0a,
-	0x06, 0x53, 0x69, 0x67, 0x6e, 0x55, 0x70, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70,
-	0x70, 0x61, 0x73, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x17, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x41, 0x75, 0x74, 0x68,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x38, 0x0a, 0x05, 0x4c, 0x6f, 0x67, 0x49,
-	0x6e, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x41, 0x75,
-	0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x67, 0x6f, 0x6b, 0x65,
-	0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x34, 0x0a, 0x03, 0x41, 0x64, 0x64, 0x12, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65,
-	0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12,
-	0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70,
-	0x61, 0x73, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x38,
-	0x0a, 0x06, 0x47, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x12, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65,
-	0x70, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x17, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x41, 0x75, 0x74, 0x68,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x04, 0x4c, 0x69, 0x73, 0x74,
-	0x12, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70,
-	0x70, 0x61, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x37, 0x0a, 0x06, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x12, 0x15, 0x2e, 0x67, 0x6f, 0x6b,
-	0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69,
-	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x06, 0x52, 0x65, 0x6e,
-	0x61, 0x6d, 0x65, 0x12, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e,
-	0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6b,
-	0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x37, 0x0a, 0x06, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x15, 0x2e, 0x67,
-	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e,
-	0x42, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x04, 0x43,
-	0x6f, 0x70, 0x79, 0x12, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e,
-	0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6b,
-	0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x42, 0x2e, 0x5a, 0x2c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
-	0x2f, 0x68, 0x72, 0x61, 0x70, 0x6f, 0x76, 0x64, 0x31, 0x2f, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70,
-	0x70, 0x61, 0x73, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *ShareRequest) GetType() Type {
+	if x != nil {
+		return x.Type
+	}
+	return Type_TEXT
 }
 
-var (
-	file_internal_proto_gokeepas_proto_rawDescOnce sync.Once
-	file_internal_proto_gokeepas_proto_rawDescData = file_internal_proto_gokeepas_proto_rawDesc
-)
+func (x *ShareRequest) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
 
-func file_internal_proto_gokeepas_proto_rawDescGZIP() []byte {
-	file_internal_proto_gokeepas_proto_rawDescOnce.Do(func() {
-		file_internal_proto_gokeepas_proto_rawDescData = protoimpl.X.CompressGZIP(file_internal_proto_gokeepas_proto_rawDescData)
-	})
-	return file_internal_proto_gokeepas_proto_rawDescData
-}
-
-var file_internal_proto_gokeepas_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_internal_proto_gokeepas_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
-var file_internal_proto_gokeepas_proto_goTypes = []interface{}{
-	(Type)(0),            // 0: gokeepas.Type
-	(*AuthRequest)(nil),  // 1: gokeepas.AuthRequest
-	(*AuthResponse)(nil), // 2: gokeepas.AuthResponse
-	(*BinRequest)(nil),   // 3: gokeepas.BinRequest
-	(*BinResponse)(nil),  // 4: gokeepas.BinResponse
-	(*GetResponse)(nil),  // 5: gokeepas.GetResponse
-	(*ListResponse)(nil), // 6: gokeepas.ListResponse
-}
-var file_internal_proto_gokeepas_proto_depIdxs = []int32{
-	0,  // 0: gokeepas.BinRequest.type:type_name -> gokeepas.Type
-	0,  // 1: gokeepas.GetResponse.type:type_name -> gokeepas.Type
-	1,  // 2: gokeepas.KeepPas.SignUp:input_type -> gokeepas.AuthRequest
-	1,  // 3: gokeepas.KeepPas.LogIn:input_type -> gokeepas.AuthRequest
-	3,  // 4: gokeepas.KeepPas.Add:input_type -> gokeepas.BinRequest
-	3,  // 5: gokeepas.KeepPas.Get:input_type -> gokeepas.BinRequest
-	3,  // 6: gokeepas.KeepPas.GetKey:input_type -> gokeepas.BinRequest
-	3,  // 7: gokeepas.KeepPas.List:input_type -> gokeepas.BinRequest
-	3,  // 8: gokeepas.KeepPas.Remove:input_type -> gokeepas.BinRequest
-	3,  // 9: gokeepas.KeepPas.Rename:input_type -> gokeepas.BinRequest
-	3,  // 10: gokeepas.KeepPas.Update:input_type -> gokeepas.BinRequest
-	3,  // 11: gokeepas.KeepPas.Copy:input_type -> gokeepas.BinRequest
-	2,  // 12: gokeepas.KeepPas.SignUp:output_type -> gokeepas.AuthResponse
-	2,  // 13: gokeepas.KeepPas.LogIn:output_type -> gokeepas.AuthResponse
-	4,  // 14: gokeepas.KeepPas.Add:output_type -> gokeepas.BinResponse
-	5,  // 15: gokeepas.KeepPas.Get:output_type -> gokeepas.GetResponse
-	2,  // 16: gokeepas.KeepPas.GetKey:output_type -> gokeepas.AuthResponse
-	6,  // 17: gokeepas.KeepPas.List:output_type -> gokeepas.ListResponse
-	4,  // 18: gokeepas.KeepPas.Remove:output_type -> gokeepas.BinResponse
-	4,  // 19: gokeepas.KeepPas.Rename:output_type -> gokeepas.BinResponse
-	4,  // 20: gokeepas.KeepPas.Update:output_type -> gokeepas.BinResponse
-	4,  // 21: gokeepas.KeepPas.Copy:output_type -> gokeepas.BinResponse
-	12, // [12:22] is the sub-list for method output_type
-	2,  // [2:12] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
-}
-
-func init() { file_internal_proto_gokeepas_proto_init() }
-func file_internal_proto_gokeepas_proto_init() {
-	if File_internal_proto_gokeepas_proto != nil {
-		return
+func (x *ShareRequest) GetDataKey() string {
+	if x != nil {
+		return x.DataKey
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_internal_proto_gokeepas_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AuthRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+	return ""
+}
+
+type SharedSecret struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Owner    string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`                   // login of secret owner
+	Key      string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`                       // key of secret in owner's storage
+	Type     Type   `protobuf:"varint,3,opt,name=type,proto3,enum=gokeepas.Type" json:"type,omitempty"` // type of secret
+	Data     string `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`                     // secret sealed to recipient's public key
+	ReadOnly bool   `protobuf:"varint,5,opt,name=readOnly,proto3" json:"readOnly,omitempty"`
+	DataKey  string `protobuf:"bytes,6,opt,name=dataKey,proto3" json:"dataKey,omitempty"` // data key of secret sealed to recipient's public key, data is encrypted with it
+}
+
+func (x *SharedSecret) Reset() {
+	*x = SharedSecret{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SharedSecret) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SharedSecret) ProtoMessage() {}
+
+func (x *SharedSecret) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_internal_proto_gokeepas_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AuthResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SharedSecret.ProtoReflect.Descriptor instead.
+func (*SharedSecret) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SharedSecret) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *SharedSecret) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SharedSecret) GetType() Type {
+	if x != nil {
+		return x.Type
+	}
+	return Type_TEXT
+}
+
+func (x *SharedSecret) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *SharedSecret) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+func (x *SharedSecret) GetDataKey() string {
+	if x != nil {
+		return x.DataKey
+	}
+	return ""
+}
+
+type SharedList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Secrets []*SharedSecret `protobuf:"bytes,1,rep,name=secrets,proto3" json:"secrets,omitempty"`
+}
+
+func (x *SharedList) Reset() {
+	*x = SharedList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SharedList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SharedList) ProtoMessage() {}
+
+func (x *SharedList) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SharedList.ProtoReflect.Descriptor instead.
+func (*SharedList) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SharedList) GetSecrets() []*SharedSecret {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
+type VaultRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Vault    string `protobuf:"bytes,1,opt,name=vault,proto3" json:"vault,omitempty"`       // name of team vault
+	VaultKey string `protobuf:"bytes,2,opt,name=vaultKey,proto3" json:"vaultKey,omitempty"` // vault key sealed to caller's public key, in CreateVault
+}
+
+func (x *VaultRequest) Reset() {
+	*x = VaultRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VaultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultRequest) ProtoMessage() {}
+
+func (x *VaultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultRequest.ProtoReflect.Descriptor instead.
+func (*VaultRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *VaultRequest) GetVault() string {
+	if x != nil {
+		return x.Vault
+	}
+	return ""
+}
+
+func (x *VaultRequest) GetVaultKey() string {
+	if x != nil {
+		return x.VaultKey
+	}
+	return ""
+}
+
+type VaultKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VaultKey string `protobuf:"bytes,1,opt,name=vaultKey,proto3" json:"vaultKey,omitempty"` // vault key sealed to caller's public key
+	Version  int64  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`  // version of vault key, it is increased on rotation
+	Role     string `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`         // caller's role in vault
+}
+
+func (x *VaultKey) Reset() {
+	*x = VaultKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VaultKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultKey) ProtoMessage() {}
+
+func (x *VaultKey) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultKey.ProtoReflect.Descriptor instead.
+func (*VaultKey) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *VaultKey) GetVaultKey() string {
+	if x != nil {
+		return x.VaultKey
+	}
+	return ""
+}
+
+func (x *VaultKey) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *VaultKey) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type VaultMember struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Login    string `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`       // login of member
+	Role     string `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`         // admin | write | read
+	VaultKey string `protobuf:"bytes,3,opt,name=vaultKey,proto3" json:"vaultKey,omitempty"` // vault key sealed to member's public key
+}
+
+func (x *VaultMember) Reset() {
+	*x = VaultMember{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VaultMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultMember) ProtoMessage() {}
+
+func (x *VaultMember) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultMember.ProtoReflect.Descriptor instead.
+func (*VaultMember) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *VaultMember) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+func (x *VaultMember) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *VaultMember) GetVaultKey() string {
+	if x != nil {
+		return x.VaultKey
+	}
+	return ""
+}
+
+type VaultMembers struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Members []*VaultMember `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	Version int64          `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"` // version of vault key
+}
+
+func (x *VaultMembers) Reset() {
+	*x = VaultMembers{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VaultMembers) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultMembers) ProtoMessage() {}
+
+func (x *VaultMembers) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultMembers.ProtoReflect.Descriptor instead.
+func (*VaultMembers) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *VaultMembers) GetMembers() []*VaultMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *VaultMembers) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type VaultInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Vault string `protobuf:"bytes,1,opt,name=vault,proto3" json:"vault,omitempty"` // name of team vault
+	Role  string `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`   // caller's role in vault
+}
+
+func (x *VaultInfo) Reset() {
+	*x = VaultInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VaultInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultInfo) ProtoMessage() {}
+
+func (x *VaultInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultInfo.ProtoReflect.Descriptor instead.
+func (*VaultInfo) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *VaultInfo) GetVault() string {
+	if x != nil {
+		return x.Vault
+	}
+	return ""
+}
+
+func (x *VaultInfo) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type VaultList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Vaults []*VaultInfo `protobuf:"bytes,1,rep,name=vaults,proto3" json:"vaults,omitempty"`
+}
+
+func (x *VaultList) Reset() {
+	*x = VaultList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VaultList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultList) ProtoMessage() {}
+
+func (x *VaultList) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultList.ProtoReflect.Descriptor instead.
+func (*VaultList) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *VaultList) GetVaults() []*VaultInfo {
+	if x != nil {
+		return x.Vaults
+	}
+	return nil
+}
+
+type VaultMemberRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Vault   string       `protobuf:"bytes,1,opt,name=vault,proto3" json:"vault,omitempty"` // name of team vault
+	Member  *VaultMember `protobuf:"bytes,2,opt,name=member,proto3" json:"member,omitempty"`
+	Version int64        `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"` // version of vault key sealed in member.vaultKey
+}
+
+func (x *VaultMemberRequest) Reset() {
+	*x = VaultMemberRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VaultMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultMemberRequest) ProtoMessage() {}
+
+func (x *VaultMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultMemberRequest.ProtoReflect.Descriptor instead.
+func (*VaultMemberRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *VaultMemberRequest) GetVault() string {
+	if x != nil {
+		return x.Vault
+	}
+	return ""
+}
+
+func (x *VaultMemberRequest) GetMember() *VaultMember {
+	if x != nil {
+		return x.Member
+	}
+	return nil
+}
+
+func (x *VaultMemberRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type VaultRotateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Vault   string         `protobuf:"bytes,1,opt,name=vault,proto3" json:"vault,omitempty"`      // name of team vault
+	Removed string         `protobuf:"bytes,2,opt,name=removed,proto3" json:"removed,omitempty"`  // login of removed member
+	Version int64          `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"` // current version of vault key
+	Members []*VaultMember `protobuf:"bytes,4,rep,name=members,proto3" json:"members,omitempty"`  // new vault key sealed to each of remaining members
+	Secrets []*BinRequest  `protobuf:"bytes,5,rep,name=secrets,proto3" json:"secrets,omitempty"`  // all vault secrets encrypted with new vault key
+}
+
+func (x *VaultRotateRequest) Reset() {
+	*x = VaultRotateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VaultRotateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultRotateRequest) ProtoMessage() {}
+
+func (x *VaultRotateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultRotateRequest.ProtoReflect.Descriptor instead.
+func (*VaultRotateRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *VaultRotateRequest) GetVault() string {
+	if x != nil {
+		return x.Vault
+	}
+	return ""
+}
+
+func (x *VaultRotateRequest) GetRemoved() string {
+	if x != nil {
+		return x.Removed
+	}
+	return ""
+}
+
+func (x *VaultRotateRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *VaultRotateRequest) GetMembers() []*VaultMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *VaultRotateRequest) GetSecrets() []*BinRequest {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
+type VaultSecrets struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Secrets []*GetResponse `protobuf:"bytes,1,rep,name=secrets,proto3" json:"secrets,omitempty"`
+	Version int64          `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"` // version of vault key
+}
+
+func (x *VaultSecrets) Reset() {
+	*x = VaultSecrets{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VaultSecrets) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VaultSecrets) ProtoMessage() {}
+
+func (x *VaultSecrets) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VaultSecrets.ProtoReflect.Descriptor instead.
+func (*VaultSecrets) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *VaultSecrets) GetSecrets() []*GetResponse {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
+func (x *VaultSecrets) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type BinRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data    string `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`                     // encrypted data with symm key
+	Key     string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`                       // key of value
+	Type    Type   `protobuf:"varint,3,opt,name=type,proto3,enum=gokeepas.Type" json:"type,omitempty"` // type of value
+	NewKey  string `protobuf:"bytes,4,opt,name=newKey,proto3" json:"newKey,omitempty"`                 // new key value
+	DataKey string `protobuf:"bytes,5,opt,name=dataKey,proto3" json:"dataKey,omitempty"`               // data key of secret wrapped by symm key, data is encrypted with it
+}
+
+func (x *BinRequest) Reset() {
+	*x = BinRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BinRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BinRequest) ProtoMessage() {}
+
+func (x *BinRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BinRequest.ProtoReflect.Descriptor instead.
+func (*BinRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *BinRequest) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *BinRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *BinRequest) GetType() Type {
+	if x != nil {
+		return x.Type
+	}
+	return Type_TEXT
+}
+
+func (x *BinRequest) GetNewKey() string {
+	if x != nil {
+		return x.NewKey
+	}
+	return ""
+}
+
+func (x *BinRequest) GetDataKey() string {
+	if x != nil {
+		return x.DataKey
+	}
+	return ""
+}
+
+type BinResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Error string `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *BinResponse) Reset() {
+	*x = BinResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BinResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BinResponse) ProtoMessage() {}
+
+func (x *BinResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BinResponse.ProtoReflect.Descriptor instead.
+func (*BinResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *BinResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data    []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`                     // encrypted data with symm key
+	Key     string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`                       // key of value
+	Type    Type   `protobuf:"varint,3,opt,name=type,proto3,enum=gokeepas.Type" json:"type,omitempty"` // type of value
+	DataKey string `protobuf:"bytes,4,opt,name=dataKey,proto3" json:"dataKey,omitempty"`               // data key of secret wrapped by symm key, data is encrypted with it
+}
+
+func (x *GetResponse) Reset() {
+	*x = GetResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResponse) ProtoMessage() {}
+
+func (x *GetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResponse.ProtoReflect.Descriptor instead.
+func (*GetResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *GetResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *GetResponse) GetType() Type {
+	if x != nil {
+		return x.Type
+	}
+	return Type_TEXT
+}
+
+func (x *GetResponse) GetDataKey() string {
+	if x != nil {
+		return x.DataKey
+	}
+	return ""
+}
+
+type BatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items []*BinRequest `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // secrets of GetMany, AddMany, RemoveMany, only keys are used in GetMany and RemoveMany
+}
+
+func (x *BatchRequest) Reset() {
+	*x = BatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchRequest) ProtoMessage() {}
+
+func (x *BatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchRequest.ProtoReflect.Descriptor instead.
+func (*BatchRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *BatchRequest) GetItems() []*BinRequest {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type BatchItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key    string       `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`       // key of value
+	Code   int32        `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`    // grpc status code of item, 0 is OK
+	Error  string       `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`   // error of item when code isn't OK
+	Secret *GetResponse `protobuf:"bytes,4,opt,name=secret,proto3" json:"secret,omitempty"` // secret of GetMany
+}
+
+func (x *BatchItem) Reset() {
+	*x = BatchItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchItem) ProtoMessage() {}
+
+func (x *BatchItem) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchItem.ProtoReflect.Descriptor instead.
+func (*BatchItem) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *BatchItem) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *BatchItem) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *BatchItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *BatchItem) GetSecret() *GetResponse {
+	if x != nil {
+		return x.Secret
+	}
+	return nil
+}
+
+type BatchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items []*BatchItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // status of each item in order of request
+}
+
+func (x *BatchResponse) Reset() {
+	*x = BatchResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchResponse) ProtoMessage() {}
+
+func (x *BatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchResponse.ProtoReflect.Descriptor instead.
+func (*BatchResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *BatchResponse) GetItems() []*BatchItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"` // name prefix of watched secrets, empty means all secrets
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *WatchRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type WatchEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Kind   WatchEvent_Kind `protobuf:"varint,1,opt,name=kind,proto3,enum=gokeepas.WatchEvent_Kind" json:"kind,omitempty"` // kind of change
+	Key    string          `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`                                  // key of changed secret, old key in RENAME
+	NewKey string          `protobuf:"bytes,3,opt,name=newKey,proto3" json:"newKey,omitempty"`                            // new key in RENAME
+	Type   Type            `protobuf:"varint,4,opt,name=type,proto3,enum=gokeepas.Type" json:"type,omitempty"`            // type of secret, it isn't known in REMOVE
+	Time   int64           `protobuf:"varint,5,opt,name=time,proto3" json:"time,omitempty"`                               // time of change, unix milliseconds
+	Seq    int64           `protobuf:"varint,6,opt,name=seq,proto3" json:"seq,omitempty"`                                 // sequence number of change in change log of secrets owner
+}
+
+func (x *WatchEvent) Reset() {
+	*x = WatchEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEvent) ProtoMessage() {}
+
+func (x *WatchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEvent.ProtoReflect.Descriptor instead.
+func (*WatchEvent) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *WatchEvent) GetKind() WatchEvent_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return WatchEvent_ADD
+}
+
+func (x *WatchEvent) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *WatchEvent) GetNewKey() string {
+	if x != nil {
+		return x.NewKey
+	}
+	return ""
+}
+
+func (x *WatchEvent) GetType() Type {
+	if x != nil {
+		return x.Type
+	}
+	return Type_TEXT
+}
+
+func (x *WatchEvent) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *WatchEvent) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+type ChangesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Since int64 `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"` // sequence number of last applied change, 0 means no changes are applied
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // max count of changes in response, 0 means server default
+}
+
+func (x *ChangesRequest) Reset() {
+	*x = ChangesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangesRequest) ProtoMessage() {}
+
+func (x *ChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangesRequest.ProtoReflect.Descriptor instead.
+func (*ChangesRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ChangesRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *ChangesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ChangesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Changes []*WatchEvent `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"` // changes after since ordered by seq
+	Seq     int64         `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`        // sequence number of last change in change log
+	Reset_  bool          `protobuf:"varint,3,opt,name=reset,proto3" json:"reset,omitempty"`    // changes after since aren't kept anymore, client must get all secrets again
+	More    bool          `protobuf:"varint,4,opt,name=more,proto3" json:"more,omitempty"`      // there are more changes, repeat request with since of last change
+}
+
+func (x *ChangesResponse) Reset() {
+	*x = ChangesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChangesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangesResponse) ProtoMessage() {}
+
+func (x *ChangesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangesResponse.ProtoReflect.Descriptor instead.
+func (*ChangesResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ChangesResponse) GetChanges() []*WatchEvent {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+func (x *ChangesResponse) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *ChangesResponse) GetReset_() bool {
+	if x != nil {
+		return x.Reset_
+	}
+	return false
+}
+
+func (x *ChangesResponse) GetMore() bool {
+	if x != nil {
+		return x.More
+	}
+	return false
+}
+
+type ListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Prefix    string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`                          // name prefix of secrets
+	Types     []Type `protobuf:"varint,2,rep,packed,name=types,proto3,enum=gokeepas.Type" json:"types,omitempty"` // types of secrets, empty means all types
+	PageSize  int32  `protobuf:"varint,3,opt,name=pageSize,proto3" json:"pageSize,omitempty"`                     // max count of entries in response, 0 means all
+	PageToken string `protobuf:"bytes,4,opt,name=pageToken,proto3" json:"pageToken,omitempty"`                    // nextPageToken of previous response
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ListRequest) GetTypes() []Type {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *ListRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name    string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                     // key of secret
+	Type    Type   `protobuf:"varint,2,opt,name=type,proto3,enum=gokeepas.Type" json:"type,omitempty"` // type of secret
+	DataKey bool   `protobuf:"varint,3,opt,name=dataKey,proto3" json:"dataKey,omitempty"`              // secret is encrypted with its own data key
+}
+
+func (x *ListEntry) Reset() {
+	*x = ListEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEntry) ProtoMessage() {}
+
+func (x *ListEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEntry.ProtoReflect.Descriptor instead.
+func (*ListEntry) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ListEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ListEntry) GetType() Type {
+	if x != nil {
+		return x.Type
+	}
+	return Type_TEXT
+}
+
+func (x *ListEntry) GetDataKey() bool {
+	if x != nil {
+		return x.DataKey
+	}
+	return false
+}
+
+type ListResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Keys          string       `protobuf:"bytes,1,opt,name=keys,proto3" json:"keys,omitempty"`                   // deprecated, keys of entries quoted and joined by comma: 'key1','key2'
+	Entries       []*ListEntry `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty"`             // secrets sorted by name
+	NextPageToken string       `protobuf:"bytes,3,opt,name=nextPageToken,proto3" json:"nextPageToken,omitempty"` // token of next page, empty on last page
+}
+
+func (x *ListResponse) Reset() {
+	*x = ListResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListResponse) ProtoMessage() {}
+
+func (x *ListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListResponse.ProtoReflect.Descriptor instead.
+func (*ListResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ListResponse) GetKeys() string {
+	if x != nil {
+		return x.Keys
+	}
+	return ""
+}
+
+func (x *ListResponse) GetEntries() []*ListEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *ListResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type AuditLogRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Since int64 `protobuf:"varint,1,opt,name=since,proto3" json:"since,omitempty"` // unix milliseconds, events after it are returned
+	Limit int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // max count of last events in response, 0 means server default
+}
+
+func (x *AuditLogRequest) Reset() {
+	*x = AuditLogRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AuditLogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditLogRequest) ProtoMessage() {}
+
+func (x *AuditLogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditLogRequest.ProtoReflect.Descriptor instead.
+func (*AuditLogRequest) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *AuditLogRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *AuditLogRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type AuditEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Login  string `protobuf:"bytes,1,opt,name=login,proto3" json:"login,omitempty"`   // login of caller, empty when it isn't known
+	Method string `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"` // full name of rpc method
+	Key    string `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`       // key of secret, keys of batch are joined by comma
+	Code   string `protobuf:"bytes,4,opt,name=code,proto3" json:"code,omitempty"`     // grpc status code of result
+	Peer   string `protobuf:"bytes,5,opt,name=peer,proto3" json:"peer,omitempty"`     // address of client
+	Time   int64  `protobuf:"varint,6,opt,name=time,proto3" json:"time,omitempty"`    // unix milliseconds
+}
+
+func (x *AuditEvent) Reset() {
+	*x = AuditEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AuditEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEvent) ProtoMessage() {}
+
+func (x *AuditEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEvent.ProtoReflect.Descriptor instead.
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *AuditEvent) GetLogin() string {
+	if x != nil {
+		return x.Login
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetPeer() string {
+	if x != nil {
+		return x.Peer
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+type AuditLogResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Events []*AuditEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"` // events ordered by time
+}
+
+func (x *AuditLogResponse) Reset() {
+	*x = AuditLogResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_proto_gokeeppas_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AuditLogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditLogResponse) ProtoMessage() {}
+
+func (x *AuditLogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_proto_gokeeppas_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditLogResponse.ProtoReflect.Descriptor instead.
+func (*AuditLogResponse) Descriptor() ([]byte, []int) {
+	return file_internal_proto_gokeeppas_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *AuditLogResponse) GetEvents() []*AuditEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+var File_internal_proto_gokeeppas_proto protoreflect.FileDescriptor
+
+var file_internal_proto_gokeeppas_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x70, 0x61, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x08, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x22, 0x51, 0x0a, 0x0b, 0x41, 0x75,
+	0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67,
+	0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x12,
+	0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x6f,
+	0x74, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6f, 0x74, 0x70, 0x22, 0x96, 0x01,
+	0x0a, 0x0c, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x73, 0x79, 0x6d, 0x6d, 0x4b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x07, 0x73, 0x79, 0x6d, 0x6d, 0x4b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x75, 0x74, 0x68,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x75, 0x74,
+	0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x22, 0x0a, 0x0c,
+	0x72, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x72, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x22, 0x34, 0x0a, 0x0e, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73,
+	0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x72, 0x65, 0x66, 0x72,
+	0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x72, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x20, 0x0a, 0x08,
+	0x4b, 0x65, 0x79, 0x53, 0x68, 0x61, 0x72, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x68, 0x61, 0x72,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x68, 0x61, 0x72, 0x65, 0x22, 0x5e,
+	0x0a, 0x0a, 0x53, 0x65, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x65, 0x61, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x65,
+	0x61, 0x6c, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f,
+	0x6c, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x22, 0x22,
+	0x0a, 0x0c, 0x54, 0x77, 0x6f, 0x46, 0x41, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f,
+	0x64, 0x65, 0x22, 0x47, 0x0a, 0x0d, 0x54, 0x77, 0x6f, 0x46, 0x41, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x75, 0x72, 0x69, 0x12, 0x24, 0x0a, 0x0d, 0x72, 0x65, 0x63, 0x6f, 0x76, 0x65, 0x72,
+	0x79, 0x43, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x72, 0x65,
+	0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x43, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x47, 0x0a, 0x07, 0x4b,
+	0x65, 0x79, 0x50, 0x61, 0x69, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63,
+	0x4b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69,
+	0x63, 0x4b, 0x65, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4b,
+	0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74,
+	0x65, 0x4b, 0x65, 0x79, 0x22, 0xac, 0x01, 0x0a, 0x0c, 0x53, 0x68, 0x61, 0x72, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x63, 0x69, 0x70,
+	0x69, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x63, 0x69,
+	0x70, 0x69, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x22, 0x0a, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x61, 0x73, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x72, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x08, 0x72, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x61, 0x74,
+	0x61, 0x4b, 0x65, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x64, 0x61, 0x74, 0x61,
+	0x4b, 0x65, 0x79, 0x22, 0xa4, 0x01, 0x0a, 0x0c, 0x53, 0x68, 0x61, 0x72, 0x65, 0x64, 0x53, 0x65,
+	0x63, 0x72, 0x65, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x22, 0x0a, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x67, 0x6f, 0x6b,
+	0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79,
+	0x12, 0x18, 0x0a, 0x07, 0x64, 0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x64, 0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x22, 0x3e, 0x0a, 0x0a, 0x53, 0x68,
+	0x61, 0x72, 0x65, 0x64, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x30, 0x0a, 0x07, 0x73, 0x65, 0x63, 0x72,
+	0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65,
+	0x65, 0x70, 0x61, 0x73, 0x2e, 0x53, 0x68, 0x61, 0x72, 0x65, 0x64, 0x53, 0x65, 0x63, 0x72, 0x65,
+	0x74, 0x52, 0x07, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x22, 0x40, 0x0a, 0x0c, 0x56, 0x61,
+	0x75, 0x6c, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x75, 0x6c, 0x74,
+	0x12, 0x1a, 0x0a, 0x08, 0x76, 0x61, 0x75, 0x6c, 0x74, 0x4b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x76, 0x61, 0x75, 0x6c, 0x74, 0x4b, 0x65, 0x79, 0x22, 0x54, 0x0a, 0x08,
+	0x56, 0x61, 0x75, 0x6c, 0x74, 0x4b, 0x65, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x61, 0x75, 0x6c,
+	0x74, 0x4b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x76, 0x61, 0x75, 0x6c,
+	0x74, 0x4b, 0x65, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x12,
+	0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f,
+	0x6c, 0x65, 0x22, 0x53, 0x0a, 0x0b, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x4d, 0x65, 0x6d, 0x62, 0x65,
+	0x72, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x76,
+	0x61, 0x75, 0x6c, 0x74, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x76,
+	0x61, 0x75, 0x6c, 0x74, 0x4b, 0x65, 0x79, 0x22, 0x59, 0x0a, 0x0c, 0x56, 0x61, 0x75, 0x6c, 0x74,
+	0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x12, 0x2f, 0x0a, 0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65,
+	0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65,
+	0x70, 0x61, 0x73, 0x2e, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x52,
+	0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x22, 0x35, 0x0a, 0x09, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x76, 0x61, 0x75, 0x6c, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x22, 0x38, 0x0a, 0x09, 0x56, 0x61, 0x75,
+	0x6c, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x06, 0x76, 0x61, 0x75, 0x6c, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61,
+	0x73, 0x2e, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x06, 0x76, 0x61, 0x75,
+	0x6c, 0x74, 0x73, 0x22, 0x73, 0x0a, 0x12, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x4d, 0x65, 0x6d, 0x62,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x75,
+	0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x75, 0x6c, 0x74, 0x12,
+	0x2d, 0x0a, 0x06, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x56, 0x61, 0x75, 0x6c, 0x74,
+	0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x18,
+	0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xbf, 0x01, 0x0a, 0x12, 0x56, 0x61, 0x75,
+	0x6c, 0x74, 0x52, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x76, 0x61, 0x75, 0x6c, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x12,
+	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2f, 0x0a, 0x07, 0x6d, 0x65, 0x6d,
+	0x62, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x6f, 0x6b,
+	0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x4d, 0x65, 0x6d, 0x62, 0x65,
+	0x72, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x12, 0x2e, 0x0a, 0x07, 0x73, 0x65,
+	0x63, 0x72, 0x65, 0x74, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f,
+	0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x52, 0x07, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x22, 0x59, 0x0a, 0x0c, 0x56, 0x61,
+	0x75, 0x6c, 0x74, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x12, 0x2f, 0x0a, 0x07, 0x73, 0x65,
+	0x63, 0x72, 0x65, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x67, 0x6f,
+	0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x52, 0x07, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x88, 0x01, 0x0a, 0x0a, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x22, 0x0a, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65,
+	0x70, 0x61, 0x73, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x6e, 0x65, 0x77, 0x4b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x6e, 0x65, 0x77, 0x4b, 0x65, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x61, 0x74, 0x61, 0x4b, 0x65,
+	0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x64, 0x61, 0x74, 0x61, 0x4b, 0x65, 0x79,
+	0x22, 0x23, 0x0a, 0x0b, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x71, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x22, 0x0a, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65,
+	0x70, 0x61, 0x73, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x64, 0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x64, 0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x22, 0x3a, 0x0a, 0x0c, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x05, 0x69,
+	0x74, 0x65, 0x6d, 0x73, 0x22, 0x76, 0x0a, 0x09, 0x42, 0x61, 0x74, 0x63, 0x68, 0x49, 0x74, 0x65,
+	0x6d, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x2d, 0x0a,
+	0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e,
+	0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x47, 0x65, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x52, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x22, 0x3a, 0x0a, 0x0d,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a,
+	0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x67,
+	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x49, 0x74, 0x65,
+	0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x22, 0x26, 0x0a, 0x0c, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78,
+	0x22, 0xe4, 0x01, 0x0a, 0x0a, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12,
+	0x2d, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x2e, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x16, 0x0a, 0x06, 0x6e, 0x65, 0x77, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x6e, 0x65, 0x77, 0x4b, 0x65, 0x79, 0x12, 0x22, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61,
+	0x73, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65,
+	0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x73,
+	0x65, 0x71, 0x22, 0x33, 0x0a, 0x04, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x07, 0x0a, 0x03, 0x41, 0x44,
+	0x44, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x10, 0x01, 0x12,
+	0x0a, 0x0a, 0x06, 0x52, 0x45, 0x4e, 0x41, 0x4d, 0x45, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x52,
+	0x45, 0x4d, 0x4f, 0x56, 0x45, 0x10, 0x03, 0x22, 0x3c, 0x0a, 0x0e, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x69, 0x6e,
+	0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x7d, 0x0a, 0x0f, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x63, 0x68, 0x61, 0x6e,
+	0x67, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65,
+	0x65, 0x70, 0x61, 0x73, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
+	0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x73, 0x65, 0x71, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65,
+	0x73, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x72, 0x65, 0x73, 0x65, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x6d, 0x6f, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04,
+	0x6d, 0x6f, 0x72, 0x65, 0x22, 0x85, 0x01, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x24, 0x0a, 0x05,
+	0x74, 0x79, 0x70, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x67, 0x6f,
+	0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x05, 0x74, 0x79, 0x70,
+	0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1c,
+	0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x5d, 0x0a, 0x09,
+	0x4c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x22, 0x0a,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x67, 0x6f,
+	0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x64, 0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x22, 0x77, 0x0a, 0x0c, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6b,
+	0x65, 0x79, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6b, 0x65, 0x79, 0x73, 0x12,
+	0x2d, 0x0a, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x24,
+	0x0a, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x3d, 0x0a, 0x0f, 0x41, 0x75, 0x64, 0x69, 0x74, 0x4c, 0x6f, 0x67,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x22, 0x88, 0x01, 0x0a, 0x0a, 0x41, 0x75, 0x64, 0x69, 0x74, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x69, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68,
+	0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x65, 0x65, 0x72, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x65, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x22, 0x40,
+	0x0a, 0x10, 0x41, 0x75, 0x64, 0x69, 0x74, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x2c, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x41, 0x75,
+	0x64, 0x69, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73,
+	0x2a, 0x3a, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x08, 0x0a, 0x04, 0x54, 0x45, 0x58, 0x54,
+	0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x42, 0x49, 0x4e, 0x41, 0x52, 0x59, 0x10, 0x01, 0x12, 0x09,
+	0x0a, 0x05, 0x4c, 0x4f, 0x47, 0x49, 0x4e, 0x10, 0x02, 0x12, 0x08, 0x0a, 0x04, 0x43, 0x41, 0x52,
+	0x54, 0x10, 0x03, 0x12, 0x07, 0x0a, 0x03, 0x4f, 0x54, 0x50, 0x10, 0x04, 0x32, 0x9d, 0x11, 0x0a,
+	0x07, 0x4b, 0x65, 0x65, 0x70, 0x50, 0x61, 0x73, 0x12, 0x32, 0x0a, 0x06, 0x55, 0x6e, 0x73, 0x65,
+	0x61, 0x6c, 0x12, 0x12, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x4b, 0x65,
+	0x79, 0x53, 0x68, 0x61, 0x72, 0x65, 0x1a, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61,
+	0x73, 0x2e, 0x53, 0x65, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x30, 0x0a, 0x04,
+	0x53, 0x65, 0x61, 0x6c, 0x12, 0x12, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e,
+	0x4b, 0x65, 0x79, 0x53, 0x68, 0x61, 0x72, 0x65, 0x1a, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65,
+	0x70, 0x61, 0x73, 0x2e, 0x53, 0x65, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x37,
+	0x0a, 0x06, 0x53, 0x69, 0x67, 0x6e, 0x55, 0x70, 0x12, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65,
+	0x70, 0x61, 0x73, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x05, 0x4c, 0x6f, 0x67, 0x49, 0x6e,
+	0x12, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x41, 0x75, 0x74, 0x68,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x61, 0x73, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x3b, 0x0a, 0x07, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x12, 0x18, 0x2e, 0x67, 0x6f, 0x6b,
+	0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e,
+	0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a, 0x06,
+	0x4c, 0x6f, 0x67, 0x4f, 0x75, 0x74, 0x12, 0x18, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61,
+	0x73, 0x2e, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3a, 0x0a, 0x09, 0x45, 0x6e, 0x61, 0x62, 0x6c,
+	0x65, 0x32, 0x46, 0x41, 0x12, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e,
+	0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x67, 0x6f, 0x6b,
+	0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x54, 0x77, 0x6f, 0x46, 0x41, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x0a, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x32, 0x46,
+	0x41, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x54, 0x77, 0x6f,
+	0x46, 0x41, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x67, 0x6f, 0x6b, 0x65,
+	0x65, 0x70, 0x61, 0x73, 0x2e, 0x54, 0x77, 0x6f, 0x46, 0x41, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x3b, 0x0a, 0x0a, 0x44, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x32, 0x46, 0x41,
+	0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x54, 0x77, 0x6f, 0x46,
+	0x41, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65,
+	0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x32, 0x0a, 0x03, 0x41, 0x64, 0x64, 0x12, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61,
+	0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x67,
+	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x14, 0x2e, 0x67, 0x6f, 0x6b,
+	0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x47, 0x65, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x4b, 0x65,
+	0x79, 0x12, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x61, 0x73, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x35, 0x0a, 0x04, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x61, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16,
+	0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x05, 0x57, 0x61, 0x74, 0x63, 0x68, 0x12,
+	0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x61, 0x73, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x12,
+	0x3e, 0x0a, 0x07, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x12, 0x18, 0x2e, 0x67, 0x6f, 0x6b,
+	0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e,
+	0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x35, 0x0a, 0x06, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x12, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65,
+	0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x06, 0x52, 0x65, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61,
+	0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a,
+	0x06, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e,
+	0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a, 0x04, 0x43, 0x6f, 0x70, 0x79, 0x12, 0x14, 0x2e, 0x67,
+	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69,
+	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3a, 0x0a, 0x07, 0x47, 0x65, 0x74,
+	0x4d, 0x61, 0x6e, 0x79, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x67,
+	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3a, 0x0a, 0x07, 0x41, 0x64, 0x64, 0x4d, 0x61, 0x6e, 0x79,
+	0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65,
+	0x70, 0x61, 0x73, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x3d, 0x0a, 0x0a, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x4d, 0x61, 0x6e, 0x79, 0x12,
+	0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70,
+	0x61, 0x73, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x36, 0x0a, 0x0a, 0x53, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x50, 0x61, 0x69, 0x72, 0x12, 0x11,
+	0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x4b, 0x65, 0x79, 0x50, 0x61, 0x69,
+	0x72, 0x1a, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x4b,
+	0x65, 0x79, 0x50, 0x61, 0x69, 0x72, 0x12, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61,
+	0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x67,
+	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x4b, 0x65, 0x79, 0x50, 0x61, 0x69, 0x72, 0x12,
+	0x37, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12,
+	0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73,
+	0x2e, 0x4b, 0x65, 0x79, 0x50, 0x61, 0x69, 0x72, 0x12, 0x36, 0x0a, 0x05, 0x53, 0x68, 0x61, 0x72,
+	0x65, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x53, 0x68, 0x61,
+	0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65,
+	0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x38, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x68, 0x61, 0x72, 0x65, 0x64, 0x12, 0x14,
+	0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e,
+	0x53, 0x68, 0x61, 0x72, 0x65, 0x64, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x38, 0x0a, 0x07, 0x55, 0x6e,
+	0x73, 0x68, 0x61, 0x72, 0x65, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73,
+	0x2e, 0x53, 0x68, 0x61, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e,
+	0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3c, 0x0a, 0x0b, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x56, 0x61,
+	0x75, 0x6c, 0x74, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x56,
+	0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x67, 0x6f,
+	0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x39, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x4b, 0x65,
+	0x79, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x56, 0x61, 0x75,
+	0x6c, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x67, 0x6f, 0x6b, 0x65,
+	0x65, 0x70, 0x61, 0x73, 0x2e, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x4b, 0x65, 0x79, 0x12, 0x37, 0x0a,
+	0x0a, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x14, 0x2e, 0x67, 0x6f,
+	0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x13, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x56, 0x61, 0x75,
+	0x6c, 0x74, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x42, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x61,
+	0x75, 0x6c, 0x74, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6b,
+	0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x56, 0x61,
+	0x75, 0x6c, 0x74, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x12, 0x45, 0x0a, 0x0e, 0x41, 0x64,
+	0x64, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1c, 0x2e, 0x67,
+	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x4d, 0x65, 0x6d,
+	0x62, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x67, 0x6f, 0x6b,
+	0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x46, 0x0a, 0x0f, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x56, 0x61, 0x75, 0x6c, 0x74,
+	0x52, 0x6f, 0x6c, 0x65, 0x12, 0x1c, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e,
+	0x56, 0x61, 0x75, 0x6c, 0x74, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x15, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69,
+	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x48, 0x0a, 0x11, 0x52, 0x65, 0x6d,
+	0x6f, 0x76, 0x65, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1c,
+	0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x52,
+	0x6f, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x67,
+	0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x42, 0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x53,
+	0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61,
+	0x73, 0x2e, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16,
+	0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x56, 0x61, 0x75, 0x6c, 0x74, 0x53,
+	0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x12, 0x44, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x41, 0x75, 0x64,
+	0x69, 0x74, 0x4c, 0x6f, 0x67, 0x12, 0x19, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73,
+	0x2e, 0x41, 0x75, 0x64, 0x69, 0x74, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1a, 0x2e, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2e, 0x41, 0x75, 0x64, 0x69,
+	0x74, 0x4c, 0x6f, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2d, 0x5a, 0x2b,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x72, 0x61, 0x70, 0x6f,
+	0x76, 0x64, 0x31, 0x2f, 0x67, 0x6f, 0x6b, 0x65, 0x65, 0x70, 0x61, 0x73, 0x2f, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_internal_proto_gokeeppas_proto_rawDescOnce sync.Once
+	file_internal_proto_gokeeppas_proto_rawDescData = file_internal_proto_gokeeppas_proto_rawDesc
+)
+
+func file_internal_proto_gokeeppas_proto_rawDescGZIP() []byte {
+	file_internal_proto_gokeeppas_proto_rawDescOnce.Do(func() {
+		file_internal_proto_gokeeppas_proto_rawDescData = protoimpl.X.CompressGZIP(file_internal_proto_gokeeppas_proto_rawDescData)
+	})
+	return file_internal_proto_gokeeppas_proto_rawDescData
+}
+
+var file_internal_proto_gokeeppas_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_internal_proto_gokeeppas_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
+var file_internal_proto_gokeeppas_proto_goTypes = []interface{}{
+	(Type)(0),                  // 0: gokeepas.Type
+	(WatchEvent_Kind)(0),       // 1: gokeepas.WatchEvent.Kind
+	(*AuthRequest)(nil),        // 2: gokeepas.AuthRequest
+	(*AuthResponse)(nil),       // 3: gokeepas.AuthResponse
+	(*RefreshRequest)(nil),     // 4: gokeepas.RefreshRequest
+	(*KeyShare)(nil),           // 5: gokeepas.KeyShare
+	(*SealStatus)(nil),         // 6: gokeepas.SealStatus
+	(*TwoFARequest)(nil),       // 7: gokeepas.TwoFARequest
+	(*TwoFAResponse)(nil),      // 8: gokeepas.TwoFAResponse
+	(*KeyPair)(nil),            // 9: gokeepas.KeyPair
+	(*ShareRequest)(nil),       // 10: gokeepas.ShareRequest
+	(*SharedSecret)(nil),       // 11: gokeepas.SharedSecret
+	(*SharedList)(nil),         // 12: gokeepas.SharedList
+	(*VaultRequest)(nil),       // 13: gokeepas.VaultRequest
+	(*VaultKey)(nil),           // 14: gokeepas.VaultKey
+	(*VaultMember)(nil),        // 15: gokeepas.VaultMember
+	(*VaultMembers)(nil),       // 16: gokeepas.VaultMembers
+	(*VaultInfo)(nil),          // 17: gokeepas.VaultInfo
+	(*VaultList)(nil),          // 18: gokeepas.VaultList
+	(*VaultMemberRequest)(nil), // 19: gokeepas.VaultMemberRequest
+	(*VaultRotateRequest)(nil), // 20: gokeepas.VaultRotateRequest
+	(*VaultSecrets)(nil),       // 21: gokeepas.VaultSecrets
+	(*BinRequest)(nil),         // 22: gokeepas.BinRequest
+	(*BinResponse)(nil),        // 23: gokeepas.BinResponse
+	(*GetResponse)(nil),        // 24: gokeepas.GetResponse
+	(*BatchRequest)(nil),       // 25: gokeepas.BatchRequest
+	(*BatchItem)(nil),          // 26: gokeepas.BatchItem
+	(*BatchResponse)(nil),      // 27: gokeepas.BatchResponse
+	(*WatchRequest)(nil),       // 28: gokeepas.WatchRequest
+	(*WatchEvent)(nil),         // 29: gokeepas.WatchEvent
+	(*ChangesRequest)(nil),     // 30: gokeepas.ChangesRequest
+	(*ChangesResponse)(nil),    // 31: gokeepas.ChangesResponse
+	(*ListRequest)(nil),        // 32: gokeepas.ListRequest
+	(*ListEntry)(nil),          // 33: gokeepas.ListEntry
+	(*ListResponse)(nil),       // 34: gokeepas.ListResponse
+	(*AuditLogRequest)(nil),    // 35: gokeepas.AuditLogRequest
+	(*AuditEvent)(nil),         // 36: gokeepas.AuditEvent
+	(*AuditLogResponse)(nil),   // 37: gokeepas.AuditLogResponse
+}
+var file_internal_proto_gokeeppas_proto_depIdxs = []int32{
+	0,  // 0: gokeepas.ShareRequest.type:type_name -> gokeepas.Type
+	0,  // 1: gokeepas.SharedSecret.type:type_name -> gokeepas.Type
+	11, // 2: gokeepas.SharedList.secrets:type_name -> gokeepas.SharedSecret
+	15, // 3: gokeepas.VaultMembers.members:type_name -> gokeepas.VaultMember
+	17, // 4: gokeepas.VaultList.vaults:type_name -> gokeepas.VaultInfo
+	15, // 5: gokeepas.VaultMemberRequest.member:type_name -> gokeepas.VaultMember
+	15, // 6: gokeepas.VaultRotateRequest.members:type_name -> gokeepas.VaultMember
+	22, // 7: gokeepas.VaultRotateRequest.secrets:type_name -> gokeepas.BinRequest
+	24, // 8: gokeepas.VaultSecrets.secrets:type_name -> gokeepas.GetResponse
+	0,  // 9: gokeepas.BinRequest.type:type_name -> gokeepas.Type
+	0,  // 10: gokeepas.GetResponse.type:type_name -> gokeepas.Type
+	22, // 11: gokeepas.BatchRequest.items:type_name -> gokeepas.BinRequest
+	24, // 12: gokeepas.BatchItem.secret:type_name -> gokeepas.GetResponse
+	26, // 13: gokeepas.BatchResponse.items:type_name -> gokeepas.BatchItem
+	1,  // 14: gokeepas.WatchEvent.kind:type_name -> gokeepas.WatchEvent.Kind
+	0,  // 15: gokeepas.WatchEvent.type:type_name -> gokeepas.Type
+	29, // 16: gokeepas.ChangesResponse.changes:type_name -> gokeepas.WatchEvent
+	0,  // 17: gokeepas.ListRequest.types:type_name -> gokeepas.Type
+	0,  // 18: gokeepas.ListEntry.type:type_name -> gokeepas.Type
+	33, // 19: gokeepas.ListResponse.entries:type_name -> gokeepas.ListEntry
+	36, // 20: gokeepas.AuditLogResponse.events:type_name -> gokeepas.AuditEvent
+	5,  // 21: gokeepas.KeepPas.Unseal:input_type -> gokeepas.KeyShare
+	5,  // 22: gokeepas.KeepPas.Seal:input_type -> gokeepas.KeyShare
+	2,  // 23: gokeepas.KeepPas.SignUp:input_type -> gokeepas.AuthRequest
+	2,  // 24: gokeepas.KeepPas.LogIn:input_type -> gokeepas.AuthRequest
+	4,  // 25: gokeepas.KeepPas.Refresh:input_type -> gokeepas.RefreshRequest
+	4,  // 26: gokeepas.KeepPas.LogOut:input_type -> gokeepas.RefreshRequest
+	22, // 27: gokeepas.KeepPas.Enable2FA:input_type -> gokeepas.BinRequest
+	7,  // 28: gokeepas.KeepPas.Confirm2FA:input_type -> gokeepas.TwoFARequest
+	7,  // 29: gokeepas.KeepPas.Disable2FA:input_type -> gokeepas.TwoFARequest
+	22, // 30: gokeepas.KeepPas.Add:input_type -> gokeepas.BinRequest
+	22, // 31: gokeepas.KeepPas.Get:input_type -> gokeepas.BinRequest
+	22, // 32: gokeepas.KeepPas.GetKey:input_type -> gokeepas.BinRequest
+	32, // 33: gokeepas.KeepPas.List:input_type -> gokeepas.ListRequest
+	28, // 34: gokeepas.KeepPas.Watch:input_type -> gokeepas.WatchRequest
+	30, // 35: gokeepas.KeepPas.Changes:input_type -> gokeepas.ChangesRequest
+	22, // 36: gokeepas.KeepPas.Remove:input_type -> gokeepas.BinRequest
+	22, // 37: gokeepas.KeepPas.Rename:input_type -> gokeepas.BinRequest
+	22, // 38: gokeepas.KeepPas.Update:input_type -> gokeepas.BinRequest
+	22, // 39: gokeepas.KeepPas.Copy:input_type -> gokeepas.BinRequest
+	25, // 40: gokeepas.KeepPas.GetMany:input_type -> gokeepas.BatchRequest
+	25, // 41: gokeepas.KeepPas.AddMany:input_type -> gokeepas.BatchRequest
+	25, // 42: gokeepas.KeepPas.RemoveMany:input_type -> gokeepas.BatchRequest
+	9,  // 43: gokeepas.KeepPas.SetKeyPair:input_type -> gokeepas.KeyPair
+	22, // 44: gokeepas.KeepPas.GetKeyPair:input_type -> gokeepas.BinRequest
+	22, // 45: gokeepas.KeepPas.GetPublicKey:input_type -> gokeepas.BinRequest
+	10, // 46: gokeepas.KeepPas.Share:input_type -> gokeepas.ShareRequest
+	22, // 47: gokeepas.KeepPas.ListShared:input_type -> gokeepas.BinRequest
+	10, // 48: gokeepas.KeepPas.Unshare:input_type -> gokeepas.ShareRequest
+	13, // 49: gokeepas.KeepPas.CreateVault:input_type -> gokeepas.VaultRequest
+	13, // 50: gokeepas.KeepPas.GetVaultKey:input_type -> gokeepas.VaultRequest
+	22, // 51: gokeepas.KeepPas.ListVaults:input_type -> gokeepas.BinRequest
+	13, // 52: gokeepas.KeepPas.ListVaultMembers:input_type -> gokeepas.VaultRequest
+	19, // 53: gokeepas.KeepPas.AddVaultMember:input_type -> gokeepas.VaultMemberRequest
+	19, // 54: gokeepas.KeepPas.ChangeVaultRole:input_type -> gokeepas.VaultMemberRequest
+	20, // 55: gokeepas.KeepPas.RemoveVaultMember:input_type -> gokeepas.VaultRotateRequest
+	13, // 56: gokeepas.KeepPas.GetVaultSecrets:input_type -> gokeepas.VaultRequest
+	35, // 57: gokeepas.KeepPas.GetAuditLog:input_type -> gokeepas.AuditLogRequest
+	6,  // 58: gokeepas.KeepPas.Unseal:output_type -> gokeepas.SealStatus
+	6,  // 59: gokeepas.KeepPas.Seal:output_type -> gokeepas.SealStatus
+	3,  // 60: gokeepas.KeepPas.SignUp:output_type -> gokeepas.AuthResponse
+	3,  // 61: gokeepas.KeepPas.LogIn:output_type -> gokeepas.AuthResponse
+	3,  // 62: gokeepas.KeepPas.Refresh:output_type -> gokeepas.AuthResponse
+	23, // 63: gokeepas.KeepPas.LogOut:output_type -> gokeepas.BinResponse
+	8,  // 64: gokeepas.KeepPas.Enable2FA:output_type -> gokeepas.TwoFAResponse
+	8,  // 65: gokeepas.KeepPas.Confirm2FA:output_type -> gokeepas.TwoFAResponse
+	23, // 66: gokeepas.KeepPas.Disable2FA:output_type -> gokeepas.BinResponse
+	23, // 67: gokeepas.KeepPas.Add:output_type -> gokeepas.BinResponse
+	24, // 68: gokeepas.KeepPas.Get:output_type -> gokeepas.GetResponse
+	3,  // 69: gokeepas.KeepPas.GetKey:output_type -> gokeepas.AuthResponse
+	34, // 70: gokeepas.KeepPas.List:output_type -> gokeepas.ListResponse
+	29, // 71: gokeepas.KeepPas.Watch:output_type -> gokeepas.WatchEvent
+	31, // 72: gokeepas.KeepPas.Changes:output_type -> gokeepas.ChangesResponse
+	23, // 73: gokeepas.KeepPas.Remove:output_type -> gokeepas.BinResponse
+	23, // 74: gokeepas.KeepPas.Rename:output_type -> gokeepas.BinResponse
+	23, // 75: gokeepas.KeepPas.Update:output_type -> gokeepas.BinResponse
+	23, // 76: gokeepas.KeepPas.Copy:output_type -> gokeepas.BinResponse
+	27, // 77: gokeepas.KeepPas.GetMany:output_type -> gokeepas.BatchResponse
+	27, // 78: gokeepas.KeepPas.AddMany:output_type -> gokeepas.BatchResponse
+	27, // 79: gokeepas.KeepPas.RemoveMany:output_type -> gokeepas.BatchResponse
+	23, // 80: gokeepas.KeepPas.SetKeyPair:output_type -> gokeepas.BinResponse
+	9,  // 81: gokeepas.KeepPas.GetKeyPair:output_type -> gokeepas.KeyPair
+	9,  // 82: gokeepas.KeepPas.GetPublicKey:output_type -> gokeepas.KeyPair
+	23, // 83: gokeepas.KeepPas.Share:output_type -> gokeepas.BinResponse
+	12, // 84: gokeepas.KeepPas.ListShared:output_type -> gokeepas.SharedList
+	23, // 85: gokeepas.KeepPas.Unshare:output_type -> gokeepas.BinResponse
+	23, // 86: gokeepas.KeepPas.CreateVault:output_type -> gokeepas.BinResponse
+	14, // 87: gokeepas.KeepPas.GetVaultKey:output_type -> gokeepas.VaultKey
+	18, // 88: gokeepas.KeepPas.ListVaults:output_type -> gokeepas.VaultList
+	16, // 89: gokeepas.KeepPas.ListVaultMembers:output_type -> gokeepas.VaultMembers
+	23, // 90: gokeepas.KeepPas.AddVaultMember:output_type -> gokeepas.BinResponse
+	23, // 91: gokeepas.KeepPas.ChangeVaultRole:output_type -> gokeepas.BinResponse
+	23, // 92: gokeepas.KeepPas.RemoveVaultMember:output_type -> gokeepas.BinResponse
+	21, // 93: gokeepas.KeepPas.GetVaultSecrets:output_type -> gokeepas.VaultSecrets
+	37, // 94: gokeepas.KeepPas.GetAuditLog:output_type -> gokeepas.AuditLogResponse
+	58, // [58:95] is the sub-list for method output_type
+	21, // [21:58] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
+}
+
+func init() { file_internal_proto_gokeeppas_proto_init() }
+func file_internal_proto_gokeeppas_proto_init() {
+	if File_internal_proto_gokeeppas_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_internal_proto_gokeeppas_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuthRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuthResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RefreshRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KeyShare); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SealStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TwoFARequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TwoFAResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KeyPair); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ShareRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SharedSecret); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SharedList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VaultRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VaultKey); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VaultMember); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VaultMembers); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VaultInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VaultList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VaultMemberRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VaultRotateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VaultSecrets); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
 				return &v.unknownFields
 			default:
 				return nil
 			}
 		}
-		file_internal_proto_gokeepas_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_gokeeppas_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*BinRequest); i {
 			case 0:
 				return &v.state
@@ -591,7 +3132,7 @@ func file_internal_proto_gokeepas_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_gokeepas_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_gokeeppas_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*BinResponse); i {
 			case 0:
 				return &v.state
@@ -603,7 +3144,7 @@ func file_internal_proto_gokeepas_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_gokeepas_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_gokeeppas_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetResponse); i {
 			case 0:
 				return &v.state
@@ -615,7 +3156,115 @@ func file_internal_proto_gokeepas_proto_init() {
 				return nil
 			}
 		}
-		file_internal_proto_gokeepas_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_internal_proto_gokeeppas_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ListResponse); i {
 			case 0:
 				return &v.state
@@ -627,24 +3276,60 @@ func file_internal_proto_gokeepas_proto_init() {
 				return nil
 			}
 		}
+		file_internal_proto_gokeeppas_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuditLogRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuditEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_proto_gokeeppas_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuditLogResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_internal_proto_gokeepas_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   6,
+			RawDescriptor: file_internal_proto_gokeeppas_proto_rawDesc,
+			NumEnums:      2,
+			NumMessages:   36,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
-		GoTypes:           file_internal_proto_gokeepas_proto_goTypes,
-		DependencyIndexes: file_internal_proto_gokeepas_proto_depIdxs,
-		EnumInfos:         file_internal_proto_gokeepas_proto_enumTypes,
-		MessageInfos:      file_internal_proto_gokeepas_proto_msgTypes,
+		GoTypes:           file_internal_proto_gokeeppas_proto_goTypes,
+		DependencyIndexes: file_internal_proto_gokeeppas_proto_depIdxs,
+		EnumInfos:         file_internal_proto_gokeeppas_proto_enumTypes,
+		MessageInfos:      file_internal_proto_gokeeppas_proto_msgTypes,
 	}.Build()
-	File_internal_proto_gokeepas_proto = out.File
-	file_internal_proto_gokeepas_proto_rawDesc = nil
-	file_internal_proto_gokeepas_proto_goTypes = nil
-	file_internal_proto_gokeepas_proto_depIdxs = nil
+	File_internal_proto_gokeeppas_proto = out.File
+	file_internal_proto_gokeeppas_proto_rawDesc = nil
+	file_internal_proto_gokeeppas_proto_goTypes = nil
+	file_internal_proto_gokeeppas_proto_depIdxs = nil
 }
@@ -2,7 +2,7 @@
 // versions:
 // - protoc-gen-go-grpc v1.3.0
 // - protoc             v3.15.8
-// source: internal/proto/gokeepas.proto
+// source: internal/proto/gokeeppas.proto
 
 package proto
 
@@ -19,32 +19,86 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	KeepPas_SignUp_FullMethodName = "/gokeepas.KeepPas/SignUp"
-	KeepPas_LogIn_FullMethodName  = "/gokeepas.KeepPas/LogIn"
-	KeepPas_Add_FullMethodName    = "/gokeepas.KeepPas/Add"
-	KeepPas_Get_FullMethodName    = "/gokeepas.KeepPas/Get"
-	KeepPas_GetKey_FullMethodName = "/gokeepas.KeepPas/GetKey"
-	KeepPas_List_FullMethodName   = "/gokeepas.KeepPas/List"
-	KeepPas_Remove_FullMethodName = "/gokeepas.KeepPas/Remove"
-	KeepPas_Rename_FullMethodName = "/gokeepas.KeepPas/Rename"
-	KeepPas_Update_FullMethodName = "/gokeepas.KeepPas/Update"
-	KeepPas_Copy_FullMethodName   = "/gokeepas.KeepPas/Copy"
+	KeepPas_Unseal_FullMethodName            = "/gokeepas.KeepPas/Unseal"
+	KeepPas_Seal_FullMethodName              = "/gokeepas.KeepPas/Seal"
+	KeepPas_SignUp_FullMethodName            = "/gokeepas.KeepPas/SignUp"
+	KeepPas_LogIn_FullMethodName             = "/gokeepas.KeepPas/LogIn"
+	KeepPas_Refresh_FullMethodName           = "/gokeepas.KeepPas/Refresh"
+	KeepPas_LogOut_FullMethodName            = "/gokeepas.KeepPas/LogOut"
+	KeepPas_Enable2FA_FullMethodName         = "/gokeepas.KeepPas/Enable2FA"
+	KeepPas_Confirm2FA_FullMethodName        = "/gokeepas.KeepPas/Confirm2FA"
+	KeepPas_Disable2FA_FullMethodName        = "/gokeepas.KeepPas/Disable2FA"
+	KeepPas_Add_FullMethodName               = "/gokeepas.KeepPas/Add"
+	KeepPas_Get_FullMethodName               = "/gokeepas.KeepPas/Get"
+	KeepPas_GetKey_FullMethodName            = "/gokeepas.KeepPas/GetKey"
+	KeepPas_List_FullMethodName              = "/gokeepas.KeepPas/List"
+	KeepPas_Watch_FullMethodName             = "/gokeepas.KeepPas/Watch"
+	KeepPas_Changes_FullMethodName           = "/gokeepas.KeepPas/Changes"
+	KeepPas_Remove_FullMethodName            = "/gokeepas.KeepPas/Remove"
+	KeepPas_Rename_FullMethodName            = "/gokeepas.KeepPas/Rename"
+	KeepPas_Update_FullMethodName            = "/gokeepas.KeepPas/Update"
+	KeepPas_Copy_FullMethodName              = "/gokeepas.KeepPas/Copy"
+	KeepPas_GetMany_FullMethodName           = "/gokeepas.KeepPas/GetMany"
+	KeepPas_AddMany_FullMethodName           = "/gokeepas.KeepPas/AddMany"
+	KeepPas_RemoveMany_FullMethodName        = "/gokeepas.KeepPas/RemoveMany"
+	KeepPas_SetKeyPair_FullMethodName        = "/gokeepas.KeepPas/SetKeyPair"
+	KeepPas_GetKeyPair_FullMethodName        = "/gokeepas.KeepPas/GetKeyPair"
+	KeepPas_GetPublicKey_FullMethodName      = "/gokeepas.KeepPas/GetPublicKey"
+	KeepPas_Share_FullMethodName             = "/gokeepas.KeepPas/Share"
+	KeepPas_ListShared_FullMethodName        = "/gokeepas.KeepPas/ListShared"
+	KeepPas_Unshare_FullMethodName           = "/gokeepas.KeepPas/Unshare"
+	KeepPas_CreateVault_FullMethodName       = "/gokeepas.KeepPas/CreateVault"
+	KeepPas_GetVaultKey_FullMethodName       = "/gokeepas.KeepPas/GetVaultKey"
+	KeepPas_ListVaults_FullMethodName        = "/gokeepas.KeepPas/ListVaults"
+	KeepPas_ListVaultMembers_FullMethodName  = "/gokeepas.KeepPas/ListVaultMembers"
+	KeepPas_AddVaultMember_FullMethodName    = "/gokeepas.KeepPas/AddVaultMember"
+	KeepPas_ChangeVaultRole_FullMethodName   = "/gokeepas.KeepPas/ChangeVaultRole"
+	KeepPas_RemoveVaultMember_FullMethodName = "/gokeepas.KeepPas/RemoveVaultMember"
+	KeepPas_GetVaultSecrets_FullMethodName   = "/gokeepas.KeepPas/GetVaultSecrets"
+	KeepPas_GetAuditLog_FullMethodName       = "/gokeepas.KeepPas/GetAuditLog"
 )
 
 // KeepPasClient is the client API for KeepPas service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type KeepPasClient interface {
+	Unseal(ctx context.Context, in *KeyShare, opts ...grpc.CallOption) (*SealStatus, error)
+	Seal(ctx context.Context, in *KeyShare, opts ...grpc.CallOption) (*SealStatus, error)
 	SignUp(ctx context.Context, in *AuthRequest, opts ...grpc.CallOption) (*AuthResponse, error)
 	LogIn(ctx context.Context, in *AuthRequest, opts ...grpc.CallOption) (*AuthResponse, error)
+	Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*AuthResponse, error)
+	LogOut(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*BinResponse, error)
+	Enable2FA(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*TwoFAResponse, error)
+	Confirm2FA(ctx context.Context, in *TwoFARequest, opts ...grpc.CallOption) (*TwoFAResponse, error)
+	Disable2FA(ctx context.Context, in *TwoFARequest, opts ...grpc.CallOption) (*BinResponse, error)
 	Add(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*BinResponse, error)
 	Get(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*GetResponse, error)
 	GetKey(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*AuthResponse, error)
-	List(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (KeepPas_WatchClient, error)
+	Changes(ctx context.Context, in *ChangesRequest, opts ...grpc.CallOption) (*ChangesResponse, error)
 	Remove(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*BinResponse, error)
 	Rename(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*BinResponse, error)
 	Update(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*BinResponse, error)
 	Copy(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*BinResponse, error)
+	GetMany(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	AddMany(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	RemoveMany(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	SetKeyPair(ctx context.Context, in *KeyPair, opts ...grpc.CallOption) (*BinResponse, error)
+	GetKeyPair(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*KeyPair, error)
+	GetPublicKey(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*KeyPair, error)
+	Share(ctx context.Context, in *ShareRequest, opts ...grpc.CallOption) (*BinResponse, error)
+	ListShared(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*SharedList, error)
+	Unshare(ctx context.Context, in *ShareRequest, opts ...grpc.CallOption) (*BinResponse, error)
+	CreateVault(ctx context.Context, in *VaultRequest, opts ...grpc.CallOption) (*BinResponse, error)
+	GetVaultKey(ctx context.Context, in *VaultRequest, opts ...grpc.CallOption) (*VaultKey, error)
+	ListVaults(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*VaultList, error)
+	ListVaultMembers(ctx context.Context, in *VaultRequest, opts ...grpc.CallOption) (*VaultMembers, error)
+	AddVaultMember(ctx context.Context, in *VaultMemberRequest, opts ...grpc.CallOption) (*BinResponse, error)
+	ChangeVaultRole(ctx context.Context, in *VaultMemberRequest, opts ...grpc.CallOption) (*BinResponse, error)
+	RemoveVaultMember(ctx context.Context, in *VaultRotateRequest, opts ...grpc.CallOption) (*BinResponse, error)
+	GetVaultSecrets(ctx context.Context, in *VaultRequest, opts ...grpc.CallOption) (*VaultSecrets, error)
+	GetAuditLog(ctx context.Context, in *AuditLogRequest, opts ...grpc.CallOption) (*AuditLogResponse, error)
 }
 
 type keepPasClient struct {
@@ -55,6 +109,24 @@ func NewKeepPasClient(cc grpc.ClientConnInterface) KeepPasClient {
 	return &keepPasClient{cc}
 }
 
+func (c *keepPasClient) Unseal(ctx context.Context, in *KeyShare, opts ...grpc.CallOption) (*SealStatus, error) {
+	out := new(SealStatus)
+	err := c.cc.Invoke(ctx, KeepPas_Unseal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) Seal(ctx context.Context, in *KeyShare, opts ...grpc.CallOption) (*SealStatus, error) {
+	out := new(SealStatus)
+	err := c.cc.Invoke(ctx, KeepPas_Seal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *keepPasClient) SignUp(ctx context.Context, in *AuthRequest, opts ...grpc.CallOption) (*AuthResponse, error) {
 	out := new(AuthResponse)
 	err := c.cc.Invoke(ctx, KeepPas_SignUp_FullMethodName, in, out, opts...)
@@ -73,6 +145,51 @@ func (c *keepPasClient) LogIn(ctx context.Context, in *AuthRequest, opts ...grpc
 	return out, nil
 }
 
+func (c *keepPasClient) Refresh(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*AuthResponse, error) {
+	out := new(AuthResponse)
+	err := c.cc.Invoke(ctx, KeepPas_Refresh_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) LogOut(ctx context.Context, in *RefreshRequest, opts ...grpc.CallOption) (*BinResponse, error) {
+	out := new(BinResponse)
+	err := c.cc.Invoke(ctx, KeepPas_LogOut_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) Enable2FA(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*TwoFAResponse, error) {
+	out := new(TwoFAResponse)
+	err := c.cc.Invoke(ctx, KeepPas_Enable2FA_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) Confirm2FA(ctx context.Context, in *TwoFARequest, opts ...grpc.CallOption) (*TwoFAResponse, error) {
+	out := new(TwoFAResponse)
+	err := c.cc.Invoke(ctx, KeepPas_Confirm2FA_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) Disable2FA(ctx context.Context, in *TwoFARequest, opts ...grpc.CallOption) (*BinResponse, error) {
+	out := new(BinResponse)
+	err := c.cc.Invoke(ctx, KeepPas_Disable2FA_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *keepPasClient) Add(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*BinResponse, error) {
 	out := new(BinResponse)
 	err := c.cc.Invoke(ctx, KeepPas_Add_FullMethodName, in, out, opts...)
@@ -100,7 +217,7 @@ func (c *keepPasClient) GetKey(ctx context.Context, in *BinRequest, opts ...grpc
 	return out, nil
 }
 
-func (c *keepPasClient) List(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+func (c *keepPasClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
 	out := new(ListResponse)
 	err := c.cc.Invoke(ctx, KeepPas_List_FullMethodName, in, out, opts...)
 	if err != nil {
@@ -109,6 +226,47 @@ func (c *keepPasClient) List(ctx context.Context, in *BinRequest, opts ...grpc.C
 	return out, nil
 }
 
+func (c *keepPasClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (KeepPas_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &KeepPas_ServiceDesc.Streams[0], KeepPas_Watch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &keepPasWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type KeepPas_WatchClient interface {
+	Recv() (*WatchEvent, error)
+	grpc.ClientStream
+}
+
+type keepPasWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *keepPasWatchClient) Recv() (*WatchEvent, error) {
+	m := new(WatchEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *keepPasClient) Changes(ctx context.Context, in *ChangesRequest, opts ...grpc.CallOption) (*ChangesResponse, error) {
+	out := new(ChangesResponse)
+	err := c.cc.Invoke(ctx, KeepPas_Changes_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *keepPasClient) Remove(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*BinResponse, error) {
 	out := new(BinResponse)
 	err := c.cc.Invoke(ctx, KeepPas_Remove_FullMethodName, in, out, opts...)
@@ -145,20 +303,209 @@ func (c *keepPasClient) Copy(ctx context.Context, in *BinRequest, opts ...grpc.C
 	return out, nil
 }
 
+func (c *keepPasClient) GetMany(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, KeepPas_GetMany_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) AddMany(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, KeepPas_AddMany_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) RemoveMany(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, KeepPas_RemoveMany_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) SetKeyPair(ctx context.Context, in *KeyPair, opts ...grpc.CallOption) (*BinResponse, error) {
+	out := new(BinResponse)
+	err := c.cc.Invoke(ctx, KeepPas_SetKeyPair_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) GetKeyPair(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*KeyPair, error) {
+	out := new(KeyPair)
+	err := c.cc.Invoke(ctx, KeepPas_GetKeyPair_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) GetPublicKey(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*KeyPair, error) {
+	out := new(KeyPair)
+	err := c.cc.Invoke(ctx, KeepPas_GetPublicKey_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) Share(ctx context.Context, in *ShareRequest, opts ...grpc.CallOption) (*BinResponse, error) {
+	out := new(BinResponse)
+	err := c.cc.Invoke(ctx, KeepPas_Share_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) ListShared(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*SharedList, error) {
+	out := new(SharedList)
+	err := c.cc.Invoke(ctx, KeepPas_ListShared_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) Unshare(ctx context.Context, in *ShareRequest, opts ...grpc.CallOption) (*BinResponse, error) {
+	out := new(BinResponse)
+	err := c.cc.Invoke(ctx, KeepPas_Unshare_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) CreateVault(ctx context.Context, in *VaultRequest, opts ...grpc.CallOption) (*BinResponse, error) {
+	out := new(BinResponse)
+	err := c.cc.Invoke(ctx, KeepPas_CreateVault_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) GetVaultKey(ctx context.Context, in *VaultRequest, opts ...grpc.CallOption) (*VaultKey, error) {
+	out := new(VaultKey)
+	err := c.cc.Invoke(ctx, KeepPas_GetVaultKey_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) ListVaults(ctx context.Context, in *BinRequest, opts ...grpc.CallOption) (*VaultList, error) {
+	out := new(VaultList)
+	err := c.cc.Invoke(ctx, KeepPas_ListVaults_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) ListVaultMembers(ctx context.Context, in *VaultRequest, opts ...grpc.CallOption) (*VaultMembers, error) {
+	out := new(VaultMembers)
+	err := c.cc.Invoke(ctx, KeepPas_ListVaultMembers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) AddVaultMember(ctx context.Context, in *VaultMemberRequest, opts ...grpc.CallOption) (*BinResponse, error) {
+	out := new(BinResponse)
+	err := c.cc.Invoke(ctx, KeepPas_AddVaultMember_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) ChangeVaultRole(ctx context.Context, in *VaultMemberRequest, opts ...grpc.CallOption) (*BinResponse, error) {
+	out := new(BinResponse)
+	err := c.cc.Invoke(ctx, KeepPas_ChangeVaultRole_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) RemoveVaultMember(ctx context.Context, in *VaultRotateRequest, opts ...grpc.CallOption) (*BinResponse, error) {
+	out := new(BinResponse)
+	err := c.cc.Invoke(ctx, KeepPas_RemoveVaultMember_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) GetVaultSecrets(ctx context.Context, in *VaultRequest, opts ...grpc.CallOption) (*VaultSecrets, error) {
+	out := new(VaultSecrets)
+	err := c.cc.Invoke(ctx, KeepPas_GetVaultSecrets_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keepPasClient) GetAuditLog(ctx context.Context, in *AuditLogRequest, opts ...grpc.CallOption) (*AuditLogResponse, error) {
+	out := new(AuditLogResponse)
+	err := c.cc.Invoke(ctx, KeepPas_GetAuditLog_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // KeepPasServer is the server API for KeepPas service.
 // All implementations must embed UnimplementedKeepPasServer
 // for forward compatibility
 type KeepPasServer interface {
+	Unseal(context.Context, *KeyShare) (*SealStatus, error)
+	Seal(context.Context, *KeyShare) (*SealStatus, error)
 	SignUp(context.Context, *AuthRequest) (*AuthResponse, error)
 	LogIn(context.Context, *AuthRequest) (*AuthResponse, error)
+	Refresh(context.Context, *RefreshRequest) (*AuthResponse, error)
+	LogOut(context.Context, *RefreshRequest) (*BinResponse, error)
+	Enable2FA(context.Context, *BinRequest) (*TwoFAResponse, error)
+	Confirm2FA(context.Context, *TwoFARequest) (*TwoFAResponse, error)
+	Disable2FA(context.Context, *TwoFARequest) (*BinResponse, error)
 	Add(context.Context, *BinRequest) (*BinResponse, error)
 	Get(context.Context, *BinRequest) (*GetResponse, error)
 	GetKey(context.Context, *BinRequest) (*AuthResponse, error)
-	List(context.Context, *BinRequest) (*ListResponse, error)
+	List(context.Context, *ListRequest) (*ListResponse, error)
+	Watch(*WatchRequest, KeepPas_WatchServer) error
+	Changes(context.Context, *ChangesRequest) (*ChangesResponse, error)
 	Remove(context.Context, *BinRequest) (*BinResponse, error)
 	Rename(context.Context, *BinRequest) (*BinResponse, error)
 	Update(context.Context, *BinRequest) (*BinResponse, error)
 	Copy(context.Context, *BinRequest) (*BinResponse, error)
+	GetMany(context.Context, *BatchRequest) (*BatchResponse, error)
+	AddMany(context.Context, *BatchRequest) (*BatchResponse, error)
+	RemoveMany(context.Context, *BatchRequest) (*BatchResponse, error)
+	SetKeyPair(context.Context, *KeyPair) (*BinResponse, error)
+	GetKeyPair(context.Context, *BinRequest) (*KeyPair, error)
+	GetPublicKey(context.Context, *BinRequest) (*KeyPair, error)
+	Share(context.Context, *ShareRequest) (*BinResponse, error)
+	ListShared(context.Context, *BinRequest) (*SharedList, error)
+	Unshare(context.Context, *ShareRequest) (*BinResponse, error)
+	CreateVault(context.Context, *VaultRequest) (*BinResponse, error)
+	GetVaultKey(context.Context, *VaultRequest) (*VaultKey, error)
+	ListVaults(context.Context, *BinRequest) (*VaultList, error)
+	ListVaultMembers(context.Context, *VaultRequest) (*VaultMembers, error)
+	AddVaultMember(context.Context, *VaultMemberRequest) (*BinResponse, error)
+	ChangeVaultRole(context.Context, *VaultMemberRequest) (*BinResponse, error)
+	RemoveVaultMember(context.Context, *VaultRotateRequest) (*BinResponse, error)
+	GetVaultSecrets(context.Context, *VaultRequest) (*VaultSecrets, error)
+	GetAuditLog(context.Context, *AuditLogRequest) (*AuditLogResponse, error)
 	mustEmbedUnimplementedKeepPasServer()
 }
 
@@ -166,12 +513,33 @@ type KeepPasServer interface {
 type UnimplementedKeepPasServer struct {
 }
 
+func (UnimplementedKeepPasServer) Unseal(context.Context, *KeyShare) (*SealStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unseal not implemented")
+}
+func (UnimplementedKeepPasServer) Seal(context.Context, *KeyShare) (*SealStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Seal not implemented")
+}
 func (UnimplementedKeepPasServer) SignUp(context.Context, *AuthRequest) (*AuthResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SignUp not implemented")
 }
 func (UnimplementedKeepPasServer) LogIn(context.Context, *AuthRequest) (*AuthResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LogIn not implemented")
 }
+func (UnimplementedKeepPasServer) Refresh(context.Context, *RefreshRequest) (*AuthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Refresh not implemented")
+}
+func (UnimplementedKeepPasServer) LogOut(context.Context, *RefreshRequest) (*BinResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LogOut not implemented")
+}
+func (UnimplementedKeepPasServer) Enable2FA(context.Context, *BinRequest) (*TwoFAResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Enable2FA not implemented")
+}
+func (UnimplementedKeepPasServer) Confirm2FA(context.Context, *TwoFARequest) (*TwoFAResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Confirm2FA not implemented")
+}
+func (UnimplementedKeepPasServer) Disable2FA(context.Context, *TwoFARequest) (*BinResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Disable2FA not implemented")
+}
 func (UnimplementedKeepPasServer) Add(context.Context, *BinRequest) (*BinResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Add not implemented")
 }
@@ -181,9 +549,15 @@ func (UnimplementedKeepPasServer) Get(context.Context, *BinRequest) (*GetRespons
 func (UnimplementedKeepPasServer) GetKey(context.Context, *BinRequest) (*AuthResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetKey not implemented")
 }
-func (UnimplementedKeepPasServer) List(context.Context, *BinRequest) (*ListResponse, error) {
+func (UnimplementedKeepPasServer) List(context.Context, *ListRequest) (*ListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
 }
+func (UnimplementedKeepPasServer) Watch(*WatchRequest, KeepPas_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedKeepPasServer) Changes(context.Context, *ChangesRequest) (*ChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Changes not implemented")
+}
 func (UnimplementedKeepPasServer) Remove(context.Context, *BinRequest) (*BinResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Remove not implemented")
 }
@@ -196,6 +570,60 @@ func (UnimplementedKeepPasServer) Update(context.Context, *BinRequest) (*BinResp
 func (UnimplementedKeepPasServer) Copy(context.Context, *BinRequest) (*BinResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Copy not implemented")
 }
+func (UnimplementedKeepPasServer) GetMany(context.Context, *BatchRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMany not implemented")
+}
+func (UnimplementedKeepPasServer) AddMany(context.Context, *BatchRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMany not implemented")
+}
+func (UnimplementedKeepPasServer) RemoveMany(context.Context, *BatchRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveMany not implemented")
+}
+func (UnimplementedKeepPasServer) SetKeyPair(context.Context, *KeyPair) (*BinResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetKeyPair not implemented")
+}
+func (UnimplementedKeepPasServer) GetKeyPair(context.Context, *BinRequest) (*KeyPair, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKeyPair not implemented")
+}
+func (UnimplementedKeepPasServer) GetPublicKey(context.Context, *BinRequest) (*KeyPair, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPublicKey not implemented")
+}
+func (UnimplementedKeepPasServer) Share(context.Context, *ShareRequest) (*BinResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Share not implemented")
+}
+func (UnimplementedKeepPasServer) ListShared(context.Context, *BinRequest) (*SharedList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListShared not implemented")
+}
+func (UnimplementedKeepPasServer) Unshare(context.Context, *ShareRequest) (*BinResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unshare not implemented")
+}
+func (UnimplementedKeepPasServer) CreateVault(context.Context, *VaultRequest) (*BinResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateVault not implemented")
+}
+func (UnimplementedKeepPasServer) GetVaultKey(context.Context, *VaultRequest) (*VaultKey, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVaultKey not implemented")
+}
+func (UnimplementedKeepPasServer) ListVaults(context.Context, *BinRequest) (*VaultList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVaults not implemented")
+}
+func (UnimplementedKeepPasServer) ListVaultMembers(context.Context, *VaultRequest) (*VaultMembers, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVaultMembers not implemented")
+}
+func (UnimplementedKeepPasServer) AddVaultMember(context.Context, *VaultMemberRequest) (*BinResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddVaultMember not implemented")
+}
+func (UnimplementedKeepPasServer) ChangeVaultRole(context.Context, *VaultMemberRequest) (*BinResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeVaultRole not implemented")
+}
+func (UnimplementedKeepPasServer) RemoveVaultMember(context.Context, *VaultRotateRequest) (*BinResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveVaultMember not implemented")
+}
+func (UnimplementedKeepPasServer) GetVaultSecrets(context.Context, *VaultRequest) (*VaultSecrets, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVaultSecrets not implemented")
+}
+func (UnimplementedKeepPasServer) GetAuditLog(context.Context, *AuditLogRequest) (*AuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuditLog not implemented")
+}
 func (UnimplementedKeepPasServer) mustEmbedUnimplementedKeepPasServer() {}
 
 // UnsafeKeepPasServer may be embedded to opt out of forward compatibility for this service.
@@ -209,139 +637,304 @@ func RegisterKeepPasServer(s grpc.ServiceRegistrar, srv KeepPasServer) {
 	s.RegisterService(&KeepPas_ServiceDesc, srv)
 }
 
-func _KeepPas_SignUp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AuthRequest)
+func _KeepPas_Unseal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyShare)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeepPasServer).SignUp(ctx, in)
+		return srv.(KeepPasServer).Unseal(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: KeepPas_SignUp_FullMethodName,
+		FullMethod: KeepPas_Unseal_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeepPasServer).SignUp(ctx, req.(*AuthRequest))
+		return srv.(KeepPasServer).Unseal(ctx, req.(*KeyShare))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeepPas_LogIn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AuthRequest)
+func _KeepPas_Seal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyShare)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeepPasServer).LogIn(ctx, in)
+		return srv.(KeepPasServer).Seal(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: KeepPas_LogIn_FullMethodName,
+		FullMethod: KeepPas_Seal_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeepPasServer).LogIn(ctx, req.(*AuthRequest))
+		return srv.(KeepPasServer).Seal(ctx, req.(*KeyShare))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeepPas_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BinRequest)
+func _KeepPas_SignUp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeepPasServer).Add(ctx, in)
+		return srv.(KeepPasServer).SignUp(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: KeepPas_Add_FullMethodName,
+		FullMethod: KeepPas_SignUp_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeepPasServer).Add(ctx, req.(*BinRequest))
+		return srv.(KeepPasServer).SignUp(ctx, req.(*AuthRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeepPas_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BinRequest)
+func _KeepPas_LogIn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeepPasServer).Get(ctx, in)
+		return srv.(KeepPasServer).LogIn(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: KeepPas_Get_FullMethodName,
+		FullMethod: KeepPas_LogIn_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeepPasServer).Get(ctx, req.(*BinRequest))
+		return srv.(KeepPasServer).LogIn(ctx, req.(*AuthRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeepPas_GetKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BinRequest)
+func _KeepPas_Refresh_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeepPasServer).GetKey(ctx, in)
+		return srv.(KeepPasServer).Refresh(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: KeepPas_GetKey_FullMethodName,
+		FullMethod: KeepPas_Refresh_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeepPasServer).GetKey(ctx, req.(*BinRequest))
+		return srv.(KeepPasServer).Refresh(ctx, req.(*RefreshRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeepPas_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BinRequest)
+func _KeepPas_LogOut_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeepPasServer).List(ctx, in)
+		return srv.(KeepPasServer).LogOut(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: KeepPas_List_FullMethodName,
+		FullMethod: KeepPas_LogOut_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeepPasServer).List(ctx, req.(*BinRequest))
+		return srv.(KeepPasServer).LogOut(ctx, req.(*RefreshRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeepPas_Remove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+func _KeepPas_Enable2FA_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(BinRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeepPasServer).Remove(ctx, in)
+		return srv.(KeepPasServer).Enable2FA(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: KeepPas_Remove_FullMethodName,
+		FullMethod: KeepPas_Enable2FA_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(KeepPasServer).Remove(ctx, req.(*BinRequest))
+		return srv.(KeepPasServer).Enable2FA(ctx, req.(*BinRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _KeepPas_Rename_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(BinRequest)
+func _KeepPas_Confirm2FA_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TwoFARequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(KeepPasServer).Rename(ctx, in)
+		return srv.(KeepPasServer).Confirm2FA(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_Confirm2FA_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).Confirm2FA(ctx, req.(*TwoFARequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_Disable2FA_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TwoFARequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).Disable2FA(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_Disable2FA_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).Disable2FA(ctx, req.(*TwoFARequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).Add(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_Add_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).Add(ctx, req.(*BinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_Get_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).Get(ctx, req.(*BinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_GetKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).GetKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_GetKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).GetKey(ctx, req.(*BinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_List_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).List(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KeepPasServer).Watch(m, &keepPasWatchServer{stream})
+}
+
+type KeepPas_WatchServer interface {
+	Send(*WatchEvent) error
+	grpc.ServerStream
+}
+
+type keepPasWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *keepPasWatchServer) Send(m *WatchEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _KeepPas_Changes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).Changes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_Changes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).Changes(ctx, req.(*ChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_Remove_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).Remove(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_Remove_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).Remove(ctx, req.(*BinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_Rename_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).Rename(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
@@ -389,6 +982,330 @@ func _KeepPas_Copy_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _KeepPas_GetMany_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).GetMany(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_GetMany_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).GetMany(ctx, req.(*BatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_AddMany_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).AddMany(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_AddMany_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).AddMany(ctx, req.(*BatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_RemoveMany_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).RemoveMany(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_RemoveMany_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).RemoveMany(ctx, req.(*BatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_SetKeyPair_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeyPair)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).SetKeyPair(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_SetKeyPair_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).SetKeyPair(ctx, req.(*KeyPair))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_GetKeyPair_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).GetKeyPair(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_GetKeyPair_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).GetKeyPair(ctx, req.(*BinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_GetPublicKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).GetPublicKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_GetPublicKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).GetPublicKey(ctx, req.(*BinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_Share_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).Share(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_Share_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).Share(ctx, req.(*ShareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_ListShared_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).ListShared(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_ListShared_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).ListShared(ctx, req.(*BinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_Unshare_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).Unshare(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_Unshare_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).Unshare(ctx, req.(*ShareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_CreateVault_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).CreateVault(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_CreateVault_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).CreateVault(ctx, req.(*VaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_GetVaultKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).GetVaultKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_GetVaultKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).GetVaultKey(ctx, req.(*VaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_ListVaults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).ListVaults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_ListVaults_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).ListVaults(ctx, req.(*BinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_ListVaultMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).ListVaultMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_ListVaultMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).ListVaultMembers(ctx, req.(*VaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_AddVaultMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VaultMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).AddVaultMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_AddVaultMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).AddVaultMember(ctx, req.(*VaultMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_ChangeVaultRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VaultMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).ChangeVaultRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_ChangeVaultRole_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).ChangeVaultRole(ctx, req.(*VaultMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_RemoveVaultMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VaultRotateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).RemoveVaultMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_RemoveVaultMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).RemoveVaultMember(ctx, req.(*VaultRotateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_GetVaultSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VaultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).GetVaultSecrets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_GetVaultSecrets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).GetVaultSecrets(ctx, req.(*VaultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeepPas_GetAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeepPasServer).GetAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeepPas_GetAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeepPasServer).GetAuditLog(ctx, req.(*AuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // KeepPas_ServiceDesc is the grpc.ServiceDesc for KeepPas service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -396,6 +1313,14 @@ var KeepPas_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "gokeepas.KeepPas",
 	HandlerType: (*KeepPasServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Unseal",
+			Handler:    _KeepPas_Unseal_Handler,
+		},
+		{
+			MethodName: "Seal",
+			Handler:    _KeepPas_Seal_Handler,
+		},
 		{
 			MethodName: "SignUp",
 			Handler:    _KeepPas_SignUp_Handler,
@@ -404,6 +1329,26 @@ var KeepPas_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "LogIn",
 			Handler:    _KeepPas_LogIn_Handler,
 		},
+		{
+			MethodName: "Refresh",
+			Handler:    _KeepPas_Refresh_Handler,
+		},
+		{
+			MethodName: "LogOut",
+			Handler:    _KeepPas_LogOut_Handler,
+		},
+		{
+			MethodName: "Enable2FA",
+			Handler:    _KeepPas_Enable2FA_Handler,
+		},
+		{
+			MethodName: "Confirm2FA",
+			Handler:    _KeepPas_Confirm2FA_Handler,
+		},
+		{
+			MethodName: "Disable2FA",
+			Handler:    _KeepPas_Disable2FA_Handler,
+		},
 		{
 			MethodName: "Add",
 			Handler:    _KeepPas_Add_Handler,
@@ -420,6 +1365,10 @@ var KeepPas_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "List",
 			Handler:    _KeepPas_List_Handler,
 		},
+		{
+			MethodName: "Changes",
+			Handler:    _KeepPas_Changes_Handler,
+		},
 		{
 			MethodName: "Remove",
 			Handler:    _KeepPas_Remove_Handler,
@@ -436,7 +1385,85 @@ var KeepPas_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Copy",
 			Handler:    _KeepPas_Copy_Handler,
 		},
+		{
+			MethodName: "GetMany",
+			Handler:    _KeepPas_GetMany_Handler,
+		},
+		{
+			MethodName: "AddMany",
+			Handler:    _KeepPas_AddMany_Handler,
+		},
+		{
+			MethodName: "RemoveMany",
+			Handler:    _KeepPas_RemoveMany_Handler,
+		},
+		{
+			MethodName: "SetKeyPair",
+			Handler:    _KeepPas_SetKeyPair_Handler,
+		},
+		{
+			MethodName: "GetKeyPair",
+			Handler:    _KeepPas_GetKeyPair_Handler,
+		},
+		{
+			MethodName: "GetPublicKey",
+			Handler:    _KeepPas_GetPublicKey_Handler,
+		},
+		{
+			MethodName: "Share",
+			Handler:    _KeepPas_Share_Handler,
+		},
+		{
+			MethodName: "ListShared",
+			Handler:    _KeepPas_ListShared_Handler,
+		},
+		{
+			MethodName: "Unshare",
+			Handler:    _KeepPas_Unshare_Handler,
+		},
+		{
+			MethodName: "CreateVault",
+			Handler:    _KeepPas_CreateVault_Handler,
+		},
+		{
+			MethodName: "GetVaultKey",
+			Handler:    _KeepPas_GetVaultKey_Handler,
+		},
+		{
+			MethodName: "ListVaults",
+			Handler:    _KeepPas_ListVaults_Handler,
+		},
+		{
+			MethodName: "ListVaultMembers",
+			Handler:    _KeepPas_ListVaultMembers_Handler,
+		},
+		{
+			MethodName: "AddVaultMember",
+			Handler:    _KeepPas_AddVaultMember_Handler,
+		},
+		{
+			MethodName: "ChangeVaultRole",
+			Handler:    _KeepPas_ChangeVaultRole_Handler,
+		},
+		{
+			MethodName: "RemoveVaultMember",
+			Handler:    _KeepPas_RemoveVaultMember_Handler,
+		},
+		{
+			MethodName: "GetVaultSecrets",
+			Handler:    _KeepPas_GetVaultSecrets_Handler,
+		},
+		{
+			MethodName: "GetAuditLog",
+			Handler:    _KeepPas_GetAuditLog_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _KeepPas_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "internal/proto/gokeepas.proto",
+	Metadata: "internal/proto/gokeeppas.proto",
 }
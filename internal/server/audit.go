@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/audit"
+	"github.com/hrapovd1/gokeepas/internal/config"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/storage"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxAuditLimit = 1000            // limits count of events in one GetAuditLog response
+	auditTimeout  = 5 * time.Second // limits recording of audit event
+)
+
+// newAuditSink returns sink of audit log configured for server.
+func newAuditSink(conf config.Config, stor storage.Storage) (audit.Sink, error) {
+	switch conf.AuditSink {
+	case "", audit.SinkStorage:
+		return audit.NewStorageSink(stor), nil
+	case audit.SinkFile:
+		return audit.NewFileSink(conf.AuditFile), nil
+	}
+	return nil, fmt.Errorf("unknown audit sink %q", conf.AuditSink)
+}
+
+// recordAudit saves audit event of rpc call, errors are only logged so broken sink doesn't
+// stop the service. Event is recorded after call, even when client canceled it.
+func (kps *KeepPasSrv) recordAudit(ctx context.Context, login string, method string, req any, err error) {
+	if kps.audit == nil {
+		return
+	}
+	ev := types.AuditEvent{
+		Login:  login,
+		Method: method,
+		Key:    auditKey(req),
+		Code:   status.Code(err).String(),
+		Time:   time.Now().UnixMilli(),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ev.Peer = p.Addr.String()
+	}
+	actx, cancel := context.WithTimeout(context.Background(), auditTimeout)
+	defer cancel()
+	if err := kps.audit.Record(actx, &ev); err != nil {
+		kps.logger.Errorf("audit record of %s: %v", method, err)
+	}
+}
+
+// authAuditLogin returns login which unauthenticated call of SignUp or LogIn is recorded for.
+// Failed attempt is recorded only for existing user, otherwise anyone could flood audit log
+// of other user, such attempt is recorded without login.
+func (kps *KeepPasSrv) authAuditLogin(ctx context.Context, login string, err error) string {
+	if err == nil || login == "" {
+		return login
+	}
+	data := types.StorageModel{}
+	if err := kps.Stor.Get(ctx, "/users/"+login, &data); err != nil || data.PassHash == "" {
+		return ""
+	}
+	return login
+}
+
+// auditKey returns key of secret addressed by request, keys of batch are joined by comma.
+func auditKey(req any) string {
+	switch r := req.(type) {
+	case *pb.BatchRequest:
+		keys := make([]string, 0, len(r.Items))
+		for _, item := range r.Items {
+			keys = append(keys, item.Key)
+		}
+		return strings.Join(keys, ",")
+	case interface{ GetKey() string }:
+		return r.GetKey()
+	}
+	return ""
+}
+
+// GetAuditLog returns last audit events of caller after unix milliseconds req.Since.
+func (kps *KeepPasSrv) GetAuditLog(ctx context.Context, req *pb.AuditLogRequest) (*pb.AuditLogResponse, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Since < 0 || req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "since and limit can't be negative")
+	}
+	if kps.audit == nil {
+		return nil, status.Error(codes.FailedPrecondition, "audit log is disabled")
+	}
+	limit := int(req.Limit)
+	if limit == 0 || limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+	events, err := kps.audit.List(ctx, login, req.Since, limit)
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when get audit log")
+	}
+	resp := pb.AuditLogResponse{Events: make([]*pb.AuditEvent, 0, len(events))}
+	for _, ev := range events {
+		resp.Events = append(resp.Events, &pb.AuditEvent{
+			Login:  ev.Login,
+			Method: ev.Method,
+			Key:    ev.Key,
+			Code:   ev.Code,
+			Peer:   ev.Peer,
+			Time:   ev.Time,
+		})
+	}
+	return &resp, nil
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/audit"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// fakeAuditSink keeps recorded events in memory
+type fakeAuditSink struct {
+	events []types.AuditEvent
+	err    error
+}
+
+func (f *fakeAuditSink) Record(_ context.Context, ev *types.AuditEvent) error {
+	f.events = append(f.events, *ev)
+	return f.err
+}
+
+func (f *fakeAuditSink) List(_ context.Context, _ string, _ int64, _ int) ([]types.AuditEvent, error) {
+	return f.events, f.err
+}
+
+func TestKeepPasSrv_AuthInterceptor_audit(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	sink := fakeAuditSink{}
+	srv.audit = &sink
+	token, err := crypto.NewToken("test", "sid", srv.conf.ServerKey, crypto.ExpireDuration)
+	require.NoError(t, err)
+	ctx := peer.NewContext(
+		metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"bearer-token": token})),
+		&peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 5555}},
+	)
+	info := &grpc.UnaryServerInfo{FullMethod: "/gokeepas.KeepPas/Get"}
+	t.Run("authenticated", func(t *testing.T) {
+		sink.events = nil
+		mock.ExpectHGetAll("/sessions/sid").SetVal(map[string]string{"login": "test", "refresh": "hash"})
+		_, err := srv.AuthInterceptor(ctx, &pb.BinRequest{Key: "one"}, info, func(c context.Context, r any) (any, error) {
+			return nil, status.Error(codes.NotFound, "key doesn't exists")
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		require.Len(t, sink.events, 1)
+		ev := sink.events[0]
+		assert.Equal(t, "test", ev.Login)
+		assert.Equal(t, "/gokeepas.KeepPas/Get", ev.Method)
+		assert.Equal(t, "one", ev.Key)
+		assert.Equal(t, "NotFound", ev.Code)
+		assert.Equal(t, "127.0.0.1:5555", ev.Peer)
+		assert.NotZero(t, ev.Time)
+	})
+	failedLogin := func(c context.Context, r any) (any, error) {
+		return nil, status.Error(codes.Unauthenticated, "wrong login or password")
+	}
+	loginInfo := &grpc.UnaryServerInfo{FullMethod: "/gokeepas.KeepPas/LogIn"}
+	t.Run("failed login", func(t *testing.T) {
+		sink.events = nil
+		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{"pass": "hash"})
+		_, err := srv.AuthInterceptor(ctx, &pb.AuthRequest{Login: "test", Password: "wrong"}, loginInfo, failedLogin)
+		assert.Error(t, err)
+		require.Len(t, sink.events, 1)
+		assert.Equal(t, "test", sink.events[0].Login)
+		assert.Equal(t, "Unauthenticated", sink.events[0].Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("failed login of unknown user", func(t *testing.T) {
+		sink.events = nil
+		mock.ExpectHGetAll("/users/victim").RedisNil()
+		_, err := srv.AuthInterceptor(ctx, &pb.AuthRequest{Login: "victim", Password: "wrong"}, loginInfo, failedLogin)
+		assert.Error(t, err)
+		// attempt isn't stored in audit log of login
+		require.Len(t, sink.events, 1)
+		assert.Empty(t, sink.events[0].Login)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("sink error", func(t *testing.T) {
+		sink.events, sink.err = nil, errors.New("disk is full")
+		_, err := srv.AuthInterceptor(context.Background(), &pb.BinRequest{}, info, func(c context.Context, r any) (any, error) {
+			return nil, nil
+		})
+		// call isn't authenticated, but it is still recorded
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		require.Len(t, sink.events, 1)
+		assert.Empty(t, sink.events[0].Login)
+	})
+}
+
+func Test_auditKey(t *testing.T) {
+	assert.Equal(t, "one", auditKey(&pb.BinRequest{Key: "one"}))
+	assert.Equal(t, "one,two", auditKey(&pb.BatchRequest{Items: []*pb.BinRequest{{Key: "one"}, {Key: "two"}}}))
+	assert.Empty(t, auditKey(&pb.KeyShare{Share: "secret"}))
+	assert.Empty(t, auditKey(nil))
+}
+
+func TestKeepPasSrv_GetAuditLog(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	t.Run("disabled", func(t *testing.T) {
+		_, err := srv.GetAuditLog(ctx, &pb.AuditLogRequest{})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+	srv.audit = audit.NewStorageSink(srv.Stor)
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectZRevRangeByScore("/audit/test", &redis.ZRangeBy{Min: "(10", Max: "+inf", Count: maxAuditLimit}).SetVal([]string{
+			`1:{"login":"test","method":"/gokeepas.KeepPas/Get","key":"one","code":"OK","peer":"127.0.0.1:5555","time":11}`,
+		})
+		resp, err := srv.GetAuditLog(ctx, &pb.AuditLogRequest{Since: 10})
+		require.NoError(t, err)
+		require.Len(t, resp.Events, 1)
+		assert.Equal(t, "/gokeepas.KeepPas/Get", resp.Events[0].Method)
+		assert.Equal(t, "127.0.0.1:5555", resp.Events[0].Peer)
+		assert.Equal(t, int64(11), resp.Events[0].Time)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("storage error", func(t *testing.T) {
+		mock.ExpectZRevRangeByScore("/audit/test", &redis.ZRangeBy{Min: "(0", Max: "+inf", Count: 5}).SetErr(errors.New("broken"))
+		_, err := srv.GetAuditLog(ctx, &pb.AuditLogRequest{Limit: 5})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		mock.ClearExpect()
+	})
+	t.Run("negative limit", func(t *testing.T) {
+		_, err := srv.GetAuditLog(ctx, &pb.AuditLogRequest{Limit: -1})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("empty login", func(t *testing.T) {
+		_, err := srv.GetAuditLog(context.Background(), &pb.AuditLogRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxBatchItems limits count of keys in one batch request
+const maxBatchItems = 1000
+
+// batchKeys checks batch request and returns storage keys of its items and their prefix, items
+// with empty key get InvalidArgument status in out and their storage keys stay empty.
+func (kps *KeepPasSrv) batchKeys(ctx context.Context, req *pb.BatchRequest, write bool) ([]string, []*pb.BatchItem, string, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if len(req.Items) == 0 || len(req.Items) > maxBatchItems {
+		return nil, nil, "", status.Errorf(codes.InvalidArgument, "batch must have from 1 to %d items", maxBatchItems)
+	}
+	prefix, err := kps.secretsPrefix(ctx, login, write)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	keys := make([]string, len(req.Items))
+	out := make([]*pb.BatchItem, len(req.Items))
+	for i, item := range req.Items {
+		out[i] = &pb.BatchItem{Key: item.Key}
+		if item.Key == "" {
+			setItemStatus(out[i], status.Error(codes.InvalidArgument, "empty key"))
+			continue
+		}
+		keys[i] = prefix + item.Key
+	}
+	return keys, out, prefix, nil
+}
+
+// setItemStatus keeps status of err in batch item, nil err means OK.
+func setItemStatus(item *pb.BatchItem, err error) {
+	st := status.Convert(err)
+	item.Code = int32(st.Code())
+	item.Error = st.Message()
+}
+
+// validKeys returns storage keys and their indexes in batch, items with status are skipped.
+func validKeys(keys []string, out []*pb.BatchItem) ([]string, []int) {
+	valid := make([]string, 0, len(keys))
+	idx := make([]int, 0, len(keys))
+	for i, key := range keys {
+		if out[i].Code == int32(codes.OK) {
+			valid = append(valid, key)
+			idx = append(idx, i)
+		}
+	}
+	return valid, idx
+}
+
+// GetMany returns encrypted values of list of keys by one storage request, each item has its own status.
+func (kps *KeepPasSrv) GetMany(ctx context.Context, req *pb.BatchRequest) (*pb.BatchResponse, error) {
+	keys, out, _, err := kps.batchKeys(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	valid, idx := validKeys(keys, out)
+	vals, errs := kps.Stor.GetMany(ctx, valid)
+	for j, i := range idx {
+		if errs[j] != nil {
+			kps.logger.Debug(errs[j])
+			setItemStatus(out[i], status.Error(codes.Internal, "error when get"))
+			continue
+		}
+		secretType, ok := pb.Type_value[vals[j].Type]
+		if !ok {
+			setItemStatus(out[i], status.Error(codes.NotFound, "key doesn't exists"))
+			continue
+		}
+		out[i].Secret = &pb.GetResponse{
+			Data:    []byte(vals[j].Data),
+			Key:     req.Items[i].Key,
+			Type:    pb.Type(secretType),
+			DataKey: vals[j].SymmKey,
+		}
+	}
+	return &pb.BatchResponse{Items: out}, nil
+}
+
+// AddMany keeps encrypted values of list of keys by one storage request, each item has its own status.
+func (kps *KeepPasSrv) AddMany(ctx context.Context, req *pb.BatchRequest) (*pb.BatchResponse, error) {
+	keys, out, prefix, err := kps.batchKeys(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	valid, idx := validKeys(keys, out)
+	vals := make([]types.StorageModel, len(idx))
+	for j, i := range idx {
+		item := req.Items[i]
+		vals[j] = types.StorageModel{SymmKey: item.DataKey, Data: item.Data, Type: item.Type.String()}
+	}
+	for j, err := range kps.Stor.AddMany(ctx, valid, vals) {
+		if err != nil {
+			kps.logger.Debug(err)
+			setItemStatus(out[idx[j]], status.Error(codes.Internal, "error when add"))
+			continue
+		}
+		kps.publish(prefix, types.Event{Kind: types.EventAdd, Key: req.Items[idx[j]].Key, Type: vals[j].Type})
+	}
+	return &pb.BatchResponse{Items: out}, nil
+}
+
+// RemoveMany removes list of keys by one storage request, each item has its own status.
+func (kps *KeepPasSrv) RemoveMany(ctx context.Context, req *pb.BatchRequest) (*pb.BatchResponse, error) {
+	keys, out, prefix, err := kps.batchKeys(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	valid, idx := validKeys(keys, out)
+	for j, err := range kps.Stor.RemoveMany(ctx, valid) {
+		if err != nil {
+			kps.logger.Debug(err)
+			setItemStatus(out[idx[j]], status.Error(codes.Internal, "error when remove"))
+			continue
+		}
+		kps.publish(prefix, types.Event{Kind: types.EventRemove, Key: req.Items[idx[j]].Key})
+	}
+	return &pb.BatchResponse{Items: out}, nil
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestKeepPasSrv_GetMany(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectHGetAll("test/one").SetVal(map[string]string{"data": "enc", "symmkey": "dk", "type": "LOGIN"})
+		mock.ExpectHGetAll("test/none").SetVal(map[string]string{})
+		mock.ExpectHGetAll("test/broken").SetErr(errors.New("broken"))
+		resp, err := srv.GetMany(ctx, &pb.BatchRequest{Items: []*pb.BinRequest{{Key: "one"}, {Key: ""}, {Key: "none"}, {Key: "broken"}}})
+		require.NoError(t, err)
+		require.Len(t, resp.Items, 4)
+		assert.Equal(t, &pb.GetResponse{Key: "one", Data: []byte("enc"), DataKey: "dk", Type: pb.Type_LOGIN}, resp.Items[0].Secret)
+		assert.Equal(t, int32(codes.OK), resp.Items[0].Code)
+		assert.Equal(t, int32(codes.InvalidArgument), resp.Items[1].Code)
+		assert.Equal(t, int32(codes.NotFound), resp.Items[2].Code)
+		assert.Nil(t, resp.Items[2].Secret)
+		assert.Equal(t, int32(codes.Internal), resp.Items[3].Code)
+		assert.Equal(t, "broken", resp.Items[3].Key)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("empty batch", func(t *testing.T) {
+		_, err := srv.GetMany(ctx, &pb.BatchRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("too big batch", func(t *testing.T) {
+		_, err := srv.GetMany(ctx, &pb.BatchRequest{Items: make([]*pb.BinRequest, maxBatchItems+1)})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("empty login", func(t *testing.T) {
+		_, err := srv.GetMany(context.Background(), &pb.BatchRequest{Items: []*pb.BinRequest{{Key: "one"}}})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestKeepPasSrv_AddMany(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	mock.ExpectHSet("test/one", "pass", "", "symmkey", "dk1", "data", "enc1", "type", "TEXT").SetVal(3)
+	mock.ExpectHSet("test/two", "pass", "", "symmkey", "dk2", "data", "enc2", "type", "CART").SetErr(errors.New("broken"))
+	resp, err := srv.AddMany(ctx, &pb.BatchRequest{Items: []*pb.BinRequest{
+		{Key: "one", Data: "enc1", DataKey: "dk1", Type: pb.Type_TEXT},
+		{Key: "two", Data: "enc2", DataKey: "dk2", Type: pb.Type_CART},
+	}})
+	require.NoError(t, err)
+	require.Len(t, resp.Items, 2)
+	assert.Equal(t, &pb.BatchItem{Key: "one"}, resp.Items[0])
+	assert.Equal(t, int32(codes.Internal), resp.Items[1].Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeepPasSrv_RemoveMany(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	t.Run("right", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+		mock.ExpectDel("test/one").SetVal(1)
+		mock.ExpectDel("test/two").SetVal(1)
+		resp, err := srv.RemoveMany(ctx, &pb.BatchRequest{Items: []*pb.BinRequest{{Key: "one"}, {Key: "two"}}})
+		require.NoError(t, err)
+		assert.Equal(t, []*pb.BatchItem{{Key: "one"}, {Key: "two"}}, resp.Items)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("read-only vault", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test", "vault": "infra"}))
+		mock.ExpectHGet("/vaultmembers/infra", "test").SetVal("read")
+		mock.ExpectHGet("/vaultkeys/infra", "test").SetVal("sealed")
+		_, err := srv.RemoveMany(ctx, &pb.BatchRequest{Items: []*pb.BinRequest{{Key: "one"}}})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxChangesLimit limits count of changes in one Changes response
+const maxChangesLimit = 1000
+
+// Changes returns changes of caller's secrets after sequence number req.Since, clients use it
+// to sync local replica of secrets. Reset in response means client must get all secrets again.
+func (kps *KeepPasSrv) Changes(ctx context.Context, req *pb.ChangesRequest) (*pb.ChangesResponse, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Since < 0 || req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "since and limit can't be negative")
+	}
+	prefix, err := kps.secretsPrefix(ctx, login, false)
+	if err != nil {
+		return nil, err
+	}
+	limit := int(req.Limit)
+	if limit == 0 || limit > maxChangesLimit {
+		limit = maxChangesLimit
+	}
+	changes, err := kps.Stor.ListChanges(ctx, prefix, req.Since, limit)
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when get changes")
+	}
+	resp := pb.ChangesResponse{Seq: changes.Seq, Reset_: changes.Reset}
+	for i := range changes.Events {
+		resp.Changes = append(resp.Changes, watchEvent(&changes.Events[i]))
+	}
+	if n := len(changes.Events); n == limit && changes.Events[n-1].Seq < changes.Seq {
+		resp.More = true
+	}
+	return &resp, nil
+}
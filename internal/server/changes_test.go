@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestKeepPasSrv_Changes(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectTxPipeline()
+		mock.ExpectZRangeByScoreWithScores("/changes/test/", &redis.ZRangeBy{Min: "(1", Max: "+inf", Count: 2}).SetVal([]redis.Z{
+			{Score: 2, Member: `2:{"kind":"ADD","key":"one","type":"LOGIN","time":10}`},
+			{Score: 3, Member: `3:{"kind":"REMOVE","key":"two","time":11}`},
+		})
+		mock.ExpectZRangeWithScores("/changes/test/", 0, 0).SetVal([]redis.Z{{Score: 1, Member: `1:{}`}})
+		mock.ExpectGet("/changeseq/test/").SetVal("4")
+		mock.ExpectTxPipelineExec()
+		resp, err := srv.Changes(ctx, &pb.ChangesRequest{Since: 1, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), resp.Seq)
+		assert.False(t, resp.Reset_)
+		assert.True(t, resp.More)
+		require.Len(t, resp.Changes, 2)
+		assert.Equal(t, &pb.WatchEvent{Kind: pb.WatchEvent_ADD, Key: "one", Type: pb.Type_LOGIN, Time: 10, Seq: 2}, resp.Changes[0])
+		assert.Equal(t, &pb.WatchEvent{Kind: pb.WatchEvent_REMOVE, Key: "two", Time: 11, Seq: 3}, resp.Changes[1])
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("reset", func(t *testing.T) {
+		mock.ExpectTxPipeline()
+		mock.ExpectZRangeByScoreWithScores("/changes/test/", &redis.ZRangeBy{Min: "(0", Max: "+inf", Count: maxChangesLimit}).SetVal(nil)
+		mock.ExpectZRangeWithScores("/changes/test/", 0, 0).SetVal([]redis.Z{{Score: 1, Member: `1:{}`}})
+		mock.ExpectGet("/changeseq/test/").SetVal("1")
+		mock.ExpectTxPipelineExec()
+		resp, err := srv.Changes(ctx, &pb.ChangesRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, &pb.ChangesResponse{Seq: 1, Reset_: true}, resp)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("storage error", func(t *testing.T) {
+		mock.ExpectTxPipeline()
+		mock.ExpectZRangeByScoreWithScores("/changes/test/", &redis.ZRangeBy{Min: "(5", Max: "+inf", Count: maxChangesLimit}).SetErr(errors.New("broken"))
+		_, err := srv.Changes(ctx, &pb.ChangesRequest{Since: 5})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		mock.ClearExpect()
+	})
+	t.Run("negative since", func(t *testing.T) {
+		_, err := srv.Changes(ctx, &pb.ChangesRequest{Since: -1})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("empty login", func(t *testing.T) {
+		_, err := srv.Changes(context.Background(), &pb.ChangesRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
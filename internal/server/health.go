@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// HealthCheckInterval is period of storage pings which drive health status of server
+	HealthCheckInterval = 10 * time.Second
+	healthPingTimeout   = 3 * time.Second // max time of one storage ping
+)
+
+// publicServices are prefixes of rpc methods of standard services, they don't need token and
+// aren't recorded in audit log.
+var publicServices = []string{"/grpc.health.v1.Health/", "/grpc.reflection."}
+
+// isPublicMethod reports whether method belongs to health or reflection service.
+func isPublicMethod(method string) bool {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateHealth pings storage and sets status of whole server and KeepPas service, server is
+// NOT_SERVING while storage is down or server is sealed.
+func (kps *KeepPasSrv) updateHealth(ctx context.Context, hs *health.Server) {
+	st := healthpb.HealthCheckResponse_SERVING
+	pctx, cancel := context.WithTimeout(ctx, healthPingTimeout)
+	defer cancel()
+	// master key isn't checked here, it is checked once at start and on unseal
+	if err := kps.Stor.Ping(pctx, nil); err != nil {
+		kps.logger.Errorf("health check: %v", err)
+		st = healthpb.HealthCheckResponse_NOT_SERVING
+	} else if kps.isSealed() {
+		st = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	hs.SetServingStatus("", st)
+	hs.SetServingStatus(pb.KeepPas_ServiceDesc.ServiceName, st)
+}
+
+// RunHealthChecks updates status of hs right away and then every interval until ctx is done,
+// at the end all services become NOT_SERVING.
+func (kps *KeepPasSrv) RunHealthChecks(ctx context.Context, hs *health.Server, interval time.Duration) {
+	defer hs.Shutdown()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		kps.updateHealth(ctx, hs)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func healthStatus(t *testing.T, hs *health.Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+	resp, err := hs.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return resp.Status
+}
+
+func TestKeepPasSrv_updateHealth(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	hs := health.NewServer()
+	t.Run("serving", func(t *testing.T) {
+		mock.ExpectPing().SetVal("PONG")
+		srv.updateHealth(context.Background(), hs)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, healthStatus(t, hs, ""))
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, healthStatus(t, hs, pb.KeepPas_ServiceDesc.ServiceName))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("storage is down", func(t *testing.T) {
+		mock.ExpectPing().SetErr(errors.New("connection refused"))
+		srv.updateHealth(context.Background(), hs)
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, healthStatus(t, hs, ""))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, healthStatus(t, hs, pb.KeepPas_ServiceDesc.ServiceName))
+	})
+	t.Run("sealed", func(t *testing.T) {
+		srv.conf.ServerKey = nil
+		mock.ExpectPing().SetVal("PONG")
+		srv.updateHealth(context.Background(), hs)
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, healthStatus(t, hs, ""))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestKeepPasSrv_RunHealthChecks(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	hs := health.NewServer()
+	mock.ExpectPing().SetVal("PONG")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	srv.RunHealthChecks(ctx, hs, time.Hour)
+	// status is checked once at start and every service stops serving at the end
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, healthStatus(t, hs, pb.KeepPas_ServiceDesc.ServiceName))
+}
+
+func TestKeepPasSrv_AuthInterceptor_public(t *testing.T) {
+	srv, _ := newTestSrv(t)
+	sink := fakeAuditSink{}
+	srv.audit = &sink
+	// server is sealed and call has no token, but health check still passes
+	srv.conf.ServerKey = nil
+	_, err := srv.AuthInterceptor(context.Background(), &healthpb.HealthCheckRequest{}, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"},
+		func(c context.Context, r any) (any, error) {
+			return &healthpb.HealthCheckResponse{}, nil
+		})
+	assert.NoError(t, err)
+	err = srv.StreamAuthInterceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo"},
+		func(s any, ss grpc.ServerStream) error {
+			return nil
+		})
+	assert.NoError(t, err)
+	assert.Empty(t, sink.events)
+
+	_, err = srv.AuthInterceptor(context.Background(), &pb.BinRequest{}, &grpc.UnaryServerInfo{FullMethod: "/gokeepas.KeepPas/Get"},
+		func(c context.Context, r any) (any, error) {
+			return nil, nil
+		})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
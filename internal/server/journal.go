@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/journal"
+	"github.com/hrapovd1/gokeepas/internal/storage"
+	"github.com/hrapovd1/gokeepas/internal/types"
+)
+
+const (
+	// JournalCheckpointInterval is period of signed checkpoints of security journal
+	JournalCheckpointInterval = time.Hour
+	journalChunk              = 1000 // count of records read by one request in VerifyJournal
+)
+
+// recordSecurity adds security event in journal, errors are only logged so broken journal
+// doesn't stop the service.
+func (kps *KeepPasSrv) recordSecurity(ctx context.Context, kind string, login string, detail string) {
+	if err := kps.Stor.AppendJournal(ctx, journal.Event(kind, login, detail)); err != nil {
+		kps.logger.Errorf("security journal record %s: %v", kind, err)
+	}
+}
+
+// checkpointJournal signs last record of security journal by key derived from master key.
+func (kps *KeepPasSrv) checkpointJournal(ctx context.Context, key []byte) error {
+	signing, err := crypto.SigningKey(key)
+	if err != nil {
+		return err
+	}
+	return kps.Stor.AppendJournal(ctx, journal.Checkpoint(signing))
+}
+
+// RunJournalCheckpoints adds signed checkpoint in security journal every interval until ctx is
+// done. Checkpoint isn't added while server is sealed or when there are no new records.
+func (kps *KeepPasSrv) RunJournalCheckpoints(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		key := kps.serverKey()
+		if len(key) == 0 {
+			continue
+		}
+		if err := kps.checkpointJournal(ctx, key); err != nil {
+			kps.logger.Errorf("security journal checkpoint: %v", err)
+		}
+	}
+}
+
+// JournalPublicKey returns base64 public key of checkpoints signed by key derived from master key.
+func JournalPublicKey(key []byte) (string, error) {
+	signing, err := crypto.SigningKey(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signing.Public().(ed25519.PublicKey)), nil
+}
+
+// VerifyJournal walks whole security journal, checks chain of hashes and signatures of
+// checkpoints by base64 public key pub printed at server init. Head of journal must be
+// the last record.
+func VerifyJournal(ctx context.Context, stor storage.Storage, pub string) (journal.Report, error) {
+	key, err := base64.StdEncoding.DecodeString(pub)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return journal.Report{}, errors.New("journal key must be base64 ed25519 public key")
+	}
+	head := types.JournalEntry{}
+	if err := stor.GetJournalHead(ctx, &head); err != nil {
+		return journal.Report{}, err
+	}
+	verifier := journal.NewVerifier(key)
+	since := int64(0)
+	for {
+		entries, err := stor.ListJournal(ctx, since, journalChunk)
+		if err != nil {
+			return journal.Report{}, err
+		}
+		for _, e := range entries {
+			verifier.Add(e)
+			since = e.Seq
+		}
+		if len(entries) < journalChunk {
+			verifier.Head(head)
+			return verifier.Report(), nil
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/journal"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectJournal expects append of record of kind after record head, head is empty for empty journal
+func expectJournal(mock redismock.ClientMock, head string, kind string) {
+	match := func(expected, actual []interface{}) error {
+		data := fmt.Sprintf("%s", actual[len(actual)-1])
+		if !strings.Contains(data, `"kind":"`+kind+`"`) {
+			return fmt.Errorf("unexpected journal record %s", data)
+		}
+		return nil
+	}
+	mock.ExpectWatch("/journal/head")
+	if head == "" {
+		mock.ExpectGet("/journal/head").RedisNil()
+	} else {
+		mock.ExpectGet("/journal/head").SetVal(head)
+	}
+	mock.ExpectTxPipeline()
+	mock.CustomMatch(match).ExpectSet("/journal/head", "", 0).SetVal("OK")
+	mock.CustomMatch(match).ExpectZAdd("/journal", redis.Z{}).SetVal(1)
+	mock.ExpectTxPipelineExec()
+}
+
+// journalChain returns records of journal ended by checkpoint signed by master key
+func journalChain(t *testing.T, key []byte) []string {
+	signing, err := crypto.SigningKey(key)
+	require.NoError(t, err)
+	var out []string
+	var prev *types.JournalEntry
+	for _, fn := range []func(*types.JournalEntry) (*types.JournalEntry, error){
+		journal.Event(journal.KindSignUp, "test", ""),
+		journal.Event(journal.KindLoginFailed, "test", "wrong password"),
+		journal.Checkpoint(signing),
+	} {
+		e, err := fn(prev)
+		require.NoError(t, err)
+		data, err := json.Marshal(e)
+		require.NoError(t, err)
+		out = append(out, string(data))
+		prev = e
+	}
+	return out
+}
+
+func TestVerifyJournal(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := context.Background()
+	records := journalChain(t, srv.conf.ServerKey)
+	pub, err := JournalPublicKey(srv.conf.ServerKey)
+	require.NoError(t, err)
+	t.Run("valid", func(t *testing.T) {
+		mock.ExpectGet("/journal/head").SetVal(records[2])
+		mock.ExpectZRangeByScore("/journal", &redis.ZRangeBy{Min: "(0", Max: "+inf", Count: journalChunk}).SetVal(records)
+		report, err := VerifyJournal(ctx, srv.Stor, pub)
+		require.NoError(t, err)
+		assert.Equal(t, journal.Report{Entries: 3, Last: 3, Checkpoints: 1, Signed: 3}, report)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("other key", func(t *testing.T) {
+		other, err := JournalPublicKey([]byte("0123456789abcdef"))
+		require.NoError(t, err)
+		mock.ExpectGet("/journal/head").SetVal(records[2])
+		mock.ExpectZRangeByScore("/journal", &redis.ZRangeBy{Min: "(0", Max: "+inf", Count: journalChunk}).SetVal(records)
+		report, err := VerifyJournal(ctx, srv.Stor, other)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"checkpoint 3 has wrong signature"}, report.Problems)
+		mock.ClearExpect()
+	})
+	t.Run("removed record", func(t *testing.T) {
+		mock.ExpectGet("/journal/head").SetVal(records[2])
+		mock.ExpectZRangeByScore("/journal", &redis.ZRangeBy{Min: "(0", Max: "+inf", Count: journalChunk}).SetVal([]string{records[0], records[2]})
+		report, err := VerifyJournal(ctx, srv.Stor, pub)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"records 2-2 are missing"}, report.Problems)
+		mock.ClearExpect()
+	})
+	t.Run("removed checkpoint", func(t *testing.T) {
+		mock.ExpectGet("/journal/head").SetVal(records[2])
+		mock.ExpectZRangeByScore("/journal", &redis.ZRangeBy{Min: "(0", Max: "+inf", Count: journalChunk}).SetVal(records[:2])
+		report, err := VerifyJournal(ctx, srv.Stor, pub)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"head of journal is record 3, but last record is 2"}, report.Problems)
+		mock.ClearExpect()
+	})
+	t.Run("unsigned tail", func(t *testing.T) {
+		last := types.JournalEntry{}
+		require.NoError(t, json.Unmarshal([]byte(records[2]), &last))
+		tail, err := journal.Event(journal.KindSignUp, "other", "")(&last)
+		require.NoError(t, err)
+		data, err := json.Marshal(tail)
+		require.NoError(t, err)
+		mock.ExpectGet("/journal/head").SetVal(string(data))
+		mock.ExpectZRangeByScore("/journal", &redis.ZRangeBy{Min: "(0", Max: "+inf", Count: journalChunk}).SetVal(append(records, string(data)))
+		report, err := VerifyJournal(ctx, srv.Stor, pub)
+		require.NoError(t, err)
+		assert.Equal(t, journal.Report{Entries: 4, Last: 4, Checkpoints: 1, Signed: 3, Unsigned: 1}, report)
+		mock.ClearExpect()
+	})
+	t.Run("wrong key", func(t *testing.T) {
+		_, err := VerifyJournal(ctx, srv.Stor, "c2hvcnQ=")
+		assert.Error(t, err)
+	})
+}
+
+func TestKeepPasSrv_checkpointJournal(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	records := journalChain(t, srv.conf.ServerKey)
+	expectJournal(mock, records[1], journal.KindCheckpoint)
+	require.NoError(t, srv.checkpointJournal(context.Background(), srv.conf.ServerKey))
+	assert.NoError(t, mock.ExpectationsWereMet())
+	mock.ClearExpect()
+
+	// journal already ends with checkpoint
+	mock.ExpectWatch("/journal/head")
+	mock.ExpectGet("/journal/head").SetVal(records[2])
+	require.NoError(t, srv.checkpointJournal(context.Background(), srv.conf.ServerKey))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/kek"
+	"github.com/hrapovd1/gokeepas/internal/types"
+)
+
+// newKEK returns provider of key encryption key configured for server.
+func newKEK(conf config.Config, serverKey func() []byte) (kek.Provider, error) {
+	switch conf.KEK {
+	case "", kek.ProviderMaster:
+		return kek.NewMasterKey(serverKey), nil
+	case kek.ProviderFile:
+		return kek.NewKeyFile(conf.KEKFile)
+	case kek.ProviderPassphrase:
+		return kek.NewPassphrase(conf.KEKPass)
+	case kek.ProviderKMS:
+		return kek.NewKMS(conf.KEKAddress)
+	}
+	return nil, fmt.Errorf("unknown kek provider %q", conf.KEK)
+}
+
+// keyProvider returns provider of key encryption key, it is master key when provider isn't set.
+func (kps *KeepPasSrv) keyProvider() kek.Provider {
+	if kps.kek == nil {
+		return kek.NewMasterKey(kps.serverKey)
+	}
+	return kps.kek
+}
+
+// wrapUserKey encrypts user symmetric key by key encryption key.
+func (kps *KeepPasSrv) wrapUserKey(ctx context.Context, key []byte) (string, error) {
+	return kps.keyProvider().Wrap(ctx, key)
+}
+
+// unwrapUserKey decrypts user symmetric key. Keys wrapped by master key before other
+// provider was configured are still decrypted by master key. stale is true when key should
+// be wrapped again: it is wrapped by previous provider or in outdated format.
+func (kps *KeepPasSrv) unwrapUserKey(ctx context.Context, wrapped string) ([]byte, bool, error) {
+	provider := kps.keyProvider()
+	key, err := provider.Unwrap(ctx, wrapped)
+	if err != nil && kek.Kind(wrapped) == kek.ProviderMaster && provider.Name() != kek.ProviderMaster {
+		key, err = kek.NewMasterKey(kps.serverKey).Unwrap(ctx, wrapped)
+		return key, true, err
+	}
+	return key, kek.Kind(wrapped) == kek.ProviderMaster && crypto.Outdated(wrapped), err
+}
+
+// rewrapUserKey saves user key wrapped by current provider, errors are only logged
+// because key is wrapped again on next login.
+func (kps *KeepPasSrv) rewrapUserKey(ctx context.Context, userKey string, data types.StorageModel, symmKey []byte) {
+	wrapped, err := kps.wrapUserKey(ctx, symmKey)
+	if err != nil {
+		kps.logger.Debug(err)
+		return
+	}
+	data.SymmKey = wrapped
+	if err := kps.Stor.Add(ctx, userKey, &data); err != nil {
+		kps.logger.Debug(err)
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/kek"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestKeepPasSrv_kek(t *testing.T) {
+	stub, err := kek.NewKMSStub([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	kms := httptest.NewServer(stub)
+	defer kms.Close()
+	srv, mock := newTestSrv(t)
+	srv.kek, err = newKEK(config.Config{KEK: kek.ProviderKMS, KEKAddress: kms.URL}, srv.serverKey)
+	require.NoError(t, err)
+	userKey := []byte("0123456789abcdefghijklmn")
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+
+	t.Run("sign up", func(t *testing.T) {
+		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{})
+		mock.Regexp().ExpectHSet("/users/test", "pass", "ae6d41f07eb6718e95b9cf8a31309e16b0e76c61", "symmkey", `^kms:.+$`, "data", "", "type", "").SetVal(2)
+		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{})
+		_, err := srv.SignUp(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("get key", func(t *testing.T) {
+		wrapped, err := srv.wrapUserKey(ctx, userKey)
+		require.NoError(t, err)
+		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{"pass": "ae6d41f07eb6718e95b9cf8a31309e16b0e76c61", "symmkey": wrapped})
+		resp, err := srv.GetKey(ctx, &pb.BinRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, userKey, resp.SymmKey)
+		mock.ClearExpect()
+	})
+	t.Run("legacy key", func(t *testing.T) {
+		// key wrapped by master key before kms provider
+		wrapped, err := crypto.EncryptKey(srv.serverKey(), userKey)
+		require.NoError(t, err)
+		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{"pass": "ae6d41f07eb6718e95b9cf8a31309e16b0e76c61", "symmkey": wrapped})
+		resp, err := srv.GetKey(ctx, &pb.BinRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, userKey, resp.SymmKey)
+		mock.ClearExpect()
+	})
+	t.Run("login rewraps legacy key", func(t *testing.T) {
+		wrapped, err := crypto.EncryptKey(srv.serverKey(), userKey)
+		require.NoError(t, err)
+		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{"pass": "ae6d41f07eb6718e95b9cf8a31309e16b0e76c61", "symmkey": wrapped})
+		mock.ExpectHGetAll("/2fa/test").SetVal(map[string]string{})
+		mock.Regexp().ExpectHSet("/users/test", "pass", "ae6d41f07eb6718e95b9cf8a31309e16b0e76c61", "symmkey", `^kms:.+$`, "data", "", "type", "").SetVal(0)
+		// session isn't mocked
+		_, err = srv.LogIn(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("unknown provider", func(t *testing.T) {
+		_, err := newKEK(config.Config{KEK: "vault"}, srv.serverKey)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/journal"
+	"github.com/hrapovd1/gokeepas/internal/metrics"
+	"github.com/hrapovd1/gokeepas/internal/storage"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Kinds of lockout subjects
+const (
+	LockoutLogin = "login" // failed logins of user
+	LockoutIP    = "ip"    // failed logins from client address
+	LockoutShare = "share" // wrong master key shares from client address
+)
+
+const (
+	lockoutBaseDelay = time.Second      // delay after first failed login, it doubles after each next one
+	lockoutMaxDelay  = 5 * time.Minute  // max delay between failed logins before lockout
+	defaultLockTime  = 15 * time.Minute // time of lockout when it isn't configured
+	retryAfterHeader = "retry-after"    // header of rejected login with seconds to wait
+	shareThreshold   = 5                // wrong key shares in a row before lockout, it can't be disabled
+)
+
+// lockoutSubject is tracked source of failed logins
+type lockoutSubject struct {
+	key       string // key of attempts in storage: <kind>/<value>
+	threshold int    // count of failed logins in a row before lockout
+}
+
+// lockTime returns time of lockout from config or default one.
+func (kps *KeepPasSrv) lockTime() time.Duration {
+	if kps.conf.LockTime > 0 {
+		return kps.conf.LockTime
+	}
+	return defaultLockTime
+}
+
+// lockoutSubjects returns tracked login and client address of call, subject with zero
+// threshold isn't tracked.
+func (kps *KeepPasSrv) lockoutSubjects(ctx context.Context, login string) []lockoutSubject {
+	subjects := make([]lockoutSubject, 0, 2)
+	if kps.conf.LockLogin > 0 && login != "" {
+		subjects = append(subjects, lockoutSubject{key: LockoutLogin + "/" + login, threshold: kps.conf.LockLogin})
+	}
+	if addr := peerHost(ctx); kps.conf.LockIP > 0 && addr != "" {
+		subjects = append(subjects, lockoutSubject{key: LockoutIP + "/" + addr, threshold: kps.conf.LockIP})
+	}
+	return subjects
+}
+
+// shareSubjects returns tracked client address of Seal and Unseal call.
+func shareSubjects(ctx context.Context) []lockoutSubject {
+	if addr := peerHost(ctx); addr != "" {
+		return []lockoutSubject{{key: LockoutShare + "/" + addr, threshold: shareThreshold}}
+	}
+	return nil
+}
+
+// peerHost returns address of client without port, it is empty when address is unknown.
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// lockoutDelay returns time when next login is rejected after failures in a row, it grows
+// exponentially and becomes lockTime at threshold.
+func lockoutDelay(failures int64, threshold int, lockTime time.Duration) time.Duration {
+	if failures >= int64(threshold) {
+		return lockTime
+	}
+	delay := lockoutMaxDelay
+	if failures <= 32 {
+		delay = lockoutBaseDelay << (failures - 1)
+	}
+	if delay > lockoutMaxDelay {
+		delay = lockoutMaxDelay
+	}
+	if delay > lockTime {
+		delay = lockTime
+	}
+	return delay
+}
+
+// checkLockout rejects login with codes.ResourceExhausted while login or client address waits
+// after failed logins, seconds to wait are sent in retry-after header.
+func (kps *KeepPasSrv) checkLockout(ctx context.Context, login string) error {
+	err := kps.checkSubjects(ctx, kps.lockoutSubjects(ctx, login))
+	if status.Code(err) == codes.ResourceExhausted {
+		kps.Metrics.ObserveLogin(metrics.LoginLocked)
+	}
+	return err
+}
+
+// checkSubjects rejects call with codes.ResourceExhausted while one of subjects waits after
+// failures, seconds to wait are sent in retry-after header.
+func (kps *KeepPasSrv) checkSubjects(ctx context.Context, subjects []lockoutSubject) error {
+	now := time.Now()
+	var wait time.Duration
+	for _, s := range subjects {
+		lo := types.Lockout{}
+		if err := kps.Stor.GetLockout(ctx, s.key, &lo); err != nil {
+			kps.logger.Debug(err)
+			return err
+		}
+		if d := time.UnixMilli(lo.Until).Sub(now); d > wait {
+			wait = d
+		}
+	}
+	if wait <= 0 {
+		return nil
+	}
+	secs := int(math.Ceil(wait.Seconds()))
+	if err := grpc.SetHeader(ctx, metadata.Pairs(retryAfterHeader, strconv.Itoa(secs))); err != nil {
+		kps.logger.Debug(err)
+	}
+	return status.Errorf(codes.ResourceExhausted, "too many failed attempts, retry after %d seconds", secs)
+}
+
+// recordLoginFailure counts failed login of user and client address, subject which reaches
+// threshold is locked and the lockout is recorded in security journal. Errors are only logged.
+func (kps *KeepPasSrv) recordLoginFailure(ctx context.Context, login string) {
+	kps.recordFailure(ctx, login, kps.lockoutSubjects(ctx, login))
+}
+
+// recordFailure counts failure of subjects, subject which reaches threshold is locked and
+// the lockout is recorded in security journal for login. Errors are only logged.
+func (kps *KeepPasSrv) recordFailure(ctx context.Context, login string, subjects []lockoutSubject) {
+	now := time.Now()
+	lockTime := kps.lockTime()
+	for _, s := range subjects {
+		locked := false
+		err := kps.Stor.UpdateLockout(ctx, s.key, func(lo *types.Lockout) error {
+			lo.Failures++
+			lo.Until = now.Add(lockoutDelay(lo.Failures, s.threshold, lockTime)).UnixMilli()
+			locked = lo.Failures == int64(s.threshold)
+			return nil
+		}, lockTime)
+		if err != nil {
+			kps.logger.Errorf("lockout of %s: %v", s.key, err)
+			continue
+		}
+		if locked {
+			kps.recordSecurity(ctx, journal.KindLockout, login, fmt.Sprintf("%s is locked after %d failed attempts", s.key, s.threshold))
+		}
+	}
+}
+
+// resetLockout forgets failed logins of user after successful login, failures of client
+// address are kept, so own account doesn't help to guess others.
+func (kps *KeepPasSrv) resetLockout(ctx context.Context, login string) {
+	if kps.conf.LockLogin == 0 {
+		return
+	}
+	if err := kps.Stor.RemoveLockout(ctx, LockoutLogin+"/"+login); err != nil {
+		kps.logger.Errorf("reset lockout of %s: %v", login, err)
+	}
+}
+
+// Unlock forgets failed logins of user or client address by admin and records it in security
+// journal, kind is LockoutLogin, LockoutIP or LockoutShare.
+func Unlock(ctx context.Context, stor storage.Storage, kind string, value string) error {
+	login := ""
+	switch kind {
+	case LockoutLogin:
+		login = value
+	case LockoutIP, LockoutShare:
+	default:
+		return fmt.Errorf("unknown lockout kind %q, use %s, %s or %s", kind, LockoutLogin, LockoutIP, LockoutShare)
+	}
+	if value == "" {
+		return fmt.Errorf("empty %s", kind)
+	}
+	if err := stor.RemoveLockout(ctx, kind+"/"+value); err != nil {
+		return err
+	}
+	return stor.AppendJournal(ctx, journal.Event(journal.KindUnlock, login, kind+"/"+value+" is unlocked by admin"))
+}
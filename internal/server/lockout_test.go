@@ -0,0 +1,186 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// newLockoutTestSrv returns server which locks login after 3 and address after 10 failed logins,
+// and context of call from 10.0.0.1
+func newLockoutTestSrv(t *testing.T) (*KeepPasSrv, redismock.ClientMock, context.Context) {
+	srv, mock := newTestSrv(t)
+	srv.conf.LockLogin, srv.conf.LockIP, srv.conf.LockTime = 3, 10, time.Hour
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5555}})
+	return srv, mock, ctx
+}
+
+// expectLockout expects increment of failed logins of subject key
+func expectLockout(mock redismock.ClientMock, key string, failures int64) {
+	mock.ExpectWatch("/lockout/" + key)
+	if failures == 1 {
+		mock.ExpectHGetAll("/lockout/" + key).SetVal(map[string]string{})
+	} else {
+		mock.ExpectHGetAll("/lockout/" + key).SetVal(map[string]string{"failures": strconv.FormatInt(failures-1, 10)})
+	}
+	mock.ExpectTxPipeline()
+	mock.Regexp().ExpectHSet("/lockout/"+key, "failures", failures, "until", `^\d+$`).SetVal(2)
+	mock.ExpectExpire("/lockout/"+key, time.Hour).SetVal(true)
+	mock.ExpectTxPipelineExec()
+}
+
+func Test_lockoutDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int64
+		want     time.Duration
+	}{
+		{"first", 1, time.Second},
+		{"third", 3, 4 * time.Second},
+		{"max delay", 12, lockoutMaxDelay},
+		{"big count", 99, lockoutMaxDelay},
+		{"threshold", 100, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, lockoutDelay(tt.failures, 100, time.Hour))
+		})
+	}
+	assert.Equal(t, time.Minute, lockoutDelay(10, 100, time.Minute))
+}
+
+func TestKeepPasSrv_checkLockout(t *testing.T) {
+	srv, mock, ctx := newLockoutTestSrv(t)
+	t.Run("free", func(t *testing.T) {
+		mock.ExpectHGetAll("/lockout/login/test").SetVal(map[string]string{"failures": "2", "until": "1"})
+		mock.ExpectHGetAll("/lockout/ip/10.0.0.1").SetVal(map[string]string{})
+		assert.NoError(t, srv.checkLockout(ctx, "test"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("locked", func(t *testing.T) {
+		until := time.Now().Add(time.Minute).UnixMilli()
+		mock.ExpectHGetAll("/lockout/login/test").SetVal(map[string]string{})
+		mock.ExpectHGetAll("/lockout/ip/10.0.0.1").SetVal(map[string]string{"failures": "10", "until": strconv.FormatInt(until, 10)})
+		err := srv.checkLockout(ctx, "test")
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Contains(t, status.Convert(err).Message(), "retry after 60 seconds")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("disabled", func(t *testing.T) {
+		srv.conf.LockLogin, srv.conf.LockIP = 0, 0
+		assert.NoError(t, srv.checkLockout(ctx, "test"))
+	})
+}
+
+func TestKeepPasSrv_recordLoginFailure(t *testing.T) {
+	srv, mock, ctx := newLockoutTestSrv(t)
+	t.Run("backoff", func(t *testing.T) {
+		expectLockout(mock, "login/test", 1)
+		expectLockout(mock, "ip/10.0.0.1", 1)
+		srv.recordLoginFailure(ctx, "test")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("threshold", func(t *testing.T) {
+		expectLockout(mock, "login/test", 3)
+		expectJournal(mock, "", "lockout")
+		expectLockout(mock, "ip/10.0.0.1", 2)
+		srv.recordLoginFailure(ctx, "test")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestKeepPasSrv_LogIn_lockout(t *testing.T) {
+	srv, mock, ctx := newLockoutTestSrv(t)
+	t.Run("locked", func(t *testing.T) {
+		until := time.Now().Add(time.Hour).UnixMilli()
+		mock.ExpectHGetAll("/lockout/login/test").SetVal(map[string]string{"failures": "3", "until": strconv.FormatInt(until, 10)})
+		mock.ExpectHGetAll("/lockout/ip/10.0.0.1").SetVal(map[string]string{})
+		// password isn't checked while login is locked
+		_, err := srv.LogIn(ctx, &pb.AuthRequest{Login: "test", Password: "pass"})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("unknown user", func(t *testing.T) {
+		mock.ExpectHGetAll("/lockout/login/test").SetVal(map[string]string{})
+		mock.ExpectHGetAll("/lockout/ip/10.0.0.1").SetVal(map[string]string{})
+		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{})
+		expectJournal(mock, "", "login_failed")
+		expectLockout(mock, "login/test", 1)
+		expectLockout(mock, "ip/10.0.0.1", 1)
+		_, err := srv.LogIn(ctx, &pb.AuthRequest{Login: "test", Password: "pass"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestKeepPasSrv_SignUp_lockout(t *testing.T) {
+	srv, mock, ctx := newLockoutTestSrv(t)
+	t.Run("locked", func(t *testing.T) {
+		until := time.Now().Add(time.Hour).UnixMilli()
+		// login isn't checked, so sign up can't lock other user
+		mock.ExpectHGetAll("/lockout/ip/10.0.0.1").SetVal(map[string]string{"failures": "10", "until": strconv.FormatInt(until, 10)})
+		_, err := srv.SignUp(ctx, &pb.AuthRequest{Login: "test", Password: "pass"})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("existing user", func(t *testing.T) {
+		mock.ExpectHGetAll("/lockout/ip/10.0.0.1").SetVal(map[string]string{})
+		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{"pass": "hash"})
+		expectLockout(mock, "ip/10.0.0.1", 1)
+		_, err := srv.SignUp(ctx, &pb.AuthRequest{Login: "test", Password: "pass"})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestKeepPasSrv_Unseal_lockout(t *testing.T) {
+	srv, mock, ctx := newLockoutTestSrv(t)
+	wrong := &pb.KeyShare{Share: "d3Jvbmc="}
+	t.Run("wrong share", func(t *testing.T) {
+		mock.ExpectHGetAll("/lockout/share/10.0.0.1").SetVal(map[string]string{})
+		mock.ExpectHGetAll("/seal").SetVal(map[string]string{"shares": "3", "threshold": "2", "hashes": "aa,bb,cc"})
+		expectJournal(mock, "", "unseal")
+		expectLockout(mock, "share/10.0.0.1", 1)
+		_, err := srv.Unseal(ctx, wrong)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("locked", func(t *testing.T) {
+		until := time.Now().Add(time.Hour).UnixMilli()
+		// share isn't checked and journal isn't written while address is locked
+		mock.ExpectHGetAll("/lockout/share/10.0.0.1").SetVal(map[string]string{"failures": "5", "until": strconv.FormatInt(until, 10)})
+		_, err := srv.Seal(ctx, wrong)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUnlock(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	t.Run("login", func(t *testing.T) {
+		mock.ExpectDel("/lockout/login/test").SetVal(1)
+		expectJournal(mock, "", "unlock")
+		require.NoError(t, Unlock(context.Background(), srv.Stor, LockoutLogin, "test"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("ip", func(t *testing.T) {
+		mock.ExpectDel("/lockout/ip/10.0.0.1").SetVal(1)
+		expectJournal(mock, "", "unlock")
+		require.NoError(t, Unlock(context.Background(), srv.Stor, LockoutIP, "10.0.0.1"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("wrong", func(t *testing.T) {
+		assert.Error(t, Unlock(context.Background(), srv.Stor, "user", "test"))
+		assert.Error(t, Unlock(context.Background(), srv.Stor, LockoutLogin, ""))
+	})
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Classes of rpc methods, each class has own rate limit
+const (
+	RateRead  = "read"  // reading of one secret or key
+	RateWrite = "write" // changes, it is class of every method which isn't listed
+	RateList  = "list"  // listing and streaming of many secrets
+)
+
+const (
+	rateBurst          = 2 * time.Second        // bucket holds calls of this period, so short bursts aren't rejected
+	inFlightTTL        = time.Minute            // slot of in-flight call without deadline is freed after it, if server instance crashed
+	inFlightMargin     = 5 * time.Second        // slot of in-flight call with deadline is kept so long after deadline
+	inFlightRetry      = 200 * time.Millisecond // hint for call rejected by concurrency limit
+	releaseTimeout     = 5 * time.Second        // max time of freeing slot of in-flight call
+	retryAfterMsHeader = "retry-after-ms"       // header of rejected call with milliseconds to wait
+)
+
+// rateClasses are classes of read and list methods, other methods are writes
+var rateClasses = map[string]string{
+	"/gokeepas.KeepPas/Get":              RateRead,
+	"/gokeepas.KeepPas/GetKey":           RateRead,
+	"/gokeepas.KeepPas/GetMany":          RateRead,
+	"/gokeepas.KeepPas/GetKeyPair":       RateRead,
+	"/gokeepas.KeepPas/GetPublicKey":     RateRead,
+	"/gokeepas.KeepPas/GetVaultKey":      RateRead,
+	"/gokeepas.KeepPas/List":             RateList,
+	"/gokeepas.KeepPas/Watch":            RateList,
+	"/gokeepas.KeepPas/Changes":          RateList,
+	"/gokeepas.KeepPas/ListShared":       RateList,
+	"/gokeepas.KeepPas/ListVaults":       RateList,
+	"/gokeepas.KeepPas/ListVaultMembers": RateList,
+	"/gokeepas.KeepPas/GetVaultSecrets":  RateList,
+	"/gokeepas.KeepPas/GetAuditLog":      RateList,
+}
+
+// methodClass returns rate class of rpc method.
+func methodClass(method string) string {
+	if class, ok := rateClasses[method]; ok {
+		return class
+	}
+	return RateWrite
+}
+
+// classRate returns configured calls per second of class, 0 means no limit.
+func (kps *KeepPasSrv) classRate(class string) float64 {
+	switch class {
+	case RateRead:
+		return kps.conf.RateRead
+	case RateList:
+		return kps.conf.RateList
+	}
+	return kps.conf.RateWrite
+}
+
+// rejectCall returns codes.ResourceExhausted error and sends time to wait in retry-after-ms header,
+// cli waits it before retry.
+func (kps *KeepPasSrv) rejectCall(ctx context.Context, wait time.Duration, msg string) error {
+	if err := grpc.SetHeader(ctx, metadata.Pairs(retryAfterMsHeader, strconv.FormatInt(wait.Milliseconds(), 10))); err != nil {
+		kps.logger.Debug(err)
+	}
+	return status.Errorf(codes.ResourceExhausted, "%s, retry after %v", msg, wait)
+}
+
+// checkRateLimit takes token from bucket of user login for class of method. Buckets are kept in
+// storage, so limit is shared by all server instances. Broken storage doesn't reject calls.
+func (kps *KeepPasSrv) checkRateLimit(ctx context.Context, login string, method string) error {
+	class := methodClass(method)
+	rate := kps.classRate(class)
+	if rate <= 0 {
+		return nil
+	}
+	burst := int(math.Ceil(rate * rateBurst.Seconds()))
+	wait, err := kps.Stor.TakeRateToken(ctx, login+"/"+class, rate, burst)
+	if err != nil {
+		kps.logger.Errorf("rate limit of %s: %v", login, err)
+		return nil
+	}
+	if wait > 0 {
+		return kps.rejectCall(ctx, wait, "rate limit of "+class+" calls is exceeded")
+	}
+	return nil
+}
+
+// slotTTL returns time of in-flight slot of call: till deadline of call with margin, calls
+// without deadline get inFlightTTL.
+func slotTTL(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return inFlightTTL
+	}
+	return time.Until(deadline) + inFlightMargin
+}
+
+// limitCall checks rate limit and takes slot of in-flight call of user login, returned func
+// frees the slot when call ends.
+func (kps *KeepPasSrv) limitCall(ctx context.Context, login string, method string) (func(), error) {
+	if err := kps.checkRateLimit(ctx, login, method); err != nil {
+		return nil, err
+	}
+	if kps.conf.MaxFlight <= 0 {
+		return func() {}, nil
+	}
+	slot, err := kps.Stor.AcquireInFlight(ctx, login, kps.conf.MaxFlight, slotTTL(ctx))
+	if err != nil {
+		kps.logger.Errorf("concurrency limit of %s: %v", login, err)
+		return func() {}, nil
+	}
+	if slot == "" {
+		return nil, kps.rejectCall(ctx, inFlightRetry, "too many concurrent calls")
+	}
+	return func() {
+		// call context may be already canceled
+		rctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+		defer cancel()
+		if err := kps.Stor.ReleaseInFlight(rctx, login, slot); err != nil {
+			kps.logger.Errorf("concurrency limit of %s: %v", login, err)
+		}
+	}, nil
+}
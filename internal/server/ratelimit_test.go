@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// matchRateToken matches take of token from bucket key with rate and burst
+func matchRateToken(key string, rate float64, burst int) redismock.CustomMatch {
+	return func(expected, actual []interface{}) error {
+		// evalsha sha 1 key rate burst now
+		if actual[3] != "/ratelimit/"+key || actual[4] != rate || actual[5] != burst {
+			return fmt.Errorf("unexpected args %v", actual)
+		}
+		return nil
+	}
+}
+
+// matchInFlight matches acquire of in-flight slot of login with limit
+func matchInFlight(login string, limit int) redismock.CustomMatch {
+	return func(expected, actual []interface{}) error {
+		// evalsha sha 1 key limit ttl slot
+		if actual[3] != "/inflight/"+login {
+			return fmt.Errorf("unexpected key %v", actual[3])
+		}
+		if len(actual) != 7 || actual[4] != limit || actual[6] == "" {
+			return fmt.Errorf("unexpected args %v", actual)
+		}
+		return nil
+	}
+}
+
+func Test_methodClass(t *testing.T) {
+	assert.Equal(t, RateRead, methodClass("/gokeepas.KeepPas/Get"))
+	assert.Equal(t, RateList, methodClass("/gokeepas.KeepPas/List"))
+	assert.Equal(t, RateList, methodClass("/gokeepas.KeepPas/Watch"))
+	assert.Equal(t, RateWrite, methodClass("/gokeepas.KeepPas/Add"))
+	assert.Equal(t, RateWrite, methodClass("/gokeepas.KeepPas/NewMethod"))
+}
+
+func Test_slotTTL(t *testing.T) {
+	assert.Equal(t, inFlightTTL, slotTTL(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	ttl := slotTTL(ctx)
+	assert.Greater(t, ttl, 10*time.Minute)
+	assert.LessOrEqual(t, ttl, 10*time.Minute+inFlightMargin)
+}
+
+func TestKeepPasSrv_checkRateLimit(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	srv.conf.RateRead = 5
+	ctx := context.Background()
+	t.Run("allowed", func(t *testing.T) {
+		mock.CustomMatch(matchRateToken("test/read", 5, 10)).ExpectEvalSha("", []string{""}, nil, nil, nil).SetVal(int64(0))
+		assert.NoError(t, srv.checkRateLimit(ctx, "test", "/gokeepas.KeepPas/Get"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("exceeded", func(t *testing.T) {
+		mock.CustomMatch(matchRateToken("test/read", 5, 10)).ExpectEvalSha("", []string{""}, nil, nil, nil).SetVal(int64(150))
+		err := srv.checkRateLimit(ctx, "test", "/gokeepas.KeepPas/Get")
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Contains(t, status.Convert(err).Message(), "retry after 150ms")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("storage error", func(t *testing.T) {
+		mock.CustomMatch(matchRateToken("test/read", 5, 10)).ExpectEvalSha("", []string{""}, nil, nil, nil).SetErr(errors.New("broken"))
+		assert.NoError(t, srv.checkRateLimit(ctx, "test", "/gokeepas.KeepPas/Get"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("disabled", func(t *testing.T) {
+		// write calls aren't limited
+		assert.NoError(t, srv.checkRateLimit(ctx, "test", "/gokeepas.KeepPas/Add"))
+	})
+}
+
+func TestKeepPasSrv_limitCall(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	srv.conf.MaxFlight = 2
+	ctx := context.Background()
+	t.Run("slot", func(t *testing.T) {
+		mock.CustomMatch(matchInFlight("test", 2)).ExpectEvalSha("", []string{""}, nil, nil, nil).SetVal(int64(1))
+		// released slot is one which was taken
+		slot := ""
+		mock.CustomMatch(func(expected, actual []interface{}) error {
+			if actual[0] != "zrem" || actual[1] != "/inflight/test" || actual[2] == "" {
+				return fmt.Errorf("unexpected release %v", actual)
+			}
+			slot = actual[2].(string)
+			return nil
+		}).ExpectZRem("", "").SetVal(1)
+		release, err := srv.limitCall(ctx, "test", "/gokeepas.KeepPas/Get")
+		require.NoError(t, err)
+		release()
+		assert.NotEmpty(t, slot)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("busy", func(t *testing.T) {
+		mock.CustomMatch(matchInFlight("test", 2)).ExpectEvalSha("", []string{""}, nil, nil, nil).SetVal(int64(0))
+		_, err := srv.limitCall(ctx, "test", "/gokeepas.KeepPas/Get")
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestKeepPasSrv_AuthInterceptor_rateLimit(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	srv.conf.RateList = 1
+	token, err := crypto.NewToken("test", "sid", srv.conf.ServerKey, crypto.ExpireDuration)
+	require.NoError(t, err)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"bearer-token": token}))
+	mock.ExpectHGetAll("/sessions/sid").SetVal(map[string]string{"login": "test", "refresh": "hash"})
+	mock.CustomMatch(matchRateToken("test/list", 1, 2)).ExpectEvalSha("", []string{""}, nil, nil, nil).SetVal(int64(1000))
+	called := false
+	_, err = srv.AuthInterceptor(ctx, &pb.ListRequest{}, &grpc.UnaryServerInfo{FullMethod: "/gokeepas.KeepPas/List"}, func(c context.Context, r any) (any, error) {
+		called = true
+		return nil, nil
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.False(t, called)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
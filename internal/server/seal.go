@@ -0,0 +1,184 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/journal"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/shamir"
+	"github.com/hrapovd1/gokeepas/internal/storage"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// InitSeal splits master key in shares, any threshold of them unseal server. New key is generated
+// when key is empty, existed key must match db. Hashes of shares are kept in db, shares are returned
+// in base64 and they aren't kept anywhere. Public key of journal checkpoints is kept in db, recorded
+// in journal and returned, journal is verified by it without master key.
+func InitSeal(ctx context.Context, stor storage.Storage, key []byte, shares int, threshold int) ([]string, string, error) {
+	if len(key) == 0 {
+		srvKey, err := crypto.GenServerKey(crypto.SymmKeyLength)
+		if err != nil {
+			return nil, "", err
+		}
+		key = []byte(srvKey)
+	}
+	parts, err := shamir.Split(key, shares, threshold)
+	if err != nil {
+		return nil, "", err
+	}
+	journalKey, err := JournalPublicKey(key)
+	if err != nil {
+		return nil, "", err
+	}
+	out := make([]string, 0, len(parts))
+	hashes := make([]string, 0, len(parts))
+	for _, part := range parts {
+		out = append(out, base64.StdEncoding.EncodeToString(part))
+		hashes = append(hashes, shareHash(part))
+	}
+	cfg := types.SealConfig{Shares: shares, Threshold: threshold, ShareHashes: strings.Join(hashes, ","), JournalKey: journalKey}
+	if err := stor.InitSeal(ctx, &cfg, key); err != nil {
+		return nil, "", err
+	}
+	detail := fmt.Sprintf("shares %d, threshold %d, journal key %s", shares, threshold, journalKey)
+	if err := stor.AppendJournal(ctx, journal.Event(journal.KindInit, "", detail)); err != nil {
+		return nil, "", err
+	}
+	return out, journalKey, nil
+}
+
+// shareHash returns hex sha256 of key share, shares have enough entropy for plain hash.
+func shareHash(share []byte) string {
+	sum := sha256.Sum256(share)
+	return hex.EncodeToString(sum[:])
+}
+
+// serverKey returns copy of master key, it is empty while server is sealed. Copy isn't wiped
+// by Seal, so call in progress doesn't use partly wiped key.
+func (kps *KeepPasSrv) serverKey() []byte {
+	kps.keyMu.RLock()
+	defer kps.keyMu.RUnlock()
+	if len(kps.conf.ServerKey) == 0 {
+		return nil
+	}
+	return append([]byte(nil), kps.conf.ServerKey...)
+}
+
+func (kps *KeepPasSrv) isSealed() bool {
+	kps.keyMu.RLock()
+	defer kps.keyMu.RUnlock()
+	return len(kps.conf.ServerKey) == 0
+}
+
+// checkShare decodes key share and checks it is one of shares created by init. Wrong shares
+// lock client address, so calls without share can't flood security journal.
+func (kps *KeepPasSrv) checkShare(ctx context.Context, req *pb.KeyShare, kind string) ([]byte, *types.SealConfig, error) {
+	if err := kps.checkSubjects(ctx, shareSubjects(ctx)); err != nil {
+		return nil, nil, err
+	}
+	share, cfg, err := kps.findShare(ctx, req)
+	if status.Code(err) == codes.InvalidArgument {
+		kps.recordSecurity(ctx, kind, "", status.Convert(err).Message())
+		kps.recordFailure(ctx, "", shareSubjects(ctx))
+	}
+	return share, cfg, err
+}
+
+// findShare decodes key share and finds it in shares created by init.
+func (kps *KeepPasSrv) findShare(ctx context.Context, req *pb.KeyShare) ([]byte, *types.SealConfig, error) {
+	cfg := types.SealConfig{}
+	if err := kps.Stor.GetSealConfig(ctx, &cfg); err != nil {
+		kps.logger.Debug(err)
+		return nil, nil, status.Errorf(codes.Internal, "error when get seal config")
+	}
+	if cfg.Threshold == 0 {
+		return nil, nil, status.Error(codes.FailedPrecondition, "server isn't initialized, run keeppas-server init")
+	}
+	share, err := base64.StdEncoding.DecodeString(req.Share)
+	if err != nil {
+		return nil, nil, status.Error(codes.InvalidArgument, "wrong key share")
+	}
+	hash := shareHash(share)
+	for _, h := range strings.Split(cfg.ShareHashes, ",") {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			return share, &cfg, nil
+		}
+	}
+	return nil, nil, status.Error(codes.InvalidArgument, "wrong key share")
+}
+
+// Unseal accepts master key share, when threshold of shares is reached master key is restored
+// and server starts to serve requests.
+func (kps *KeepPasSrv) Unseal(ctx context.Context, req *pb.KeyShare) (*pb.SealStatus, error) {
+	share, cfg, err := kps.checkShare(ctx, req, journal.KindUnseal)
+	if err != nil {
+		return nil, err
+	}
+	kps.keyMu.Lock()
+	defer kps.keyMu.Unlock()
+	if len(kps.conf.ServerKey) > 0 {
+		return &pb.SealStatus{Threshold: int32(cfg.Threshold), Progress: int32(cfg.Threshold)}, nil
+	}
+	for _, s := range kps.shares {
+		if bytes.Equal(s, share) {
+			return &pb.SealStatus{Sealed: true, Threshold: int32(cfg.Threshold), Progress: int32(len(kps.shares))}, nil
+		}
+	}
+	kps.shares = append(kps.shares, share)
+	kps.recordSecurity(ctx, journal.KindUnseal, "", fmt.Sprintf("key share is accepted, %d of %d", len(kps.shares), cfg.Threshold))
+	if len(kps.shares) < cfg.Threshold {
+		return &pb.SealStatus{Sealed: true, Threshold: int32(cfg.Threshold), Progress: int32(len(kps.shares))}, nil
+	}
+	key, err := shamir.Combine(kps.shares)
+	kps.shares = nil
+	if err != nil {
+		kps.logger.Debug(err)
+		kps.recordSecurity(ctx, journal.KindUnseal, "", "wrong key shares")
+		return nil, status.Error(codes.InvalidArgument, "wrong key shares, start again")
+	}
+	if err := kps.Stor.Ping(ctx, key); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Error(codes.InvalidArgument, "key shares don't match db, start again")
+	}
+	kps.conf.ServerKey = key
+	kps.recordSecurity(ctx, journal.KindUnseal, "", "server is unsealed")
+	kps.logger.Info("server is unsealed")
+	return &pb.SealStatus{Threshold: int32(cfg.Threshold), Progress: int32(cfg.Threshold)}, nil
+}
+
+// Seal wipes master key from memory, server rejects requests until it is unsealed again.
+// Requests in progress may fail.
+func (kps *KeepPasSrv) Seal(ctx context.Context, req *pb.KeyShare) (*pb.SealStatus, error) {
+	_, cfg, err := kps.checkShare(ctx, req, journal.KindSeal)
+	if err != nil {
+		return nil, err
+	}
+	kps.keyMu.Lock()
+	defer kps.keyMu.Unlock()
+	if len(kps.conf.ServerKey) > 0 {
+		// sign journal up to seal, key isn't available after it
+		kps.recordSecurity(ctx, journal.KindSeal, "", "server is sealed")
+		if err := kps.checkpointJournal(ctx, kps.conf.ServerKey); err != nil {
+			kps.logger.Errorf("security journal checkpoint: %v", err)
+		}
+	}
+	// only server keeps the buffer, callers get copies of it
+	key := kps.conf.ServerKey
+	kps.conf.ServerKey = nil
+	for i := range key {
+		key[i] = 0
+	}
+	kps.shares = nil
+	kps.logger.Info("server is sealed")
+	return &pb.SealStatus{Sealed: true, Threshold: int32(cfg.Threshold)}, nil
+}
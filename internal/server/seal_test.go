@@ -0,0 +1,144 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/journal"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/shamir"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInitSeal(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	key := []byte("wfgxRxAwTILuvwpqD3JSgqnE")
+	keyHash, err := crypto.HashPasswd(context.Background(), key)
+	require.NoError(t, err)
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectWatch("/seal", "server")
+		mock.ExpectExists("/seal").SetVal(0)
+		mock.ExpectHGetAll("server").SetVal(map[string]string{"pass": keyHash})
+		mock.ExpectTxPipeline()
+		mock.ExpectHSet("server", "pass", keyHash).SetVal(0)
+		journalKey, err := JournalPublicKey(key)
+		require.NoError(t, err)
+		mock.Regexp().ExpectHSet("/seal", "shares", 3, "threshold", 2, "hashes", `^[0-9a-f]{64}(,[0-9a-f]{64}){2}$`, "journalkey", "^"+regexp.QuoteMeta(journalKey)+"$").SetVal(4)
+		mock.ExpectTxPipelineExec()
+		expectJournal(mock, "", journal.KindInit)
+		shares, pub, err := InitSeal(context.Background(), srv.Stor, key, 3, 2)
+		require.NoError(t, err)
+		assert.Equal(t, journalKey, pub)
+		require.Len(t, shares, 3)
+		parts := make([][]byte, 0, 2)
+		for _, s := range shares[1:] {
+			part, err := base64.StdEncoding.DecodeString(s)
+			require.NoError(t, err)
+			parts = append(parts, part)
+		}
+		restored, err := shamir.Combine(parts)
+		require.NoError(t, err)
+		assert.Equal(t, key, restored)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("other key", func(t *testing.T) {
+		mock.ExpectWatch("/seal", "server")
+		mock.ExpectExists("/seal").SetVal(0)
+		mock.ExpectHGetAll("server").SetVal(map[string]string{"pass": "other"})
+		_, _, err := InitSeal(context.Background(), srv.Stor, key, 3, 2)
+		assert.Error(t, err)
+		mock.ClearExpect()
+	})
+	t.Run("wrong threshold", func(t *testing.T) {
+		_, _, err := InitSeal(context.Background(), srv.Stor, key, 3, 4)
+		assert.ErrorIs(t, err, shamir.ErrParams)
+	})
+}
+
+func TestKeepPasSrv_Unseal(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	key := append([]byte{}, srv.conf.ServerKey...)
+	srv.conf = config.Config{}
+	keyHash, err := crypto.HashPasswd(context.Background(), key)
+	require.NoError(t, err)
+	parts, err := shamir.Split(key, 3, 2)
+	require.NoError(t, err)
+	shares := make([]string, 0, len(parts))
+	hashes := make([]string, 0, len(parts))
+	for _, part := range parts {
+		shares = append(shares, base64.StdEncoding.EncodeToString(part))
+		hashes = append(hashes, shareHash(part))
+	}
+	sealCfg := map[string]string{"shares": "3", "threshold": "2", "hashes": strings.Join(hashes, ",")}
+	handler := func(context.Context, any) (any, error) { return &pb.AuthResponse{}, nil }
+	ctx := context.Background()
+
+	// sealed server rejects requests
+	_, err = srv.AuthInterceptor(ctx, &pb.AuthRequest{}, &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	t.Run("wrong share", func(t *testing.T) {
+		mock.ExpectHGetAll("/seal").SetVal(sealCfg)
+		_, err := srv.Unseal(ctx, &pb.KeyShare{Share: base64.StdEncoding.EncodeToString([]byte("wrong"))})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		mock.ClearExpect()
+	})
+	t.Run("first share", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			// repeated share isn't counted
+			mock.ExpectHGetAll("/seal").SetVal(sealCfg)
+			resp, err := srv.Unseal(ctx, &pb.KeyShare{Share: shares[0]})
+			require.NoError(t, err)
+			assert.Equal(t, &pb.SealStatus{Sealed: true, Threshold: 2, Progress: 1}, resp)
+		}
+		mock.ClearExpect()
+	})
+	t.Run("threshold", func(t *testing.T) {
+		mock.ExpectHGetAll("/seal").SetVal(sealCfg)
+		expectJournal(mock, "", journal.KindUnseal)
+		mock.ExpectPing().SetVal("PONG")
+		mock.ExpectHGetAll("server").SetVal(map[string]string{"pass": keyHash})
+		expectJournal(mock, "", journal.KindMasterKeyCheck)
+		expectJournal(mock, "", journal.KindUnseal)
+		resp, err := srv.Unseal(ctx, &pb.KeyShare{Share: shares[2]})
+		require.NoError(t, err)
+		assert.False(t, resp.Sealed)
+		assert.Equal(t, key, srv.serverKey())
+		_, err = srv.AuthInterceptor(ctx, &pb.AuthRequest{}, &grpc.UnaryServerInfo{}, handler)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("seal", func(t *testing.T) {
+		mock.ExpectHGetAll("/seal").SetVal(sealCfg)
+		expectJournal(mock, "", journal.KindSeal)
+		expectJournal(mock, `{"seq":1,"kind":"seal","time":1,"prev":"","hash":"aa"}`, journal.KindCheckpoint)
+		// key of call in progress isn't wiped
+		held := srv.serverKey()
+		resp, err := srv.Seal(ctx, &pb.KeyShare{Share: shares[1]})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.True(t, resp.Sealed)
+		assert.Empty(t, srv.serverKey())
+		assert.Equal(t, key, held)
+		_, err = srv.AuthInterceptor(ctx, &pb.AuthRequest{}, &grpc.UnaryServerInfo{}, handler)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		mock.ClearExpect()
+	})
+	t.Run("not initialized", func(t *testing.T) {
+		mock.ExpectHGetAll("/seal").SetVal(map[string]string{})
+		_, err := srv.Unseal(ctx, &pb.KeyShare{Share: shares[0]})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		mock.ClearExpect()
+	})
+}
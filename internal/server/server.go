@@ -3,9 +3,17 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/hrapovd1/gokeepas/internal/audit"
 	"github.com/hrapovd1/gokeepas/internal/config"
 	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/journal"
+	"github.com/hrapovd1/gokeepas/internal/kek"
+	"github.com/hrapovd1/gokeepas/internal/metrics"
 	pb "github.com/hrapovd1/gokeepas/internal/proto"
 	"github.com/hrapovd1/gokeepas/internal/storage"
 	"github.com/hrapovd1/gokeepas/internal/types"
@@ -22,6 +30,12 @@ type KeepPasSrv struct {
 	Stor   storage.Storage
 	conf   config.Config
 	logger *zap.SugaredLogger
+	keyMu  sync.RWMutex // guards conf.ServerKey and shares, key is empty while server is sealed
+	shares [][]byte     // accepted master key shares while server is sealed
+	kek    kek.Provider // wraps user keys, master key is used when it is nil
+	audit  audit.Sink   // records rpc calls, nothing is recorded when it is nil
+	// Metrics records Prometheus metrics of server, nothing is recorded when it is nil
+	Metrics *metrics.Metrics
 }
 
 // NewKeepPasSrv constructs new app grpc server from config
@@ -36,17 +50,40 @@ func NewKeepPasSrv(l *zap.Logger, conf config.Config) (*KeepPasSrv, error) {
 		conf:   conf,
 		logger: l.Sugar(),
 	}
+	// master key is wiped by Seal, it mustn't share buffer with caller
+	server.conf.ServerKey = append([]byte(nil), conf.ServerKey...)
+	if conf.Metrics != "" {
+		server.Metrics = metrics.New()
+		if err := server.Metrics.RegisterRedis(storage); err != nil {
+			return nil, err
+		}
+		server.Stor = metrics.Storage(server.Metrics, storage)
+	}
+	server.kek, err = newKEK(conf, server.serverKey)
+	if err != nil {
+		return nil, err
+	}
+	server.audit, err = newAuditSink(conf, server.Stor)
+	if err != nil {
+		return nil, err
+	}
 	return &server, nil
 }
 
 // SignUp implements sign up process for new users, it creates new user and makes login for it.
+// Sign up of existing user is rejected and counted as failed login of client address, so it
+// can't be used to guess passwords or enumerate users without limit.
 func (kps *KeepPasSrv) SignUp(ctx context.Context, req *pb.AuthRequest) (*pb.AuthResponse, error) {
 	data := types.StorageModel{}
 	// check reserved names
-	if req.Login == "server" || req.Login == "/users" {
+	if req.Login == "" || req.Login == "server" || strings.HasPrefix(req.Login, "/") {
 		kps.logger.Debugf("prohibited login: %v", req.Login)
 		return nil, status.Error(codes.Unauthenticated, "wrong login or password")
 	}
+	// only client address is checked, login of other user can't be locked by sign up
+	if err := kps.checkLockout(ctx, ""); err != nil {
+		return nil, err
+	}
 	userKey := "/users/" + req.Login
 	if err := kps.Stor.Get(ctx, userKey, &data); err != nil {
 		kps.logger.Debug(err)
@@ -54,8 +91,8 @@ func (kps *KeepPasSrv) SignUp(ctx context.Context, req *pb.AuthRequest) (*pb.Aut
 	}
 	// check if user exists
 	if data.PassHash != "" {
-		// if exists - log-in
-		return kps.LogIn(ctx, req)
+		kps.recordLoginFailure(ctx, "")
+		return nil, status.Error(codes.AlreadyExists, "user already exists")
 	}
 	// create new user
 	userSymmKey, err := crypto.GenSymmKey(crypto.SymmKeyLength)
@@ -63,8 +100,7 @@ func (kps *KeepPasSrv) SignUp(ctx context.Context, req *pb.AuthRequest) (*pb.Aut
 		kps.logger.Debug(err)
 		return nil, err
 	}
-	data.SymmKey, err = crypto.EncryptKey([]byte(kps.conf.ServerKey), userSymmKey)
-	kps.logger.Debugf("srvKey: %v, usrKey: %v", kps.conf.ServerKey, userSymmKey)
+	data.SymmKey, err = kps.wrapUserKey(ctx, userSymmKey)
 	if err != nil {
 		kps.logger.Debug(err)
 		return nil, err
@@ -78,12 +114,16 @@ func (kps *KeepPasSrv) SignUp(ctx context.Context, req *pb.AuthRequest) (*pb.Aut
 		kps.logger.Debug(err)
 		return nil, err
 	}
+	kps.recordSecurity(ctx, journal.KindSignUp, req.Login, "")
 	// log-in after create user
 	return kps.LogIn(ctx, req)
 }
 
 // LogIn makes login for existed users, it returns bearer token or error
 func (kps *KeepPasSrv) LogIn(ctx context.Context, req *pb.AuthRequest) (*pb.AuthResponse, error) {
+	if err := kps.checkLockout(ctx, req.Login); err != nil {
+		return nil, err
+	}
 	data := types.StorageModel{}
 	userKey := "/users/" + req.Login
 	if err := kps.Stor.Get(ctx, userKey, &data); err != nil {
@@ -93,24 +133,132 @@ func (kps *KeepPasSrv) LogIn(ctx context.Context, req *pb.AuthRequest) (*pb.Auth
 	// check if user exists
 	if data.PassHash == "" {
 		kps.logger.Debugf("got empty pass hash, data: %v", data)
+		kps.recordSecurity(ctx, journal.KindLoginFailed, req.Login, "unknown user")
+		kps.Metrics.ObserveLogin(metrics.LoginFailure)
+		kps.recordLoginFailure(ctx, req.Login)
 		return nil, status.Error(codes.Unauthenticated, "wrong login or password")
 	}
-	userToken, err := crypto.GetToken(ctx, req.Login, req.Password, data, kps.conf.ServerKey)
+	sid, err := crypto.GenSessionID()
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, err
+	}
+	userToken, err := crypto.GetToken(ctx, req.Login, req.Password, sid, data, kps.serverKey(), kps.tokenTTL())
 	if err != nil {
 		kps.logger.Debug(err)
+		kps.recordSecurity(ctx, journal.KindLoginFailed, req.Login, "wrong password")
+		kps.Metrics.ObserveLogin(metrics.LoginFailure)
+		kps.recordLoginFailure(ctx, req.Login)
 		return nil, status.Error(codes.Unauthenticated, "wrong login or password")
 	}
-	symmKey, err := crypto.DecryptKey([]byte(kps.conf.ServerKey), data.SymmKey)
+	if err := kps.check2FA(ctx, req.Login, req.Otp); err != nil {
+		kps.recordSecurity(ctx, journal.KindLoginFailed, req.Login, status.Convert(err).Message())
+		kps.Metrics.ObserveLogin(metrics.LoginFailure)
+		if status.Code(err) == codes.Unauthenticated {
+			// missed one-time code isn't a guess
+			kps.recordLoginFailure(ctx, req.Login)
+		}
+		return nil, err
+	}
+	symmKey, stale, err := kps.unwrapUserKey(ctx, data.SymmKey)
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, err
+	}
+	if stale {
+		kps.rewrapUserKey(ctx, userKey, data, symmKey)
+	}
+	refreshToken, err := crypto.GenRefreshToken(sid)
 	if err != nil {
 		kps.logger.Debug(err)
 		return nil, err
 	}
+	sess := types.Session{Login: req.Login, RefreshHash: crypto.HashToken(refreshToken)}
+	if err := kps.Stor.AddSession(ctx, sid, &sess, kps.refreshTTL()); err != nil {
+		kps.logger.Debug(err)
+		return nil, err
+	}
+	kps.Metrics.ObserveLogin(metrics.LoginSuccess)
+	kps.resetLockout(ctx, req.Login)
 	return &pb.AuthResponse{
-		SymmKey:   symmKey,
-		AuthToken: userToken,
+		SymmKey:      symmKey,
+		AuthToken:    userToken,
+		RefreshToken: refreshToken,
+		Login:        req.Login,
 	}, nil
 }
 
+// Refresh rotates refresh token of client session and returns new bearer token.
+// Reuse of already rotated refresh token revokes the whole session.
+func (kps *KeepPasSrv) Refresh(ctx context.Context, req *pb.RefreshRequest) (*pb.AuthResponse, error) {
+	sid, sess, err := kps.checkRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := crypto.GenRefreshToken(sid)
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, err
+	}
+	newSess := types.Session{Login: sess.Login, RefreshHash: crypto.HashToken(refreshToken)}
+	if err := kps.Stor.RotateSession(ctx, sid, sess.RefreshHash, &newSess, kps.refreshTTL()); err != nil {
+		kps.logger.Debug(err)
+		if err == storage.ErrSessionChanged {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return nil, err
+	}
+	userToken, err := crypto.NewToken(sess.Login, sid, kps.serverKey(), kps.tokenTTL())
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, err
+	}
+	return &pb.AuthResponse{
+		AuthToken:    userToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// LogOut revokes client session, all tokens of the session are rejected after it.
+func (kps *KeepPasSrv) LogOut(ctx context.Context, req *pb.RefreshRequest) (*pb.BinResponse, error) {
+	sid, _, err := kps.checkRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := kps.Stor.RemoveSession(ctx, sid); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when logout")
+	}
+	return &pb.BinResponse{}, nil
+}
+
+// checkRefreshToken returns session of refresh token or error if token is wrong or revoked
+func (kps *KeepPasSrv) checkRefreshToken(ctx context.Context, token string) (string, *types.Session, error) {
+	sid, err := crypto.ParseRefreshToken(token)
+	if err != nil {
+		kps.logger.Debug(err)
+		return "", nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	sess := types.Session{}
+	if err := kps.Stor.GetSession(ctx, sid, &sess); err != nil {
+		kps.logger.Debug(err)
+		return "", nil, err
+	}
+	if sess.Login == "" {
+		kps.logger.Debugf("session %v doesn't exist", sid)
+		return "", nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	if !crypto.EqualHash(sess.RefreshHash, crypto.HashToken(token)) {
+		// token was already rotated, somebody else uses it - revoke session
+		kps.logger.Debugf("reuse of refresh token in session %v", sid)
+		if err := kps.Stor.RemoveSession(ctx, sid); err != nil {
+			kps.logger.Debug(err)
+		}
+		return "", nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return sid, &sess, nil
+}
+
 // GetKey returns user symmetric key for data encryption
 func (kps *KeepPasSrv) GetKey(ctx context.Context, _ *pb.BinRequest) (*pb.AuthResponse, error) {
 	data := types.StorageModel{}
@@ -135,13 +283,14 @@ func (kps *KeepPasSrv) GetKey(ctx context.Context, _ *pb.BinRequest) (*pb.AuthRe
 	if data.PassHash == "" {
 		return nil, status.Error(codes.Unauthenticated, "wrong login or password")
 	}
-	symmKey, err := crypto.DecryptKey([]byte(kps.conf.ServerKey), data.SymmKey)
+	symmKey, _, err := kps.unwrapUserKey(ctx, data.SymmKey)
 	if err != nil {
 		kps.logger.Debug(err)
 		return nil, err
 	}
 	return &pb.AuthResponse{
 		SymmKey: symmKey,
+		Login:   login,
 	}, nil
 }
 
@@ -159,13 +308,18 @@ func (kps *KeepPasSrv) Add(ctx context.Context, req *pb.BinRequest) (*pb.BinResp
 			return nil, status.Error(codes.Unauthenticated, "wrong login or password")
 		}
 	}
-	data := types.StorageModel{Data: string(req.Data), Type: req.Type.String()}
-	key := login + "/" + req.Key
+	prefix, err := kps.secretsPrefix(ctx, login, true)
+	if err != nil {
+		return nil, err
+	}
+	data := types.StorageModel{SymmKey: req.DataKey, Data: string(req.Data), Type: req.Type.String()}
+	key := prefix + req.Key
 	kps.logger.Debugf("name: %v, data: %v", key, req.Data)
 	if err := kps.Stor.Add(ctx, key, &data); err != nil {
 		kps.logger.Debug(err)
 		return nil, err
 	}
+	kps.publish(prefix, types.Event{Kind: types.EventAdd, Key: req.Key, Type: data.Type})
 	return &pb.BinResponse{}, nil
 }
 
@@ -183,13 +337,17 @@ func (kps *KeepPasSrv) Get(ctx context.Context, req *pb.BinRequest) (*pb.GetResp
 			return nil, status.Error(codes.Unauthenticated, "wrong login or password")
 		}
 	}
+	prefix, err := kps.secretsPrefix(ctx, login, false)
+	if err != nil {
+		return nil, err
+	}
 	data := types.StorageModel{}
-	key := login + "/" + req.Key
+	key := prefix + req.Key
 	if err := kps.Stor.Get(ctx, key, &data); err != nil {
 		kps.logger.Debug(err)
 		return nil, err
 	}
-	resp := pb.GetResponse{Data: []byte(data.Data), Key: req.Key}
+	resp := pb.GetResponse{Data: []byte(data.Data), Key: req.Key, DataKey: data.SymmKey}
 	switch data.Type {
 	case "TEXT":
 		resp.Type = pb.Type_TEXT
@@ -199,6 +357,8 @@ func (kps *KeepPasSrv) Get(ctx context.Context, req *pb.BinRequest) (*pb.GetResp
 		resp.Type = pb.Type_LOGIN
 	case "CART":
 		resp.Type = pb.Type_CART
+	case "OTP":
+		resp.Type = pb.Type_OTP
 	default:
 		return nil, status.Errorf(codes.NotFound, "key doesn't exists")
 	}
@@ -219,15 +379,21 @@ func (kps *KeepPasSrv) Remove(ctx context.Context, req *pb.BinRequest) (*pb.BinR
 			return nil, status.Error(codes.Unauthenticated, "wrong login or password")
 		}
 	}
-	key := login + "/" + req.Key
+	prefix, err := kps.secretsPrefix(ctx, login, true)
+	if err != nil {
+		return nil, err
+	}
+	key := prefix + req.Key
 	if err := kps.Stor.Remove(ctx, key); err != nil {
 		kps.logger.Debug(err)
 		return nil, status.Errorf(codes.Internal, "error when remove")
 	}
+	kps.publish(prefix, types.Event{Kind: types.EventRemove, Key: req.Key})
 	return &pb.BinResponse{}, nil
 }
 
-// Rename implements process of rename existed secret
+// Rename implements process of rename existed secret.
+// If req.Data or req.DataKey is provided, it is stored for new key instead of old one.
 func (kps *KeepPasSrv) Rename(ctx context.Context, req *pb.BinRequest) (*pb.BinResponse, error) {
 	var login string
 	md, ok := metadata.FromIncomingContext(ctx)
@@ -241,7 +407,11 @@ func (kps *KeepPasSrv) Rename(ctx context.Context, req *pb.BinRequest) (*pb.BinR
 			return nil, status.Error(codes.Unauthenticated, "wrong login or password")
 		}
 	}
-	oldKey := login + "/" + req.Key
+	prefix, err := kps.secretsPrefix(ctx, login, true)
+	if err != nil {
+		return nil, err
+	}
+	oldKey := prefix + req.Key
 	data := types.StorageModel{}
 	if err := kps.Stor.Get(ctx, oldKey, &data); err != nil {
 		kps.logger.Debug(err)
@@ -250,8 +420,18 @@ func (kps *KeepPasSrv) Rename(ctx context.Context, req *pb.BinRequest) (*pb.BinR
 	if data.Type == "" {
 		return nil, status.Errorf(codes.NotFound, "key doesn't exists")
 	}
-	newKey := login + "/" + req.NewKey
-	if err := kps.Stor.Copy(ctx, oldKey, newKey); err != nil {
+	newKey := prefix + req.NewKey
+	if req.Data != "" || req.DataKey != "" {
+		// client re-encrypted secret or re-wrapped its data key for new key name
+		if req.Data != "" {
+			data.Data = req.Data
+		}
+		data.SymmKey = req.DataKey
+		if err := kps.Stor.Add(ctx, newKey, &data); err != nil {
+			kps.logger.Debug(err)
+			return nil, status.Errorf(codes.Internal, "error when rename: %d", err)
+		}
+	} else if err := kps.Stor.Copy(ctx, oldKey, newKey); err != nil {
 		kps.logger.Debug(err)
 		return nil, status.Errorf(codes.Internal, "error when rename: %d", err)
 	}
@@ -259,6 +439,7 @@ func (kps *KeepPasSrv) Rename(ctx context.Context, req *pb.BinRequest) (*pb.BinR
 		kps.logger.Debug(err)
 		return nil, status.Errorf(codes.Internal, "error when rename: %d", err)
 	}
+	kps.publish(prefix, types.Event{Kind: types.EventRename, Key: req.Key, NewKey: req.NewKey, Type: data.Type})
 	return &pb.BinResponse{}, nil
 }
 
@@ -276,7 +457,11 @@ func (kps *KeepPasSrv) Update(ctx context.Context, req *pb.BinRequest) (*pb.BinR
 			return nil, status.Error(codes.Unauthenticated, "wrong login or password")
 		}
 	}
-	key := login + "/" + req.Key
+	prefix, err := kps.secretsPrefix(ctx, login, true)
+	if err != nil {
+		return nil, err
+	}
+	key := prefix + req.Key
 	data := types.StorageModel{}
 	if err := kps.Stor.Get(ctx, key, &data); err != nil {
 		kps.logger.Debug(err)
@@ -285,14 +470,16 @@ func (kps *KeepPasSrv) Update(ctx context.Context, req *pb.BinRequest) (*pb.BinR
 	if data.Type == "" {
 		return nil, status.Errorf(codes.NotFound, "key doesn't exists")
 	}
-	if err := kps.Stor.Update(ctx, key, &types.StorageModel{Data: string(req.Data), Type: req.Type.String()}); err != nil {
+	if err := kps.Stor.Update(ctx, key, &types.StorageModel{SymmKey: req.DataKey, Data: string(req.Data), Type: req.Type.String()}); err != nil {
 		kps.logger.Debug(err)
 		return nil, status.Errorf(codes.Internal, "error when update: %d", err)
 	}
+	kps.publish(prefix, types.Event{Kind: types.EventUpdate, Key: req.Key, Type: req.Type.String()})
 	return &pb.BinResponse{}, nil
 }
 
-// Copy implements clone of existed secret
+// Copy implements clone of existed secret.
+// If req.Data or req.DataKey is provided, it is stored for new key instead of source one.
 func (kps *KeepPasSrv) Copy(ctx context.Context, req *pb.BinRequest) (*pb.BinResponse, error) {
 	var login string
 	md, ok := metadata.FromIncomingContext(ctx)
@@ -306,7 +493,11 @@ func (kps *KeepPasSrv) Copy(ctx context.Context, req *pb.BinRequest) (*pb.BinRes
 			return nil, status.Error(codes.Unauthenticated, "wrong login or password")
 		}
 	}
-	srcKey := login + "/" + req.Key
+	prefix, err := kps.secretsPrefix(ctx, login, true)
+	if err != nil {
+		return nil, err
+	}
+	srcKey := prefix + req.Key
 	data := types.StorageModel{}
 	if err := kps.Stor.Get(ctx, srcKey, &data); err != nil {
 		kps.logger.Debug(err)
@@ -315,41 +506,195 @@ func (kps *KeepPasSrv) Copy(ctx context.Context, req *pb.BinRequest) (*pb.BinRes
 	if data.Type == "" {
 		return nil, status.Errorf(codes.NotFound, "key doesn't exists")
 	}
-	dstKey := login + "/" + req.NewKey
+	dstKey := prefix + req.NewKey
+	if req.Data != "" || req.DataKey != "" {
+		// client re-encrypted secret or re-wrapped its data key for new key name
+		if req.Data != "" {
+			data.Data = req.Data
+		}
+		data.SymmKey = req.DataKey
+	}
 	if err := kps.Stor.Add(ctx, dstKey, &data); err != nil {
 		kps.logger.Debug(err)
 		return nil, status.Errorf(codes.Internal, "error when copy: %d", err)
 	}
+	kps.publish(prefix, types.Event{Kind: types.EventAdd, Key: req.NewKey, Type: data.Type})
 	return &pb.BinResponse{}, nil
 }
 
-// List return existed key names in one line: "'key1','key2',..."
-func (kps *KeepPasSrv) List(ctx context.Context, _ *pb.BinRequest) (*pb.ListResponse, error) {
-	var login string
+// List returns page of secrets sorted by name with their types, filtered by name prefix and types.
+func (kps *KeepPasSrv) List(ctx context.Context, req *pb.ListRequest) (*pb.ListResponse, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := kps.secretsPrefix(ctx, login, false)
+	if err != nil {
+		return nil, err
+	}
+	if req.PageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "wrong page size")
+	}
+	after, err := base64.RawURLEncoding.DecodeString(req.PageToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "wrong page token")
+	}
+	filter := types.ListFilter{Prefix: req.Prefix, After: string(after), Limit: int(req.PageSize)}
+	for _, t := range req.Types {
+		filter.Types = append(filter.Types, t.String())
+	}
+	secrets, more, err := kps.Stor.ListSecrets(ctx, prefix, filter)
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when list")
+	}
+	resp := pb.ListResponse{Entries: make([]*pb.ListEntry, 0, len(secrets))}
+	names := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		resp.Entries = append(resp.Entries, &pb.ListEntry{Name: s.Name, Type: pb.Type(pb.Type_value[s.Type]), DataKey: s.DataKey})
+		names = append(names, s.Name)
+	}
+	resp.Keys = legacyKeys(names)
+	if more {
+		resp.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(names[len(names)-1]))
+	}
+	kps.logger.Debugf("List keys: %s", resp.Keys)
+	return &resp, nil
+}
+
+// legacyKeys joins names in line of old clients: 'key1','key2', quote in name is escaped as \'
+func legacyKeys(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "'" + strings.ReplaceAll(name, "'", `\'`) + "'"
+	}
+	return strings.Join(quoted, ",")
+}
+
+// tokenTTL returns live time of jwt tokens from config or default one.
+func (kps *KeepPasSrv) tokenTTL() time.Duration {
+	if kps.conf.TokenTTL > 0 {
+		return kps.conf.TokenTTL
+	}
+	return crypto.ExpireDuration
+}
+
+// refreshTTL returns live time of refresh tokens and sessions from config or default one.
+func (kps *KeepPasSrv) refreshTTL() time.Duration {
+	if kps.conf.RefreshTTL > 0 {
+		return kps.conf.RefreshTTL
+	}
+	return crypto.RefreshExpireDuration
+}
+
+// getLogin returns login of authenticated user from context metadata
+func (kps *KeepPasSrv) getLogin(ctx context.Context) (string, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	kps.logger.Debugf("got metadata: %v", md)
 	if ok {
 		values := md.Get("login")
-		if len(values) > 0 {
-			login = values[0]
-		} else {
-			kps.logger.Debug("Login metadata is empty")
-			return nil, status.Error(codes.Unauthenticated, "wrong login or password")
+		if len(values) > 0 && values[0] != "" {
+			return values[0], nil
 		}
 	}
-	keyPattern := login + "/*"
-	keys := kps.Stor.List(ctx, keyPattern)
-	kps.logger.Debugf("List keys: %s", keys)
-	return &pb.ListResponse{
-		Keys: keys,
-	}, nil
+	kps.logger.Debug("Login metadata is empty")
+	return "", status.Error(codes.Unauthenticated, "wrong login or password")
 }
 
-// AuthInterceptor check bearer token from metadata and allow or reject access
-func (kps *KeepPasSrv) AuthInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-	if _, ok := req.(*pb.AuthRequest); ok {
+// AuthInterceptor check bearer token from metadata and allow or reject access,
+// every call is recorded in audit log. Health and reflection calls pass without token.
+// Calls of user are limited by rate of method class and count of concurrent calls.
+func (kps *KeepPasSrv) AuthInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	if isPublicMethod(info.FullMethod) {
 		return handler(ctx, req)
 	}
+	login := ""
+	defer func() {
+		if r, ok := req.(*pb.AuthRequest); ok {
+			login = kps.authAuditLogin(ctx, r.Login, err)
+		}
+		kps.recordAudit(ctx, login, info.FullMethod, req, err)
+	}()
+	if _, ok := req.(*pb.KeyShare); ok {
+		// Unseal and Seal check key share instead of token
+		return handler(ctx, req)
+	}
+	if kps.isSealed() {
+		return nil, status.Error(codes.Unavailable, "server is sealed")
+	}
+	switch req.(type) {
+	case *pb.AuthRequest, *pb.RefreshRequest:
+		return handler(ctx, req)
+	}
+	lctx, err := kps.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	login, _ = kps.getLogin(lctx)
+	release, err := kps.limitCall(lctx, login, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	kps.logger.Debugf("info: %v", info.FullMethod)
+
+	resp, err = handler(lctx, req)
+	if err != nil {
+		kps.logger.Debug(err)
+		kps.logger.Errorf("rpc interceptor got error: %v", err)
+	}
+
+	return resp, err
+}
+
+// StreamAuthInterceptor check bearer token from metadata of streaming call and allow or reject access,
+// every call is recorded in audit log when it ends. Health and reflection calls pass without token.
+// Start of stream is limited by rate of method class.
+func (kps *KeepPasSrv) StreamAuthInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	if isPublicMethod(info.FullMethod) {
+		return handler(srv, ss)
+	}
+	login := ""
+	defer func() {
+		kps.recordAudit(ss.Context(), login, info.FullMethod, nil, err)
+	}()
+	if kps.isSealed() {
+		return status.Error(codes.Unavailable, "server is sealed")
+	}
+	lctx, err := kps.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	login, _ = kps.getLogin(lctx)
+	// streams live long, so they take token of rate limit but no slot of in-flight calls
+	if err := kps.checkRateLimit(lctx, login, info.FullMethod); err != nil {
+		return err
+	}
+
+	kps.logger.Debugf("info: %v", info.FullMethod)
+
+	err = handler(srv, &authStream{ServerStream: ss, ctx: lctx})
+	if err != nil {
+		kps.logger.Debug(err)
+		kps.logger.Errorf("rpc stream interceptor got error: %v", err)
+	}
+	return err
+}
+
+// authStream is server stream with context of authenticated user
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns context with login of authenticated user
+func (as *authStream) Context() context.Context {
+	return as.ctx
+}
+
+// authenticate checks bearer token from metadata and returns context with login of user
+func (kps *KeepPasSrv) authenticate(ctx context.Context) (context.Context, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		kps.logger.Debugln("missing metadata")
@@ -358,31 +703,39 @@ func (kps *KeepPasSrv) AuthInterceptor(ctx context.Context, req any, info *grpc.
 	login, err := kps.isValidToken(ctx, md["bearer-token"])
 	if err != nil {
 		kps.logger.Debugf("check user token error: %v", err)
-		return nil, status.Errorf(codes.Internal, err.Error())
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.Unauthenticated, err.Error())
 	}
 	if login == "" {
 		kps.logger.Debugf("invalid token, got metadata: %v", md)
 		return nil, status.Errorf(codes.Unauthenticated, "invalid token")
 	}
-
+	md = md.Copy()
 	md.Set("login", login)
-	lctx := metadata.NewIncomingContext(ctx, md)
-
-	kps.logger.Debugf("info: %v", info.FullMethod)
-
-	hndlr, err := handler(lctx, req)
-	if err != nil {
-		kps.logger.Debug(err)
-		kps.logger.Errorf("rpc interceptor got error: %v", err)
-	}
-
-	return hndlr, err
+	return metadata.NewIncomingContext(ctx, md), nil
 }
 
-// isValidToken check bearer token
-func (kps *KeepPasSrv) isValidToken(_ context.Context, token []string) (string, error) {
+// isValidToken check bearer token and its session isn't revoked
+func (kps *KeepPasSrv) isValidToken(ctx context.Context, token []string) (string, error) {
 	if len(token) == 0 {
 		return "", nil
 	}
-	return crypto.CheckToken(token[0], kps.conf.ServerKey)
+	claims, err := crypto.ParseToken(token[0], kps.serverKey())
+	if err != nil {
+		return "", err
+	}
+	sess := types.Session{}
+	if claims.ID != "" {
+		if err := kps.Stor.GetSession(ctx, claims.ID, &sess); err != nil {
+			kps.logger.Debug(err)
+			return "", status.Error(codes.Internal, "error when check session")
+		}
+	}
+	if sess.Login != claims.Login {
+		kps.logger.Debugf("session %v of %v is revoked", claims.ID, claims.Login)
+		return "", nil
+	}
+	return claims.Login, nil
 }
@@ -6,15 +6,28 @@ import (
 
 	"github.com/go-redis/redismock/v9"
 	"github.com/hrapovd1/gokeepas/internal/config"
+	"github.com/hrapovd1/gokeepas/internal/crypto"
 	pb "github.com/hrapovd1/gokeepas/internal/proto"
 	"github.com/hrapovd1/gokeepas/internal/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+// newTestSrv returns server with storage on redis mock.
+func newTestSrv(t *testing.T) (*KeepPasSrv, redismock.ClientMock) {
+	db, mock := redismock.NewClientMock()
+	stor, err := storage.NewRedisStor(config.Config{DBdsn: "redis://localhost/0"})
+	require.NoError(t, err)
+	storage.SetRedisClient(stor, db)
+	srv := KeepPasSrv{Stor: stor, logger: zap.NewNop().Sugar(), conf: config.Config{ServerKey: []byte("wfgxRxAwTILuvwpqD3JSgqnE")}}
+	return &srv, mock
+}
+
 func TestNewKeepPasSrv(t *testing.T) {
 	t.Run("right", func(t *testing.T) {
 		srv, err := NewKeepPasSrv(&zap.Logger{}, config.Config{DBdsn: "redis://localhost:6379/0"})
@@ -23,6 +36,14 @@ func TestNewKeepPasSrv(t *testing.T) {
 		assert.IsType(t, storage.Storage(&storage.RedisStor{}), srv.Stor)
 	})
 
+	t.Run("metrics", func(t *testing.T) {
+		srv, err := NewKeepPasSrv(&zap.Logger{}, config.Config{DBdsn: "redis://localhost:6379/0", Metrics: "127.0.0.1:9090"})
+		require.NoError(t, err)
+		assert.NotNil(t, srv.Metrics)
+		_, raw := srv.Stor.(*storage.RedisStor)
+		assert.False(t, raw, "storage isn't measured")
+	})
+
 	t.Run("wrong", func(t *testing.T) {
 		srv, err := NewKeepPasSrv(&zap.Logger{}, config.Config{DBdsn: ""})
 		assert.Error(t, err)
@@ -31,11 +52,7 @@ func TestNewKeepPasSrv(t *testing.T) {
 }
 
 func TestKeepPasSrv_SignUp(t *testing.T) {
-	db, mock := redismock.NewClientMock()
-	stor, err := storage.NewRedisStor(config.Config{DBdsn: "redis://localhost/0"})
-	require.NoError(t, err)
-	storage.SetRedisClient(stor, db)
-	srv := KeepPasSrv{Stor: stor, logger: zap.NewNop().Sugar(), conf: config.Config{ServerKey: []byte("wfgxRxAwTILuvwpqD3JSgqnE")}}
+	srv, mock := newTestSrv(t)
 	t.Run("right", func(t *testing.T) {
 		// expectation
 		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{})
@@ -43,47 +60,42 @@ func TestKeepPasSrv_SignUp(t *testing.T) {
 		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{})
 
 		// test
-		_, err = srv.SignUp(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
+		_, err := srv.SignUp(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
 	})
 	t.Run("wrong login", func(t *testing.T) {
-		_, err = srv.SignUp(context.Background(), &pb.AuthRequest{Login: "server", Password: "pass"})
+		_, err := srv.SignUp(context.Background(), &pb.AuthRequest{Login: "server", Password: "pass"})
 		assert.Error(t, err)
 	})
 	t.Run("wrong user name", func(t *testing.T) {
 		mock.ExpectHGetAll("/users/test").RedisNil()
 		// test
-		_, err = srv.SignUp(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
+		_, err := srv.SignUp(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
 	})
-	t.Run("wrong pass", func(t *testing.T) {
+	t.Run("existing user", func(t *testing.T) {
 		// expectation
 		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{"pass": "ae4"})
-		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{})
 
 		// test
-		_, err = srv.SignUp(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
-		assert.Error(t, err)
+		_, err := srv.SignUp(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
 	})
 }
 
 func TestKeepPasSrv_LogIn(t *testing.T) {
-	db, mock := redismock.NewClientMock()
-	stor, err := storage.NewRedisStor(config.Config{DBdsn: "redis://localhost/0"})
-	require.NoError(t, err)
-	storage.SetRedisClient(stor, db)
-	srv := KeepPasSrv{Stor: stor, logger: zap.NewNop().Sugar(), conf: config.Config{ServerKey: []byte("wfgxRxAwTILuvwpqD3JSgqnE")}}
+	srv, mock := newTestSrv(t)
 	t.Run("right", func(t *testing.T) {
 		// expectation
 		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{})
 
-		_, err = srv.LogIn(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
+		_, err := srv.LogIn(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -92,7 +104,7 @@ func TestKeepPasSrv_LogIn(t *testing.T) {
 	t.Run("get user err", func(t *testing.T) {
 		mock.ExpectHGetAll("/users/test").RedisNil()
 
-		_, err = srv.LogIn(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
+		_, err := srv.LogIn(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -101,19 +113,89 @@ func TestKeepPasSrv_LogIn(t *testing.T) {
 	t.Run("wrong pass", func(t *testing.T) {
 		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{"pass": "ae6d41f07eb6718e95b9cf8a31309e16b0e76c61"})
 
-		_, err = srv.LogIn(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
+		_, err := srv.LogIn(context.Background(), &pb.AuthRequest{Login: "test", Password: "pass"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
 	})
 }
 
-func TestKeepPasSrv_GetKey(t *testing.T) {
-	db, mock := redismock.NewClientMock()
-	stor, err := storage.NewRedisStor(config.Config{DBdsn: "redis://localhost/0"})
+func TestKeepPasSrv_Refresh(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	sid, err := crypto.GenSessionID()
 	require.NoError(t, err)
-	storage.SetRedisClient(stor, db)
-	srv := KeepPasSrv{Stor: stor, logger: zap.NewNop().Sugar(), conf: config.Config{ServerKey: []byte("wfgxRxAwTILuvwpqD3JSgqnE")}}
+	token, err := crypto.GenRefreshToken(sid)
+	require.NoError(t, err)
+	sessKey := "/sessions/" + sid
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectHGetAll(sessKey).SetVal(map[string]string{"login": "test", "refresh": crypto.HashToken(token)})
+		mock.ExpectWatch(sessKey)
+		mock.ExpectHGet(sessKey, "refresh").SetVal(crypto.HashToken(token))
+		mock.ExpectTxPipeline()
+		mock.Regexp().ExpectHSet(sessKey, "login", "test", "refresh", `^[0-9a-f]{64}$`).SetVal(0)
+		mock.ExpectExpire(sessKey, crypto.RefreshExpireDuration).SetVal(true)
+		mock.ExpectTxPipelineExec()
+
+		resp, err := srv.Refresh(context.Background(), &pb.RefreshRequest{RefreshToken: token})
+		require.NoError(t, err)
+		assert.NotEqual(t, token, resp.RefreshToken)
+		claims, err := crypto.ParseToken(resp.AuthToken, srv.conf.ServerKey)
+		require.NoError(t, err)
+		assert.Equal(t, sid, claims.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("reused token", func(t *testing.T) {
+		mock.ExpectHGetAll(sessKey).SetVal(map[string]string{"login": "test", "refresh": "rotated"})
+		mock.ExpectDel(sessKey).SetVal(1)
+
+		_, err := srv.Refresh(context.Background(), &pb.RefreshRequest{RefreshToken: token})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("revoked session", func(t *testing.T) {
+		mock.ExpectHGetAll(sessKey).SetVal(map[string]string{})
+
+		_, err := srv.Refresh(context.Background(), &pb.RefreshRequest{RefreshToken: token})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("wrong token", func(t *testing.T) {
+		_, err := srv.Refresh(context.Background(), &pb.RefreshRequest{RefreshToken: "test"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestKeepPasSrv_LogOut(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	sid, err := crypto.GenSessionID()
+	require.NoError(t, err)
+	token, err := crypto.GenRefreshToken(sid)
+	require.NoError(t, err)
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectHGetAll("/sessions/" + sid).SetVal(map[string]string{"login": "test", "refresh": crypto.HashToken(token)})
+		mock.ExpectDel("/sessions/" + sid).SetVal(1)
+
+		_, err := srv.LogOut(context.Background(), &pb.RefreshRequest{RefreshToken: token})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("remove err", func(t *testing.T) {
+		mock.ExpectHGetAll("/sessions/" + sid).SetVal(map[string]string{"login": "test", "refresh": crypto.HashToken(token)})
+		mock.ExpectDel("/sessions/" + sid).RedisNil()
+
+		_, err := srv.LogOut(context.Background(), &pb.RefreshRequest{RefreshToken: token})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+}
+
+func TestKeepPasSrv_GetKey(t *testing.T) {
+	srv, mock := newTestSrv(t)
 	t.Run("right", func(t *testing.T) {
 		ctx := metadata.NewIncomingContext(
 			context.Background(),
@@ -122,7 +204,7 @@ func TestKeepPasSrv_GetKey(t *testing.T) {
 		// expectation
 		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{"pass": "ae6d41f07eb6718e95b9cf8a31309e16b0e76c61"})
 
-		_, err = srv.GetKey(ctx, &pb.BinRequest{})
+		_, err := srv.GetKey(ctx, &pb.BinRequest{})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -131,7 +213,7 @@ func TestKeepPasSrv_GetKey(t *testing.T) {
 			context.Background(),
 			metadata.New(map[string]string{"logn": "test"}),
 		)
-		_, err = srv.GetKey(ctx, &pb.BinRequest{})
+		_, err := srv.GetKey(ctx, &pb.BinRequest{})
 		assert.Error(t, err)
 	})
 	t.Run("wrong user", func(t *testing.T) {
@@ -142,18 +224,14 @@ func TestKeepPasSrv_GetKey(t *testing.T) {
 		// expectation
 		mock.ExpectHGetAll("/users/test").SetVal(map[string]string{"pass": ""})
 
-		_, err = srv.GetKey(ctx, &pb.BinRequest{})
+		_, err := srv.GetKey(ctx, &pb.BinRequest{})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
 func TestKeepPasSrv_Add(t *testing.T) {
-	db, mock := redismock.NewClientMock()
-	stor, err := storage.NewRedisStor(config.Config{DBdsn: "redis://localhost/0"})
-	require.NoError(t, err)
-	storage.SetRedisClient(stor, db)
-	srv := KeepPasSrv{Stor: stor, logger: zap.NewNop().Sugar(), conf: config.Config{ServerKey: []byte("wfgxRxAwTILuvwpqD3JSgqnE")}}
+	srv, mock := newTestSrv(t)
 	t.Run("right", func(t *testing.T) {
 		ctx := metadata.NewIncomingContext(
 			context.Background(),
@@ -162,7 +240,19 @@ func TestKeepPasSrv_Add(t *testing.T) {
 		// expectation
 		mock.ExpectHSet("test/key", "pass", "", "symmkey", "", "data", "testData", "type", "TEXT").SetVal(2)
 
-		_, err = srv.Add(ctx, &pb.BinRequest{Key: "key", Type: pb.Type_TEXT, Data: "testData"})
+		_, err := srv.Add(ctx, &pb.BinRequest{Key: "key", Type: pb.Type_TEXT, Data: "testData"})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("data key", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(
+			context.Background(),
+			metadata.New(map[string]string{"login": "test"}),
+		)
+		mock.ExpectHSet("test/key", "pass", "", "symmkey", "wrapped", "data", "testData", "type", "TEXT").SetVal(2)
+
+		_, err := srv.Add(ctx, &pb.BinRequest{Key: "key", Type: pb.Type_TEXT, Data: "testData", DataKey: "wrapped"})
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -173,18 +263,14 @@ func TestKeepPasSrv_Add(t *testing.T) {
 			metadata.New(map[string]string{"logn": "test"}),
 		)
 
-		_, err = srv.Add(ctx, &pb.BinRequest{Key: "key", Type: pb.Type_TEXT, Data: "testData"})
+		_, err := srv.Add(ctx, &pb.BinRequest{Key: "key", Type: pb.Type_TEXT, Data: "testData"})
 		assert.Error(t, err)
 		mock.ClearExpect()
 	})
 }
 
 func TestKeepPasSrv_Get(t *testing.T) {
-	db, mock := redismock.NewClientMock()
-	stor, err := storage.NewRedisStor(config.Config{DBdsn: "redis://localhost/0"})
-	require.NoError(t, err)
-	storage.SetRedisClient(stor, db)
-	srv := KeepPasSrv{Stor: stor, logger: zap.NewNop().Sugar(), conf: config.Config{ServerKey: []byte("wfgxRxAwTILuvwpqD3JSgqnE")}}
+	srv, mock := newTestSrv(t)
 	t.Run("right", func(t *testing.T) {
 		ctx := metadata.NewIncomingContext(
 			context.Background(),
@@ -192,7 +278,7 @@ func TestKeepPasSrv_Get(t *testing.T) {
 		)
 		// TEXT
 		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "", "type": "TEXT"})
-		_, err = srv.Get(ctx, &pb.BinRequest{Key: "key"})
+		_, err := srv.Get(ctx, &pb.BinRequest{Key: "key"})
 		assert.NoError(t, err)
 		// BINARY
 		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "", "type": "BINARY"})
@@ -206,6 +292,15 @@ func TestKeepPasSrv_Get(t *testing.T) {
 		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "", "type": "CART"})
 		_, err = srv.Get(ctx, &pb.BinRequest{Key: "key"})
 		assert.NoError(t, err)
+		// OTP
+		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "", "type": "OTP"})
+		_, err = srv.Get(ctx, &pb.BinRequest{Key: "key"})
+		assert.NoError(t, err)
+		// data key
+		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "enc", "symmkey": "wrapped", "type": "TEXT"})
+		resp, err := srv.Get(ctx, &pb.BinRequest{Key: "key"})
+		require.NoError(t, err)
+		assert.Equal(t, "wrapped", resp.DataKey)
 		// UNKNOWN
 		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "", "type": ""})
 		_, err = srv.Get(ctx, &pb.BinRequest{Key: "key"})
@@ -219,17 +314,13 @@ func TestKeepPasSrv_Get(t *testing.T) {
 			metadata.New(map[string]string{"logn": "test"}),
 		)
 
-		_, err = srv.Get(ctx, &pb.BinRequest{Key: "key"})
+		_, err := srv.Get(ctx, &pb.BinRequest{Key: "key"})
 		assert.Error(t, err)
 	})
 }
 
 func TestKeepPasSrv_Remove(t *testing.T) {
-	db, mock := redismock.NewClientMock()
-	stor, err := storage.NewRedisStor(config.Config{DBdsn: "redis://localhost/0"})
-	require.NoError(t, err)
-	storage.SetRedisClient(stor, db)
-	srv := KeepPasSrv{Stor: stor, logger: zap.NewNop().Sugar(), conf: config.Config{ServerKey: []byte("wfgxRxAwTILuvwpqD3JSgqnE")}}
+	srv, mock := newTestSrv(t)
 	t.Run("right", func(t *testing.T) {
 		ctx := metadata.NewIncomingContext(
 			context.Background(),
@@ -238,7 +329,7 @@ func TestKeepPasSrv_Remove(t *testing.T) {
 		// expectation
 		mock.ExpectDel("test/test").SetVal(1)
 
-		_, err = srv.Remove(ctx, &pb.BinRequest{Key: "test"})
+		_, err := srv.Remove(ctx, &pb.BinRequest{Key: "test"})
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -248,17 +339,13 @@ func TestKeepPasSrv_Remove(t *testing.T) {
 			context.Background(),
 			metadata.New(map[string]string{"logn": "test"}),
 		)
-		_, err = srv.Remove(ctx, &pb.BinRequest{Key: "test"})
+		_, err := srv.Remove(ctx, &pb.BinRequest{Key: "test"})
 		assert.Error(t, err)
 	})
 }
 
 func TestKeepPasSrv_Rename(t *testing.T) {
-	db, mock := redismock.NewClientMock()
-	stor, err := storage.NewRedisStor(config.Config{DBdsn: "redis://localhost/0"})
-	require.NoError(t, err)
-	storage.SetRedisClient(stor, db)
-	srv := KeepPasSrv{Stor: stor, logger: zap.NewNop().Sugar(), conf: config.Config{ServerKey: []byte("wfgxRxAwTILuvwpqD3JSgqnE")}}
+	srv, mock := newTestSrv(t)
 	ctx := metadata.NewIncomingContext(
 		context.Background(),
 		metadata.New(map[string]string{"login": "test"}),
@@ -273,21 +360,41 @@ func TestKeepPasSrv_Rename(t *testing.T) {
 		mock.ExpectTxPipelineExec()
 		mock.ExpectDel("test/key").SetVal(1)
 
-		_, err = srv.Rename(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
+		_, err := srv.Rename(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("re-encrypted data", func(t *testing.T) {
+		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "old", "type": "TEXT"})
+		mock.ExpectHSet("test/key1", "pass", "", "symmkey", "", "data", "new", "type", "TEXT").SetVal(2)
+		mock.ExpectDel("test/key").SetVal(1)
+
+		_, err := srv.Rename(ctx, &pb.BinRequest{Key: "key", NewKey: "key1", Data: "new"})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("re-wrapped data key", func(t *testing.T) {
+		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "old", "symmkey": "wrapped", "type": "TEXT"})
+		mock.ExpectHSet("test/key1", "pass", "", "symmkey", "rewrapped", "data", "old", "type", "TEXT").SetVal(2)
+		mock.ExpectDel("test/key").SetVal(1)
+
+		_, err := srv.Rename(ctx, &pb.BinRequest{Key: "key", NewKey: "key1", DataKey: "rewrapped"})
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
 	})
 	t.Run("get err", func(t *testing.T) {
 		mock.ExpectHGetAll("test/key").RedisNil()
-		_, err = srv.Rename(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
+		_, err := srv.Rename(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
 	})
 	t.Run("get err2", func(t *testing.T) {
 		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "", "type": ""})
-		_, err = srv.Rename(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
+		_, err := srv.Rename(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -296,7 +403,7 @@ func TestKeepPasSrv_Rename(t *testing.T) {
 		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "", "type": "TEXT"})
 		mock.ExpectWatch("test/key")
 		mock.ExpectHGetAll("test/key").RedisNil()
-		_, err = srv.Rename(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
+		_, err := srv.Rename(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -309,7 +416,7 @@ func TestKeepPasSrv_Rename(t *testing.T) {
 		mock.ExpectHSet("test/key1", "pass", "", "symmkey", "", "data", "", "type", "TEXT").SetVal(2)
 		mock.ExpectTxPipelineExec()
 		mock.ExpectDel("test/key").RedisNil()
-		_, err = srv.Rename(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
+		_, err := srv.Rename(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -319,17 +426,13 @@ func TestKeepPasSrv_Rename(t *testing.T) {
 			context.Background(),
 			metadata.New(map[string]string{"logn": "test"}),
 		)
-		_, err = srv.Rename(wctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
+		_, err := srv.Rename(wctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
 		assert.Error(t, err)
 	})
 }
 
 func TestKeepPasSrv_Update(t *testing.T) {
-	db, mock := redismock.NewClientMock()
-	stor, err := storage.NewRedisStor(config.Config{DBdsn: "redis://localhost/0"})
-	require.NoError(t, err)
-	storage.SetRedisClient(stor, db)
-	srv := KeepPasSrv{Stor: stor, logger: zap.NewNop().Sugar(), conf: config.Config{ServerKey: []byte("wfgxRxAwTILuvwpqD3JSgqnE")}}
+	srv, mock := newTestSrv(t)
 	ctx := metadata.NewIncomingContext(
 		context.Background(),
 		metadata.New(map[string]string{"login": "test"}),
@@ -339,7 +442,7 @@ func TestKeepPasSrv_Update(t *testing.T) {
 		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "", "type": "TEXT"})
 		mock.ExpectHSet("test/key", "pass", "", "symmkey", "", "data", "", "type", "TEXT").SetVal(2)
 
-		_, err = srv.Update(ctx, &pb.BinRequest{Key: "key"})
+		_, err := srv.Update(ctx, &pb.BinRequest{Key: "key"})
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -348,7 +451,7 @@ func TestKeepPasSrv_Update(t *testing.T) {
 		// expectation
 		mock.ExpectHGetAll("test/key").RedisNil()
 
-		_, err = srv.Update(ctx, &pb.BinRequest{Key: "key"})
+		_, err := srv.Update(ctx, &pb.BinRequest{Key: "key"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -357,7 +460,7 @@ func TestKeepPasSrv_Update(t *testing.T) {
 		// expectation
 		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "", "type": ""})
 
-		_, err = srv.Update(ctx, &pb.BinRequest{Key: "key"})
+		_, err := srv.Update(ctx, &pb.BinRequest{Key: "key"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -367,7 +470,7 @@ func TestKeepPasSrv_Update(t *testing.T) {
 		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "", "type": "TEXT"})
 		mock.ExpectHSet("test/key", "pass", "", "symmkey", "", "data", "", "type", "TEXT").RedisNil()
 
-		_, err = srv.Update(ctx, &pb.BinRequest{Key: "key"})
+		_, err := srv.Update(ctx, &pb.BinRequest{Key: "key"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -377,17 +480,13 @@ func TestKeepPasSrv_Update(t *testing.T) {
 			context.Background(),
 			metadata.New(map[string]string{"logn": "test"}),
 		)
-		_, err = srv.Update(wctx, &pb.BinRequest{Key: "key"})
+		_, err := srv.Update(wctx, &pb.BinRequest{Key: "key"})
 		assert.Error(t, err)
 	})
 }
 
 func TestKeepPasSrv_Copy(t *testing.T) {
-	db, mock := redismock.NewClientMock()
-	stor, err := storage.NewRedisStor(config.Config{DBdsn: "redis://localhost/0"})
-	require.NoError(t, err)
-	storage.SetRedisClient(stor, db)
-	srv := KeepPasSrv{Stor: stor, logger: zap.NewNop().Sugar(), conf: config.Config{ServerKey: []byte("wfgxRxAwTILuvwpqD3JSgqnE")}}
+	srv, mock := newTestSrv(t)
 	ctx := metadata.NewIncomingContext(
 		context.Background(),
 		metadata.New(map[string]string{"login": "test"}),
@@ -397,7 +496,16 @@ func TestKeepPasSrv_Copy(t *testing.T) {
 		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "", "type": "TEXT"})
 		mock.ExpectHSet("test/key1", "pass", "", "symmkey", "", "data", "", "type", "TEXT").SetVal(2)
 
-		_, err = srv.Copy(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
+		_, err := srv.Copy(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("re-encrypted data", func(t *testing.T) {
+		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "old", "type": "TEXT"})
+		mock.ExpectHSet("test/key1", "pass", "", "symmkey", "", "data", "new", "type", "TEXT").SetVal(2)
+
+		_, err := srv.Copy(ctx, &pb.BinRequest{Key: "key", NewKey: "key1", Data: "new"})
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -406,7 +514,7 @@ func TestKeepPasSrv_Copy(t *testing.T) {
 		// expectation
 		mock.ExpectHGetAll("test/key").RedisNil()
 
-		_, err = srv.Copy(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
+		_, err := srv.Copy(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -415,7 +523,7 @@ func TestKeepPasSrv_Copy(t *testing.T) {
 		// expectation
 		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "", "type": ""})
 
-		_, err = srv.Copy(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
+		_, err := srv.Copy(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -425,7 +533,7 @@ func TestKeepPasSrv_Copy(t *testing.T) {
 		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "", "type": "TEXT"})
 		mock.ExpectHSet("test/key1", "pass", "", "symmkey", "", "data", "", "type", "TEXT").RedisNil()
 
-		_, err = srv.Copy(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
+		_, err := srv.Copy(ctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
@@ -435,36 +543,66 @@ func TestKeepPasSrv_Copy(t *testing.T) {
 			context.Background(),
 			metadata.New(map[string]string{"logn": "test"}),
 		)
-		_, err = srv.Copy(wctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
+		_, err := srv.Copy(wctx, &pb.BinRequest{Key: "key", NewKey: "key1"})
 		assert.Error(t, err)
 	})
 }
 
 func TestKeepPasSrv_List(t *testing.T) {
-	db, mock := redismock.NewClientMock()
-	stor, err := storage.NewRedisStor(config.Config{DBdsn: "redis://localhost/0"})
-	require.NoError(t, err)
-	storage.SetRedisClient(stor, db)
-	srv := KeepPasSrv{Stor: stor, logger: zap.NewNop().Sugar(), conf: config.Config{ServerKey: []byte("wfgxRxAwTILuvwpqD3JSgqnE")}}
+	srv, mock := newTestSrv(t)
 	ctx := metadata.NewIncomingContext(
 		context.Background(),
 		metadata.New(map[string]string{"login": "test"}),
 	)
-	// expectation
-	mock.ExpectKeys("test/*").SetVal([]string{})
+	t.Run("empty", func(t *testing.T) {
+		mock.ExpectKeys("test/*").SetVal([]string{})
+
+		resp, err := srv.List(ctx, &pb.ListRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, &pb.ListResponse{Entries: []*pb.ListEntry{}}, resp)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("pages", func(t *testing.T) {
+		mock.ExpectKeys("test/db*").SetVal([]string{"test/db'1", "test/db2", "test/db3"})
+		mock.ExpectHMGet("test/db'1", "type", "symmkey").SetVal([]interface{}{"LOGIN", "dk"})
+		mock.ExpectHMGet("test/db2", "type", "symmkey").SetVal([]interface{}{"TEXT", nil})
+		mock.ExpectHMGet("test/db3", "type", "symmkey").SetVal([]interface{}{"LOGIN", nil})
+
+		resp, err := srv.List(ctx, &pb.ListRequest{Prefix: "db", Types: []pb.Type{pb.Type_LOGIN}, PageSize: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []*pb.ListEntry{{Name: "db'1", Type: pb.Type_LOGIN, DataKey: true}}, resp.Entries)
+		assert.Equal(t, `'db\'1'`, resp.Keys)
+		require.NotEmpty(t, resp.NextPageToken)
+
+		mock.ExpectKeys("test/db*").SetVal([]string{"test/db'1", "test/db2", "test/db3"})
+		mock.ExpectHMGet("test/db2", "type", "symmkey").SetVal([]interface{}{"TEXT", nil})
+		mock.ExpectHMGet("test/db3", "type", "symmkey").SetVal([]interface{}{"LOGIN", nil})
+		resp, err = srv.List(ctx, &pb.ListRequest{Prefix: "db", Types: []pb.Type{pb.Type_LOGIN}, PageSize: 1, PageToken: resp.NextPageToken})
+		require.NoError(t, err)
+		assert.Equal(t, []*pb.ListEntry{{Name: "db3", Type: pb.Type_LOGIN}}, resp.Entries)
+		assert.Empty(t, resp.NextPageToken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("wrong page", func(t *testing.T) {
+		_, err := srv.List(ctx, &pb.ListRequest{PageToken: "!"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = srv.List(ctx, &pb.ListRequest{PageSize: -1})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
 
-	_, err = srv.List(ctx, &pb.BinRequest{})
-	assert.NoError(t, err)
-	assert.NoError(t, mock.ExpectationsWereMet())
+func Test_legacyKeys(t *testing.T) {
+	assert.Equal(t, "", legacyKeys(nil))
+	assert.Equal(t, `'1\'1','11'`, legacyKeys([]string{"1'1", "11"}))
 }
 
 func TestKeepPasSrv_AuthInterceptor(t *testing.T) {
 	handler := func(c context.Context, r any) (any, error) {
 		return nil, nil
 	}
-	stor, err := storage.NewRedisStor(config.Config{DBdsn: "redis://localhost/0"})
-	require.NoError(t, err)
-	srv := KeepPasSrv{Stor: stor, logger: zap.NewNop().Sugar(), conf: config.Config{ServerKey: []byte("wfgxRxAwTILuvwpqD3JSgqnE")}}
+	srv, _ := newTestSrv(t)
 	t.Run("auth request", func(t *testing.T) {
 		_, err := srv.AuthInterceptor(context.Background(), &pb.AuthRequest{}, &grpc.UnaryServerInfo{}, handler)
 		require.NoError(t, err)
@@ -489,3 +627,29 @@ func TestKeepPasSrv_isValidToken(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "", res)
 }
+
+func TestKeepPasSrv_isValidToken_session(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	token, err := crypto.NewToken("test", "sid", srv.conf.ServerKey, crypto.ExpireDuration)
+	require.NoError(t, err)
+	t.Run("active session", func(t *testing.T) {
+		mock.ExpectHGetAll("/sessions/sid").SetVal(map[string]string{"login": "test", "refresh": "hash"})
+		res, err := srv.isValidToken(context.Background(), []string{token})
+		assert.NoError(t, err)
+		assert.Equal(t, "test", res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("revoked session", func(t *testing.T) {
+		mock.ExpectHGetAll("/sessions/sid").SetVal(map[string]string{})
+		res, err := srv.isValidToken(context.Background(), []string{token})
+		assert.NoError(t, err)
+		assert.Equal(t, "", res)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("storage error", func(t *testing.T) {
+		mock.ExpectHGetAll("/sessions/sid").RedisNil()
+		_, err := srv.isValidToken(context.Background(), []string{token})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/storage"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetKeyPair keeps user keypair for sharing secrets, existed keypair can't be replaced
+// because secrets already shared with user are sealed to its public key.
+func (kps *KeepPasSrv) SetKeyPair(ctx context.Context, req *pb.KeyPair) (*pb.BinResponse, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := base64.StdEncoding.DecodeString(req.PublicKey)
+	if err != nil || len(pub) != crypto.ShareKeyLength || req.PrivateKey == "" {
+		return nil, status.Error(codes.InvalidArgument, "wrong keypair")
+	}
+	err = kps.Stor.SetKeyPair(ctx, login, &types.KeyPair{Public: req.PublicKey, Private: req.PrivateKey})
+	if err == storage.ErrKeyPairExists {
+		return nil, status.Error(codes.AlreadyExists, "keypair already exists")
+	}
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when set keypair")
+	}
+	return &pb.BinResponse{}, nil
+}
+
+// GetKeyPair returns user keypair with private key encrypted by user key.
+func (kps *KeepPasSrv) GetKeyPair(ctx context.Context, _ *pb.BinRequest) (*pb.KeyPair, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	kp := types.KeyPair{}
+	if err := kps.Stor.GetKeyPair(ctx, login, &kp); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when get keypair")
+	}
+	if kp.Public == "" {
+		return nil, status.Error(codes.NotFound, "keypair doesn't exist")
+	}
+	return &pb.KeyPair{PublicKey: kp.Public, PrivateKey: kp.Private}, nil
+}
+
+// GetPublicKey returns public key of user req.Key.
+func (kps *KeepPasSrv) GetPublicKey(ctx context.Context, req *pb.BinRequest) (*pb.KeyPair, error) {
+	if _, err := kps.getLogin(ctx); err != nil {
+		return nil, err
+	}
+	kp := types.KeyPair{}
+	if err := kps.Stor.GetKeyPair(ctx, req.Key, &kp); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when get public key")
+	}
+	if kp.Public == "" {
+		return nil, status.Errorf(codes.NotFound, "user %s has no public key", req.Key)
+	}
+	return &pb.KeyPair{PublicKey: kp.Public}, nil
+}
+
+// Share keeps secret sealed by owner's client to recipient's public key, server never sees plaintext.
+func (kps *KeepPasSrv) Share(ctx context.Context, req *pb.ShareRequest) (*pb.BinResponse, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Recipient == "" || req.Recipient == login || req.Data == "" {
+		return nil, status.Error(codes.InvalidArgument, "wrong share request")
+	}
+	data := types.StorageModel{}
+	if err := kps.Stor.Get(ctx, login+"/"+req.Key, &data); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when share")
+	}
+	if data.Type == "" {
+		return nil, status.Errorf(codes.NotFound, "key doesn't exists")
+	}
+	if data.Type != req.Type.String() {
+		return nil, status.Error(codes.InvalidArgument, "wrong type of secret")
+	}
+	kp := types.KeyPair{}
+	if err := kps.Stor.GetKeyPair(ctx, req.Recipient, &kp); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when share")
+	}
+	if kp.Public == "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "user %s has no public key", req.Recipient)
+	}
+	share := types.Share{Owner: login, Key: req.Key, Type: data.Type, Data: req.Data, DataKey: req.DataKey, ReadOnly: req.ReadOnly}
+	if err := kps.Stor.AddShare(ctx, req.Recipient, &share); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when share")
+	}
+	return &pb.BinResponse{}, nil
+}
+
+// ListShared returns secrets shared with user.
+func (kps *KeepPasSrv) ListShared(ctx context.Context, _ *pb.BinRequest) (*pb.SharedList, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := kps.Stor.ListShares(ctx, login)
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when list shared")
+	}
+	out := pb.SharedList{Secrets: make([]*pb.SharedSecret, 0, len(shares))}
+	for _, s := range shares {
+		secretType, ok := pb.Type_value[s.Type]
+		if !ok {
+			kps.logger.Debugf("unknown type %v of shared secret %v/%v", s.Type, s.Owner, s.Key)
+			continue
+		}
+		out.Secrets = append(out.Secrets, &pb.SharedSecret{
+			Owner:    s.Owner,
+			Key:      s.Key,
+			Type:     pb.Type(secretType),
+			Data:     s.Data,
+			ReadOnly: s.ReadOnly,
+			DataKey:  s.DataKey,
+		})
+	}
+	return &out, nil
+}
+
+// Unshare revokes secret shared with req.Recipient or with all recipients if it is empty.
+func (kps *KeepPasSrv) Unshare(ctx context.Context, req *pb.ShareRequest) (*pb.BinResponse, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := kps.Stor.RemoveShare(ctx, login, req.Key, req.Recipient); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when unshare")
+	}
+	return &pb.BinResponse{}, nil
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestKeepPasSrv_SetKeyPair(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	pub := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectWatch("/keypairs/test")
+		mock.ExpectExists("/keypairs/test").SetVal(0)
+		mock.ExpectTxPipeline()
+		mock.ExpectHSet("/keypairs/test", "public", pub, "private", "priv").SetVal(2)
+		mock.ExpectTxPipelineExec()
+		_, err := srv.SetKeyPair(ctx, &pb.KeyPair{PublicKey: pub, PrivateKey: "priv"})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("exists", func(t *testing.T) {
+		mock.ExpectWatch("/keypairs/test")
+		mock.ExpectExists("/keypairs/test").SetVal(1)
+		_, err := srv.SetKeyPair(ctx, &pb.KeyPair{PublicKey: pub, PrivateKey: "priv"})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+		mock.ClearExpect()
+	})
+	t.Run("wrong key", func(t *testing.T) {
+		_, err := srv.SetKeyPair(ctx, &pb.KeyPair{PublicKey: "c2hvcnQ=", PrivateKey: "priv"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestKeepPasSrv_GetKeyPair(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectHGetAll("/keypairs/test").SetVal(map[string]string{"public": "pub", "private": "priv"})
+		resp, err := srv.GetKeyPair(ctx, &pb.BinRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, "pub", resp.PublicKey)
+		assert.Equal(t, "priv", resp.PrivateKey)
+		mock.ClearExpect()
+	})
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectHGetAll("/keypairs/test").SetVal(map[string]string{})
+		_, err := srv.GetKeyPair(ctx, &pb.BinRequest{})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		mock.ClearExpect()
+	})
+}
+
+func TestKeepPasSrv_GetPublicKey(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	mock.ExpectHGetAll("/keypairs/other").SetVal(map[string]string{"public": "pub", "private": "priv"})
+	resp, err := srv.GetPublicKey(ctx, &pb.BinRequest{Key: "other"})
+	require.NoError(t, err)
+	assert.Equal(t, "pub", resp.PublicKey)
+	assert.Empty(t, resp.PrivateKey)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeepPasSrv_Share(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	req := pb.ShareRequest{Key: "key", Recipient: "other", Data: "enc", DataKey: "sealed", Type: pb.Type_TEXT, ReadOnly: true}
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "enc", "type": "TEXT"})
+		mock.ExpectHGetAll("/keypairs/other").SetVal(map[string]string{"public": "pub"})
+		mock.ExpectTxPipeline()
+		mock.ExpectHSet("/shared/other/test/key", "owner", "test", "key", "key", "type", "TEXT", "data", "enc", "readonly", true, "datakey", "sealed").SetVal(6)
+		mock.ExpectSAdd("/sharedby/test/key", "other").SetVal(1)
+		mock.ExpectTxPipelineExec()
+		_, err := srv.Share(ctx, &req)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("no public key", func(t *testing.T) {
+		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "enc", "type": "TEXT"})
+		mock.ExpectHGetAll("/keypairs/other").SetVal(map[string]string{})
+		_, err := srv.Share(ctx, &req)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		mock.ClearExpect()
+	})
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectHGetAll("test/key").SetVal(map[string]string{})
+		_, err := srv.Share(ctx, &req)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		mock.ClearExpect()
+	})
+	t.Run("wrong type", func(t *testing.T) {
+		mock.ExpectHGetAll("test/key").SetVal(map[string]string{"data": "enc", "type": "LOGIN"})
+		_, err := srv.Share(ctx, &req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		mock.ClearExpect()
+	})
+	t.Run("self", func(t *testing.T) {
+		_, err := srv.Share(ctx, &pb.ShareRequest{Key: "key", Recipient: "test", Data: "sealed"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestKeepPasSrv_ListShared(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	mock.ExpectKeys("/shared/test/*").SetVal([]string{"/shared/test/owner/key", "/shared/test/owner/bad"})
+	mock.ExpectHGetAll("/shared/test/owner/key").SetVal(map[string]string{"owner": "owner", "key": "key", "type": "LOGIN", "data": "sealed", "readonly": "1"})
+	mock.ExpectHGetAll("/shared/test/owner/bad").SetVal(map[string]string{"owner": "owner", "key": "bad", "type": "UNKNOWN"})
+	resp, err := srv.ListShared(ctx, &pb.BinRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.Secrets, 1)
+	assert.Equal(t, "owner", resp.Secrets[0].Owner)
+	assert.Equal(t, pb.Type_LOGIN, resp.Secrets[0].Type)
+	assert.True(t, resp.Secrets[0].ReadOnly)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeepPasSrv_Unshare(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	mock.ExpectTxPipeline()
+	mock.ExpectDel("/shared/other/test/key").SetVal(1)
+	mock.ExpectSRem("/sharedby/test/key", "other").SetVal(1)
+	mock.ExpectTxPipelineExec()
+	_, err := srv.Unshare(ctx, &pb.ShareRequest{Key: "key", Recipient: "other"})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
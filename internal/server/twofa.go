@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/otp"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	otpIssuer          = "KeepPas" // issuer in provisioning URI
+	otpSkew            = 1         // count of allowed time steps before and after current
+	recoveryCodesCount = 8         // count of generated recovery codes
+	recoveryCodeLength = 10        // length of recovery code without delimiter
+)
+
+// Enable2FA generates new 2FA seed for user and returns provisioning URI.
+// 2FA isn't required on login until it is confirmed with Confirm2FA.
+func (kps *KeepPasSrv) Enable2FA(ctx context.Context, _ *pb.BinRequest) (*pb.TwoFAResponse, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, err := otp.NewKey(otpIssuer, login)
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, err
+	}
+	encSeed, err := crypto.EncryptKey(kps.serverKey(), key.Secret)
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, err
+	}
+	err = kps.Stor.Update2FA(ctx, login, func(tf *types.TwoFactor) error {
+		if tf.Enabled {
+			return status.Error(codes.FailedPrecondition, "2FA is already enabled")
+		}
+		*tf = types.TwoFactor{Seed: encSeed}
+		return nil
+	})
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, err
+	}
+	return &pb.TwoFAResponse{Uri: key.URI()}, nil
+}
+
+// Confirm2FA checks one-time code for generated seed, turns on 2FA and returns recovery codes.
+func (kps *KeepPasSrv) Confirm2FA(ctx context.Context, req *pb.TwoFARequest) (*pb.TwoFAResponse, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recCodes, recHashes, err := genRecoveryCodes()
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, err
+	}
+	err = kps.Stor.Update2FA(ctx, login, func(tf *types.TwoFactor) error {
+		if tf.Seed == "" {
+			return status.Error(codes.FailedPrecondition, "2FA isn't initialized")
+		}
+		if tf.Enabled {
+			return status.Error(codes.FailedPrecondition, "2FA is already enabled")
+		}
+		if !kps.checkTOTP(tf, req.Code) {
+			return status.Error(codes.InvalidArgument, "wrong one-time code")
+		}
+		tf.Enabled = true
+		tf.Recovery = strings.Join(recHashes, ",")
+		return nil
+	})
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, err
+	}
+	return &pb.TwoFAResponse{RecoveryCodes: recCodes}, nil
+}
+
+// Disable2FA turns off 2FA for user, it requires one-time or recovery code.
+func (kps *KeepPasSrv) Disable2FA(ctx context.Context, req *pb.TwoFARequest) (*pb.BinResponse, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = kps.Stor.Update2FA(ctx, login, func(tf *types.TwoFactor) error {
+		if !tf.Enabled {
+			return status.Error(codes.FailedPrecondition, "2FA isn't enabled")
+		}
+		if !kps.checkCode(tf, req.Code) {
+			return status.Error(codes.InvalidArgument, "wrong one-time code")
+		}
+		return nil
+	})
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, err
+	}
+	if err := kps.Stor.Remove2FA(ctx, login); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when disable 2FA")
+	}
+	return &pb.BinResponse{}, nil
+}
+
+// check2FA checks second factor of user login if it is enabled.
+func (kps *KeepPasSrv) check2FA(ctx context.Context, login string, code string) error {
+	tf := types.TwoFactor{}
+	if err := kps.Stor.Get2FA(ctx, login, &tf); err != nil {
+		kps.logger.Debug(err)
+		return err
+	}
+	if !tf.Enabled {
+		return nil
+	}
+	if code == "" {
+		return status.Error(codes.FailedPrecondition, "one-time code required")
+	}
+	err := kps.Stor.Update2FA(ctx, login, func(tf *types.TwoFactor) error {
+		if tf.Enabled && !kps.checkCode(tf, code) {
+			return status.Error(codes.Unauthenticated, "wrong login or password")
+		}
+		return nil
+	})
+	if err != nil {
+		kps.logger.Debug(err)
+	}
+	return err
+}
+
+// checkCode checks one-time code or recovery code, used recovery code is removed from tf.
+func (kps *KeepPasSrv) checkCode(tf *types.TwoFactor, code string) bool {
+	if kps.checkTOTP(tf, code) {
+		return true
+	}
+	code = strings.ReplaceAll(strings.TrimSpace(code), "-", "")
+	if len(code) != recoveryCodeLength {
+		return false
+	}
+	codeHash := crypto.HashToken(code)
+	hashes := strings.Split(tf.Recovery, ",")
+	for i, h := range hashes {
+		if crypto.EqualHash(h, codeHash) {
+			tf.Recovery = strings.Join(append(hashes[:i], hashes[i+1:]...), ",")
+			return true
+		}
+	}
+	return false
+}
+
+// checkTOTP checks one-time code, each time step can be used only once.
+func (kps *KeepPasSrv) checkTOTP(tf *types.TwoFactor, code string) bool {
+	seed, err := crypto.DecryptKey(kps.serverKey(), tf.Seed)
+	if err != nil {
+		kps.logger.Debug(err)
+		return false
+	}
+	key := otp.Key{Secret: seed, Algorithm: otp.DefaultAlgorithm, Digits: otp.DefaultDigits, Period: otp.DefaultPeriod}
+	step, ok := key.Validate(strings.TrimSpace(code), time.Now(), otpSkew)
+	if !ok || step <= tf.LastStep {
+		return false
+	}
+	tf.LastStep = step
+	// 2FA state is saved after check, so seed of outdated format is encrypted again
+	if crypto.Outdated(tf.Seed) {
+		if encSeed, err := crypto.EncryptKey(kps.serverKey(), seed); err == nil {
+			tf.Seed = encSeed
+		}
+	}
+	return true
+}
+
+// genRecoveryCodes returns recovery codes in format "xxxxx-xxxxx" and their hashes
+func genRecoveryCodes() ([]string, []string, error) {
+	recCodes := make([]string, 0, recoveryCodesCount)
+	recHashes := make([]string, 0, recoveryCodesCount)
+	for i := 0; i < recoveryCodesCount; i++ {
+		code, err := crypto.GenServerKey(recoveryCodeLength)
+		if err != nil {
+			return nil, nil, err
+		}
+		recCodes = append(recCodes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+		recHashes = append(recHashes, crypto.HashToken(code))
+	}
+	return recCodes, recHashes, nil
+}
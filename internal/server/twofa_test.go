@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/otp"
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestKeepPasSrv_Enable2FA(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectWatch("/2fa/test")
+		mock.ExpectHGetAll("/2fa/test").SetVal(map[string]string{})
+		mock.ExpectTxPipeline()
+		mock.Regexp().ExpectHSet("/2fa/test", "seed", `^.+$`, "enabled", false, "laststep", int64(0), "recovery", "").SetVal(4)
+		mock.ExpectTxPipelineExec()
+
+		resp, err := srv.Enable2FA(ctx, &pb.BinRequest{})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(resp.Uri, "otpauth://totp/KeepPas:test?"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("already enabled", func(t *testing.T) {
+		mock.ExpectWatch("/2fa/test")
+		mock.ExpectHGetAll("/2fa/test").SetVal(map[string]string{"enabled": "1"})
+
+		_, err := srv.Enable2FA(ctx, &pb.BinRequest{})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("empty login", func(t *testing.T) {
+		_, err := srv.Enable2FA(context.Background(), &pb.BinRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestKeepPasSrv_Confirm2FA(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	key, err := otp.NewKey(otpIssuer, "test")
+	require.NoError(t, err)
+	encSeed, err := crypto.EncryptKey(srv.conf.ServerKey, key.Secret)
+	require.NoError(t, err)
+	now := time.Now()
+	code, err := key.Code(now)
+	require.NoError(t, err)
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectWatch("/2fa/test")
+		mock.ExpectHGetAll("/2fa/test").SetVal(map[string]string{"seed": encSeed, "enabled": "0"})
+		mock.ExpectTxPipeline()
+		mock.Regexp().ExpectHSet("/2fa/test", "seed", `^.+$`, "enabled", true, "laststep", `^\d+$`, "recovery", `^([0-9a-f]{64},){7}[0-9a-f]{64}$`).SetVal(4)
+		mock.ExpectTxPipelineExec()
+
+		resp, err := srv.Confirm2FA(ctx, &pb.TwoFARequest{Code: code})
+		require.NoError(t, err)
+		assert.Equal(t, recoveryCodesCount, len(resp.RecoveryCodes))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("wrong code", func(t *testing.T) {
+		mock.ExpectWatch("/2fa/test")
+		mock.ExpectHGetAll("/2fa/test").SetVal(map[string]string{"seed": encSeed, "enabled": "0"})
+
+		_, err := srv.Confirm2FA(ctx, &pb.TwoFARequest{Code: "00000000"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("not initialized", func(t *testing.T) {
+		mock.ExpectWatch("/2fa/test")
+		mock.ExpectHGetAll("/2fa/test").SetVal(map[string]string{})
+
+		_, err := srv.Confirm2FA(ctx, &pb.TwoFARequest{Code: code})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+}
+
+func TestKeepPasSrv_check2FA(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	key, err := otp.NewKey(otpIssuer, "test")
+	require.NoError(t, err)
+	encSeed, err := crypto.EncryptKey(srv.conf.ServerKey, key.Secret)
+	require.NoError(t, err)
+	now := time.Now()
+	code, err := key.Code(now)
+	require.NoError(t, err)
+	enabled := map[string]string{"seed": encSeed, "enabled": "1", "laststep": "0"}
+	t.Run("disabled", func(t *testing.T) {
+		mock.ExpectHGetAll("/2fa/test").SetVal(map[string]string{})
+		assert.NoError(t, srv.check2FA(context.Background(), "test", ""))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("code required", func(t *testing.T) {
+		mock.ExpectHGetAll("/2fa/test").SetVal(enabled)
+		err := srv.check2FA(context.Background(), "test", "")
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("right code", func(t *testing.T) {
+		mock.ExpectHGetAll("/2fa/test").SetVal(enabled)
+		mock.ExpectWatch("/2fa/test")
+		mock.ExpectHGetAll("/2fa/test").SetVal(enabled)
+		mock.ExpectTxPipeline()
+		mock.Regexp().ExpectHSet("/2fa/test", "seed", `^.+$`, "enabled", true, "laststep", `^\d+$`, "recovery", "").SetVal(4)
+		mock.ExpectTxPipelineExec()
+		assert.NoError(t, srv.check2FA(context.Background(), "test", code))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("replayed code", func(t *testing.T) {
+		used := map[string]string{"seed": encSeed, "enabled": "1", "laststep": strconv.FormatInt(key.Step(now)+otpSkew, 10)}
+		mock.ExpectHGetAll("/2fa/test").SetVal(used)
+		mock.ExpectWatch("/2fa/test")
+		mock.ExpectHGetAll("/2fa/test").SetVal(used)
+		err := srv.check2FA(context.Background(), "test", code)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+}
+
+func TestKeepPasSrv_checkCode(t *testing.T) {
+	srv, _ := newTestSrv(t)
+	recCodes, recHashes, err := genRecoveryCodes()
+	require.NoError(t, err)
+	assert.Equal(t, recoveryCodesCount, len(recCodes))
+	tf := types.TwoFactor{Enabled: true, Recovery: strings.Join(recHashes, ",")}
+	t.Run("recovery code", func(t *testing.T) {
+		assert.True(t, srv.checkCode(&tf, recCodes[1]))
+		assert.Equal(t, strings.Join(append(recHashes[:1:1], recHashes[2:]...), ","), tf.Recovery)
+	})
+	t.Run("used recovery code", func(t *testing.T) {
+		assert.False(t, srv.checkCode(&tf, recCodes[1]))
+	})
+	t.Run("wrong code", func(t *testing.T) {
+		assert.False(t, srv.checkCode(&tf, "12345"))
+	})
+}
@@ -0,0 +1,333 @@
+package server
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/storage"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// vaultNameRe allows names like "infra" or "team/infra", they can't contain ':' and redis patterns.
+var vaultNameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*(/[a-zA-Z0-9][a-zA-Z0-9._-]*)*$`)
+
+// secretsPrefix returns storage prefix of secrets which request works with: own secrets of user
+// or secrets of vault from "vault" metadata. Changes of vault need write role and current vault key,
+// its version is sent by client in "vault-version" metadata.
+func (kps *KeepPasSrv) secretsPrefix(ctx context.Context, login string, write bool) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("vault")
+	if len(values) == 0 || values[0] == "" {
+		return login + "/", nil
+	}
+	vault := values[0]
+	member, err := kps.vaultMember(ctx, vault, login)
+	if err != nil {
+		return "", err
+	}
+	if !write {
+		return storage.VaultKeyPrefix(vault), nil
+	}
+	if member.Role == types.VaultRoleRead {
+		return "", status.Error(codes.PermissionDenied, "read-only member of vault")
+	}
+	v := types.Vault{}
+	if err := kps.Stor.GetVault(ctx, vault, &v); err != nil {
+		kps.logger.Debug(err)
+		return "", status.Errorf(codes.Internal, "error when get vault")
+	}
+	if version := md.Get("vault-version"); len(version) == 0 || version[0] != strconv.FormatInt(v.Version, 10) {
+		return "", status.Error(codes.FailedPrecondition, "vault key is rotated, repeat with new key")
+	}
+	return storage.VaultKeyPrefix(vault), nil
+}
+
+// vaultMember returns member login of vault, it returns NotFound when user isn't member.
+func (kps *KeepPasSrv) vaultMember(ctx context.Context, vault string, login string) (*types.VaultMember, error) {
+	member, err := kps.Stor.GetVaultMember(ctx, vault, login)
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when get vault member")
+	}
+	if member.Role == "" {
+		return nil, status.Errorf(codes.NotFound, "vault %s doesn't exist or you aren't its member", vault)
+	}
+	return member, nil
+}
+
+// vaultAdmin checks that user login is admin of vault.
+func (kps *KeepPasSrv) vaultAdmin(ctx context.Context, vault string, login string) error {
+	member, err := kps.vaultMember(ctx, vault, login)
+	if err != nil {
+		return err
+	}
+	if member.Role != types.VaultRoleAdmin {
+		return status.Error(codes.PermissionDenied, "only admin can manage vault")
+	}
+	return nil
+}
+
+// isLastAdmin returns true if login is the only admin of vault.
+func (kps *KeepPasSrv) isLastAdmin(ctx context.Context, vault string, login string) (bool, error) {
+	members, err := kps.Stor.ListVaultMembers(ctx, vault)
+	if err != nil {
+		kps.logger.Debug(err)
+		return false, status.Errorf(codes.Internal, "error when list vault members")
+	}
+	for _, m := range members {
+		if m.Role == types.VaultRoleAdmin && m.Login != login {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func isVaultRole(role string) bool {
+	return role == types.VaultRoleAdmin || role == types.VaultRoleWrite || role == types.VaultRoleRead
+}
+
+// CreateVault creates team vault with caller as admin, req.VaultKey is vault key sealed to caller.
+func (kps *KeepPasSrv) CreateVault(ctx context.Context, req *pb.VaultRequest) (*pb.BinResponse, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !vaultNameRe.MatchString(req.Vault) || req.VaultKey == "" {
+		return nil, status.Error(codes.InvalidArgument, "wrong vault request")
+	}
+	err = kps.Stor.CreateVault(ctx, req.Vault, &types.VaultMember{Login: login, Role: types.VaultRoleAdmin, VaultKey: req.VaultKey})
+	if err == storage.ErrVaultExists {
+		return nil, status.Errorf(codes.AlreadyExists, "vault %s already exists", req.Vault)
+	}
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when create vault")
+	}
+	return &pb.BinResponse{}, nil
+}
+
+// GetVaultKey returns vault key sealed to caller with its version and caller's role.
+func (kps *KeepPasSrv) GetVaultKey(ctx context.Context, req *pb.VaultRequest) (*pb.VaultKey, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	member, err := kps.vaultMember(ctx, req.Vault, login)
+	if err != nil {
+		return nil, err
+	}
+	v := types.Vault{}
+	if err := kps.Stor.GetVault(ctx, req.Vault, &v); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when get vault")
+	}
+	return &pb.VaultKey{VaultKey: member.VaultKey, Version: v.Version, Role: member.Role}, nil
+}
+
+// ListVaults returns vaults of caller with caller's role.
+func (kps *KeepPasSrv) ListVaults(ctx context.Context, _ *pb.BinRequest) (*pb.VaultList, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vaults, err := kps.Stor.ListUserVaults(ctx, login)
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when list vaults")
+	}
+	out := pb.VaultList{Vaults: make([]*pb.VaultInfo, 0, len(vaults))}
+	for _, vault := range vaults {
+		member, err := kps.Stor.GetVaultMember(ctx, vault, login)
+		if err != nil {
+			kps.logger.Debug(err)
+			return nil, status.Errorf(codes.Internal, "error when list vaults")
+		}
+		if member.Role == "" {
+			continue
+		}
+		out.Vaults = append(out.Vaults, &pb.VaultInfo{Vault: vault, Role: member.Role})
+	}
+	return &out, nil
+}
+
+// ListVaultMembers returns members of vault and version of vault key, caller must be member.
+func (kps *KeepPasSrv) ListVaultMembers(ctx context.Context, req *pb.VaultRequest) (*pb.VaultMembers, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := kps.vaultMember(ctx, req.Vault, login); err != nil {
+		return nil, err
+	}
+	members, err := kps.Stor.ListVaultMembers(ctx, req.Vault)
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when list vault members")
+	}
+	v := types.Vault{}
+	if err := kps.Stor.GetVault(ctx, req.Vault, &v); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when get vault")
+	}
+	out := pb.VaultMembers{Members: make([]*pb.VaultMember, 0, len(members)), Version: v.Version}
+	for _, m := range members {
+		out.Members = append(out.Members, &pb.VaultMember{Login: m.Login, Role: m.Role})
+	}
+	return &out, nil
+}
+
+// AddVaultMember invites user into vault, req.Member.VaultKey is vault key sealed to the user by admin's client.
+func (kps *KeepPasSrv) AddVaultMember(ctx context.Context, req *pb.VaultMemberRequest) (*pb.BinResponse, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Member == nil || req.Member.Login == "" || req.Member.VaultKey == "" || !isVaultRole(req.Member.Role) {
+		return nil, status.Error(codes.InvalidArgument, "wrong member request")
+	}
+	if err := kps.vaultAdmin(ctx, req.Vault, login); err != nil {
+		return nil, err
+	}
+	member, err := kps.Stor.GetVaultMember(ctx, req.Vault, req.Member.Login)
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when add vault member")
+	}
+	if member.Role != "" {
+		return nil, status.Errorf(codes.AlreadyExists, "user %s is already member of vault", req.Member.Login)
+	}
+	v := types.Vault{}
+	if err := kps.Stor.GetVault(ctx, req.Vault, &v); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when add vault member")
+	}
+	if v.Version != req.Version {
+		return nil, status.Error(codes.FailedPrecondition, "vault key is rotated, repeat with new key")
+	}
+	err = kps.Stor.SetVaultMember(ctx, req.Vault, &types.VaultMember{Login: req.Member.Login, Role: req.Member.Role, VaultKey: req.Member.VaultKey})
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when add vault member")
+	}
+	return &pb.BinResponse{}, nil
+}
+
+// ChangeVaultRole changes role of vault member, the last admin can't be demoted.
+func (kps *KeepPasSrv) ChangeVaultRole(ctx context.Context, req *pb.VaultMemberRequest) (*pb.BinResponse, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Member == nil || !isVaultRole(req.Member.Role) {
+		return nil, status.Error(codes.InvalidArgument, "wrong member request")
+	}
+	if err := kps.vaultAdmin(ctx, req.Vault, login); err != nil {
+		return nil, err
+	}
+	member, err := kps.vaultMember(ctx, req.Vault, req.Member.Login)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == types.VaultRoleAdmin && req.Member.Role != types.VaultRoleAdmin {
+		last, err := kps.isLastAdmin(ctx, req.Vault, member.Login)
+		if err != nil {
+			return nil, err
+		}
+		if last {
+			return nil, status.Error(codes.FailedPrecondition, "vault must have admin")
+		}
+	}
+	if err := kps.Stor.SetVaultMember(ctx, req.Vault, &types.VaultMember{Login: member.Login, Role: req.Member.Role}); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when change vault role")
+	}
+	return &pb.BinResponse{}, nil
+}
+
+// RemoveVaultMember removes member from vault and rotates vault key. Admin's client sends new vault key
+// sealed to each of remaining members and all vault secrets encrypted with it, so removed member
+// can't read vault with the key it has seen.
+func (kps *KeepPasSrv) RemoveVaultMember(ctx context.Context, req *pb.VaultRotateRequest) (*pb.BinResponse, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Removed == "" {
+		return nil, status.Error(codes.InvalidArgument, "wrong member request")
+	}
+	if err := kps.vaultAdmin(ctx, req.Vault, login); err != nil {
+		return nil, err
+	}
+	last, err := kps.isLastAdmin(ctx, req.Vault, req.Removed)
+	if err != nil {
+		return nil, err
+	}
+	if last {
+		return nil, status.Error(codes.FailedPrecondition, "vault must have admin")
+	}
+	rot := types.VaultRotation{
+		Removed: req.Removed,
+		Version: req.Version,
+		Keys:    make(map[string]string, len(req.Members)),
+		Secrets: make(map[string]types.StorageModel, len(req.Secrets)),
+	}
+	for _, m := range req.Members {
+		if m.VaultKey == "" {
+			return nil, status.Error(codes.InvalidArgument, "wrong member request")
+		}
+		rot.Keys[m.Login] = m.VaultKey
+	}
+	for _, s := range req.Secrets {
+		rot.Secrets[s.Key] = types.StorageModel{SymmKey: s.DataKey, Data: s.Data, Type: s.Type.String()}
+	}
+	err = kps.Stor.RotateVault(ctx, req.Vault, &rot)
+	if err == storage.ErrVaultChanged {
+		return nil, status.Error(codes.Aborted, "vault is changed meanwhile, repeat")
+	}
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when remove vault member")
+	}
+	for _, s := range req.Secrets {
+		kps.publish(storage.VaultKeyPrefix(req.Vault), types.Event{Kind: types.EventUpdate, Key: s.Key, Type: s.Type.String()})
+	}
+	return &pb.BinResponse{}, nil
+}
+
+// GetVaultSecrets returns all vault secrets and version of vault key, admin's client uses them for rotation.
+func (kps *KeepPasSrv) GetVaultSecrets(ctx context.Context, req *pb.VaultRequest) (*pb.VaultSecrets, error) {
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := kps.vaultAdmin(ctx, req.Vault, login); err != nil {
+		return nil, err
+	}
+	v := types.Vault{}
+	if err := kps.Stor.GetVault(ctx, req.Vault, &v); err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when get vault")
+	}
+	secrets, err := kps.Stor.ListVaultSecrets(ctx, req.Vault)
+	if err != nil {
+		kps.logger.Debug(err)
+		return nil, status.Errorf(codes.Internal, "error when get vault secrets")
+	}
+	out := pb.VaultSecrets{Secrets: make([]*pb.GetResponse, 0, len(secrets)), Version: v.Version}
+	for key, data := range secrets {
+		secretType, ok := pb.Type_value[data.Type]
+		if !ok {
+			kps.logger.Debugf("unknown type %v of vault secret %v", data.Type, key)
+			continue
+		}
+		out.Secrets = append(out.Secrets, &pb.GetResponse{Key: key, Type: pb.Type(secretType), Data: []byte(data.Data), DataKey: data.SymmKey})
+	}
+	sort.Slice(out.Secrets, func(i, j int) bool { return out.Secrets[i].Key < out.Secrets[j].Key })
+	return &out, nil
+}
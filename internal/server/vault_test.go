@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestKeepPasSrv_CreateVault(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectWatch("/vaults/team/infra")
+		mock.ExpectExists("/vaults/team/infra").SetVal(0)
+		mock.ExpectTxPipeline()
+		mock.ExpectHSet("/vaults/team/infra", "version", int64(1)).SetVal(1)
+		mock.ExpectHSet("/vaultmembers/team/infra", "test", "admin").SetVal(1)
+		mock.ExpectHSet("/vaultkeys/team/infra", "test", "sealed").SetVal(1)
+		mock.ExpectSAdd("/uservaults/test", "team/infra").SetVal(1)
+		mock.ExpectTxPipelineExec()
+		_, err := srv.CreateVault(ctx, &pb.VaultRequest{Vault: "team/infra", VaultKey: "sealed"})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("exists", func(t *testing.T) {
+		mock.ExpectWatch("/vaults/infra")
+		mock.ExpectExists("/vaults/infra").SetVal(1)
+		_, err := srv.CreateVault(ctx, &pb.VaultRequest{Vault: "infra", VaultKey: "sealed"})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+		mock.ClearExpect()
+	})
+	t.Run("wrong name", func(t *testing.T) {
+		for _, name := range []string{"", "a:b", "team/", "/team", "te*m"} {
+			_, err := srv.CreateVault(ctx, &pb.VaultRequest{Vault: name, VaultKey: "sealed"})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err), name)
+		}
+	})
+}
+
+func TestKeepPasSrv_GetVaultKey(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectHGet("/vaultmembers/infra", "test").SetVal("read")
+		mock.ExpectHGet("/vaultkeys/infra", "test").SetVal("sealed")
+		mock.ExpectHGetAll("/vaults/infra").SetVal(map[string]string{"version": "3"})
+		resp, err := srv.GetVaultKey(ctx, &pb.VaultRequest{Vault: "infra"})
+		require.NoError(t, err)
+		assert.Equal(t, &pb.VaultKey{VaultKey: "sealed", Version: 3, Role: "read"}, resp)
+		mock.ClearExpect()
+	})
+	t.Run("not member", func(t *testing.T) {
+		mock.ExpectHGet("/vaultmembers/infra", "test").RedisNil()
+		_, err := srv.GetVaultKey(ctx, &pb.VaultRequest{Vault: "infra"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		mock.ClearExpect()
+	})
+}
+
+func TestKeepPasSrv_AddWithVault(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{
+		"login":         "test",
+		"vault":         "infra",
+		"vault-version": "2",
+	}))
+	req := pb.BinRequest{Key: "key", Data: "enc", Type: pb.Type_TEXT}
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectHGet("/vaultmembers/infra", "test").SetVal("write")
+		mock.ExpectHGet("/vaultkeys/infra", "test").SetVal("sealed")
+		mock.ExpectHGetAll("/vaults/infra").SetVal(map[string]string{"version": "2"})
+		mock.ExpectHSet("/vaultdata/infra:key", "pass", "", "symmkey", "", "data", "enc", "type", "TEXT").SetVal(2)
+		_, err := srv.Add(ctx, &req)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("rotated key", func(t *testing.T) {
+		mock.ExpectHGet("/vaultmembers/infra", "test").SetVal("write")
+		mock.ExpectHGet("/vaultkeys/infra", "test").SetVal("sealed")
+		mock.ExpectHGetAll("/vaults/infra").SetVal(map[string]string{"version": "3"})
+		_, err := srv.Add(ctx, &req)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		mock.ClearExpect()
+	})
+	t.Run("read-only", func(t *testing.T) {
+		mock.ExpectHGet("/vaultmembers/infra", "test").SetVal("read")
+		mock.ExpectHGet("/vaultkeys/infra", "test").SetVal("sealed")
+		_, err := srv.Add(ctx, &req)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		mock.ClearExpect()
+	})
+}
+
+func TestKeepPasSrv_ChangeVaultRole(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	req := pb.VaultMemberRequest{Vault: "infra", Member: &pb.VaultMember{Login: "test", Role: "write"}}
+	t.Run("last admin", func(t *testing.T) {
+		mock.ExpectHGet("/vaultmembers/infra", "test").SetVal("admin")
+		mock.ExpectHGet("/vaultkeys/infra", "test").SetVal("sealed")
+		mock.ExpectHGet("/vaultmembers/infra", "test").SetVal("admin")
+		mock.ExpectHGet("/vaultkeys/infra", "test").SetVal("sealed")
+		mock.ExpectHGetAll("/vaultmembers/infra").SetVal(map[string]string{"test": "admin", "other": "write"})
+		_, err := srv.ChangeVaultRole(ctx, &req)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		mock.ClearExpect()
+	})
+	t.Run("not admin", func(t *testing.T) {
+		mock.ExpectHGet("/vaultmembers/infra", "test").SetVal("write")
+		mock.ExpectHGet("/vaultkeys/infra", "test").SetVal("sealed")
+		_, err := srv.ChangeVaultRole(ctx, &req)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		mock.ClearExpect()
+	})
+	t.Run("wrong role", func(t *testing.T) {
+		_, err := srv.ChangeVaultRole(ctx, &pb.VaultMemberRequest{Vault: "infra", Member: &pb.VaultMember{Login: "other", Role: "owner"}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestKeepPasSrv_RemoveVaultMember(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	req := pb.VaultRotateRequest{
+		Vault:   "infra",
+		Removed: "other",
+		Version: 1,
+		Members: []*pb.VaultMember{{Login: "test", VaultKey: "sealed2"}},
+		Secrets: []*pb.BinRequest{{Key: "db", Type: pb.Type_TEXT, Data: "enc2"}},
+	}
+	expectAdmin := func() {
+		mock.ExpectHGet("/vaultmembers/infra", "test").SetVal("admin")
+		mock.ExpectHGet("/vaultkeys/infra", "test").SetVal("sealed")
+		mock.ExpectHGetAll("/vaultmembers/infra").SetVal(map[string]string{"test": "admin", "other": "write"})
+		mock.ExpectWatch("/vaults/infra", "/vaultmembers/infra")
+		mock.ExpectHGetAll("/vaults/infra").SetVal(map[string]string{"version": "1"})
+		mock.ExpectHKeys("/vaultmembers/infra").SetVal([]string{"test", "other"})
+	}
+	t.Run("right", func(t *testing.T) {
+		expectAdmin()
+		mock.ExpectKeys("/vaultdata/infra:*").SetVal([]string{"/vaultdata/infra:db"})
+		mock.ExpectTxPipeline()
+		mock.ExpectHDel("/vaultmembers/infra", "other").SetVal(1)
+		mock.ExpectHDel("/vaultkeys/infra", "other").SetVal(1)
+		mock.ExpectSRem("/uservaults/other", "infra").SetVal(1)
+		mock.ExpectHSet("/vaultkeys/infra", "test", "sealed2").SetVal(0)
+		mock.ExpectHSet("/vaultdata/infra:db", "pass", "", "symmkey", "", "data", "enc2", "type", "TEXT").SetVal(0)
+		mock.ExpectHSet("/vaults/infra", "version", int64(2)).SetVal(0)
+		mock.ExpectTxPipelineExec()
+		_, err := srv.RemoveVaultMember(ctx, &req)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("new secret", func(t *testing.T) {
+		expectAdmin()
+		mock.ExpectKeys("/vaultdata/infra:*").SetVal([]string{"/vaultdata/infra:db", "/vaultdata/infra:new"})
+		_, err := srv.RemoveVaultMember(ctx, &req)
+		assert.Equal(t, codes.Aborted, status.Code(err))
+		mock.ClearExpect()
+	})
+	t.Run("last admin", func(t *testing.T) {
+		mock.ExpectHGet("/vaultmembers/infra", "test").SetVal("admin")
+		mock.ExpectHGet("/vaultkeys/infra", "test").SetVal("sealed")
+		mock.ExpectHGetAll("/vaultmembers/infra").SetVal(map[string]string{"test": "admin", "other": "write"})
+		_, err := srv.RemoveVaultMember(ctx, &pb.VaultRotateRequest{Vault: "infra", Removed: "test", Version: 1})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+		mock.ClearExpect()
+	})
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	watchCheckInterval = time.Minute     // period of checking watcher's session and vault membership
+	publishTimeout     = 5 * time.Second // max time of logging and publishing change
+)
+
+// Watch streams changes of caller's secrets with name prefix req.Prefix. Changes are received
+// from storage pub/sub, so changes made through other server instances are streamed too.
+// Stream is ended with Unauthenticated when session of caller expires or is revoked.
+func (kps *KeepPasSrv) Watch(req *pb.WatchRequest, stream pb.KeepPas_WatchServer) error {
+	ctx := stream.Context()
+	login, err := kps.getLogin(ctx)
+	if err != nil {
+		return err
+	}
+	prefix, err := kps.secretsPrefix(ctx, login, false)
+	if err != nil {
+		return err
+	}
+	events, closeSub, err := kps.Stor.SubscribeEvents(ctx, prefix)
+	if err != nil {
+		kps.logger.Debug(err)
+		return status.Errorf(codes.Internal, "error when watch")
+	}
+	defer func() {
+		if err := closeSub(); err != nil {
+			kps.logger.Debug(err)
+		}
+	}()
+	ticker := time.NewTicker(watchCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := kps.checkWatcher(ctx, login); err != nil {
+				return err
+			}
+		case ev, ok := <-events:
+			if !ok {
+				return status.Error(codes.Unavailable, "watch is interrupted, repeat it")
+			}
+			if !strings.HasPrefix(ev.Key, req.Prefix) && (ev.NewKey == "" || !strings.HasPrefix(ev.NewKey, req.Prefix)) {
+				continue
+			}
+			if err := stream.Send(watchEvent(&ev)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// checkWatcher checks session of watcher is still valid and watcher is still member of watched vault.
+func (kps *KeepPasSrv) checkWatcher(ctx context.Context, login string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	valid, err := kps.isValidToken(ctx, md.Get("bearer-token"))
+	if err != nil || valid != login {
+		kps.logger.Debugf("watch of %v is stopped: %v", login, err)
+		return status.Error(codes.Unauthenticated, "session is expired")
+	}
+	_, err = kps.secretsPrefix(ctx, login, false)
+	return err
+}
+
+// watchEvent converts storage event to protobuf one
+func watchEvent(ev *types.Event) *pb.WatchEvent {
+	out := pb.WatchEvent{
+		Kind:   pb.WatchEvent_Kind(pb.WatchEvent_Kind_value[ev.Kind]),
+		Key:    ev.Key,
+		NewKey: ev.NewKey,
+		Time:   ev.Time,
+		Seq:    ev.Seq,
+	}
+	if ev.Type != "" {
+		out.Type = pb.Type(pb.Type_value[ev.Type])
+	}
+	return &out
+}
+
+// publish adds change of secret to change log of secrets with storage key prefix and notifies
+// their watchers. Change is already saved, so errors are only logged; change which isn't logged
+// still takes sequence number, so syncing clients are reset instead of missing it. Canceled
+// call doesn't stop publish.
+func (kps *KeepPasSrv) publish(prefix string, ev types.Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	ev.Time = time.Now().UnixMilli()
+	if err := kps.Stor.AppendChange(ctx, prefix, &ev); err != nil {
+		kps.logger.Warnf("log %s change of %s: %v", ev.Kind, prefix+ev.Key, err)
+		if err := kps.Stor.MarkChangeGap(ctx, prefix); err != nil {
+			kps.logger.Errorf("mark missed change of %s: %v", prefix+ev.Key, err)
+		}
+	}
+	if err := kps.Stor.PublishEvent(ctx, prefix, &ev); err != nil {
+		kps.logger.Warnf("publish %s event of %s: %v", ev.Kind, prefix+ev.Key, err)
+	}
+}
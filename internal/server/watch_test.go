@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	pb "github.com/hrapovd1/gokeepas/internal/proto"
+	"github.com/hrapovd1/gokeepas/internal/storage"
+	"github.com/hrapovd1/gokeepas/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeEventsStor returns prepared events instead of redis pub/sub
+type fakeEventsStor struct {
+	storage.Storage
+	prefix string
+	events chan types.Event
+	closed bool
+}
+
+func (f *fakeEventsStor) SubscribeEvents(_ context.Context, prefix string) (<-chan types.Event, func() error, error) {
+	if f.events == nil {
+		return nil, nil, errors.New("broken")
+	}
+	f.prefix = prefix
+	return f.events, func() error {
+		f.closed = true
+		return nil
+	}, nil
+}
+
+// fakeWatchStream keeps sent events
+type fakeWatchStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*pb.WatchEvent
+}
+
+func (f *fakeWatchStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeWatchStream) Send(ev *pb.WatchEvent) error {
+	f.sent = append(f.sent, ev)
+	return nil
+}
+
+func TestKeepPasSrv_Watch(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	t.Run("right", func(t *testing.T) {
+		stor := fakeEventsStor{events: make(chan types.Event, 4)}
+		srv := KeepPasSrv{Stor: &stor, logger: zap.NewNop().Sugar()}
+		stor.events <- types.Event{Kind: types.EventAdd, Key: "work/vpn", Type: "LOGIN", Time: 1}
+		stor.events <- types.Event{Kind: types.EventAdd, Key: "home/wifi", Type: "LOGIN", Time: 2}
+		stor.events <- types.Event{Kind: types.EventRename, Key: "home/tv", NewKey: "work/tv", Type: "TEXT", Time: 3}
+		stor.events <- types.Event{Kind: types.EventRemove, Key: "work/old", Time: 4}
+		close(stor.events)
+		stream := fakeWatchStream{ctx: ctx}
+
+		err := srv.Watch(&pb.WatchRequest{Prefix: "work/"}, &stream)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		assert.Equal(t, "test/", stor.prefix)
+		assert.True(t, stor.closed)
+		require.Len(t, stream.sent, 3)
+		assert.Equal(t, &pb.WatchEvent{Kind: pb.WatchEvent_ADD, Key: "work/vpn", Type: pb.Type_LOGIN, Time: 1}, stream.sent[0])
+		assert.Equal(t, &pb.WatchEvent{Kind: pb.WatchEvent_RENAME, Key: "home/tv", NewKey: "work/tv", Type: pb.Type_TEXT, Time: 3}, stream.sent[1])
+		assert.Equal(t, pb.WatchEvent_REMOVE, stream.sent[2].Kind)
+	})
+	t.Run("canceled", func(t *testing.T) {
+		stor := fakeEventsStor{events: make(chan types.Event)}
+		srv := KeepPasSrv{Stor: &stor, logger: zap.NewNop().Sugar()}
+		cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		err := srv.Watch(&pb.WatchRequest{}, &fakeWatchStream{ctx: cctx})
+		assert.NoError(t, err)
+		assert.True(t, stor.closed)
+	})
+	t.Run("subscribe error", func(t *testing.T) {
+		srv := KeepPasSrv{Stor: &fakeEventsStor{}, logger: zap.NewNop().Sugar()}
+		err := srv.Watch(&pb.WatchRequest{}, &fakeWatchStream{ctx: ctx})
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+	t.Run("empty login", func(t *testing.T) {
+		srv := KeepPasSrv{Stor: &fakeEventsStor{}, logger: zap.NewNop().Sugar()}
+		err := srv.Watch(&pb.WatchRequest{}, &fakeWatchStream{ctx: context.Background()})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestKeepPasSrv_publish(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
+	// event is matched without time of change
+	matchEvent := func(data any, seq int64) error {
+		ev := types.Event{}
+		if err := json.Unmarshal(data.([]byte), &ev); err != nil {
+			return err
+		}
+		if ev.Kind != types.EventRemove || ev.Key != "one" || ev.Time == 0 || ev.Seq != seq {
+			return fmt.Errorf("unexpected event %s", data)
+		}
+		return nil
+	}
+	mock.ExpectDel("test/one").SetVal(1)
+	mock.CustomMatch(func(expected, actual []interface{}) error {
+		// evalsha sha 2 seqkey logkey event limit
+		if actual[3] != "/changeseq/test/" || actual[4] != "/changes/test/" {
+			return fmt.Errorf("unexpected keys %v", actual)
+		}
+		return matchEvent(actual[5], 0)
+	}).ExpectEvalSha("", []string{"", ""}, nil, nil).SetVal(int64(3))
+	mock.CustomMatch(func(expected, actual []interface{}) error {
+		if actual[1] != "/events/test/" {
+			return fmt.Errorf("unexpected channel %v", actual[1])
+		}
+		return matchEvent(actual[2], 3)
+	}).ExpectPublish("", nil).SetVal(1)
+	_, err := srv.Remove(ctx, &pb.BinRequest{Key: "one"})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeepPasSrv_publish_gap(t *testing.T) {
+	srv, mock := newTestSrv(t)
+	mock.CustomMatch(func(expected, actual []interface{}) error {
+		if actual[3] != "/changeseq/test/" {
+			return fmt.Errorf("unexpected keys %v", actual)
+		}
+		return nil
+	}).ExpectEvalSha("", []string{"", ""}, nil, nil).SetErr(errors.New("broken"))
+	// change takes sequence number without log entry
+	mock.ExpectIncr("/changeseq/test/").SetVal(4)
+	mock.CustomMatch(func(expected, actual []interface{}) error {
+		if actual[1] != "/events/test/" {
+			return fmt.Errorf("unexpected channel %v", actual[1])
+		}
+		return nil
+	}).ExpectPublish("", nil).SetVal(0)
+	srv.publish("test/", types.Event{Kind: types.EventRemove, Key: "one"})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestKeepPasSrv_StreamAuthInterceptor(t *testing.T) {
+	srv, _ := newTestSrv(t)
+	handler := func(_ any, ss grpc.ServerStream) error {
+		return nil
+	}
+	t.Run("missing metadata", func(t *testing.T) {
+		err := srv.StreamAuthInterceptor(nil, &fakeWatchStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, handler)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("missing token", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{}))
+		err := srv.StreamAuthInterceptor(nil, &fakeWatchStream{ctx: ctx}, &grpc.StreamServerInfo{}, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+	t.Run("sealed", func(t *testing.T) {
+		sealed := KeepPasSrv{logger: zap.NewNop().Sugar()}
+		err := sealed.StreamAuthInterceptor(nil, &fakeWatchStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, handler)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
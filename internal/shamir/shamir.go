@@ -0,0 +1,129 @@
+/*
+Package shamir implements Shamir's secret sharing over GF(2^8).
+
+Each share is the value of random polynomial for every byte of secret,
+followed by one byte with x coordinate of the share.
+*/
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+// MaxShares is maximum count of shares, x coordinate is one non-zero byte.
+const MaxShares = 255
+
+var (
+	// ErrParams returns when count of shares or threshold is wrong.
+	ErrParams = errors.New("threshold must be between 2 and count of shares, shares must be at most 255")
+	// ErrShares returns when shares can't be combined.
+	ErrShares = errors.New("wrong key shares")
+)
+
+// exp and log tables of GF(2^8) with polynomial x^8+x^4+x^3+x+1 and generator 3
+var (
+	expTable [255]byte
+	logTable [256]byte
+)
+
+func init() {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		expTable[i] = x
+		logTable[x] = byte(i)
+		// multiply by 3 = x*2 ^ x
+		x2 := x << 1
+		if x&0x80 != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+}
+
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])+int(logTable[b]))%255]
+}
+
+func div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])-int(logTable[b])+255)%255]
+}
+
+// Split divides secret in n shares, any threshold of them restore secret.
+func Split(secret []byte, n int, threshold int) ([][]byte, error) {
+	if threshold < 2 || n < threshold || n > MaxShares {
+		return nil, ErrParams
+	}
+	if len(secret) == 0 {
+		return nil, errors.New("secret is empty")
+	}
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][len(secret)] = byte(i + 1)
+	}
+	coeffs := make([]byte, threshold-1)
+	for pos, b := range secret {
+		if _, err := rand.Read(coeffs); err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			x := share[len(secret)]
+			// Horner's method
+			y := byte(0)
+			for i := len(coeffs) - 1; i >= 0; i-- {
+				y = mul(y, x) ^ coeffs[i]
+			}
+			share[pos] = mul(y, x) ^ b
+		}
+	}
+	for i := range coeffs {
+		coeffs[i] = 0
+	}
+	return shares, nil
+}
+
+// Combine restores secret from shares, count of shares must be at least threshold of Split
+// otherwise result is random.
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, ErrShares
+	}
+	size := len(shares[0])
+	if size < 2 {
+		return nil, ErrShares
+	}
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool, len(shares))
+	for i, share := range shares {
+		if len(share) != size {
+			return nil, ErrShares
+		}
+		x := share[size-1]
+		if x == 0 || seen[x] {
+			return nil, ErrShares
+		}
+		seen[x] = true
+		xs[i] = x
+	}
+	secret := make([]byte, size-1)
+	// Lagrange interpolation at x = 0
+	for i, share := range shares {
+		basis := byte(1)
+		for j := range shares {
+			if i != j {
+				basis = mul(basis, div(xs[j], xs[j]^xs[i]))
+			}
+		}
+		for pos := range secret {
+			secret[pos] ^= mul(share[pos], basis)
+		}
+	}
+	return secret, nil
+}
@@ -0,0 +1,59 @@
+package shamir
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestField(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			assert.Equal(t, byte(a), div(mul(byte(a), byte(b)), byte(b)))
+		}
+	}
+	// known product in AES field
+	assert.Equal(t, byte(0xc1), mul(0x57, 0x83))
+}
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("wfgxRxAwTILuvwpqD3JSgqnE")
+	shares, err := Split(secret, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+	t.Run("threshold", func(t *testing.T) {
+		for _, set := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+			parts := make([][]byte, 0, len(set))
+			for _, i := range set {
+				parts = append(parts, shares[i])
+			}
+			out, err := Combine(parts)
+			require.NoError(t, err)
+			assert.Equal(t, secret, out, set)
+		}
+	})
+	t.Run("below threshold", func(t *testing.T) {
+		out, err := Combine(shares[:2])
+		require.NoError(t, err)
+		assert.False(t, bytes.Equal(secret, out))
+	})
+	t.Run("duplicate", func(t *testing.T) {
+		_, err := Combine([][]byte{shares[0], shares[0], shares[1]})
+		assert.ErrorIs(t, err, ErrShares)
+	})
+	t.Run("different length", func(t *testing.T) {
+		_, err := Combine([][]byte{shares[0], shares[1][1:]})
+		assert.ErrorIs(t, err, ErrShares)
+	})
+}
+
+func TestSplitParams(t *testing.T) {
+	for _, p := range [][2]int{{5, 1}, {2, 3}, {256, 3}} {
+		_, err := Split([]byte("key"), p[0], p[1])
+		assert.ErrorIs(t, err, ErrParams, p)
+	}
+	_, err := Split(nil, 3, 2)
+	assert.Error(t, err)
+}
@@ -5,17 +5,116 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hrapovd1/gokeepas/internal/config"
 	"github.com/hrapovd1/gokeepas/internal/crypto"
+	"github.com/hrapovd1/gokeepas/internal/journal"
 	"github.com/hrapovd1/gokeepas/internal/types"
 	"github.com/redis/go-redis/v9"
-	"google.golang.org/grpc/metadata"
 )
 
-const transactWatchRetries = 100 // count of retries of transaction in Copy method
+const (
+	transactWatchRetries = 100              // count of retries of transaction in Copy method
+	sessionsPrefix       = "/sessions/"     // prefix of user sessions keys
+	twoFactorPrefix      = "/2fa/"          // prefix of users 2FA keys
+	keyPairsPrefix       = "/keypairs/"     // prefix of users sharing keypairs
+	sharedPrefix         = "/shared/"       // prefix of secrets shared with user: /shared/<recipient>/<owner>/<key>
+	sharedByPrefix       = "/sharedby/"     // prefix of sets of recipients of user secret: /sharedby/<owner>/<key>
+	vaultsPrefix         = "/vaults/"       // prefix of team vaults
+	vaultMembersPrefix   = "/vaultmembers/" // prefix of hashes of vault members: login -> role
+	vaultKeysPrefix      = "/vaultkeys/"    // prefix of hashes of vault keys: login -> key sealed to member
+	vaultDataPrefix      = "/vaultdata/"    // prefix of vault secrets: /vaultdata/<vault>:<key>
+	userVaultsPrefix     = "/uservaults/"   // prefix of sets of user vaults
+	sealKey              = "/seal"          // key of master key splitting config
+	serverKey            = "server"         // key of master key hash
+	listChunk            = 100              // count of secrets read by one request in ListSecrets
+	eventsPrefix         = "/events/"       // prefix of pub/sub channels of secret changes: /events/<secrets prefix>
+	eventsBuffer         = 100              // count of events buffered for slow watcher
+	changesPrefix        = "/changes/"      // prefix of sorted sets of secret changes by seq: /changes/<secrets prefix>
+	changeSeqPrefix      = "/changeseq/"    // prefix of counters of secret changes: /changeseq/<secrets prefix>
+	changesKept          = 10000            // count of last changes kept in change log of secrets owner
+	auditPrefix          = "/audit/"        // prefix of sorted sets of user audit events by time: /audit/<login>
+	auditKept            = 10000            // count of last audit events kept for user
+	journalKey           = "/journal"       // sorted set of security journal records by seq
+	journalHeadKey       = "/journal/head"  // last record of security journal
+	sessionsScanChunk    = 1000             // count of keys walked by one SCAN in CountSessions
+	lockoutPrefix        = "/lockout/"      // prefix of failed login attempts: /lockout/login/<login>, /lockout/ip/<address>
+	rateLimitPrefix      = "/ratelimit/"    // prefix of token buckets of users: /ratelimit/<login>/<class>
+	inFlightPrefix       = "/inflight/"     // prefix of sorted sets of in-flight calls of users by deadline
+)
+
+// appendChangeScript increments change counter and adds change to log atomically, so readers
+// never see later change without earlier one. Member of log is "<seq>:<event json>", seq keeps
+// members unique. Returns seq of change.
+var appendChangeScript = redis.NewScript(`
+local seq = redis.call('INCR', KEYS[1])
+redis.call('ZADD', KEYS[2], seq, seq .. ':' .. ARGV[1])
+redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -tonumber(ARGV[2]) - 1)
+return seq
+`)
+
+// takeTokenScript refills token bucket by elapsed time and takes one token. Bucket is hash of
+// tokens and time of last refill in unix ms, it is dropped when it would be full anyway.
+// Returns 0 when token is taken, otherwise ms to wait for next token.
+var takeTokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return wait
+`)
+
+// acquireSlotScript drops slots of in-flight calls which deadline passed, so slots of crashed
+// server instance are freed, and adds slot of call with deadline now + ttl ms if there are less
+// than limit ones. Time is taken from redis, so clocks of server instances don't matter. Slots
+// are sorted set of call ids by deadline. Returns 1 when slot is taken.
+var acquireSlotScript = redis.NewScript(`
+redis.replicate_commands()
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local ttl = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now + ttl, ARGV[3])
+if redis.call('PTTL', KEYS[1]) < ttl then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+var (
+	// ErrSessionChanged returns when refresh token of session doesn't match expected one.
+	ErrSessionChanged = errors.New("session refresh token changed")
+	// ErrKeyPairExists returns when user already has keypair.
+	ErrKeyPairExists = errors.New("keypair already exists")
+	// ErrVaultExists returns when vault with the name already exists.
+	ErrVaultExists = errors.New("vault already exists")
+	// ErrSealInitialized returns when master key is already split.
+	ErrSealInitialized = errors.New("server is already initialized")
+	// ErrServerKey returns when master key doesn't match hash in db.
+	ErrServerKey = errors.New("server hash in db doesn't match! Use different db")
+	// ErrVaultChanged returns when vault key, members or secrets don't match rotation.
+	ErrVaultChanged = errors.New("vault changed")
+)
 
 type Storage interface {
 	Add(context.Context, string, *types.StorageModel) error
@@ -23,8 +122,49 @@ type Storage interface {
 	Remove(context.Context, string) error
 	Update(context.Context, string, *types.StorageModel) error
 	Copy(context.Context, string, string) error
+	GetMany(context.Context, []string) ([]types.StorageModel, []error)
+	AddMany(context.Context, []string, []types.StorageModel) []error
+	RemoveMany(context.Context, []string) []error
+	PublishEvent(context.Context, string, *types.Event) error
+	AppendChange(context.Context, string, *types.Event) error
+	MarkChangeGap(context.Context, string) error
+	ListChanges(context.Context, string, int64, int) (*types.Changes, error)
+	AppendAudit(context.Context, string, *types.AuditEvent) error
+	ListAudit(context.Context, string, int64, int) ([]types.AuditEvent, error)
+	AppendJournal(context.Context, func(*types.JournalEntry) (*types.JournalEntry, error)) error
+	ListJournal(context.Context, int64, int) ([]types.JournalEntry, error)
+	GetJournalHead(context.Context, *types.JournalEntry) error
+	SubscribeEvents(context.Context, string) (<-chan types.Event, func() error, error)
 	Ping(context.Context, []byte) error
-	List(context.Context, string) string
+	ListSecrets(context.Context, string, types.ListFilter) ([]types.SecretInfo, bool, error)
+	AddSession(context.Context, string, *types.Session, time.Duration) error
+	GetSession(context.Context, string, *types.Session) error
+	RotateSession(context.Context, string, string, *types.Session, time.Duration) error
+	RemoveSession(context.Context, string) error
+	Get2FA(context.Context, string, *types.TwoFactor) error
+	Update2FA(context.Context, string, func(*types.TwoFactor) error) error
+	Remove2FA(context.Context, string) error
+	GetLockout(context.Context, string, *types.Lockout) error
+	UpdateLockout(context.Context, string, func(*types.Lockout) error, time.Duration) error
+	RemoveLockout(context.Context, string) error
+	TakeRateToken(context.Context, string, float64, int) (time.Duration, error)
+	AcquireInFlight(context.Context, string, int, time.Duration) (string, error)
+	ReleaseInFlight(context.Context, string, string) error
+	SetKeyPair(context.Context, string, *types.KeyPair) error
+	GetKeyPair(context.Context, string, *types.KeyPair) error
+	AddShare(context.Context, string, *types.Share) error
+	ListShares(context.Context, string) ([]types.Share, error)
+	RemoveShare(context.Context, string, string, string) error
+	CreateVault(context.Context, string, *types.VaultMember) error
+	GetVault(context.Context, string, *types.Vault) error
+	GetVaultMember(context.Context, string, string) (*types.VaultMember, error)
+	SetVaultMember(context.Context, string, *types.VaultMember) error
+	ListVaultMembers(context.Context, string) ([]types.VaultMember, error)
+	ListUserVaults(context.Context, string) ([]string, error)
+	ListVaultSecrets(context.Context, string) (map[string]types.StorageModel, error)
+	RotateVault(context.Context, string, *types.VaultRotation) error
+	GetSealConfig(context.Context, *types.SealConfig) error
+	InitSeal(context.Context, *types.SealConfig, []byte) error
 	Close() error
 }
 
@@ -60,58 +200,69 @@ func (rs RedisStor) Get(ctx context.Context, key string, val *types.StorageModel
 	return rs.rdb.HGetAll(ctx, key).Scan(val)
 }
 
-// List reads all existed user's keys
-func (rs RedisStor) List(ctx context.Context, pattern string) string {
-	res := rs.rdb.Keys(ctx, pattern)
-	out := ""
-	keys := res.Val()
-	commaCount := len(keys) - 1
-	if commaCount+1 == 0 {
-		return out
-	}
-	var login string
-	md, ok := metadata.FromIncomingContext(ctx)
-	if ok {
-		values := md.Get("login")
-		if len(values) > 0 {
-			login = values[0]
-		} else {
-			return out
+// ListSecrets returns secrets with storage key prefix sorted by name, filter selects secrets and page.
+// Names are keys without prefix. It reports there are more secrets after the page.
+func (rs RedisStor) ListSecrets(ctx context.Context, prefix string, filter types.ListFilter) ([]types.SecretInfo, bool, error) {
+	keys, err := rs.rdb.Keys(ctx, escapePattern(prefix+filter.Prefix)+"*").Result()
+	if err != nil {
+		return nil, false, err
+	}
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if name := strings.TrimPrefix(key, prefix); name > filter.After {
+			names = append(names, name)
 		}
 	}
-	prefixLen := len(login + "/")
-	// join keys in one line: "'key1','key2'..."
-	for _, val := range keys {
-		select {
-		case <-ctx.Done():
-			return out
-		default:
-			// process each key from query
-			tmpVal := `'`
-			val = val[prefixLen:]
-			// check if key contents ' - escape it
-			tmpVals := strings.Split(val, "'")
-			if len(tmpVals) > 1 {
-				escapeCount := len(tmpVals) - 1
-				for _, v := range tmpVals {
-					tmpVal += v
-					if escapeCount > 0 {
-						tmpVal += `\'`
-						escapeCount--
-					}
-				}
-				tmpVal += `'`
-			} else {
-				tmpVal += val + `'`
+	sort.Strings(names)
+	secretTypes := make(map[string]bool, len(filter.Types))
+	for _, t := range filter.Types {
+		secretTypes[t] = true
+	}
+	out := make([]types.SecretInfo, 0)
+	// metadata is read by chunks, so filtered page doesn't read whole list
+	for start := 0; start < len(names); start += listChunk {
+		chunk := names[start:]
+		if len(chunk) > listChunk {
+			chunk = chunk[:listChunk]
+		}
+		cmds := make([]*redis.SliceCmd, len(chunk))
+		_, err := rs.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, name := range chunk {
+				cmds[i] = pipe.HMGet(ctx, prefix+name, "type", "symmkey")
 			}
-			out += tmpVal
-			if commaCount > 0 {
-				out += `,`
-				commaCount--
+			return nil
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		for i, cmd := range cmds {
+			vals := cmd.Val()
+			secretType, _ := vals[0].(string)
+			dataKey, _ := vals[1].(string)
+			// key can be removed after KEYS
+			if secretType == "" || (len(secretTypes) > 0 && !secretTypes[secretType]) {
+				continue
 			}
+			out = append(out, types.SecretInfo{Name: chunk[i], Type: secretType, DataKey: dataKey != ""})
+			if filter.Limit > 0 && len(out) == filter.Limit {
+				return out, start+i+1 < len(names), nil
+			}
+		}
+	}
+	return out, false, nil
+}
+
+// escapePattern escapes glob special characters of KEYS pattern.
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
 		}
+		b.WriteRune(r)
 	}
-	return out
+	return b.String()
 }
 
 // Update change existed key/value in storage.
@@ -158,15 +309,784 @@ func (rs RedisStor) Copy(ctx context.Context, srcKey string, dstKey string) erro
 	return errors.New("increment reached maximum number of retries")
 }
 
+// GetMany returns values of keys by one pipelined request. Result has value and error of each key
+// in order of keys, missing key has empty value.
+func (rs RedisStor) GetMany(ctx context.Context, keys []string) ([]types.StorageModel, []error) {
+	cmds := make([]*redis.MapStringStringCmd, len(keys))
+	// errors of commands are checked one by one below
+	_, _ = rs.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.HGetAll(ctx, key)
+		}
+		return nil
+	})
+	vals := make([]types.StorageModel, len(keys))
+	errs := make([]error, len(keys))
+	for i, cmd := range cmds {
+		errs[i] = cmd.Scan(&vals[i])
+	}
+	return vals, errs
+}
+
+// AddMany keeps values of keys by one pipelined request, result has error of each key in order of keys.
+// It isn't transaction, some values can be saved when others fail.
+func (rs RedisStor) AddMany(ctx context.Context, keys []string, vals []types.StorageModel) []error {
+	cmds := make([]*redis.IntCmd, len(keys))
+	_, _ = rs.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.HSet(ctx, key, &vals[i])
+		}
+		return nil
+	})
+	errs := make([]error, len(keys))
+	for i, cmd := range cmds {
+		errs[i] = cmd.Err()
+	}
+	return errs
+}
+
+// RemoveMany removes keys by one pipelined request, result has error of each key in order of keys.
+func (rs RedisStor) RemoveMany(ctx context.Context, keys []string) []error {
+	cmds := make([]*redis.IntCmd, len(keys))
+	_, _ = rs.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	errs := make([]error, len(keys))
+	for i, cmd := range cmds {
+		errs[i] = cmd.Err()
+	}
+	return errs
+}
+
+// AppendChange adds change of secret to change log of secrets with storage key prefix and
+// sets ev.Seq, only last changesKept changes are kept.
+func (rs RedisStor) AppendChange(ctx context.Context, prefix string, ev *types.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	seq, err := appendChangeScript.Run(ctx, rs.rdb, []string{changeSeqPrefix + prefix, changesPrefix + prefix}, data, changesKept).Int64()
+	if err != nil {
+		return err
+	}
+	ev.Seq = seq
+	return nil
+}
+
+// MarkChangeGap takes sequence number of change which isn't logged, so ListChanges resets
+// clients which would miss it.
+func (rs RedisStor) MarkChangeGap(ctx context.Context, prefix string) error {
+	return rs.rdb.Incr(ctx, changeSeqPrefix+prefix).Err()
+}
+
+// ListChanges returns up to limit changes of secrets with storage key prefix after seq since.
+// Changes.Reset is set and no changes are returned when since is 0, changes after since
+// aren't kept anymore or some of them aren't logged.
+func (rs RedisStor) ListChanges(ctx context.Context, prefix string, since int64, limit int) (*types.Changes, error) {
+	var events, oldest *redis.ZSliceCmd
+	var seq *redis.StringCmd
+	_, err := rs.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		events = pipe.ZRangeByScoreWithScores(ctx, changesPrefix+prefix, &redis.ZRangeBy{
+			Min:   "(" + strconv.FormatInt(since, 10),
+			Max:   "+inf",
+			Count: int64(limit),
+		})
+		oldest = pipe.ZRangeWithScores(ctx, changesPrefix+prefix, 0, 0)
+		seq = pipe.Get(ctx, changeSeqPrefix+prefix)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	out := types.Changes{}
+	if out.Seq, err = seq.Int64(); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	first := out.Seq + 1
+	if len(oldest.Val()) > 0 {
+		first = int64(oldest.Val()[0].Score)
+	}
+	// changes since+1..first-1 are trimmed or counter is older than client state
+	if since == 0 || since > out.Seq || first > since+1 {
+		out.Reset = true
+		return &out, nil
+	}
+	next := since + 1
+	for _, z := range events.Val() {
+		if int64(z.Score) != next {
+			// change next isn't logged, see MarkChangeGap
+			return &types.Changes{Seq: out.Seq, Reset: true}, nil
+		}
+		next++
+		member, _ := z.Member.(string)
+		_, data, _ := strings.Cut(member, ":")
+		ev := types.Event{}
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return nil, err
+		}
+		ev.Seq = int64(z.Score)
+		out.Events = append(out.Events, ev)
+	}
+	if len(out.Events) < limit && next <= out.Seq {
+		return &types.Changes{Seq: out.Seq, Reset: true}, nil
+	}
+	return &out, nil
+}
+
+// AppendAudit adds event in audit log of user login, only last auditKept events are kept.
+func (rs RedisStor) AppendAudit(ctx context.Context, login string, ev *types.AuditEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	// nanoseconds keep members of the same events unique
+	member := strconv.FormatInt(time.Now().UnixNano(), 10) + ":" + string(data)
+	_, err = rs.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, auditPrefix+login, redis.Z{Score: float64(ev.Time), Member: member})
+		pipe.ZRemRangeByRank(ctx, auditPrefix+login, 0, -auditKept-1)
+		return nil
+	})
+	return err
+}
+
+// ListAudit returns up to limit last audit events of user login after unix milliseconds since,
+// events are ordered by time.
+func (rs RedisStor) ListAudit(ctx context.Context, login string, since int64, limit int) ([]types.AuditEvent, error) {
+	members, err := rs.rdb.ZRevRangeByScore(ctx, auditPrefix+login, &redis.ZRangeBy{
+		Min:   "(" + strconv.FormatInt(since, 10),
+		Max:   "+inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	events := make([]types.AuditEvent, len(members))
+	for i, member := range members {
+		_, data, _ := strings.Cut(member, ":")
+		if err := json.Unmarshal([]byte(data), &events[len(members)-1-i]); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// AppendJournal atomically reads last record of security journal, makes next record with fn
+// and adds it. Last record is nil when journal is empty, nothing is added when fn returns nil.
+func (rs RedisStor) AppendJournal(ctx context.Context, fn func(*types.JournalEntry) (*types.JournalEntry, error)) error {
+	txf := func(tx *redis.Tx) error {
+		var prev *types.JournalEntry
+		data, err := tx.Get(ctx, journalHeadKey).Bytes()
+		switch {
+		case errors.Is(err, redis.Nil):
+		case err != nil:
+			return err
+		default:
+			prev = &types.JournalEntry{}
+			if err := json.Unmarshal(data, prev); err != nil {
+				return err
+			}
+		}
+		e, err := fn(prev)
+		if err != nil || e == nil {
+			return err
+		}
+		if data, err = json.Marshal(e); err != nil {
+			return err
+		}
+		// Operation is commited only if the watched keys remain unchanged.
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, journalHeadKey, data, 0)
+			pipe.ZAdd(ctx, journalKey, redis.Z{Score: float64(e.Seq), Member: data})
+			return nil
+		})
+		return err
+	}
+	// Retry if the key has been changed.
+	for i := 0; i < transactWatchRetries; i++ {
+		err := rs.rdb.Watch(ctx, txf, journalHeadKey)
+		if err == redis.TxFailedErr {
+			// Optimistic lock lost. Retry.
+			continue
+		}
+		return err
+	}
+
+	return errors.New("increment reached maximum number of retries")
+}
+
+// GetJournalHead returns last record of security journal, head stays empty when journal is empty.
+func (rs RedisStor) GetJournalHead(ctx context.Context, head *types.JournalEntry) error {
+	data, err := rs.rdb.Get(ctx, journalHeadKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, head)
+}
+
+// ListJournal returns up to limit records of security journal after seq since ordered by seq.
+func (rs RedisStor) ListJournal(ctx context.Context, since int64, limit int) ([]types.JournalEntry, error) {
+	members, err := rs.rdb.ZRangeByScore(ctx, journalKey, &redis.ZRangeBy{
+		Min:   "(" + strconv.FormatInt(since, 10),
+		Max:   "+inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]types.JournalEntry, len(members))
+	for i, member := range members {
+		if err := json.Unmarshal([]byte(member), &entries[i]); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// PublishEvent sends change of secret to watchers of secrets with storage key prefix,
+// all server instances receive it through Redis pub/sub.
+func (rs RedisStor) PublishEvent(ctx context.Context, prefix string, ev *types.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return rs.rdb.Publish(ctx, eventsPrefix+prefix, data).Err()
+}
+
+// SubscribeEvents returns changes of secrets with storage key prefix published after return.
+// Channel is closed when ctx is done or subscription is closed by returned func.
+func (rs RedisStor) SubscribeEvents(ctx context.Context, prefix string) (<-chan types.Event, func() error, error) {
+	pubsub := rs.rdb.Subscribe(ctx, eventsPrefix+prefix)
+	// wait for confirmation of subscription, so no event is lost after return
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, nil, err
+	}
+	out := make(chan types.Event, eventsBuffer)
+	go func() {
+		defer close(out)
+		for msg := range pubsub.Channel() {
+			ev := types.Event{}
+			if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+				continue
+			}
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, pubsub.Close, nil
+}
+
+// AddSession keeps new user session sid in storage, session expires after ttl.
+func (rs RedisStor) AddSession(ctx context.Context, sid string, sess *types.Session, ttl time.Duration) error {
+	key := sessionsPrefix + sid
+	_, err := rs.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, sess)
+		pipe.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+// GetSession returns user session sid from storage, if session doesn't exist sess stays empty.
+func (rs RedisStor) GetSession(ctx context.Context, sid string, sess *types.Session) error {
+	return rs.rdb.HGetAll(ctx, sessionsPrefix+sid).Scan(sess)
+}
+
+// RotateSession replaces session sid only if its refresh token hash still equals oldHash,
+// otherwise it returns ErrSessionChanged.
+func (rs RedisStor) RotateSession(ctx context.Context, sid string, oldHash string, sess *types.Session, ttl time.Duration) error {
+	key := sessionsPrefix + sid
+	txf := func(tx *redis.Tx) error {
+		curHash, err := tx.HGet(ctx, key, "refresh").Result()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if curHash != oldHash {
+			return ErrSessionChanged
+		}
+		// Operation is commited only if the watched keys remain unchanged.
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, sess)
+			pipe.Expire(ctx, key, ttl)
+			return nil
+		})
+		return err
+	}
+	// Retry if the key has been changed.
+	for i := 0; i < transactWatchRetries; i++ {
+		err := rs.rdb.Watch(ctx, txf, key)
+		if err == redis.TxFailedErr {
+			// Optimistic lock lost. Retry.
+			continue
+		}
+		return err
+	}
+
+	return errors.New("increment reached maximum number of retries")
+}
+
+// RemoveSession revokes user session sid.
+func (rs RedisStor) RemoveSession(ctx context.Context, sid string) error {
+	return rs.rdb.Del(ctx, sessionsPrefix+sid).Err()
+}
+
+// CountSessions returns count of active user sessions. Keys are walked by SCAN, so db isn't
+// blocked, but sessions created or expired during the walk may be missed.
+func (rs RedisStor) CountSessions(ctx context.Context) (int64, error) {
+	var (
+		count  int64
+		cursor uint64
+	)
+	for {
+		keys, next, err := rs.rdb.Scan(ctx, cursor, sessionsPrefix+"*", sessionsScanChunk).Result()
+		if err != nil {
+			return 0, err
+		}
+		count += int64(len(keys))
+		if next == 0 {
+			return count, nil
+		}
+		cursor = next
+	}
+}
+
+// PoolStats returns statistics of connection pool to storage db.
+func (rs RedisStor) PoolStats() *redis.PoolStats {
+	return rs.rdb.PoolStats()
+}
+
+// Get2FA returns 2FA settings of user login, if they don't exist tf stays empty.
+func (rs RedisStor) Get2FA(ctx context.Context, login string, tf *types.TwoFactor) error {
+	return rs.rdb.HGetAll(ctx, twoFactorPrefix+login).Scan(tf)
+}
+
+// Update2FA atomically reads 2FA settings of user login, changes them with fn and saves.
+// If fn returns error, settings stay unchanged and the error is returned.
+func (rs RedisStor) Update2FA(ctx context.Context, login string, fn func(*types.TwoFactor) error) error {
+	key := twoFactorPrefix + login
+	txf := func(tx *redis.Tx) error {
+		tf := types.TwoFactor{}
+		if err := tx.HGetAll(ctx, key).Scan(&tf); err != nil {
+			return err
+		}
+		if err := fn(&tf); err != nil {
+			return err
+		}
+		// Operation is commited only if the watched keys remain unchanged.
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.HSet(ctx, key, &tf).Err()
+		})
+		return err
+	}
+	// Retry if the key has been changed.
+	for i := 0; i < transactWatchRetries; i++ {
+		err := rs.rdb.Watch(ctx, txf, key)
+		if err == redis.TxFailedErr {
+			// Optimistic lock lost. Retry.
+			continue
+		}
+		return err
+	}
+
+	return errors.New("increment reached maximum number of retries")
+}
+
+// Remove2FA removes 2FA settings of user login.
+func (rs RedisStor) Remove2FA(ctx context.Context, login string) error {
+	return rs.rdb.Del(ctx, twoFactorPrefix+login).Err()
+}
+
+// GetLockout returns failed login attempts of subject, if there are no attempts lo stays empty.
+func (rs RedisStor) GetLockout(ctx context.Context, subject string, lo *types.Lockout) error {
+	return rs.rdb.HGetAll(ctx, lockoutPrefix+subject).Scan(lo)
+}
+
+// UpdateLockout atomically reads failed login attempts of subject, changes them with fn and saves,
+// attempts are forgotten after ttl without updates. If fn returns error, attempts stay unchanged.
+func (rs RedisStor) UpdateLockout(ctx context.Context, subject string, fn func(*types.Lockout) error, ttl time.Duration) error {
+	key := lockoutPrefix + subject
+	txf := func(tx *redis.Tx) error {
+		lo := types.Lockout{}
+		if err := tx.HGetAll(ctx, key).Scan(&lo); err != nil {
+			return err
+		}
+		if err := fn(&lo); err != nil {
+			return err
+		}
+		// Operation is commited only if the watched keys remain unchanged.
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, &lo)
+			pipe.Expire(ctx, key, ttl)
+			return nil
+		})
+		return err
+	}
+	// Retry if the key has been changed.
+	for i := 0; i < transactWatchRetries; i++ {
+		err := rs.rdb.Watch(ctx, txf, key)
+		if err == redis.TxFailedErr {
+			// Optimistic lock lost. Retry.
+			continue
+		}
+		return err
+	}
+
+	return errors.New("increment reached maximum number of retries")
+}
+
+// RemoveLockout forgets failed login attempts of subject.
+func (rs RedisStor) RemoveLockout(ctx context.Context, subject string) error {
+	return rs.rdb.Del(ctx, lockoutPrefix+subject).Err()
+}
+
+// TakeRateToken takes token from bucket subject which is refilled by rate tokens per second
+// and holds burst tokens at most. It returns 0 when token is taken, otherwise time to wait for
+// next token. Bucket is shared by all server instances.
+func (rs RedisStor) TakeRateToken(ctx context.Context, subject string, rate float64, burst int) (time.Duration, error) {
+	wait, err := takeTokenScript.Run(ctx, rs.rdb, []string{rateLimitPrefix + subject}, rate, burst, time.Now().UnixMilli()).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// AcquireInFlight takes slot of in-flight call of user login if there are less than limit ones,
+// slot is freed after ttl if it isn't released. It returns id of taken slot or empty string when
+// all slots are busy.
+func (rs RedisStor) AcquireInFlight(ctx context.Context, login string, limit int, ttl time.Duration) (string, error) {
+	slot, err := crypto.GenSessionID()
+	if err != nil {
+		return "", err
+	}
+	ok, err := acquireSlotScript.Run(ctx, rs.rdb, []string{inFlightPrefix + login}, limit, ttl.Milliseconds(), slot).Int64()
+	if err != nil || ok != 1 {
+		return "", err
+	}
+	return slot, nil
+}
+
+// ReleaseInFlight frees slot of in-flight call of user login.
+func (rs RedisStor) ReleaseInFlight(ctx context.Context, login string, slot string) error {
+	return rs.rdb.ZRem(ctx, inFlightPrefix+login, slot).Err()
+}
+
+// SetKeyPair keeps keypair of user login, existed keypair isn't replaced and ErrKeyPairExists is returned.
+func (rs RedisStor) SetKeyPair(ctx context.Context, login string, kp *types.KeyPair) error {
+	key := keyPairsPrefix + login
+	txf := func(tx *redis.Tx) error {
+		n, err := tx.Exists(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrKeyPairExists
+		}
+		// Operation is commited only if the watched keys remain unchanged.
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.HSet(ctx, key, kp).Err()
+		})
+		return err
+	}
+	// Retry if the key has been changed.
+	for i := 0; i < transactWatchRetries; i++ {
+		err := rs.rdb.Watch(ctx, txf, key)
+		if err == redis.TxFailedErr {
+			// Optimistic lock lost. Retry.
+			continue
+		}
+		return err
+	}
+
+	return errors.New("increment reached maximum number of retries")
+}
+
+// GetKeyPair returns keypair of user login, if it doesn't exist kp stays empty.
+func (rs RedisStor) GetKeyPair(ctx context.Context, login string, kp *types.KeyPair) error {
+	return rs.rdb.HGetAll(ctx, keyPairsPrefix+login).Scan(kp)
+}
+
+// AddShare keeps secret shared with recipient and adds recipient in set of recipients of the secret.
+func (rs RedisStor) AddShare(ctx context.Context, recipient string, share *types.Share) error {
+	_, err := rs.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, sharedPrefix+recipient+"/"+share.Owner+"/"+share.Key, share)
+		pipe.SAdd(ctx, sharedByPrefix+share.Owner+"/"+share.Key, recipient)
+		return nil
+	})
+	return err
+}
+
+// ListShares returns secrets shared with recipient. Login can contain '/', so entries of other
+// recipients matched by the pattern are skipped by their owner and key.
+func (rs RedisStor) ListShares(ctx context.Context, recipient string) ([]types.Share, error) {
+	keys, err := rs.rdb.Keys(ctx, escapePattern(sharedPrefix+recipient+"/")+"*").Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.Share, 0, len(keys))
+	for _, key := range keys {
+		share := types.Share{}
+		if err := rs.rdb.HGetAll(ctx, key).Scan(&share); err != nil {
+			return nil, err
+		}
+		if share.Owner != "" && key == sharedPrefix+recipient+"/"+share.Owner+"/"+share.Key {
+			out = append(out, share)
+		}
+	}
+	return out, nil
+}
+
+// RemoveShare revokes secret key of owner shared with recipient, empty recipient means all recipients.
+func (rs RedisStor) RemoveShare(ctx context.Context, owner string, key string, recipient string) error {
+	setKey := sharedByPrefix + owner + "/" + key
+	recipients := []string{recipient}
+	if recipient == "" {
+		var err error
+		if recipients, err = rs.rdb.SMembers(ctx, setKey).Result(); err != nil {
+			return err
+		}
+	}
+	_, err := rs.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, r := range recipients {
+			pipe.Del(ctx, sharedPrefix+r+"/"+owner+"/"+key)
+		}
+		if recipient == "" {
+			pipe.Del(ctx, setKey)
+		} else {
+			pipe.SRem(ctx, setKey, recipient)
+		}
+		return nil
+	})
+	return err
+}
+
+// VaultKeyPrefix returns prefix of storage keys of vault secrets.
+// Vault name can't contain ':', so the first ':' separates name of secret.
+func VaultKeyPrefix(vault string) string {
+	return vaultDataPrefix + vault + ":"
+}
+
+// CreateVault creates vault with admin member, existed vault isn't replaced and ErrVaultExists is returned.
+func (rs RedisStor) CreateVault(ctx context.Context, vault string, admin *types.VaultMember) error {
+	key := vaultsPrefix + vault
+	txf := func(tx *redis.Tx) error {
+		n, err := tx.Exists(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrVaultExists
+		}
+		// Operation is commited only if the watched keys remain unchanged.
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, &types.Vault{Version: 1})
+			pipe.HSet(ctx, vaultMembersPrefix+vault, admin.Login, admin.Role)
+			pipe.HSet(ctx, vaultKeysPrefix+vault, admin.Login, admin.VaultKey)
+			pipe.SAdd(ctx, userVaultsPrefix+admin.Login, vault)
+			return nil
+		})
+		return err
+	}
+	// Retry if the key has been changed.
+	for i := 0; i < transactWatchRetries; i++ {
+		err := rs.rdb.Watch(ctx, txf, key)
+		if err == redis.TxFailedErr {
+			// Optimistic lock lost. Retry.
+			continue
+		}
+		return err
+	}
+
+	return errors.New("increment reached maximum number of retries")
+}
+
+// GetVault returns vault, if it doesn't exist v stays empty.
+func (rs RedisStor) GetVault(ctx context.Context, vault string, v *types.Vault) error {
+	return rs.rdb.HGetAll(ctx, vaultsPrefix+vault).Scan(v)
+}
+
+// GetVaultMember returns member login of vault, if user isn't member its role is empty.
+func (rs RedisStor) GetVaultMember(ctx context.Context, vault string, login string) (*types.VaultMember, error) {
+	member := types.VaultMember{Login: login}
+	var err error
+	member.Role, err = rs.rdb.HGet(ctx, vaultMembersPrefix+vault, login).Result()
+	if err == redis.Nil {
+		return &member, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	member.VaultKey, err = rs.rdb.HGet(ctx, vaultKeysPrefix+vault, login).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// SetVaultMember adds member in vault or changes its role, sealed vault key is kept if it is empty.
+func (rs RedisStor) SetVaultMember(ctx context.Context, vault string, member *types.VaultMember) error {
+	_, err := rs.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, vaultMembersPrefix+vault, member.Login, member.Role)
+		if member.VaultKey != "" {
+			pipe.HSet(ctx, vaultKeysPrefix+vault, member.Login, member.VaultKey)
+		}
+		pipe.SAdd(ctx, userVaultsPrefix+member.Login, vault)
+		return nil
+	})
+	return err
+}
+
+// ListVaultMembers returns members of vault sorted by login, without sealed keys.
+func (rs RedisStor) ListVaultMembers(ctx context.Context, vault string) ([]types.VaultMember, error) {
+	roles, err := rs.rdb.HGetAll(ctx, vaultMembersPrefix+vault).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]types.VaultMember, 0, len(roles))
+	for login, role := range roles {
+		out = append(out, types.VaultMember{Login: login, Role: role})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Login < out[j].Login })
+	return out, nil
+}
+
+// ListUserVaults returns names of vaults where user login is member.
+func (rs RedisStor) ListUserVaults(ctx context.Context, login string) ([]string, error) {
+	vaults, err := rs.rdb.SMembers(ctx, userVaultsPrefix+login).Result()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(vaults)
+	return vaults, nil
+}
+
+// ListVaultSecrets returns all secrets of vault by their names.
+func (rs RedisStor) ListVaultSecrets(ctx context.Context, vault string) (map[string]types.StorageModel, error) {
+	prefix := VaultKeyPrefix(vault)
+	keys, err := rs.rdb.Keys(ctx, prefix+"*").Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]types.StorageModel, len(keys))
+	for _, key := range keys {
+		data := types.StorageModel{}
+		if err := rs.rdb.HGetAll(ctx, key).Scan(&data); err != nil {
+			return nil, err
+		}
+		if data.Type != "" {
+			out[key[len(prefix):]] = data
+		}
+	}
+	return out, nil
+}
+
+// RotateVault replaces vault key and re-encrypted secrets, it removes rot.Removed member as well.
+// If version of vault key, remaining members or names of secrets don't match rot, ErrVaultChanged is returned.
+func (rs RedisStor) RotateVault(ctx context.Context, vault string, rot *types.VaultRotation) error {
+	key := vaultsPrefix + vault
+	membersKey := vaultMembersPrefix + vault
+	prefix := VaultKeyPrefix(vault)
+	txf := func(tx *redis.Tx) error {
+		v := types.Vault{}
+		if err := tx.HGetAll(ctx, key).Scan(&v); err != nil {
+			return err
+		}
+		if v.Version != rot.Version {
+			return ErrVaultChanged
+		}
+		members, err := tx.HKeys(ctx, membersKey).Result()
+		if err != nil {
+			return err
+		}
+		remaining := 0
+		for _, login := range members {
+			if login == rot.Removed {
+				continue
+			}
+			if _, ok := rot.Keys[login]; !ok {
+				return ErrVaultChanged
+			}
+			remaining++
+		}
+		// removed user must be member
+		if remaining != len(rot.Keys) || (rot.Removed != "" && remaining == len(members)) {
+			return ErrVaultChanged
+		}
+		keys, err := tx.Keys(ctx, prefix+"*").Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) != len(rot.Secrets) {
+			return ErrVaultChanged
+		}
+		for _, k := range keys {
+			if _, ok := rot.Secrets[k[len(prefix):]]; !ok {
+				return ErrVaultChanged
+			}
+		}
+		// Operation is commited only if the watched keys remain unchanged.
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if rot.Removed != "" {
+				pipe.HDel(ctx, membersKey, rot.Removed)
+				pipe.HDel(ctx, vaultKeysPrefix+vault, rot.Removed)
+				pipe.SRem(ctx, userVaultsPrefix+rot.Removed, vault)
+			}
+			for _, login := range sortedKeys(rot.Keys) {
+				pipe.HSet(ctx, vaultKeysPrefix+vault, login, rot.Keys[login])
+			}
+			for _, name := range sortedKeys(rot.Secrets) {
+				data := rot.Secrets[name]
+				pipe.HSet(ctx, prefix+name, &data)
+			}
+			pipe.HSet(ctx, key, &types.Vault{Version: rot.Version + 1})
+			return nil
+		})
+		return err
+	}
+	// Retry if the keys have been changed.
+	for i := 0; i < transactWatchRetries; i++ {
+		err := rs.rdb.Watch(ctx, txf, key, membersKey)
+		if err == redis.TxFailedErr {
+			// Optimistic lock lost. Retry.
+			continue
+		}
+		return err
+	}
+
+	return errors.New("increment reached maximum number of retries")
+}
+
+// sortedKeys returns keys of map in sorted order, so transactions are deterministic.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Ping check connection to storage and check server master key hash in storage.
 // If hash exists, it will be compared with server master key from server configuration.
 // Else new hash will be created and saved in storage.
+// Empty srvKey means sealed server, only connection is checked.
 func (rs RedisStor) Ping(ctx context.Context, srvKey []byte) error {
 	if err := rs.rdb.Ping(ctx).Err(); err != nil {
 		return err
 	}
+	if len(srvKey) == 0 {
+		return nil
+	}
 	data := types.StorageModel{}
-	if err := rs.Get(ctx, "server", &data); err != nil {
+	if err := rs.Get(ctx, serverKey, &data); err != nil {
 		return err
 	}
 	if data.PassHash == "" {
@@ -175,19 +1095,69 @@ func (rs RedisStor) Ping(ctx context.Context, srvKey []byte) error {
 			return err
 		}
 		data.PassHash = srvHash
-		if err := rs.Add(ctx, "server", &data); err != nil {
+		if err := rs.Add(ctx, serverKey, &data); err != nil {
 			return err
 		}
-	} else {
-		srvHash, err := crypto.HashPasswd(ctx, srvKey)
+		return rs.AppendJournal(ctx, journal.Event(journal.KindMasterKeyCheck, "", "hash is saved"))
+	}
+	srvHash, err := crypto.HashPasswd(ctx, srvKey)
+	if err != nil {
+		return err
+	}
+	if data.PassHash != srvHash {
+		// mismatch is returned even when it isn't recorded
+		_ = rs.AppendJournal(ctx, journal.Event(journal.KindMasterKeyCheck, "", "key doesn't match"))
+		return ErrServerKey
+	}
+	return rs.AppendJournal(ctx, journal.Event(journal.KindMasterKeyCheck, "", "ok"))
+}
+
+// GetSealConfig returns config of master key splitting, if server isn't initialized cfg stays empty.
+func (rs RedisStor) GetSealConfig(ctx context.Context, cfg *types.SealConfig) error {
+	return rs.rdb.HGetAll(ctx, sealKey).Scan(cfg)
+}
+
+// InitSeal keeps config of master key splitting and hash of master key. Existed config isn't replaced
+// and ErrSealInitialized is returned, existed hash must match srvKey otherwise ErrServerKey is returned.
+func (rs RedisStor) InitSeal(ctx context.Context, cfg *types.SealConfig, srvKey []byte) error {
+	srvHash, err := crypto.HashPasswd(ctx, srvKey)
+	if err != nil {
+		return err
+	}
+	txf := func(tx *redis.Tx) error {
+		n, err := tx.Exists(ctx, sealKey).Result()
 		if err != nil {
 			return err
 		}
-		if data.PassHash != srvHash {
-			return errors.New("server hash in db doesn't match! Use different db")
+		if n > 0 {
+			return ErrSealInitialized
+		}
+		data := types.StorageModel{}
+		if err := tx.HGetAll(ctx, serverKey).Scan(&data); err != nil {
+			return err
+		}
+		if data.PassHash != "" && data.PassHash != srvHash {
+			return ErrServerKey
+		}
+		// Operation is commited only if the watched keys remain unchanged.
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, serverKey, "pass", srvHash)
+			pipe.HSet(ctx, sealKey, cfg)
+			return nil
+		})
+		return err
+	}
+	// Retry if the keys have been changed.
+	for i := 0; i < transactWatchRetries; i++ {
+		err := rs.rdb.Watch(ctx, txf, sealKey, serverKey)
+		if err == redis.TxFailedErr {
+			// Optimistic lock lost. Retry.
+			continue
 		}
+		return err
 	}
-	return nil
+
+	return errors.New("increment reached maximum number of retries")
 }
 
 // Close closes connection to storage db.
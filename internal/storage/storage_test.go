@@ -2,7 +2,11 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redismock/v9"
 	"github.com/hrapovd1/gokeepas/internal/config"
@@ -10,7 +14,6 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"google.golang.org/grpc/metadata"
 )
 
 func TestNewRedisStor(t *testing.T) {
@@ -84,54 +87,56 @@ func TestRedisStor_Get(t *testing.T) {
 	})
 }
 
-func TestRedisStor_List(t *testing.T) {
+func TestRedisStor_ListSecrets(t *testing.T) {
 	db, mock := redismock.NewClientMock()
-	storage := RedisStor{rdb: db}
-	mock.ExpectKeys("*").SetVal([]string{"test/1", "test/11"})
-	tests := []struct {
-		name string
-		ctx  context.Context
-		in   string
-		out  string
-	}{
-		{
-			"two keys",
-			metadata.NewIncomingContext(
-				context.Background(),
-				metadata.New(map[string]string{"login": "test"}),
-			),
-			"*",
-			"'1','11'",
-		},
-		{
-			"without login",
-			context.Background(),
-			"*",
-			"",
-		},
-	}
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			result := storage.List(test.ctx, test.in)
-			assert.Equal(t, test.out, result)
-			assert.NoError(t, mock.ExpectationsWereMet())
-		})
-	}
-	mock.ClearExpect()
-	t.Run("one key", func(t *testing.T) {
-		mock.ExpectKeys("*").SetVal([]string{"test/1"})
-		ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
-		result := storage.List(ctx, "*")
-		assert.Equal(t, "'1'", result)
+	stor := RedisStor{rdb: db}
+	ctx := context.Background()
+	t.Run("all", func(t *testing.T) {
+		mock.ExpectKeys("test/*").SetVal([]string{"test/b','c", "test/a"})
+		mock.ExpectHMGet("test/a", "type", "symmkey").SetVal([]interface{}{"LOGIN", "dk"})
+		mock.ExpectHMGet("test/b','c", "type", "symmkey").SetVal([]interface{}{"TEXT", nil})
+		secrets, more, err := stor.ListSecrets(ctx, "test/", types.ListFilter{})
+		require.NoError(t, err)
+		assert.False(t, more)
+		assert.Equal(t, []types.SecretInfo{{Name: "a", Type: "LOGIN", DataKey: true}, {Name: "b','c", Type: "TEXT"}}, secrets)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("page", func(t *testing.T) {
+		mock.ExpectKeys(`test/db\*x*`).SetVal([]string{"test/db*x1", "test/db*x2", "test/db*x3", "test/db*x4"})
+		mock.ExpectHMGet("test/db*x2", "type", "symmkey").SetVal([]interface{}{"TEXT", nil})
+		mock.ExpectHMGet("test/db*x3", "type", "symmkey").SetVal([]interface{}{nil, nil})
+		mock.ExpectHMGet("test/db*x4", "type", "symmkey").SetVal([]interface{}{"LOGIN", nil})
+		filter := types.ListFilter{Prefix: "db*x", Types: []string{"LOGIN"}, After: "db*x1", Limit: 1}
+		secrets, more, err := stor.ListSecrets(ctx, "test/", filter)
+		require.NoError(t, err)
+		assert.False(t, more)
+		assert.Equal(t, []types.SecretInfo{{Name: "db*x4", Type: "LOGIN"}}, secrets)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("more", func(t *testing.T) {
+		mock.ExpectKeys("test/*").SetVal([]string{"test/a", "test/b"})
+		mock.ExpectHMGet("test/a", "type", "symmkey").SetVal([]interface{}{"TEXT", nil})
+		mock.ExpectHMGet("test/b", "type", "symmkey").SetVal([]interface{}{"TEXT", nil})
+		secrets, more, err := stor.ListSecrets(ctx, "test/", types.ListFilter{Limit: 1})
+		require.NoError(t, err)
+		assert.True(t, more)
+		assert.Len(t, secrets, 1)
+		mock.ClearExpect()
 	})
-	t.Run("special keys", func(t *testing.T) {
-		mock.ExpectKeys("*").SetVal([]string{"test/1'1", "test/11"})
-		ctx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{"login": "test"}))
-		result := storage.List(ctx, "*")
-		assert.Equal(t, `'1\'1','11'`, result)
+	t.Run("keys error", func(t *testing.T) {
+		mock.ExpectKeys("test/*").SetErr(errors.New("broken"))
+		_, _, err := stor.ListSecrets(ctx, "test/", types.ListFilter{})
+		assert.Error(t, err)
+		mock.ClearExpect()
 	})
 }
 
+func Test_escapePattern(t *testing.T) {
+	assert.Equal(t, `a\*b\?c\[d\]e\\f`, escapePattern(`a*b?c[d]e\f`))
+}
+
 func TestRedisStor_Update(t *testing.T) {
 	db, mock := redismock.NewClientMock()
 	stor := RedisStor{rdb: db}
@@ -181,12 +186,631 @@ func TestRedisStor_Copy(t *testing.T) {
 	mock.ClearExpect()
 }
 
+func TestRedisStor_GetMany(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectHGetAll("key1").SetVal(map[string]string{"type": "TEXT", "data": "one"})
+	mock.ExpectHGetAll("key2").SetVal(map[string]string{})
+	mock.ExpectHGetAll("key3").SetErr(errors.New("broken"))
+	vals, errs := stor.GetMany(context.Background(), []string{"key1", "key2", "key3"})
+	assert.Equal(t, []types.StorageModel{{Type: "TEXT", Data: "one"}, {}, {}}, vals)
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.Error(t, errs[2])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_AddMany(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectHSet("key1", "pass", "", "symmkey", "dk", "data", "one", "type", "TEXT").SetVal(3)
+	mock.ExpectHSet("key2", "pass", "", "symmkey", "", "data", "two", "type", "TEXT").SetErr(errors.New("broken"))
+	errs := stor.AddMany(context.Background(), []string{"key1", "key2"}, []types.StorageModel{
+		{SymmKey: "dk", Data: "one", Type: "TEXT"},
+		{Data: "two", Type: "TEXT"},
+	})
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_RemoveMany(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectDel("key1").SetVal(1)
+	mock.ExpectDel("key2").SetVal(0)
+	errs := stor.RemoveMany(context.Background(), []string{"key1", "key2"})
+	assert.Equal(t, []error{nil, nil}, errs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_PublishEvent(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectPublish("/events/test/", []byte(`{"kind":"RENAME","key":"one","newkey":"two","type":"TEXT","time":1}`)).SetVal(1)
+	err := stor.PublishEvent(context.Background(), "test/", &types.Event{Kind: types.EventRename, Key: "one", NewKey: "two", Type: "TEXT", Time: 1})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_AppendChange(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	data := []byte(`{"kind":"ADD","key":"one","type":"TEXT","time":1}`)
+	mock.ExpectEvalSha(appendChangeScript.Hash(), []string{"/changeseq/test/", "/changes/test/"}, data, changesKept).SetVal(int64(7))
+	ev := types.Event{Kind: types.EventAdd, Key: "one", Type: "TEXT", Time: 1}
+	require.NoError(t, stor.AppendChange(context.Background(), "test/", &ev))
+	assert.Equal(t, int64(7), ev.Seq)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_MarkChangeGap(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectIncr("/changeseq/test/").SetVal(8)
+	require.NoError(t, stor.MarkChangeGap(context.Background(), "test/"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_AppendAudit(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	data := `{"login":"test","method":"/gokeepas.KeepPas/Get","key":"one","code":"OK","time":5}`
+	mock.ExpectTxPipeline()
+	mock.CustomMatch(func(expected, actual []interface{}) error {
+		_, member, _ := strings.Cut(fmt.Sprint(actual[3]), ":")
+		if actual[1] != "/audit/test" || member != data {
+			return fmt.Errorf("unexpected zadd %v", actual)
+		}
+		return nil
+	}).ExpectZAdd("/audit/test", redis.Z{Score: 5, Member: ""}).SetVal(1)
+	mock.ExpectZRemRangeByRank("/audit/test", 0, -auditKept-1).SetVal(0)
+	mock.ExpectTxPipelineExec()
+	ev := types.AuditEvent{Login: "test", Method: "/gokeepas.KeepPas/Get", Key: "one", Code: "OK", Time: 5}
+	require.NoError(t, stor.AppendAudit(context.Background(), "test", &ev))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_ListAudit(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectZRevRangeByScore("/audit/test", &redis.ZRangeBy{Min: "(4", Max: "+inf", Count: 10}).SetVal([]string{
+		`2:{"login":"test","method":"/gokeepas.KeepPas/Remove","key":"one","code":"NotFound","time":7}`,
+		`1:{"login":"test","method":"/gokeepas.KeepPas/Get","key":"one","code":"OK","time":5}`,
+	})
+	events, err := stor.ListAudit(context.Background(), "test", 4, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []types.AuditEvent{
+		{Login: "test", Method: "/gokeepas.KeepPas/Get", Key: "one", Code: "OK", Time: 5},
+		{Login: "test", Method: "/gokeepas.KeepPas/Remove", Key: "one", Code: "NotFound", Time: 7},
+	}, events)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_ListChanges(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	ctx := context.Background()
+	expect := func(since int64, events []redis.Z, oldest []redis.Z, seq string) {
+		mock.ExpectTxPipeline()
+		mock.ExpectZRangeByScoreWithScores("/changes/test/", &redis.ZRangeBy{Min: fmt.Sprintf("(%d", since), Max: "+inf", Count: 10}).SetVal(events)
+		mock.ExpectZRangeWithScores("/changes/test/", 0, 0).SetVal(oldest)
+		if seq == "" {
+			// mock stops transaction at nil reply
+			mock.ExpectGet("/changeseq/test/").RedisNil()
+			return
+		}
+		mock.ExpectGet("/changeseq/test/").SetVal(seq)
+		mock.ExpectTxPipelineExec()
+	}
+	t.Run("changes", func(t *testing.T) {
+		expect(4, []redis.Z{
+			{Score: 5, Member: `5:{"kind":"ADD","key":"one","type":"TEXT","time":1}`},
+			{Score: 6, Member: `6:{"kind":"RENAME","key":"one","newkey":"two","type":"TEXT","time":2}`},
+		}, []redis.Z{{Score: 3, Member: `3:{}`}}, "6")
+		changes, err := stor.ListChanges(ctx, "test/", 4, 10)
+		require.NoError(t, err)
+		assert.Equal(t, &types.Changes{Seq: 6, Events: []types.Event{
+			{Kind: types.EventAdd, Key: "one", Type: "TEXT", Time: 1, Seq: 5},
+			{Kind: types.EventRename, Key: "one", NewKey: "two", Type: "TEXT", Time: 2, Seq: 6},
+		}}, changes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("trimmed", func(t *testing.T) {
+		expect(4, []redis.Z{{Score: 9, Member: `9:{}`}}, []redis.Z{{Score: 9, Member: `9:{}`}}, "9")
+		changes, err := stor.ListChanges(ctx, "test/", 4, 10)
+		require.NoError(t, err)
+		assert.Equal(t, &types.Changes{Seq: 9, Reset: true}, changes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("first sync", func(t *testing.T) {
+		expect(0, nil, nil, "")
+		changes, err := stor.ListChanges(ctx, "test/", 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, &types.Changes{Reset: true}, changes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("up to date", func(t *testing.T) {
+		expect(9, nil, []redis.Z{{Score: 9, Member: `9:{}`}}, "9")
+		changes, err := stor.ListChanges(ctx, "test/", 9, 10)
+		require.NoError(t, err)
+		assert.Equal(t, &types.Changes{Seq: 9}, changes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("missed change", func(t *testing.T) {
+		// change 6 is marked as gap
+		expect(4, []redis.Z{
+			{Score: 5, Member: `5:{"kind":"ADD","key":"one","type":"TEXT","time":1}`},
+			{Score: 7, Member: `7:{"kind":"REMOVE","key":"one","time":2}`},
+		}, []redis.Z{{Score: 3, Member: `3:{}`}}, "7")
+		changes, err := stor.ListChanges(ctx, "test/", 4, 10)
+		require.NoError(t, err)
+		assert.Equal(t, &types.Changes{Seq: 7, Reset: true}, changes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("missed last change", func(t *testing.T) {
+		expect(4, []redis.Z{{Score: 5, Member: `5:{"kind":"ADD","key":"one","type":"TEXT","time":1}`}}, []redis.Z{{Score: 3, Member: `3:{}`}}, "6")
+		changes, err := stor.ListChanges(ctx, "test/", 4, 10)
+		require.NoError(t, err)
+		assert.Equal(t, &types.Changes{Seq: 6, Reset: true}, changes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("newer than server", func(t *testing.T) {
+		expect(12, nil, nil, "")
+		changes, err := stor.ListChanges(ctx, "test/", 12, 10)
+		require.NoError(t, err)
+		assert.True(t, changes.Reset)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRedisStor_AddSession(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	sess := types.Session{Login: "test", RefreshHash: "hash"}
+	mock.ExpectTxPipeline()
+	mock.ExpectHSet("/sessions/sid", "login", "test", "refresh", "hash").SetVal(2)
+	mock.ExpectExpire("/sessions/sid", time.Hour).SetVal(true)
+	mock.ExpectTxPipelineExec()
+	err := stor.AddSession(context.Background(), "sid", &sess, time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_GetSession(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectHGetAll("/sessions/sid").SetVal(map[string]string{"login": "test", "refresh": "hash"})
+		sess := types.Session{}
+		err := stor.GetSession(context.Background(), "sid", &sess)
+		assert.NoError(t, err)
+		assert.Equal(t, types.Session{Login: "test", RefreshHash: "hash"}, sess)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("wrong", func(t *testing.T) {
+		mock.ExpectHGetAll("/sessions/sid").RedisNil()
+		err := stor.GetSession(context.Background(), "sid", &types.Session{})
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRedisStor_RotateSession(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	sess := types.Session{Login: "test", RefreshHash: "new"}
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectWatch("/sessions/sid")
+		mock.ExpectHGet("/sessions/sid", "refresh").SetVal("old")
+		mock.ExpectTxPipeline()
+		mock.ExpectHSet("/sessions/sid", "login", "test", "refresh", "new").SetVal(0)
+		mock.ExpectExpire("/sessions/sid", time.Hour).SetVal(true)
+		mock.ExpectTxPipelineExec()
+		err := stor.RotateSession(context.Background(), "sid", "old", &sess, time.Hour)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("changed", func(t *testing.T) {
+		mock.ExpectWatch("/sessions/sid")
+		mock.ExpectHGet("/sessions/sid", "refresh").SetVal("other")
+		err := stor.RotateSession(context.Background(), "sid", "old", &sess, time.Hour)
+		assert.ErrorIs(t, err, ErrSessionChanged)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+}
+
+func TestRedisStor_RemoveSession(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectDel("/sessions/sid").SetVal(1)
+	err := stor.RemoveSession(context.Background(), "sid")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_CountSessions(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectScan(0, "/sessions/*", sessionsScanChunk).SetVal([]string{"/sessions/a", "/sessions/b"}, 7)
+		mock.ExpectScan(7, "/sessions/*", sessionsScanChunk).SetVal([]string{"/sessions/c"}, 0)
+		count, err := stor.CountSessions(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("error", func(t *testing.T) {
+		mock.ExpectScan(0, "/sessions/*", sessionsScanChunk).SetErr(errors.New("broken"))
+		_, err := stor.CountSessions(context.Background())
+		assert.Error(t, err)
+	})
+}
+
+func TestRedisStor_Get2FA(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectHGetAll("/2fa/test").SetVal(map[string]string{"seed": "seed", "enabled": "1", "laststep": "5", "recovery": "h1,h2"})
+	tf := types.TwoFactor{}
+	err := stor.Get2FA(context.Background(), "test", &tf)
+	assert.NoError(t, err)
+	assert.Equal(t, types.TwoFactor{Seed: "seed", Enabled: true, LastStep: 5, Recovery: "h1,h2"}, tf)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_Update2FA(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectWatch("/2fa/test")
+		mock.ExpectHGetAll("/2fa/test").SetVal(map[string]string{"seed": "seed", "enabled": "1", "laststep": "5"})
+		mock.ExpectTxPipeline()
+		mock.ExpectHSet("/2fa/test", "seed", "seed", "enabled", true, "laststep", int64(6), "recovery", "").SetVal(0)
+		mock.ExpectTxPipelineExec()
+		err := stor.Update2FA(context.Background(), "test", func(tf *types.TwoFactor) error {
+			tf.LastStep++
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("fn error", func(t *testing.T) {
+		mock.ExpectWatch("/2fa/test")
+		mock.ExpectHGetAll("/2fa/test").SetVal(map[string]string{})
+		fnErr := errors.New("fn error")
+		err := stor.Update2FA(context.Background(), "test", func(tf *types.TwoFactor) error {
+			return fnErr
+		})
+		assert.ErrorIs(t, err, fnErr)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+}
+
+func TestRedisStor_Remove2FA(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectDel("/2fa/test").SetVal(1)
+	err := stor.Remove2FA(context.Background(), "test")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_GetLockout(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectHGetAll("/lockout/login/test").SetVal(map[string]string{"failures": "3", "until": "100"})
+	lo := types.Lockout{}
+	err := stor.GetLockout(context.Background(), "login/test", &lo)
+	assert.NoError(t, err)
+	assert.Equal(t, types.Lockout{Failures: 3, Until: 100}, lo)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_UpdateLockout(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectWatch("/lockout/ip/10.0.0.1")
+		mock.ExpectHGetAll("/lockout/ip/10.0.0.1").SetVal(map[string]string{})
+		mock.ExpectTxPipeline()
+		mock.ExpectHSet("/lockout/ip/10.0.0.1", "failures", int64(1), "until", int64(100)).SetVal(2)
+		mock.ExpectExpire("/lockout/ip/10.0.0.1", time.Hour).SetVal(true)
+		mock.ExpectTxPipelineExec()
+		err := stor.UpdateLockout(context.Background(), "ip/10.0.0.1", func(lo *types.Lockout) error {
+			lo.Failures++
+			lo.Until = 100
+			return nil
+		}, time.Hour)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("fn error", func(t *testing.T) {
+		mock.ExpectWatch("/lockout/ip/10.0.0.1")
+		mock.ExpectHGetAll("/lockout/ip/10.0.0.1").SetVal(map[string]string{})
+		fnErr := errors.New("fn error")
+		err := stor.UpdateLockout(context.Background(), "ip/10.0.0.1", func(lo *types.Lockout) error {
+			return fnErr
+		}, time.Hour)
+		assert.ErrorIs(t, err, fnErr)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+}
+
+func TestRedisStor_RemoveLockout(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectDel("/lockout/login/test").SetVal(1)
+	err := stor.RemoveLockout(context.Background(), "login/test")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_TakeRateToken(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	match := func(expected, actual []interface{}) error {
+		// evalsha sha 1 key rate burst now
+		if actual[3] != "/ratelimit/test/read" || actual[4] != 2.5 || actual[5] != 5 {
+			return fmt.Errorf("unexpected args %v", actual)
+		}
+		return nil
+	}
+	t.Run("taken", func(t *testing.T) {
+		mock.CustomMatch(match).ExpectEvalSha(takeTokenScript.Hash(), []string{""}, nil, nil, nil).SetVal(int64(0))
+		wait, err := stor.TakeRateToken(context.Background(), "test/read", 2.5, 5)
+		assert.NoError(t, err)
+		assert.Zero(t, wait)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("empty bucket", func(t *testing.T) {
+		mock.CustomMatch(match).ExpectEvalSha(takeTokenScript.Hash(), []string{""}, nil, nil, nil).SetVal(int64(400))
+		wait, err := stor.TakeRateToken(context.Background(), "test/read", 2.5, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, 400*time.Millisecond, wait)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRedisStor_AcquireInFlight(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	// evalsha sha 1 key limit ttl slot
+	matchSlot := func(expected, actual []interface{}) error {
+		if len(actual) != 7 || actual[3] != "/inflight/test" || actual[4] != 4 || actual[5] != int64(60000) || actual[6] == "" {
+			return fmt.Errorf("unexpected args %v", actual)
+		}
+		return nil
+	}
+	mock.CustomMatch(matchSlot).ExpectEvalSha(acquireSlotScript.Hash(), []string{""}, nil, nil, nil).SetVal(int64(1))
+	slot, err := stor.AcquireInFlight(context.Background(), "test", 4, time.Minute)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, slot)
+	mock.CustomMatch(matchSlot).ExpectEvalSha(acquireSlotScript.Hash(), []string{""}, nil, nil, nil).SetVal(int64(0))
+	slot, err = stor.AcquireInFlight(context.Background(), "test", 4, time.Minute)
+	assert.NoError(t, err)
+	assert.Empty(t, slot)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_ReleaseInFlight(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectZRem("/inflight/test", "slot").SetVal(1)
+	assert.NoError(t, stor.ReleaseInFlight(context.Background(), "test", "slot"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_SetKeyPair(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	kp := types.KeyPair{Public: "pub", Private: "priv"}
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectWatch("/keypairs/test")
+		mock.ExpectExists("/keypairs/test").SetVal(0)
+		mock.ExpectTxPipeline()
+		mock.ExpectHSet("/keypairs/test", "public", "pub", "private", "priv").SetVal(2)
+		mock.ExpectTxPipelineExec()
+		err := stor.SetKeyPair(context.Background(), "test", &kp)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("exists", func(t *testing.T) {
+		mock.ExpectWatch("/keypairs/test")
+		mock.ExpectExists("/keypairs/test").SetVal(1)
+		err := stor.SetKeyPair(context.Background(), "test", &kp)
+		assert.ErrorIs(t, err, ErrKeyPairExists)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+}
+
+func TestRedisStor_GetKeyPair(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectHGetAll("/keypairs/test").SetVal(map[string]string{"public": "pub", "private": "priv"})
+	kp := types.KeyPair{}
+	err := stor.GetKeyPair(context.Background(), "test", &kp)
+	assert.NoError(t, err)
+	assert.Equal(t, types.KeyPair{Public: "pub", Private: "priv"}, kp)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_AddShare(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	share := types.Share{Owner: "owner", Key: "key", Type: "TEXT", Data: "sealed", ReadOnly: true}
+	mock.ExpectTxPipeline()
+	mock.ExpectHSet("/shared/test/owner/key", "owner", "owner", "key", "key", "type", "TEXT", "data", "sealed", "readonly", true, "datakey", "").SetVal(5)
+	mock.ExpectSAdd("/sharedby/owner/key", "test").SetVal(1)
+	mock.ExpectTxPipelineExec()
+	err := stor.AddShare(context.Background(), "test", &share)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_ListShares(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	t.Run("right", func(t *testing.T) {
+		mock.ExpectKeys("/shared/test/*").SetVal([]string{"/shared/test/owner/key", "/shared/test/owner/gone"})
+		mock.ExpectHGetAll("/shared/test/owner/key").SetVal(map[string]string{"owner": "owner", "key": "key", "type": "TEXT", "data": "sealed", "readonly": "1"})
+		mock.ExpectHGetAll("/shared/test/owner/gone").SetVal(map[string]string{})
+		shares, err := stor.ListShares(context.Background(), "test")
+		assert.NoError(t, err)
+		assert.Equal(t, []types.Share{{Owner: "owner", Key: "key", Type: "TEXT", Data: "sealed", ReadOnly: true}}, shares)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("special chars", func(t *testing.T) {
+		mock.ExpectKeys(`/shared/te\*st/*`).SetVal([]string{`/shared/te*st/owner/key`})
+		mock.ExpectHGetAll(`/shared/te*st/owner/key`).SetVal(map[string]string{"owner": "owner", "key": "key"})
+		shares, err := stor.ListShares(context.Background(), "te*st")
+		assert.NoError(t, err)
+		assert.Len(t, shares, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("other recipient", func(t *testing.T) {
+		// entry of recipient "test/owner" is matched by pattern of "test"
+		mock.ExpectKeys("/shared/test/*").SetVal([]string{"/shared/test/owner/other/key"})
+		mock.ExpectHGetAll("/shared/test/owner/other/key").SetVal(map[string]string{"owner": "other", "key": "key"})
+		shares, err := stor.ListShares(context.Background(), "test")
+		assert.NoError(t, err)
+		assert.Empty(t, shares)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("error", func(t *testing.T) {
+		mock.ExpectKeys("/shared/test/*").SetErr(errors.New("db error"))
+		_, err := stor.ListShares(context.Background(), "test")
+		assert.Error(t, err)
+		mock.ClearExpect()
+	})
+}
+
+func TestRedisStor_RemoveShare(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	t.Run("one recipient", func(t *testing.T) {
+		mock.ExpectTxPipeline()
+		mock.ExpectDel("/shared/test/owner/key").SetVal(1)
+		mock.ExpectSRem("/sharedby/owner/key", "test").SetVal(1)
+		mock.ExpectTxPipelineExec()
+		err := stor.RemoveShare(context.Background(), "owner", "key", "test")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("all recipients", func(t *testing.T) {
+		mock.ExpectSMembers("/sharedby/owner/key").SetVal([]string{"one", "two"})
+		mock.ExpectTxPipeline()
+		mock.ExpectDel("/shared/one/owner/key").SetVal(1)
+		mock.ExpectDel("/shared/two/owner/key").SetVal(1)
+		mock.ExpectDel("/sharedby/owner/key").SetVal(1)
+		mock.ExpectTxPipelineExec()
+		err := stor.RemoveShare(context.Background(), "owner", "key", "")
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+}
+
+// expectJournal expects append of record with detail in empty security journal
+func expectJournal(mock redismock.ClientMock, detail string) {
+	match := func(expected, actual []interface{}) error {
+		data := fmt.Sprintf("%s", actual[len(actual)-1])
+		if !strings.Contains(data, `"seq":1,`) || !strings.Contains(data, `"detail":"`+detail+`"`) {
+			return fmt.Errorf("unexpected journal record %s", data)
+		}
+		return nil
+	}
+	mock.ExpectWatch(journalHeadKey)
+	mock.ExpectGet(journalHeadKey).RedisNil()
+	mock.ExpectTxPipeline()
+	mock.CustomMatch(match).ExpectSet(journalHeadKey, "", 0).SetVal("OK")
+	mock.CustomMatch(match).ExpectZAdd(journalKey, redis.Z{Score: 1, Member: ""}).SetVal(1)
+	mock.ExpectTxPipelineExec()
+}
+
+func TestRedisStor_AppendJournal(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	ctx := context.Background()
+	head := `{"seq":4,"kind":"signup","login":"test","time":1,"prev":"aa","hash":"bb"}`
+	t.Run("next record", func(t *testing.T) {
+		next := `{"seq":5,"kind":"seal","time":2,"prev":"bb","hash":"cc"}`
+		mock.ExpectWatch(journalHeadKey)
+		mock.ExpectGet(journalHeadKey).SetVal(head)
+		mock.ExpectTxPipeline()
+		mock.ExpectSet(journalHeadKey, []byte(next), 0).SetVal("OK")
+		mock.ExpectZAdd(journalKey, redis.Z{Score: 5, Member: []byte(next)}).SetVal(1)
+		mock.ExpectTxPipelineExec()
+		err := stor.AppendJournal(ctx, func(prev *types.JournalEntry) (*types.JournalEntry, error) {
+			require.NotNil(t, prev)
+			assert.Equal(t, int64(4), prev.Seq)
+			return &types.JournalEntry{Seq: 5, Kind: "seal", Time: 2, Prev: prev.Hash, Hash: "cc"}, nil
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+	t.Run("nothing to add", func(t *testing.T) {
+		mock.ExpectWatch(journalHeadKey)
+		mock.ExpectGet(journalHeadKey).SetVal(head)
+		err := stor.AppendJournal(ctx, func(prev *types.JournalEntry) (*types.JournalEntry, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		mock.ClearExpect()
+	})
+}
+
+func TestRedisStor_ListJournal(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectZRangeByScore(journalKey, &redis.ZRangeBy{Min: "(0", Max: "+inf", Count: 100}).SetVal([]string{
+		`{"seq":1,"kind":"init","detail":"shares 5, threshold 3","time":1,"prev":"","hash":"aa"}`,
+		`{"seq":2,"kind":"checkpoint","time":2,"prev":"aa","hash":"bb","sig":"c2ln"}`,
+	})
+	entries, err := stor.ListJournal(context.Background(), 0, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []types.JournalEntry{
+		{Seq: 1, Kind: "init", Detail: "shares 5, threshold 3", Time: 1, Hash: "aa"},
+		{Seq: 2, Kind: "checkpoint", Time: 2, Prev: "aa", Hash: "bb", Sig: "c2ln"},
+	}, entries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisStor_GetJournalHead(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectGet(journalHeadKey).SetVal(`{"seq":2,"kind":"checkpoint","time":2,"prev":"aa","hash":"bb","sig":"c2ln"}`)
+	head := types.JournalEntry{}
+	require.NoError(t, stor.GetJournalHead(context.Background(), &head))
+	assert.Equal(t, types.JournalEntry{Seq: 2, Kind: "checkpoint", Time: 2, Prev: "aa", Hash: "bb", Sig: "c2ln"}, head)
+	// empty journal
+	mock.ExpectGet(journalHeadKey).RedisNil()
+	head = types.JournalEntry{}
+	require.NoError(t, stor.GetJournalHead(context.Background(), &head))
+	assert.Zero(t, head.Seq)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestRedisStor_Ping(t *testing.T) {
 	db, mock := redismock.NewClientMock()
 	stor := RedisStor{rdb: db}
 	t.Run("with master key", func(t *testing.T) {
 		mock.ExpectPing().SetVal("")
 		mock.ExpectHGetAll("server").SetVal(map[string]string{"pass": "d073c221d695acfb17eeb835bf8ec1d4ae8b9655", "symmkey": "", "data": "", "type": ""})
+		expectJournal(mock, "ok")
 		err := stor.Ping(context.Background(), []byte("test"))
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -218,8 +842,9 @@ func TestRedisStor_Ping(t *testing.T) {
 	t.Run("wrong hash", func(t *testing.T) {
 		mock.ExpectPing().SetVal("")
 		mock.ExpectHGetAll("server").SetVal(map[string]string{"pass": "c221d695acfb17eeb835bf8ec1d4ae8b9655", "symmkey": "", "data": "", "type": ""})
+		expectJournal(mock, "key doesn't match")
 		err := stor.Ping(context.Background(), []byte("test"))
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, ErrServerKey)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		mock.ClearExpect()
 	})
@@ -233,3 +858,59 @@ func TestRedisStor_Close(t *testing.T) {
 	err = storage.Close()
 	assert.NoError(t, err)
 }
+
+func TestRedisStor_ListVault(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectKeys("/vaultdata/team/infra:*").SetVal([]string{"/vaultdata/team/infra:db", "/vaultdata/team/infra:a/b"})
+	mock.ExpectHMGet("/vaultdata/team/infra:a/b", "type", "symmkey").SetVal([]interface{}{"TEXT", "dk"})
+	mock.ExpectHMGet("/vaultdata/team/infra:db", "type", "symmkey").SetVal([]interface{}{"LOGIN", "dk"})
+	secrets, _, err := stor.ListSecrets(context.Background(), VaultKeyPrefix("team/infra"), types.ListFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []types.SecretInfo{{Name: "a/b", Type: "TEXT", DataKey: true}, {Name: "db", Type: "LOGIN", DataKey: true}}, secrets)
+}
+
+func TestRedisStor_ListVaultMembers(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectHGetAll("/vaultmembers/infra").SetVal(map[string]string{"bob": "read", "alice": "admin"})
+	members, err := stor.ListVaultMembers(context.Background(), "infra")
+	require.NoError(t, err)
+	assert.Equal(t, []types.VaultMember{{Login: "alice", Role: "admin"}, {Login: "bob", Role: "read"}}, members)
+}
+
+func TestRedisStor_ListVaultSecrets(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	mock.ExpectKeys("/vaultdata/infra:*").SetVal([]string{"/vaultdata/infra:db"})
+	mock.ExpectHGetAll("/vaultdata/infra:db").SetVal(map[string]string{"data": "enc", "type": "TEXT"})
+	secrets, err := stor.ListVaultSecrets(context.Background(), "infra")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]types.StorageModel{"db": {Data: "enc", Type: "TEXT"}}, secrets)
+}
+
+func TestRedisStor_RotateVault(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	stor := RedisStor{rdb: db}
+	rot := types.VaultRotation{Removed: "bob", Version: 1, Keys: map[string]string{"alice": "sealed"}}
+	t.Run("rotated meanwhile", func(t *testing.T) {
+		mock.ExpectWatch("/vaults/infra", "/vaultmembers/infra")
+		mock.ExpectHGetAll("/vaults/infra").SetVal(map[string]string{"version": "2"})
+		assert.ErrorIs(t, stor.RotateVault(context.Background(), "infra", &rot), ErrVaultChanged)
+		mock.ClearExpect()
+	})
+	t.Run("new member", func(t *testing.T) {
+		mock.ExpectWatch("/vaults/infra", "/vaultmembers/infra")
+		mock.ExpectHGetAll("/vaults/infra").SetVal(map[string]string{"version": "1"})
+		mock.ExpectHKeys("/vaultmembers/infra").SetVal([]string{"alice", "bob", "carol"})
+		assert.ErrorIs(t, stor.RotateVault(context.Background(), "infra", &rot), ErrVaultChanged)
+		mock.ClearExpect()
+	})
+	t.Run("removed isn't member", func(t *testing.T) {
+		mock.ExpectWatch("/vaults/infra", "/vaultmembers/infra")
+		mock.ExpectHGetAll("/vaults/infra").SetVal(map[string]string{"version": "1"})
+		mock.ExpectHKeys("/vaultmembers/infra").SetVal([]string{"alice"})
+		assert.ErrorIs(t, stor.RotateVault(context.Background(), "infra", &rot), ErrVaultChanged)
+		mock.ClearExpect()
+	})
+}
@@ -0,0 +1,123 @@
+package strength
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	hashPrefixLength = 5  // length of hash prefix in HIBP range files
+	hashLength       = 40 // length of SHA-1 hex
+)
+
+// Breached returns how many times password is found in HIBP Pwned Passwords SHA-1 data at path.
+// Path is either directory of range files "<PREFIX>.txt" with "SUFFIX:COUNT" lines,
+// or single file of "HASH:COUNT" lines ordered by hash.
+func Breached(path string, passwd string) (int, error) {
+	sum := sha1.Sum([]byte(passwd))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if info.IsDir() {
+		return searchRange(path, hash)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return searchSorted(f, info.Size(), hash)
+}
+
+// searchRange looks for hash suffix in range file of hash prefix, missing range file means
+// that no password with the prefix is breached.
+func searchRange(dir string, hash string) (int, error) {
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+	f, err := os.Open(filepath.Join(dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		f, err = os.Open(filepath.Join(dir, prefix))
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if h, count, ok := parseLine(scanner.Text()); ok && h == suffix {
+			return count, nil
+		}
+	}
+	return 0, scanner.Err()
+}
+
+// searchSorted looks for hash in sorted file by binary search.
+func searchSorted(r io.ReaderAt, size int64, hash string) (int, error) {
+	lo, hi := int64(0), size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		line, next, err := lineFrom(r, mid, size)
+		if err != nil {
+			return 0, err
+		}
+		h, count, ok := parseLine(line)
+		switch {
+		case !ok || len(h) != hashLength || hash < h:
+			hi = mid
+		case hash == h:
+			return count, nil
+		default:
+			lo = next
+		}
+	}
+	return 0, nil
+}
+
+// lineFrom returns first line starting at offset off or later and offset of the next line.
+func lineFrom(r io.ReaderAt, off int64, size int64) (string, int64, error) {
+	start := off
+	if off > 0 {
+		start = off - 1
+	}
+	reader := bufio.NewReader(io.NewSectionReader(r, start, size-start))
+	if off > 0 {
+		// skip rest of line which contains previous byte
+		skip, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return "", size, nil
+		}
+		if err != nil {
+			return "", 0, err
+		}
+		start += int64(len(skip))
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", 0, err
+	}
+	return strings.TrimRight(line, "\r\n"), start + int64(len(line)), nil
+}
+
+// parseLine parses "HASH:COUNT" line.
+func parseLine(line string) (string, int, bool) {
+	hash, cnt, ok := strings.Cut(strings.TrimSpace(line), ":")
+	if !ok {
+		return "", 0, false
+	}
+	count, err := strconv.Atoi(cnt)
+	if err != nil {
+		return "", 0, false
+	}
+	return strings.ToUpper(hash), count, true
+}
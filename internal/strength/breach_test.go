@@ -0,0 +1,59 @@
+package strength
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestBreached(t *testing.T) {
+	passwords := []string{"123456", "password", "qwerty", "letmein", "dragon", "monkey", "abc123"}
+	lines := make([]string, 0)
+	for i, p := range passwords {
+		lines = append(lines, fmt.Sprintf("%s:%d", sha1Hex(p), i+1))
+	}
+	sort.Strings(lines)
+
+	t.Run("sorted file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pwned.txt")
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o600))
+		for i, p := range passwords {
+			count, err := Breached(path, p)
+			require.NoError(t, err)
+			assert.Equal(t, i+1, count, p)
+		}
+		count, err := Breached(path, "not breached")
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+	t.Run("range dir", func(t *testing.T) {
+		dir := t.TempDir()
+		hash := sha1Hex("qwerty")
+		data := fmt.Sprintf("0000000000000000000000000000000000A:1\n%s:42\n", hash[hashPrefixLength:])
+		require.NoError(t, os.WriteFile(filepath.Join(dir, hash[:hashPrefixLength]+".txt"), []byte(data), 0o600))
+		count, err := Breached(dir, "qwerty")
+		require.NoError(t, err)
+		assert.Equal(t, 42, count)
+		// range file of prefix is missing
+		count, err = Breached(dir, "not breached")
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+	t.Run("wrong path", func(t *testing.T) {
+		_, err := Breached(filepath.Join(t.TempDir(), "none"), "qwerty")
+		require.Error(t, err)
+	})
+}
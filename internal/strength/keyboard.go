@@ -0,0 +1,80 @@
+package strength
+
+import "unicode"
+
+type keyPos struct {
+	row, col int
+}
+
+// qwerty rows, each row is shifted right by half of key relative to previous one
+var keyboardRows = []string{"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./"}
+
+// shiftedKeys maps shifted chars to their keys
+var shiftedKeys = map[rune]rune{
+	'~': '`', '!': '1', '@': '2', '#': '3', '$': '4', '%': '5', '^': '6', '&': '7', '*': '8', '(': '9', ')': '0', '_': '-', '+': '=',
+	'{': '[', '}': ']', '|': '\\', ':': ';', '"': '\'', '<': ',', '>': '.', '?': '/',
+}
+
+var (
+	keyPositions   = newKeyPositions()
+	keyboardDegree = averageDegree()
+)
+
+func newKeyPositions() map[rune]keyPos {
+	out := make(map[rune]keyPos)
+	for row, keys := range keyboardRows {
+		for col, key := range []rune(keys) {
+			out[key] = keyPos{row: row, col: col}
+		}
+	}
+	return out
+}
+
+// averageDegree returns average count of neighbours of keys.
+func averageDegree() float64 {
+	count := 0
+	for a := range keyPositions {
+		for b := range keyPositions {
+			if _, ok := adjacent(a, b); ok {
+				count++
+			}
+		}
+	}
+	return float64(count) / float64(len(keyPositions))
+}
+
+// adjacent returns direction from key a to key b when they are neighbours.
+func adjacent(a, b rune) (int, bool) {
+	pa, ok := keyPosition(a)
+	if !ok {
+		return 0, false
+	}
+	pb, ok := keyPosition(b)
+	if !ok {
+		return 0, false
+	}
+	dr, dc := pb.row-pa.row, pb.col-pa.col
+	switch {
+	case dr == 0 && dc == -1:
+		return 0, true
+	case dr == 0 && dc == 1:
+		return 1, true
+	case dr == -1 && dc == 0:
+		return 2, true
+	case dr == -1 && dc == 1:
+		return 3, true
+	case dr == 1 && dc == -1:
+		return 4, true
+	case dr == 1 && dc == 0:
+		return 5, true
+	}
+	return 0, false
+}
+
+func keyPosition(r rune) (keyPos, bool) {
+	if key, ok := shiftedKeys[r]; ok {
+		r = key
+	}
+	pos, ok := keyPositions[unicode.ToLower(r)]
+	return pos, ok
+}
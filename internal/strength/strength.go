@@ -0,0 +1,447 @@
+/*
+Package strength contents methods for estimation of password strength and check of known-breached passwords.
+*/
+package strength
+
+import (
+	_ "embed"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hrapovd1/gokeepas/internal/passgen"
+)
+
+// Estimation is zxcvbn-style: password is split into patterns (dictionary words, keyboard
+// walks, sequences, repeats, dates), each of them costs estimated count of guesses, rest
+// chars are guessed by brute force. All guesses are kept as log10 to avoid overflow.
+const (
+	maxLength          = 100 // longer part of password is estimated as brute force
+	minTokenLength     = 3   // minimal length of pattern
+	bruteforceGuesses  = 1.0 // log10 of guesses of one char
+	minSubmatchGuesses = 1.7 // log10 of minimal guesses of multi char pattern
+	minYearSpace       = 20  // minimal distance of year from current one
+)
+
+const (
+	warnCommon   = "This is similar to a commonly used password"
+	warnWord     = "A word by itself is easy to guess"
+	warnUser     = "Password contains your login"
+	warnSpatial  = "Keyboard patterns like qwerty are easy to guess"
+	warnSequence = "Sequences like abc or 6543 are easy to guess"
+	warnRepeat   = `Repeats like "aaa" or "abcabc" are easy to guess`
+	warnDate     = "Dates and years are easy to guess"
+	warnShort    = "Short passwords are easy to guess"
+)
+
+var labels = [...]string{"too guessable", "very guessable", "somewhat guessable", "safely unguessable", "very unguessable"}
+
+//go:embed common_passwords.txt
+var commonList string
+
+var (
+	commonDict  = rankedDict(strings.Fields(commonList))
+	englishDict = flatDict(passgen.Words())
+	leetTables  = []map[rune]rune{
+		{'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '1': 'i', '!': 'i', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z'},
+		{'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '1': 'l', '|': 'l', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z'},
+	}
+	dateDMY = regexp.MustCompile(`^(\d{1,2})([-/._ ]?)(\d{1,2})([-/._ ]?)(\d{4}|\d{2})$`)
+	dateYMD = regexp.MustCompile(`^(\d{4})([-/._ ]?)(\d{1,2})([-/._ ]?)(\d{1,2})$`)
+)
+
+// Result implements estimation of password strength.
+type Result struct {
+	Score   int     // 0 (too guessable) .. 4 (very unguessable)
+	Guesses float64 // log10 of estimated count of guesses
+	Warning string  // explanation of the weakest pattern of not strong password
+}
+
+// String returns text label of score.
+func (r Result) String() string {
+	return labels[r.Score]
+}
+
+type dictionary struct {
+	words   map[string]float64 // word -> log10 of its rank
+	warning string
+}
+
+type match struct {
+	i, j    int     // first and last index of token
+	guesses float64 // log10
+	warning string
+}
+
+// Estimate returns strength of password, userInputs (login, name, etc.) are treated as dictionary words.
+func Estimate(passwd string, userInputs ...string) Result {
+	pw := []rune(passwd)
+	extra := 0.0
+	if len(pw) > maxLength {
+		extra = float64(len(pw)-maxLength) * bruteforceGuesses
+		pw = pw[:maxLength]
+	}
+	dicts := []dictionary{
+		{commonDict, warnCommon},
+		{englishDict, warnWord},
+		{rankedDict(userInputs), warnUser},
+	}
+	guesses, seq := mostGuessable(pw, dicts)
+	res := Result{Guesses: guesses + extra}
+	res.Score = score(res.Guesses)
+	if res.Score > 2 {
+		return res
+	}
+	res.Warning = warnShort
+	longest := -1
+	for _, m := range seq {
+		if l := m.j - m.i; l > longest {
+			longest, res.Warning = l, m.warning
+		}
+	}
+	return res
+}
+
+// mostGuessable returns minimal guesses of password and sequence of patterns giving them.
+func mostGuessable(pw []rune, dicts []dictionary) (float64, []match) {
+	n := len(pw)
+	if n == 0 {
+		return 0, nil
+	}
+	byEnd := make([][]match, n)
+	for _, m := range findMatches(pw, dicts) {
+		byEnd[m.j] = append(byEnd[m.j], m)
+	}
+	best := make([]float64, n+1)
+	from := make([]*match, n+1)
+	for k := 1; k <= n; k++ {
+		best[k] = best[k-1] + bruteforceGuesses
+		for idx := range byEnd[k-1] {
+			m := &byEnd[k-1][idx]
+			if g := best[m.i] + m.guesses; g < best[k] {
+				best[k], from[k] = g, m
+			}
+		}
+	}
+	seq := make([]match, 0)
+	for k := n; k > 0; {
+		if from[k] == nil {
+			k--
+			continue
+		}
+		seq = append(seq, *from[k])
+		k = from[k].i
+	}
+	return best[n], seq
+}
+
+func findMatches(pw []rune, dicts []dictionary) []match {
+	out := dictionaryMatches(pw, dicts)
+	out = append(out, spatialMatches(pw)...)
+	out = append(out, sequenceMatches(pw)...)
+	out = append(out, repeatMatches(pw, dicts)...)
+	return append(out, dateMatches(pw)...)
+}
+
+func newMatch(i, j int, guesses float64, warning string) match {
+	return match{i: i, j: j, guesses: math.Max(guesses, minSubmatchGuesses), warning: warning}
+}
+
+func dictionaryMatches(pw []rune, dicts []dictionary) []match {
+	out := make([]match, 0)
+	lower := make([]rune, len(pw))
+	for i, r := range pw {
+		lower[i] = unicode.ToLower(r)
+	}
+	for i := range lower {
+		for j := i + minTokenLength - 1; j < len(lower); j++ {
+			token := string(lower[i : j+1])
+			upper := uppercaseVariations(pw[i : j+1])
+			reversed := reverse(token)
+			for _, d := range dicts {
+				if g, ok := d.words[token]; ok {
+					out = append(out, newMatch(i, j, g+upper, d.warning))
+				}
+				if g, ok := d.words[reversed]; ok && reversed != token {
+					out = append(out, newMatch(i, j, g+upper+math.Log10(2), d.warning))
+				}
+				for _, table := range leetTables {
+					sub, count := unleet(token, table)
+					if count == 0 {
+						continue
+					}
+					if g, ok := d.words[sub]; ok {
+						out = append(out, newMatch(i, j, g+upper+float64(count)*math.Log10(2), d.warning))
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+func spatialMatches(pw []rune) []match {
+	out := make([]match, 0)
+	for i := 0; i < len(pw); {
+		j, dir, turns := i, -1, 0
+		for j+1 < len(pw) {
+			d, ok := adjacent(pw[j], pw[j+1])
+			if !ok {
+				break
+			}
+			if d != dir {
+				turns++
+				dir = d
+			}
+			j++
+		}
+		if j-i+1 < minTokenLength {
+			i++
+			continue
+		}
+		out = append(out, newMatch(i, j, spatialGuesses(pw[i:j+1], turns), warnSpatial))
+		i = j + 1
+	}
+	return out
+}
+
+func sequenceMatches(pw []rune) []match {
+	out := make([]match, 0)
+	for i := 0; i+1 < len(pw); {
+		delta := pw[i+1] - pw[i]
+		if delta != 1 && delta != -1 {
+			i++
+			continue
+		}
+		j := i + 1
+		for j+1 < len(pw) && pw[j+1]-pw[j] == delta {
+			j++
+		}
+		if j-i+1 >= minTokenLength {
+			base := 26.0
+			switch {
+			case strings.ContainsRune("aAzZ019", pw[i]):
+				base = 4
+			case unicode.IsDigit(pw[i]):
+				base = 10
+			}
+			if delta < 0 {
+				base *= 2
+			}
+			out = append(out, newMatch(i, j, math.Log10(base*float64(j-i+1)), warnSequence))
+		}
+		i = j
+	}
+	return out
+}
+
+func repeatMatches(pw []rune, dicts []dictionary) []match {
+	out := make([]match, 0)
+	for i := 0; i < len(pw); {
+		// the longest repeat starting at i
+		bestUnit, bestCount := 0, 0
+		for unit := 1; i+2*unit <= len(pw); unit++ {
+			count := 1
+			for i+(count+1)*unit <= len(pw) && string(pw[i+count*unit:i+(count+1)*unit]) == string(pw[i:i+unit]) {
+				count++
+			}
+			if count >= 2 && unit*count > bestUnit*bestCount {
+				bestUnit, bestCount = unit, count
+			}
+		}
+		if bestUnit*bestCount < minTokenLength {
+			i++
+			continue
+		}
+		unitGuesses, _ := mostGuessable(pw[i:i+bestUnit], dicts)
+		out = append(out, newMatch(i, i+bestUnit*bestCount-1, unitGuesses+math.Log10(float64(bestCount)), warnRepeat))
+		i += bestUnit * bestCount
+	}
+	return out
+}
+
+func dateMatches(pw []rune) []match {
+	out := make([]match, 0)
+	now := time.Now().Year()
+	for i := range pw {
+		for j := i + 3; j < len(pw) && j < i+10; j++ {
+			token := string(pw[i : j+1])
+			if year, err := strconv.Atoi(token); err == nil && len(token) == 4 {
+				if year >= 1900 && year <= 2099 {
+					out = append(out, newMatch(i, j, math.Log10(yearSpace(year, now)), warnDate))
+				}
+				continue
+			}
+			if year, sep, ok := parseDate(token); ok {
+				g := math.Log10(365 * yearSpace(year, now))
+				if sep {
+					g += math.Log10(4)
+				}
+				out = append(out, newMatch(i, j, g, warnDate))
+			}
+		}
+	}
+	return out
+}
+
+// parseDate returns year of date token and whether token contains separators.
+func parseDate(token string) (int, bool, bool) {
+	type date struct {
+		day, month, year int
+	}
+	candidates := make([]date, 0)
+	sep := ""
+	if m := dateYMD.FindStringSubmatch(token); m != nil && m[2] == m[4] {
+		sep = m[2]
+		candidates = append(candidates, date{day: atoi(m[5]), month: atoi(m[3]), year: atoi(m[1])})
+	}
+	if m := dateDMY.FindStringSubmatch(token); m != nil && m[2] == m[4] {
+		sep = m[2]
+		year := atoi(m[5])
+		if len(m[5]) == 2 {
+			year += 1900
+			if year < 1950 {
+				year += 100
+			}
+		}
+		// day and month can be in both orders
+		candidates = append(candidates,
+			date{day: atoi(m[1]), month: atoi(m[3]), year: year},
+			date{day: atoi(m[3]), month: atoi(m[1]), year: year},
+		)
+	}
+	for _, d := range candidates {
+		if d.month >= 1 && d.month <= 12 && d.day >= 1 && d.day <= 31 && d.year >= 1900 && d.year <= 2099 {
+			return d.year, sep != "", true
+		}
+	}
+	return 0, false, false
+}
+
+func yearSpace(year, now int) float64 {
+	return math.Max(math.Abs(float64(year-now)), minYearSpace)
+}
+
+// score converts guesses to score as zxcvbn does.
+func score(guesses float64) int {
+	switch {
+	case guesses < 3:
+		return 0
+	case guesses < 6:
+		return 1
+	case guesses < 8:
+		return 2
+	case guesses < 10:
+		return 3
+	}
+	return 4
+}
+
+// uppercaseVariations returns log10 of count of upper case variants of token.
+func uppercaseVariations(token []rune) float64 {
+	upper, lower := 0, 0
+	for _, r := range token {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 0
+	}
+	// all upper, first upper and last upper are common
+	if lower == 0 || upper == 1 && (unicode.IsUpper(token[0]) || unicode.IsUpper(token[len(token)-1])) {
+		return math.Log10(2)
+	}
+	variations := 0.0
+	for k := 1; k <= upper && k <= lower; k++ {
+		variations += binomial(upper+lower, k)
+	}
+	return math.Log10(variations)
+}
+
+// spatialGuesses returns log10 of guesses of keyboard walk with turns changes of direction.
+func spatialGuesses(token []rune, turns int) float64 {
+	guesses := 0.0
+	for i := 2; i <= len(token); i++ {
+		for t := 1; t <= turns && t <= i-1; t++ {
+			guesses += binomial(i-1, t-1) * float64(len(keyPositions)) * math.Pow(keyboardDegree, float64(t))
+		}
+	}
+	shifted := 0
+	for _, r := range token {
+		if _, ok := shiftedKeys[r]; ok || unicode.IsUpper(r) {
+			shifted++
+		}
+	}
+	switch {
+	case shifted == len(token):
+		guesses *= 2
+	case shifted > 0:
+		variations := 0.0
+		for k := 1; k <= shifted && k <= len(token)-shifted; k++ {
+			variations += binomial(len(token), k)
+		}
+		guesses *= variations
+	}
+	return math.Log10(guesses)
+}
+
+func binomial(n, k int) float64 {
+	out := 1.0
+	for i := 1; i <= k; i++ {
+		out = out * float64(n-k+i) / float64(i)
+	}
+	return out
+}
+
+func unleet(token string, table map[rune]rune) (string, int) {
+	count := 0
+	out := []rune(token)
+	for i, r := range out {
+		if sub, ok := table[r]; ok {
+			out[i] = sub
+			count++
+		}
+	}
+	return string(out), count
+}
+
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+func atoi(s string) int {
+	v, _ := strconv.Atoi(s)
+	return v
+}
+
+// rankedDict returns dictionary where guesses of word is its rank.
+func rankedDict(words []string) map[string]float64 {
+	out := make(map[string]float64, len(words))
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if _, ok := out[w]; !ok && w != "" {
+			out[w] = math.Log10(float64(i + 1))
+		}
+	}
+	return out
+}
+
+// flatDict returns dictionary where guesses of each word is size of dictionary.
+func flatDict(words []string) map[string]float64 {
+	out := make(map[string]float64, len(words))
+	for _, w := range words {
+		out[strings.ToLower(w)] = math.Log10(float64(len(words)))
+	}
+	return out
+}
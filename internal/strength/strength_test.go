@@ -0,0 +1,77 @@
+package strength
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name     string
+		passwd   string
+		maxScore int
+		minScore int
+		warning  string
+	}{
+		{"empty", "", 0, 0, warnShort},
+		{"common", "123456", 0, 0, warnCommon},
+		{"common with case", "Password", 0, 0, warnCommon},
+		{"leet", "P@ssw0rd", 1, 0, warnCommon},
+		{"reversed", "drowssap", 1, 0, warnCommon},
+		{"keyboard", "dfghjkl", 1, 0, warnSpatial},
+		{"sequence", "lmnopqrst", 1, 0, warnSequence},
+		{"repeat", "xkxkxkxkxk", 1, 0, warnRepeat},
+		{"date", "25.12.1987", 1, 0, warnDate},
+		{"word", "blissful", 1, 0, warnWord},
+		{"passphrase", "correct horse battery staple", 4, 4, ""},
+		{"random", "x7#Kq2!mZp9@vR", 4, 4, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Estimate(tt.passwd)
+			assert.LessOrEqual(t, res.Score, tt.maxScore, res.Guesses)
+			assert.GreaterOrEqual(t, res.Score, tt.minScore, res.Guesses)
+			assert.Equal(t, tt.warning, res.Warning)
+		})
+	}
+	t.Run("user inputs", func(t *testing.T) {
+		assert.Greater(t, Estimate("zorgavik2024").Score, Estimate("zorgavik2024", "Zorgavik").Score)
+		assert.Equal(t, warnUser, Estimate("zorgavik2024", "Zorgavik").Warning)
+	})
+	t.Run("long password", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(1))
+		pw := make([]byte, 2*maxLength)
+		for i := range pw {
+			pw[i] = byte('!' + rnd.Intn(94))
+		}
+		res := Estimate(string(pw))
+		assert.Equal(t, 4, res.Score)
+		assert.Greater(t, res.Guesses, float64(maxLength))
+	})
+	assert.Equal(t, "too guessable", Estimate("qwerty").String())
+}
+
+func Test_uppercaseVariations(t *testing.T) {
+	assert.Equal(t, 0.0, uppercaseVariations([]rune("word")))
+	assert.InDelta(t, 0.301, uppercaseVariations([]rune("Word")), 0.001)
+	assert.InDelta(t, 0.301, uppercaseVariations([]rune("WORD")), 0.001)
+	// C(4,1)+C(4,2)
+	assert.InDelta(t, 1.0, uppercaseVariations([]rune("wOrD")), 0.001)
+}
+
+func Test_parseDate(t *testing.T) {
+	year, sep, ok := parseDate("1987-12-25")
+	assert.True(t, ok)
+	assert.True(t, sep)
+	assert.Equal(t, 1987, year)
+	year, sep, ok = parseDate("120399")
+	assert.True(t, ok)
+	assert.False(t, sep)
+	assert.Equal(t, 1999, year)
+	_, _, ok = parseDate("99.99.99")
+	assert.False(t, ok)
+	_, _, ok = parseDate("12.03-99")
+	assert.False(t, ok)
+}
@@ -39,10 +39,154 @@ type Cart struct {
 	Info    []string `json:"info,omitempty"`
 }
 
+// OTP implements TOTP generator secret.
+type OTP struct {
+	Secret    string   `json:"secret"` // base32 encoded seed
+	Algorithm string   `json:"algorithm"`
+	Digits    int      `json:"digits"`
+	Period    int      `json:"period"`
+	Issuer    string   `json:"issuer,omitempty"`
+	Account   string   `json:"account,omitempty"`
+	Info      []string `json:"info,omitempty"`
+}
+
 // StorageModel implements storage db model.
 type StorageModel struct {
 	PassHash string `redis:"pass"`
-	SymmKey  string `redis:"symmkey"`
+	SymmKey  string `redis:"symmkey"` // user key wrapped by server, or data key of secret wrapped by user key
 	Data     string `redis:"data"`
 	Type     string `redis:"type"`
 }
+
+// SecretInfo implements entry of secrets list, it doesn't have secret data.
+type SecretInfo struct {
+	Name    string // key of secret without owner prefix
+	Type    string
+	DataKey bool // secret is encrypted with its own data key
+}
+
+// ListFilter selects page of secrets list sorted by name.
+type ListFilter struct {
+	Prefix string   // name prefix of secrets
+	Types  []string // types of secrets, empty means all types
+	After  string   // list starts after this name
+	Limit  int      // max count of secrets, 0 means all
+}
+
+// Kinds of secret change events, names match kinds of WatchEvent.
+const (
+	EventAdd    = "ADD"
+	EventUpdate = "UPDATE"
+	EventRename = "RENAME"
+	EventRemove = "REMOVE"
+)
+
+// Event implements change of secret published to watchers of its owner.
+type Event struct {
+	Kind   string `json:"kind"`
+	Key    string `json:"key"`              // key of secret without owner prefix
+	NewKey string `json:"newkey,omitempty"` // new key of renamed secret
+	Type   string `json:"type,omitempty"`
+	Time   int64  `json:"time"`          // unix milliseconds
+	Seq    int64  `json:"seq,omitempty"` // sequence number in change log of owner, 0 when change isn't logged
+}
+
+// Changes implements part of change log of secrets owner.
+type Changes struct {
+	Events []Event // changes ordered by sequence number
+	Seq    int64   // sequence number of last change in log
+	Reset  bool    // requested changes aren't kept anymore
+}
+
+// AuditEvent implements record of rpc call in audit log.
+type AuditEvent struct {
+	Login  string `json:"login,omitempty"` // login of caller, empty when it isn't known
+	Method string `json:"method"`          // full name of rpc method
+	Key    string `json:"key,omitempty"`   // key of secret, keys of batch are joined by comma
+	Code   string `json:"code"`            // grpc status code of result
+	Peer   string `json:"peer,omitempty"`  // address of client
+	Time   int64  `json:"time"`            // unix milliseconds
+}
+
+// JournalEntry implements record of security journal, each record keeps hash of previous one.
+type JournalEntry struct {
+	Seq    int64  `json:"seq"`              // number of record, records are numbered from 1 without gaps
+	Kind   string `json:"kind"`             // kind of event
+	Login  string `json:"login,omitempty"`  // login of user, empty for server events
+	Detail string `json:"detail,omitempty"` // result or details of event
+	Time   int64  `json:"time"`             // unix milliseconds
+	Prev   string `json:"prev"`             // hex sha256 of previous record, empty for the first one
+	Hash   string `json:"hash"`             // hex sha256 of record without hash and signature
+	Sig    string `json:"sig,omitempty"`    // base64 ed25519 signature of hash, only in checkpoints
+}
+
+// Session implements user session db model, it keeps hash of refresh token.
+type Session struct {
+	Login       string `redis:"login"`
+	RefreshHash string `redis:"refresh"`
+}
+
+// TwoFactor implements user's two-factor authentication db model.
+type TwoFactor struct {
+	Seed     string `redis:"seed"`     // TOTP seed encrypted by server key
+	Enabled  bool   `redis:"enabled"`  // false until seed is confirmed by code
+	LastStep int64  `redis:"laststep"` // last used time step, protects from replay
+	Recovery string `redis:"recovery"` // hashes of unused recovery codes separated by ','
+}
+
+// Lockout implements db model of failed login attempts of login or client address.
+type Lockout struct {
+	Failures int64 `redis:"failures"` // count of failed attempts in a row
+	Until    int64 `redis:"until"`    // unix ms time, attempts are rejected until it
+}
+
+// KeyPair implements user's X25519 keypair db model for sharing secrets.
+type KeyPair struct {
+	Public  string `redis:"public"`  // base64 public key
+	Private string `redis:"private"` // private key encrypted by user symmetric key on client
+}
+
+// Share implements db model of secret shared with other user.
+type Share struct {
+	Owner    string `redis:"owner"`
+	Key      string `redis:"key"`
+	Type     string `redis:"type"`
+	Data     string `redis:"data"` // secret sealed to recipient's public key or encrypted with data key
+	ReadOnly bool   `redis:"readonly"`
+	DataKey  string `redis:"datakey"` // data key of secret sealed to recipient's public key
+}
+
+// SealConfig implements db model of master key splitting, it is created by server init.
+type SealConfig struct {
+	Shares      int    `redis:"shares"`     // count of key shares
+	Threshold   int    `redis:"threshold"`  // count of shares to unseal server
+	ShareHashes string `redis:"hashes"`     // sha256 of key shares separated by ','
+	JournalKey  string `redis:"journalkey"` // base64 ed25519 public key of security journal checkpoints
+}
+
+// Roles of team vault members.
+const (
+	VaultRoleAdmin = "admin" // reads and writes secrets, manages members
+	VaultRoleWrite = "write" // reads and writes secrets
+	VaultRoleRead  = "read"  // only reads secrets
+)
+
+// Vault implements team vault db model.
+type Vault struct {
+	Version int64 `redis:"version"` // version of vault key, it is increased on rotation
+}
+
+// VaultMember implements member of team vault.
+type VaultMember struct {
+	Login    string
+	Role     string
+	VaultKey string // vault key sealed to member's public key
+}
+
+// VaultRotation implements replacement of vault key, it is done by admin's client.
+type VaultRotation struct {
+	Removed string                  // login of removed member, may be empty
+	Version int64                   // version of vault key which is replaced
+	Keys    map[string]string       // new vault key sealed to each of remaining members
+	Secrets map[string]StorageModel // all vault secrets encrypted with new vault key
+}